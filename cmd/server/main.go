@@ -38,6 +38,14 @@ func main() {
 		"Whether to enable rate limiting for tool calls. When false, no rate limiting will be applied")
 	transport := flag.String("transport", getDefaultTransport(),
 		"Transport protocol to use: 'sse' or 'streamable-http'. Can also be set via MCP_TRANSPORT environment variable")
+	debugImages := flag.String("debug-images", "",
+		"Comma-separated allowlist of images the debug_pod tool may run (entries ending in '*' match by prefix). "+
+			"Only used with --read-write; the debug_pod tool is disabled while the allowlist is empty")
+	maxWaitTimeout := flag.Duration("max-wait-timeout", mcp.DefaultMaxWaitTimeout,
+		"Maximum time a wait_for tool call may wait for a resource to reach a state (e.g., 10m)")
+	promptsDir := flag.String("prompts-dir", "",
+		"Directory of YAML prompt templates to serve as MCP prompts along with the built-in ones, "+
+			"which templates of the same name replace")
 
 	// Impersonation flags
 	enableImpersonation := flag.Bool("enable-impersonation", false,
@@ -62,6 +70,11 @@ func main() {
 
 	flag.Parse()
 
+	// Fail fast rather than serving the built-in prompts only
+	if err := mcp.CheckPromptsDir(*promptsDir); err != nil {
+		log.Fatalf("Invalid prompts directory: %v", err)
+	}
+
 	// Create a context that can be cancelled
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
@@ -106,6 +119,9 @@ func main() {
 		ImpersonationJWKSURL:     *impersonationJWKSURL,
 		ImpersonationJWTIssuer:   *impersonationJWTIssuer,
 		ImpersonationJWTAudience: *impersonationJWTAudience,
+		DebugImages:              splitList(*debugImages),
+		MaxWaitTimeout:           *maxWaitTimeout,
+		PromptsDir:               *promptsDir,
 	}
 
 	// Create MCP server using the helper function
@@ -222,6 +238,17 @@ func getDefaultAddress() string {
 	return fmt.Sprintf(":%d", port)
 }
 
+// splitList splits a comma-separated flag value, dropping empty entries.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // getDefaultTransport returns the transport to use based on MCP_TRANSPORT environment variable.
 // If the environment variable is not set, returns "sse".
 // Valid values are "sse" and "streamable-http".
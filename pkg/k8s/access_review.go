@@ -0,0 +1,203 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// AccessCheck is an action to check with CanI. Either Resource or
+// NonResourceURL is set.
+type AccessCheck struct {
+	Verb        string `json:"verb"`
+	Group       string `json:"group,omitempty"`
+	Resource    string `json:"resource,omitempty"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+	// Namespace is empty for cluster-scoped resources, or to check the
+	// action in all namespaces.
+	Namespace      string `json:"namespace,omitempty"`
+	NonResourceURL string `json:"nonResourceURL,omitempty"`
+}
+
+// AccessCheckResult is the result of CanI.
+type AccessCheckResult struct {
+	AccessCheck
+	Allowed bool `json:"allowed"`
+	// Denied is set when an authorizer explicitly denied the action, rather
+	// than no authorizer allowing it.
+	Denied          bool   `json:"denied,omitempty"`
+	Reason          string `json:"reason,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// PermissionRule is a rule of the permissions of the current user, as
+// returned by ListPermissions.
+type PermissionRule struct {
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// PermissionsResult is the result of ListPermissions.
+type PermissionsResult struct {
+	Namespace string           `json:"namespace"`
+	Rules     []PermissionRule `json:"rules"`
+	// Incomplete is set when the rules could not all be evaluated, e.g.
+	// because an authorizer does not support listing rules, so that actions
+	// missing from Rules may still be allowed.
+	Incomplete      bool   `json:"incomplete,omitempty"`
+	EvaluationError string `json:"evaluationError,omitempty"`
+}
+
+// UserInfo is the identity the API server authenticates the client as.
+type UserInfo struct {
+	Username string              `json:"username"`
+	UID      string              `json:"uid,omitempty"`
+	Groups   []string            `json:"groups,omitempty"`
+	Extra    map[string][]string `json:"extra,omitempty"`
+	// ImpersonatedUser is the user the client impersonates, if any. The API
+	// server then authenticates the client as that user.
+	ImpersonatedUser   string   `json:"impersonatedUser,omitempty"`
+	ImpersonatedGroups []string `json:"impersonatedGroups,omitempty"`
+}
+
+// CanI returns whether the current user, impersonated or not, may perform
+// check, using a SelfSubjectAccessReview like `kubectl auth can-i`.
+func (c *Client) CanI(ctx context.Context, check AccessCheck) (*AccessCheckResult, error) {
+	if check.Verb == "" {
+		return nil, fmt.Errorf("verb cannot be empty")
+	}
+	if (check.Resource == "") == (check.NonResourceURL == "") {
+		return nil, fmt.Errorf("exactly one of resource and non-resource URL must be set")
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	review := &authorizationv1.SelfSubjectAccessReview{}
+	if check.NonResourceURL != "" {
+		review.Spec.NonResourceAttributes = &authorizationv1.NonResourceAttributes{
+			Path: check.NonResourceURL,
+			Verb: check.Verb,
+		}
+	} else {
+		review.Spec.ResourceAttributes = &authorizationv1.ResourceAttributes{
+			Namespace:   check.Namespace,
+			Verb:        check.Verb,
+			Group:       check.Group,
+			Resource:    check.Resource,
+			Subresource: check.Subresource,
+			Name:        check.Name,
+		}
+	}
+
+	review, err := clientset.AuthorizationV1().SelfSubjectAccessReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review access: %w", err)
+	}
+	return &AccessCheckResult{
+		AccessCheck:     check,
+		Allowed:         review.Status.Allowed,
+		Denied:          review.Status.Denied,
+		Reason:          review.Status.Reason,
+		EvaluationError: review.Status.EvaluationError,
+	}, nil
+}
+
+// ListPermissions returns the rules of what the current user, impersonated or
+// not, may do in namespace, using a SelfSubjectRulesReview like
+// `kubectl auth can-i --list`. Rules granted cluster-wide are included.
+func (c *Client) ListPermissions(ctx context.Context, namespace string) (*PermissionsResult, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	review := &authorizationv1.SelfSubjectRulesReview{
+		Spec: authorizationv1.SelfSubjectRulesReviewSpec{Namespace: namespace},
+	}
+	review, err := clientset.AuthorizationV1().SelfSubjectRulesReviews().Create(ctx, review, metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to review rules: %w", err)
+	}
+
+	result := &PermissionsResult{
+		Namespace:       namespace,
+		Rules:           []PermissionRule{},
+		Incomplete:      review.Status.Incomplete,
+		EvaluationError: review.Status.EvaluationError,
+	}
+	for _, rule := range review.Status.ResourceRules {
+		result.Rules = append(result.Rules, PermissionRule{
+			Verbs:         rule.Verbs,
+			APIGroups:     rule.APIGroups,
+			Resources:     rule.Resources,
+			ResourceNames: rule.ResourceNames,
+		})
+	}
+	for _, rule := range review.Status.NonResourceRules {
+		result.Rules = append(result.Rules, PermissionRule{
+			Verbs:           rule.Verbs,
+			NonResourceURLs: rule.NonResourceURLs,
+		})
+	}
+	return result, nil
+}
+
+// WhoAmI returns the identity the API server authenticates the client as,
+// using a SelfSubjectReview like `kubectl auth whoami`. API servers older
+// than Kubernetes 1.28 are asked through authentication.k8s.io/v1beta1.
+func (c *Client) WhoAmI(ctx context.Context) (*UserInfo, error) {
+	c.mu.RLock()
+	clientset := c.clientset
+	restConfig := c.restConfig
+	c.mu.RUnlock()
+
+	var user authenticationv1.UserInfo
+	review, err := clientset.AuthenticationV1().SelfSubjectReviews().Create(ctx,
+		&authenticationv1.SelfSubjectReview{}, metav1.CreateOptions{})
+	switch {
+	case err == nil:
+		user = review.Status.UserInfo
+	case apierrors.IsNotFound(err):
+		betaReview, betaErr := clientset.AuthenticationV1beta1().SelfSubjectReviews().Create(ctx,
+			&authenticationv1beta1.SelfSubjectReview{}, metav1.CreateOptions{})
+		if betaErr != nil {
+			return nil, fmt.Errorf("failed to review user: %w", betaErr)
+		}
+		user = betaReview.Status.UserInfo
+	default:
+		return nil, fmt.Errorf("failed to review user: %w", err)
+	}
+
+	info := &UserInfo{
+		Username: user.Username,
+		UID:      user.UID,
+		Groups:   user.Groups,
+	}
+	if len(user.Extra) > 0 {
+		info.Extra = make(map[string][]string, len(user.Extra))
+		for key, values := range user.Extra {
+			info.Extra[key] = values
+		}
+	}
+	if restConfig != nil {
+		info.ImpersonatedUser = restConfig.Impersonate.UserName
+		info.ImpersonatedGroups = restConfig.Impersonate.Groups
+	}
+	sort.Strings(info.Groups)
+	return info, nil
+}
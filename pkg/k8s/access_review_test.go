@@ -0,0 +1,168 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authenticationv1beta1 "k8s.io/api/authentication/v1beta1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func TestCanI(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset()
+	var reviewed *authorizationv1.SelfSubjectAccessReview
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		reviewed = action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		review := reviewed.DeepCopy()
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes != nil && attributes.Verb == "get"
+		review.Status.Denied = attributes != nil && attributes.Verb == "delete"
+		review.Status.Reason = "RBAC: test"
+		return true, review, nil
+	})
+	client := &Client{}
+	client.SetClientset(clientset)
+
+	check := AccessCheck{Verb: "get", Resource: "pods", Subresource: "log", Name: "web", Namespace: "shop"}
+	result, err := client.CanI(context.Background(), check)
+	require.NoError(t, err)
+	assert.Equal(t, &AccessCheckResult{AccessCheck: check, Allowed: true, Reason: "RBAC: test"}, result)
+	assert.Equal(t, &authorizationv1.ResourceAttributes{
+		Namespace: "shop", Verb: "get", Resource: "pods", Subresource: "log", Name: "web",
+	}, reviewed.Spec.ResourceAttributes)
+
+	result, err = client.CanI(context.Background(), AccessCheck{Verb: "delete", Group: "apps", Resource: "deployments"})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.True(t, result.Denied)
+
+	result, err = client.CanI(context.Background(), AccessCheck{Verb: "get", NonResourceURL: "/healthz"})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Nil(t, reviewed.Spec.ResourceAttributes)
+	assert.Equal(t, &authorizationv1.NonResourceAttributes{Path: "/healthz", Verb: "get"},
+		reviewed.Spec.NonResourceAttributes)
+}
+
+func TestCanIInvalid(t *testing.T) {
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset())
+
+	testCases := []struct {
+		name     string
+		check    AccessCheck
+		errorMsg string
+	}{
+		{name: "Missing verb", check: AccessCheck{Resource: "pods"}, errorMsg: "verb cannot be empty"},
+		{name: "Missing resource", check: AccessCheck{Verb: "get"}, errorMsg: "exactly one of"},
+		{
+			name:     "Resource and URL",
+			check:    AccessCheck{Verb: "get", Resource: "pods", NonResourceURL: "/healthz"},
+			errorMsg: "exactly one of",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.CanI(context.Background(), tc.check)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
+
+func TestListPermissions(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview).DeepCopy()
+		assert.Equal(t, "shop", review.Spec.Namespace)
+		review.Status = authorizationv1.SubjectRulesReviewStatus{
+			ResourceRules: []authorizationv1.ResourceRule{
+				{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"tls"}},
+			},
+			NonResourceRules: []authorizationv1.NonResourceRule{
+				{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+			},
+			Incomplete:      true,
+			EvaluationError: "webhook authorizer does not support listing rules",
+		}
+		return true, review, nil
+	})
+	client := &Client{}
+	client.SetClientset(clientset)
+
+	result, err := client.ListPermissions(context.Background(), "shop")
+	require.NoError(t, err)
+	assert.Equal(t, &PermissionsResult{
+		Namespace: "shop",
+		Rules: []PermissionRule{
+			{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"tls"}},
+			{Verbs: []string{"get"}, NonResourceURLs: []string{"/healthz"}},
+		},
+		Incomplete:      true,
+		EvaluationError: "webhook authorizer does not support listing rules",
+	}, result)
+
+	_, err = client.ListPermissions(context.Background(), "")
+	require.Error(t, err)
+}
+
+func TestWhoAmI(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		return true, &authenticationv1.SelfSubjectReview{Status: authenticationv1.SelfSubjectReviewStatus{
+			UserInfo: authenticationv1.UserInfo{
+				Username: "alice@example.com",
+				UID:      "42",
+				Groups:   []string{"system:authenticated", "developers"},
+				Extra:    map[string]authenticationv1.ExtraValue{"scopes": {"openid"}},
+			},
+		}}, nil
+	})
+	client := &Client{}
+	client.SetClientset(clientset)
+	client.SetRestConfigForTest(&rest.Config{Impersonate: rest.ImpersonationConfig{
+		UserName: "alice@example.com",
+		Groups:   []string{"developers"},
+	}})
+
+	info, err := client.WhoAmI(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &UserInfo{
+		Username:           "alice@example.com",
+		UID:                "42",
+		Groups:             []string{"developers", "system:authenticated"},
+		Extra:              map[string][]string{"scopes": {"openid"}},
+		ImpersonatedUser:   "alice@example.com",
+		ImpersonatedGroups: []string{"developers"},
+	}, info)
+}
+
+func TestWhoAmIBeta(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetResource().Version == "v1" {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Resource: "selfsubjectreviews"}, "")
+		}
+		return true, &authenticationv1beta1.SelfSubjectReview{Status: authenticationv1beta1.SelfSubjectReviewStatus{
+			UserInfo: authenticationv1.UserInfo{Username: "kubernetes-admin", Groups: []string{"system:masters"}},
+		}}, nil
+	})
+	client := &Client{}
+	client.SetClientset(clientset)
+
+	info, err := client.WhoAmI(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &UserInfo{Username: "kubernetes-admin", Groups: []string{"system:masters"}}, info)
+}
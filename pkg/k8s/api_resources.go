@@ -0,0 +1,162 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+)
+
+// APIResourceInfo describes a resource served by the API server, like a row
+// of `kubectl api-resources`.
+type APIResourceInfo struct {
+	Name         string   `json:"name"`
+	ShortNames   []string `json:"shortNames,omitempty"`
+	Group        string   `json:"group"`
+	Version      string   `json:"version"`
+	Kind         string   `json:"kind"`
+	Namespaced   bool     `json:"namespaced"`
+	Verbs        []string `json:"verbs,omitempty"`
+	Categories   []string `json:"categories,omitempty"`
+	Subresources []string `json:"subresources,omitempty"`
+	// Preferred is set for the version of the group the server prefers.
+	Preferred bool `json:"preferred"`
+}
+
+// APIResourceFilter selects the resources returned by FindAPIResources. Zero
+// fields do not filter.
+type APIResourceFilter struct {
+	// Group is the API group, if GroupSet. The core group is "".
+	Group    string
+	GroupSet bool
+	// Namespaced selects namespaced or cluster-scoped resources, if set.
+	Namespaced *bool
+	// Verb is a verb the resource must support, e.g. list or patch.
+	Verb string
+	// Category is a category the resource must belong to, e.g. all.
+	Category string
+	// Name is a case-insensitive substring of the name, singular name, kind
+	// or a short name of the resource.
+	Name string
+	// AllVersions includes every version of a group, not only the preferred
+	// one.
+	AllVersions bool
+}
+
+// APIResourcesResult is the result of FindAPIResources.
+type APIResourcesResult struct {
+	Resources []APIResourceInfo `json:"resources"`
+	// FailedGroupVersions lists the group versions whose discovery failed,
+	// which are missing from Resources.
+	FailedGroupVersions []string `json:"failedGroupVersions,omitempty"`
+}
+
+// FindAPIResources returns the resources served by the API server that match
+// filter, with their verbs, short names, categories and subresources, sorted
+// by group and name. Group versions whose discovery fails, such as those of
+// an unavailable aggregated API, are reported rather than failing the call.
+func (c *Client) FindAPIResources(_ context.Context, filter APIResourceFilter) (*APIResourcesResult, error) {
+	c.mu.RLock()
+	discoveryClient := c.discoveryClient
+	c.mu.RUnlock()
+	if discoveryClient == nil {
+		return nil, fmt.Errorf("discovery client is not initialized")
+	}
+
+	result := &APIResourcesResult{Resources: []APIResourceInfo{}}
+	groups, lists, err := discoveryClient.ServerGroupsAndResources()
+	if err != nil {
+		var failed *discovery.ErrGroupDiscoveryFailed
+		if !errors.As(err, &failed) {
+			return nil, fmt.Errorf("failed to get server resources: %w", err)
+		}
+		for gv := range failed.Groups {
+			result.FailedGroupVersions = append(result.FailedGroupVersions, gv.String())
+		}
+		sort.Strings(result.FailedGroupVersions)
+	}
+
+	preferred := map[string]string{}
+	for _, group := range groups {
+		preferred[group.Name] = group.PreferredVersion.Version
+	}
+
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		isPreferred := preferred[gv.Group] == gv.Version
+
+		subresources := map[string][]string{}
+		for _, apiResource := range list.APIResources {
+			if resource, subresource, ok := strings.Cut(apiResource.Name, "/"); ok {
+				subresources[resource] = append(subresources[resource], subresource)
+			}
+		}
+
+		for _, apiResource := range list.APIResources {
+			if strings.Contains(apiResource.Name, "/") || !filter.matches(gv, isPreferred, apiResource) {
+				continue
+			}
+			result.Resources = append(result.Resources, APIResourceInfo{
+				Name:         apiResource.Name,
+				ShortNames:   apiResource.ShortNames,
+				Group:        gv.Group,
+				Version:      gv.Version,
+				Kind:         apiResource.Kind,
+				Namespaced:   apiResource.Namespaced,
+				Verbs:        apiResource.Verbs,
+				Categories:   apiResource.Categories,
+				Subresources: subresources[apiResource.Name],
+				Preferred:    isPreferred,
+			})
+		}
+	}
+
+	sort.SliceStable(result.Resources, func(i, j int) bool {
+		a, b := result.Resources[i], result.Resources[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return a.Name < b.Name
+	})
+	return result, nil
+}
+
+// matches returns whether apiResource, served in gv, passes the filter.
+// isPreferred tells whether gv is the preferred version of its group.
+func (f APIResourceFilter) matches(gv schema.GroupVersion, isPreferred bool, apiResource metav1.APIResource) bool {
+	if f.GroupSet && gv.Group != f.Group {
+		return false
+	}
+	if !f.AllVersions && !isPreferred {
+		return false
+	}
+	if f.Namespaced != nil && apiResource.Namespaced != *f.Namespaced {
+		return false
+	}
+	if f.Verb != "" && !slices.Contains(apiResource.Verbs, f.Verb) {
+		return false
+	}
+	if f.Category != "" && !slices.Contains(apiResource.Categories, f.Category) {
+		return false
+	}
+	if f.Name == "" {
+		return true
+	}
+	name := strings.ToLower(f.Name)
+	for _, candidate := range append([]string{apiResource.Name, apiResource.SingularName, apiResource.Kind},
+		apiResource.ShortNames...) {
+		if strings.Contains(strings.ToLower(candidate), name) {
+			return true
+		}
+	}
+	return false
+}
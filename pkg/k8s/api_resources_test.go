@@ -0,0 +1,151 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func newAPIResourcesTestClient() (*Client, *discoveryfake.FakeDiscovery) {
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{
+					Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"},
+					Verbs: []string{"get", "list", "create", "delete"}, Categories: []string{"all"},
+				},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "pods/exec", Kind: "PodExecOptions", Namespaced: true, Verbs: []string{"create"}},
+				{
+					Name: "nodes", SingularName: "node", Kind: "Node", ShortNames: []string{"no"},
+					Verbs: []string{"get", "list", "patch"},
+				},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{
+					Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true,
+					ShortNames: []string{"deploy"}, Verbs: []string{"get", "list", "patch"}, Categories: []string{"all"},
+				},
+				{Name: "deployments/scale", Kind: "Scale", Namespaced: true, Verbs: []string{"get", "update"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v2",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true, Verbs: []string{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true, Verbs: []string{"get"}},
+			},
+		},
+	}
+	client := &Client{}
+	client.SetDiscoveryClient(fakeDiscovery)
+	return client, fakeDiscovery
+}
+
+func resourceNames(resources []APIResourceInfo) []string {
+	names := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		names = append(names, resource.Group+"/"+resource.Version+"/"+resource.Name)
+	}
+	return names
+}
+
+func TestFindAPIResources(t *testing.T) {
+	client, _ := newAPIResourcesTestClient()
+
+	result, err := client.FindAPIResources(context.Background(), APIResourceFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, result.FailedGroupVersions)
+	assert.Equal(t, []string{"/v1/nodes", "/v1/pods", "apps/v1/deployments", "example.com/v2/widgets"},
+		resourceNames(result.Resources))
+
+	pods := result.Resources[1]
+	assert.Equal(t, APIResourceInfo{
+		Name: "pods", ShortNames: []string{"po"}, Version: "v1", Kind: "Pod", Namespaced: true,
+		Verbs: []string{"get", "list", "create", "delete"}, Categories: []string{"all"},
+		Subresources: []string{"log", "exec"}, Preferred: true,
+	}, pods)
+	assert.Equal(t, []string{"scale"}, result.Resources[2].Subresources)
+}
+
+func TestFindAPIResourcesFilters(t *testing.T) {
+	client, _ := newAPIResourcesTestClient()
+	clusterScoped := false
+
+	testCases := []struct {
+		name     string
+		filter   APIResourceFilter
+		expected []string
+	}{
+		{name: "Core group", filter: APIResourceFilter{GroupSet: true}, expected: []string{"/v1/nodes", "/v1/pods"}},
+		{name: "Group", filter: APIResourceFilter{Group: "apps", GroupSet: true}, expected: []string{"apps/v1/deployments"}},
+		{name: "Cluster-scoped", filter: APIResourceFilter{Namespaced: &clusterScoped}, expected: []string{"/v1/nodes"}},
+		{
+			name:     "Verb",
+			filter:   APIResourceFilter{Verb: "patch"},
+			expected: []string{"/v1/nodes", "apps/v1/deployments"},
+		},
+		{
+			name:     "Category",
+			filter:   APIResourceFilter{Category: "all"},
+			expected: []string{"/v1/pods", "apps/v1/deployments"},
+		},
+		{name: "Short name", filter: APIResourceFilter{Name: "deploy"}, expected: []string{"apps/v1/deployments"}},
+		{name: "Kind", filter: APIResourceFilter{Name: "WIDG"}, expected: []string{"example.com/v2/widgets"}},
+		{
+			name:     "All versions",
+			filter:   APIResourceFilter{Group: "example.com", GroupSet: true, AllVersions: true},
+			expected: []string{"example.com/v2/widgets", "example.com/v1/widgets"},
+		},
+		{name: "No match", filter: APIResourceFilter{Name: "gadget"}, expected: []string{}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := client.FindAPIResources(context.Background(), tc.filter)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, resourceNames(result.Resources))
+		})
+	}
+}
+
+func TestFindAPIResourcesPartialFailure(t *testing.T) {
+	client, fakeDiscovery := newAPIResourcesTestClient()
+	fakeDiscovery.PrependReactor("get", "resource", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, &discovery.ErrGroupDiscoveryFailed{Groups: map[schema.GroupVersion]error{
+			{Group: "metrics.k8s.io", Version: "v1beta1"}: fmt.Errorf("service unavailable"),
+		}}
+	})
+
+	result, err := client.FindAPIResources(context.Background(), APIResourceFilter{Name: "pods"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"metrics.k8s.io/v1beta1"}, result.FailedGroupVersions)
+	assert.Equal(t, []string{"/v1/pods"}, resourceNames(result.Resources))
+
+	// Other errors fail the call
+	fakeDiscovery.PrependReactor("get", "group", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("connection refused")
+	})
+	_, err = client.FindAPIResources(context.Background(), APIResourceFilter{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "connection refused")
+}
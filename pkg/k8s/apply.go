@@ -0,0 +1,103 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// ApplyOptions configures an ApplyResource call.
+type ApplyOptions struct {
+	// RequireResourceVersion refuses to update an existing object unless the
+	// manifest carries the resourceVersion it was based on, so that changes
+	// made by others since are never overwritten.
+	RequireResourceVersion bool
+	// DryRun submits the change as a server-side dry run, which is validated
+	// and admitted but not persisted.
+	DryRun bool
+}
+
+// ConflictError is returned by ApplyResource when the object was modified
+// or deleted since the resourceVersion of the manifest, or when a required
+// resourceVersion is missing. Current holds the live object, if any, so that
+// the caller can merge its changes and retry.
+type ConflictError struct {
+	Err     error
+	Current *unstructured.Unstructured
+}
+
+// Error implements error.
+func (e *ConflictError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *ConflictError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyResource creates or updates a resource. An empty namespace addresses a
+// cluster-scoped resource.
+//
+// When the manifest carries a resourceVersion, the update is only accepted
+// by the API server if the object was not modified since; otherwise the live
+// resourceVersion is used and the manifest overwrites the object. Conflicts
+// are returned as a *ConflictError.
+func (c *Client) ApplyResource(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	obj *unstructured.Unstructured,
+	opts ApplyOptions,
+) (*unstructured.Unstructured, error) {
+	c.mu.RLock()
+	client := resourceInterface(c.dynamicClient, gvr, namespace)
+	c.mu.RUnlock()
+
+	name := obj.GetName()
+	resourceVersion := obj.GetResourceVersion()
+	var dryRun []string
+	if opts.DryRun {
+		dryRun = []string{metav1.DryRunAll}
+	}
+
+	// Check if resource exists
+	existing, err := client.Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if resourceVersion != "" && apierrors.IsNotFound(err) {
+			return nil, &ConflictError{Err: apierrors.NewConflict(gvr.GroupResource(), name,
+				fmt.Errorf("the object was deleted since resourceVersion %s", resourceVersion))}
+		}
+		// Resource doesn't exist or error occurred, create it
+		return client.Create(ctx, obj, metav1.CreateOptions{DryRun: dryRun})
+	}
+
+	switch {
+	case resourceVersion != "":
+		// Keep the caller's resourceVersion so that the API server rejects
+		// the update if the object changed in the meantime.
+	case opts.RequireResourceVersion:
+		return nil, &ConflictError{
+			Err: fmt.Errorf("%s %q already exists and the manifest has no metadata.resourceVersion; "+
+				"set it to the resourceVersion the changes are based on", gvr.Resource, name),
+			Current: existing,
+		}
+	default:
+		// Set the resource version to ensure we're updating the latest version
+		obj.SetResourceVersion(existing.GetResourceVersion())
+	}
+
+	updated, err := client.Update(ctx, obj, metav1.UpdateOptions{DryRun: dryRun})
+	if apierrors.IsConflict(err) {
+		conflict := &ConflictError{Err: err}
+		if current, getErr := client.Get(ctx, name, metav1.GetOptions{}); getErr == nil {
+			conflict.Current = current
+		}
+		return nil, conflict
+	}
+	return updated, err
+}
@@ -0,0 +1,116 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+var applyConfigMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func applyTestConfigMap(resourceVersion, value string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "default"},
+		"data":       map[string]interface{}{"mode": value},
+	}}
+	if resourceVersion != "" {
+		obj.SetResourceVersion(resourceVersion)
+	}
+	return obj
+}
+
+// newApplyTestClient returns a client holding a ConfigMap at resourceVersion
+// "7", whose updates are rejected with a conflict unless they carry that
+// resourceVersion, as the API server does.
+func newApplyTestClient(objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+	fakeDynamic.PrependReactor("update", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+		obj := action.(ktesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetResourceVersion() != "7" {
+			return true, nil, apierrors.NewConflict(applyConfigMapsGVR.GroupResource(), obj.GetName(),
+				assert.AnError)
+		}
+		return false, nil, nil
+	})
+	client := &Client{}
+	client.SetDynamicClient(fakeDynamic)
+	return client, fakeDynamic
+}
+
+func TestApplyResourceKeepsManifestResourceVersion(t *testing.T) {
+	client, _ := newApplyTestClient(applyTestConfigMap("7", "live"))
+
+	// A stale resourceVersion is rejected rather than overwritten
+	_, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("3", "stale"), ApplyOptions{})
+	require.Error(t, err)
+	assert.True(t, apierrors.IsConflict(err))
+
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	require.NotNil(t, conflict.Current)
+	assert.Equal(t, "7", conflict.Current.GetResourceVersion())
+	value, _, _ := unstructured.NestedString(conflict.Current.Object, "data", "mode")
+	assert.Equal(t, "live", value)
+
+	// The current resourceVersion is accepted
+	result, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("7", "fresh"), ApplyOptions{})
+	require.NoError(t, err)
+	value, _, _ = unstructured.NestedString(result.Object, "data", "mode")
+	assert.Equal(t, "fresh", value)
+}
+
+func TestApplyResourceWithoutResourceVersion(t *testing.T) {
+	client, _ := newApplyTestClient(applyTestConfigMap("7", "live"))
+
+	// Without a resourceVersion the live one is used
+	result, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("", "overwrite"), ApplyOptions{})
+	require.NoError(t, err)
+	value, _, _ := unstructured.NestedString(result.Object, "data", "mode")
+	assert.Equal(t, "overwrite", value)
+}
+
+func TestApplyResourceRequireResourceVersion(t *testing.T) {
+	client, _ := newApplyTestClient(applyTestConfigMap("7", "live"))
+
+	_, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("", "overwrite"), ApplyOptions{RequireResourceVersion: true})
+	require.Error(t, err)
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Contains(t, err.Error(), "no metadata.resourceVersion")
+	require.NotNil(t, conflict.Current)
+	assert.Equal(t, "7", conflict.Current.GetResourceVersion())
+
+	// Creating a new object does not need a resourceVersion
+	client, _ = newApplyTestClient()
+	result, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("", "new"), ApplyOptions{RequireResourceVersion: true})
+	require.NoError(t, err)
+	assert.Equal(t, "settings", result.GetName())
+}
+
+func TestApplyResourceDeletedSinceResourceVersion(t *testing.T) {
+	client, _ := newApplyTestClient()
+
+	_, err := client.ApplyResource(context.Background(), applyConfigMapsGVR, "default",
+		applyTestConfigMap("7", "gone"), ApplyOptions{})
+	require.Error(t, err)
+	assert.True(t, apierrors.IsConflict(err))
+	var conflict *ConflictError
+	require.ErrorAs(t, err, &conflict)
+	assert.Nil(t, conflict.Current)
+	assert.Contains(t, err.Error(), "deleted since resourceVersion 7")
+}
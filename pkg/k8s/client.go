@@ -32,9 +32,19 @@ type ExecInPodFunc func(
 	namespace, name string,
 	command []string,
 	container string,
+	stdin string,
 	timeout time.Duration,
 ) (*unstructured.Unstructured, error)
 
+// PortForwardFunc is a function type for forwarding a local port to a pod
+// port. It returns the local port the pod port is reachable on and a function
+// that tears the forward down.
+type PortForwardFunc func(
+	ctx context.Context,
+	namespace, name string,
+	port int32,
+) (uint16, func(), error)
+
 // Client represents a Kubernetes client with discovery and dynamic capabilities
 type Client struct {
 	discoveryClient discovery.DiscoveryInterface
@@ -52,14 +62,19 @@ type Client struct {
 	refreshMu       sync.Mutex // Protects refreshing state
 
 	// function overrides for testing purposes
-	getPodLogs PodLogsFunc
-	execInPod  ExecInPodFunc
+	getPodLogs  PodLogsFunc
+	execInPod   ExecInPodFunc
+	portForward PortForwardFunc
 
 	// podLogReadSem bounds the number of pod-log reads in flight at once.
 	// Each in-flight read can buffer up to maxPodLogLimitBytes; this caps
 	// the aggregate memory the read path can hold across concurrent
 	// requests. Buffered to maxConcurrentPodLogReads. See GHSA-qw5r-ppcg-f8rj.
 	podLogReadSem chan struct{}
+
+	// openAPIDocs caches the OpenAPI v3 documents of group versions for
+	// ExplainResource. Nil disables caching.
+	openAPIDocs *openAPICache
 }
 
 // maxConcurrentPodLogReads caps the number of pod-log reads that may run
@@ -99,11 +114,13 @@ func NewClient(kubeconfigPath string) (*Client, error) {
 		restConfig:      config,
 		kubeconfigPath:  kubeconfigPath,
 		podLogReadSem:   make(chan struct{}, maxConcurrentPodLogReads),
+		openAPIDocs:     newOpenAPICache(),
 	}
 
 	// Set the default implementations
 	client.getPodLogs = client.defaultGetPodLogs
 	client.execInPod = client.defaultExecInPod
+	client.portForward = client.defaultPortForward
 
 	return client, nil
 }
@@ -200,28 +217,13 @@ func (c *Client) ListNamespacedResources(
 	return c.dynamicClient.Resource(gvr).Namespace(namespace).List(ctx, listOptions)
 }
 
-// ApplyClusteredResource creates or updates a clustered resource
+// ApplyClusteredResource creates or updates a clustered resource, see ApplyResource
 func (c *Client) ApplyClusteredResource(
 	ctx context.Context,
 	gvr schema.GroupVersionResource,
 	obj *unstructured.Unstructured,
 ) (*unstructured.Unstructured, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	name := obj.GetName()
-
-	// Check if resource exists
-	existing, err := c.dynamicClient.Resource(gvr).Get(ctx, name, metav1.GetOptions{})
-	if err == nil {
-		// Resource exists, update it
-		// Set the resource version to ensure we're updating the latest version
-		obj.SetResourceVersion(existing.GetResourceVersion())
-		return c.dynamicClient.Resource(gvr).Update(ctx, obj, metav1.UpdateOptions{})
-	}
-
-	// Resource doesn't exist or error occurred, create it
-	return c.dynamicClient.Resource(gvr).Create(ctx, obj, metav1.CreateOptions{})
+	return c.ApplyResource(ctx, gvr, "", obj, ApplyOptions{})
 }
 
 // GetClusteredResource gets a clustered resource
@@ -244,45 +246,37 @@ func (c *Client) GetNamespacedResource(
 	return c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
 }
 
-// ApplyNamespacedResource creates or updates a namespaced resource
+// ApplyNamespacedResource creates or updates a namespaced resource, see ApplyResource
 func (c *Client) ApplyNamespacedResource(
 	ctx context.Context,
 	gvr schema.GroupVersionResource,
 	namespace string,
 	obj *unstructured.Unstructured,
 ) (*unstructured.Unstructured, error) {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	name := obj.GetName()
-
-	// Check if resource exists
-	existing, err := c.dynamicClient.Resource(gvr).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
-	if err == nil {
-		// Resource exists, update it
-		// Set the resource version to ensure we're updating the latest version
-		obj.SetResourceVersion(existing.GetResourceVersion())
-		return c.dynamicClient.Resource(gvr).Namespace(namespace).Update(ctx, obj, metav1.UpdateOptions{})
-	}
-
-	// Resource doesn't exist or error occurred, create it
-	return c.dynamicClient.Resource(gvr).Namespace(namespace).Create(ctx, obj, metav1.CreateOptions{})
+	return c.ApplyResource(ctx, gvr, namespace, obj, ApplyOptions{})
 }
 
 // DeleteClusteredResource deletes a clustered resource
 func (c *Client) DeleteClusteredResource(ctx context.Context, gvr schema.GroupVersionResource, name string) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-
-	return c.dynamicClient.Resource(gvr).Delete(ctx, name, metav1.DeleteOptions{})
+	return c.DeleteResource(ctx, gvr, "", name, DeleteOptions{})
 }
 
 // DeleteNamespacedResource deletes a namespaced resource
 func (c *Client) DeleteNamespacedResource(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string) error {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
+	return c.DeleteResource(ctx, gvr, namespace, name, DeleteOptions{})
+}
 
-	return c.dynamicClient.Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+// resourceInterface returns the dynamic client for gvr, scoped to namespace
+// for namespaced resources. An empty namespace selects the cluster scope.
+func resourceInterface(
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	namespace string,
+) dynamic.ResourceInterface {
+	if namespace == "" {
+		return dynamicClient.Resource(gvr)
+	}
+	return dynamicClient.Resource(gvr).Namespace(namespace)
 }
 
 // WithImpersonation returns a new Client that impersonates the given user and groups.
@@ -291,7 +285,7 @@ func (c *Client) DeleteNamespacedResource(ctx context.Context, gvr schema.GroupV
 //
 // The returned Client is independent of the receiver — mutations to one do not
 // affect the other. The returned Client does NOT inherit periodic refresh or
-// testing overrides (getPodLogs, execInPod) from the base client; it uses the
+// testing overrides (getPodLogs, execInPod, portForward) from the base client; it uses the
 // default implementations. This is intentional: impersonated clients are
 // short-lived, per-request objects.
 func (c *Client) WithImpersonation(user string, groups []string) (*Client, error) {
@@ -333,11 +327,14 @@ func (c *Client) WithImpersonation(user string, groups []string) (*Client, error
 		// not per-impersonation, so independent semaphores would let N
 		// concurrent users each hold maxConcurrentPodLogReads slots.
 		podLogReadSem: c.podLogReadSem,
+		// The OpenAPI documents do not depend on the user.
+		openAPIDocs: c.openAPIDocs,
 	}
 
-	// Set default implementations for pod logs and exec
+	// Set default implementations for pod logs, exec and port-forward
 	impersonated.getPodLogs = impersonated.defaultGetPodLogs
 	impersonated.execInPod = impersonated.defaultExecInPod
+	impersonated.portForward = impersonated.defaultPortForward
 
 	return impersonated, nil
 }
@@ -407,21 +404,32 @@ func (c *Client) GetExecInPodFunc() ExecInPodFunc {
 	return c.execInPod
 }
 
+// SetPortForwardFunc sets the function used to forward ports to pods (for testing purposes)
+func (c *Client) SetPortForwardFunc(portForwardFunc PortForwardFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.portForward = portForwardFunc
+}
+
 // ExecInPod executes a command in a pod and returns the result
 // The command will be killed if it exceeds the timeout
 // If timeout is 0 or negative, the default timeout (15s) will be used
 // If timeout exceeds MaxExecTimeout, it will be capped at MaxExecTimeout
+// If stdin is non-empty, it is streamed to the command's standard input
+// (at most MaxExecStdinBytes)
 func (c *Client) ExecInPod(
 	ctx context.Context,
 	namespace, name string,
 	command []string,
 	container string,
+	stdin string,
 	timeout time.Duration,
 ) (*unstructured.Unstructured, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
-	return c.execInPod(ctx, namespace, name, command, container, timeout)
+	return c.execInPod(ctx, namespace, name, command, container, stdin, timeout)
 }
 
 // IsReady returns true if the client is ready to use
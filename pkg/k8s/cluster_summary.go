@@ -0,0 +1,587 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"time"
+
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxSummaryItems is the largest number of entries of each list of a
+	// ClusterSummary. Entries beyond it are counted in Omitted.
+	MaxSummaryItems = 10
+	// summaryPageSize is the page size of the lists made by listPages.
+	summaryPageSize = 500
+)
+
+// apiServicesGVR is the resource of the APIServices of aggregated APIs.
+var apiServicesGVR = schema.GroupVersionResource{Group: "apiregistration.k8s.io", Version: "v1", Resource: "apiservices"}
+
+// ClusterSummary is a snapshot of the health of a cluster.
+type ClusterSummary struct {
+	ServerVersion string `json:"serverVersion,omitempty"`
+	Platform      string `json:"platform,omitempty"`
+	// Namespace scopes the pods, workloads, PVCs and events, if set.
+	Namespace          string              `json:"namespace,omitempty"`
+	Nodes              *NodesSummary       `json:"nodes,omitempty"`
+	Pods               *PodsSummary        `json:"pods,omitempty"`
+	FailingWorkloads   []WorkloadSummary   `json:"failingWorkloads,omitempty"`
+	PendingPVCs        []PVCSummary        `json:"pendingPVCs,omitempty"`
+	WarningEvents      []EventSummary      `json:"warningEvents,omitempty"`
+	FailingAPIServices []APIServiceSummary `json:"failingAPIServices,omitempty"`
+	FailingWebhooks    []WebhookSummary    `json:"failingWebhooks,omitempty"`
+	// Omitted counts the entries left out of each list beyond
+	// MaxSummaryItems, by the JSON name of the list.
+	Omitted map[string]int `json:"omitted,omitempty"`
+	// Errors lists the sections that could not be computed, e.g. because
+	// listing their objects is forbidden.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// NodesSummary summarizes the nodes of a cluster.
+type NodesSummary struct {
+	Total         int `json:"total"`
+	Ready         int `json:"ready"`
+	Unschedulable int `json:"unschedulable"`
+	// Problems lists the nodes that are not ready or under pressure.
+	Problems []NodeProblem `json:"problems,omitempty"`
+}
+
+// NodeProblem is a node that is not ready or under pressure.
+type NodeProblem struct {
+	Name string `json:"name"`
+	// Conditions are the abnormal conditions, e.g. "Ready=False: KubeletNotReady".
+	Conditions []string `json:"conditions"`
+}
+
+// PodsSummary summarizes the pods of a cluster.
+type PodsSummary struct {
+	Total       int            `json:"total"`
+	ByPhase     map[string]int `json:"byPhase"`
+	TopRestarts []PodRestarts  `json:"topRestarts,omitempty"`
+}
+
+// PodRestarts is a pod whose containers restarted.
+type PodRestarts struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Restarts  int32  `json:"restarts"`
+	// Reason is why the container restarting the most is waiting or last
+	// terminated, e.g. CrashLoopBackOff or OOMKilled.
+	Reason string `json:"reason,omitempty"`
+}
+
+// WorkloadSummary is a workload with unavailable replicas.
+type WorkloadSummary struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Desired   int32  `json:"desired"`
+	Ready     int32  `json:"ready"`
+	Available int32  `json:"available"`
+}
+
+// PVCSummary is a PersistentVolumeClaim that is not bound.
+type PVCSummary struct {
+	Namespace    string    `json:"namespace"`
+	Name         string    `json:"name"`
+	StorageClass string    `json:"storageClass,omitempty"`
+	Created      time.Time `json:"created"`
+}
+
+// EventSummary is a Warning event.
+type EventSummary struct {
+	Namespace string    `json:"namespace"`
+	Object    string    `json:"object"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Count     int32     `json:"count,omitempty"`
+	LastSeen  time.Time `json:"lastSeen"`
+}
+
+// APIServiceSummary is an APIService that is not available.
+type APIServiceSummary struct {
+	Name    string `json:"name"`
+	Service string `json:"service,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// WebhookSummary is an admission webhook whose service cannot be reached.
+type WebhookSummary struct {
+	Configuration string `json:"configuration"`
+	Webhook       string `json:"webhook"`
+	Service       string `json:"service"`
+	FailurePolicy string `json:"failurePolicy,omitempty"`
+	Problem       string `json:"problem"`
+}
+
+// ClusterSummary returns a snapshot of the health of the cluster: the server
+// version, node readiness and pressure, pods by phase and those restarting
+// the most, workloads with unavailable replicas, pending PVCs, recent Warning
+// events, and unavailable APIServices and webhooks. An empty namespace
+// summarizes all namespaces. Sections that cannot be computed are reported in
+// Errors rather than failing the call.
+func (c *Client) ClusterSummary(ctx context.Context, namespace string) (*ClusterSummary, error) {
+	c.mu.RLock()
+	clientset := c.clientset
+	discoveryClient := c.discoveryClient
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	summary := &ClusterSummary{Namespace: namespace, Omitted: map[string]int{}}
+	addError := func(section string, err error) {
+		summary.Errors = append(summary.Errors, fmt.Sprintf("%s: %v", section, err))
+	}
+
+	if discoveryClient != nil {
+		if info, err := discoveryClient.ServerVersion(); err != nil {
+			addError("serverVersion", err)
+		} else {
+			summary.ServerVersion, summary.Platform = info.GitVersion, info.Platform
+		}
+	}
+	if err := summary.addNodes(ctx, clientset); err != nil {
+		addError("nodes", err)
+	}
+	if err := summary.addPods(ctx, clientset, namespace); err != nil {
+		addError("pods", err)
+	}
+	if err := summary.addWorkloads(ctx, clientset, namespace); err != nil {
+		addError("failingWorkloads", err)
+	}
+	if err := summary.addPendingPVCs(ctx, clientset, namespace); err != nil {
+		addError("pendingPVCs", err)
+	}
+	if err := summary.addWarningEvents(ctx, clientset, namespace); err != nil {
+		addError("warningEvents", err)
+	}
+	if err := c.addFailingAPIServices(ctx, summary); err != nil {
+		addError("failingAPIServices", err)
+	}
+	if err := summary.addFailingWebhooks(ctx, clientset); err != nil {
+		addError("failingWebhooks", err)
+	}
+
+	if len(summary.Omitted) == 0 {
+		summary.Omitted = nil
+	}
+	return summary, nil
+}
+
+// addNodes summarizes the nodes.
+func (s *ClusterSummary) addNodes(ctx context.Context, clientset kubernetes.Interface) error {
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	s.Nodes = &NodesSummary{Total: len(nodes.Items)}
+	for _, node := range nodes.Items {
+		if node.Spec.Unschedulable {
+			s.Nodes.Unschedulable++
+		}
+		var conditions []string
+		for _, condition := range node.Status.Conditions {
+			abnormal := condition.Status == corev1.ConditionTrue
+			if condition.Type == corev1.NodeReady {
+				abnormal = condition.Status != corev1.ConditionTrue
+				if !abnormal {
+					s.Nodes.Ready++
+				}
+			}
+			if abnormal {
+				conditions = append(conditions,
+					fmt.Sprintf("%s=%s: %s", condition.Type, condition.Status, condition.Reason))
+			}
+		}
+		if len(conditions) > 0 {
+			s.Nodes.Problems = append(s.Nodes.Problems, NodeProblem{Name: node.Name, Conditions: conditions})
+		}
+	}
+	s.Nodes.Problems = truncateSummary(s, "nodes.problems", s.Nodes.Problems)
+	return nil
+}
+
+// addPods summarizes the pods of namespace.
+func (s *ClusterSummary) addPods(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	s.Pods = &PodsSummary{ByPhase: map[string]int{}}
+	var restarts []PodRestarts
+	err := listPages(ctx, func(options metav1.ListOptions) (string, error) {
+		pods, err := clientset.CoreV1().Pods(namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, pod := range pods.Items {
+			s.Pods.Total++
+			s.Pods.ByPhase[string(pod.Status.Phase)]++
+			if podRestarts := podRestartSummary(&pod); podRestarts.Restarts > 0 {
+				restarts = append(restarts, podRestarts)
+			}
+		}
+		return pods.Continue, nil
+	})
+	if err != nil {
+		s.Pods = nil
+		return err
+	}
+
+	sort.SliceStable(restarts, func(i, j int) bool { return restarts[i].Restarts > restarts[j].Restarts })
+	s.Pods.TopRestarts = truncateSummary(s, "pods.topRestarts", restarts)
+	return nil
+}
+
+// podRestartSummary returns the restarts of the containers of pod, with the
+// reason of the container restarting the most.
+func podRestartSummary(pod *corev1.Pod) PodRestarts {
+	result := PodRestarts{Namespace: pod.Namespace, Name: pod.Name}
+	var most int32 = -1
+	for _, status := range slices.Concat(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses) {
+		result.Restarts += status.RestartCount
+		if status.RestartCount <= most || status.RestartCount == 0 {
+			continue
+		}
+		most = status.RestartCount
+		switch {
+		case status.State.Waiting != nil:
+			result.Reason = status.State.Waiting.Reason
+		case status.LastTerminationState.Terminated != nil:
+			result.Reason = status.LastTerminationState.Terminated.Reason
+		}
+	}
+	return result
+}
+
+// addWorkloads adds the Deployments, StatefulSets and DaemonSets of namespace
+// with unavailable replicas.
+func (s *ClusterSummary) addWorkloads(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	apps := clientset.AppsV1()
+	var failing []WorkloadSummary
+
+	deployments, err := apps.Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, deployment := range deployments.Items {
+		desired := replicasOrDefault(deployment.Spec.Replicas)
+		if deployment.Status.AvailableReplicas < desired || deployment.Status.UnavailableReplicas > 0 {
+			failing = append(failing, workloadSummary("Deployment", deployment.ObjectMeta, desired,
+				deployment.Status.ReadyReplicas, deployment.Status.AvailableReplicas))
+		}
+	}
+
+	statefulSets, err := apps.StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, statefulSet := range statefulSets.Items {
+		desired := replicasOrDefault(statefulSet.Spec.Replicas)
+		if statefulSet.Status.AvailableReplicas < desired {
+			failing = append(failing, workloadSummary("StatefulSet", statefulSet.ObjectMeta, desired,
+				statefulSet.Status.ReadyReplicas, statefulSet.Status.AvailableReplicas))
+		}
+	}
+
+	daemonSets, err := apps.DaemonSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, daemonSet := range daemonSets.Items {
+		if daemonSetFailing(&daemonSet) {
+			failing = append(failing, workloadSummary("DaemonSet", daemonSet.ObjectMeta,
+				daemonSet.Status.DesiredNumberScheduled, daemonSet.Status.NumberReady, daemonSet.Status.NumberAvailable))
+		}
+	}
+
+	s.FailingWorkloads = truncateSummary(s, "failingWorkloads", failing)
+	return nil
+}
+
+// daemonSetFailing returns whether daemonSet has unavailable pods.
+func daemonSetFailing(daemonSet *appsv1.DaemonSet) bool {
+	return daemonSet.Status.NumberUnavailable > 0 ||
+		daemonSet.Status.NumberAvailable < daemonSet.Status.DesiredNumberScheduled
+}
+
+// workloadSummary returns the summary of a workload.
+func workloadSummary(kind string, meta metav1.ObjectMeta, desired, ready, available int32) WorkloadSummary {
+	return WorkloadSummary{
+		Kind:      kind,
+		Namespace: meta.Namespace,
+		Name:      meta.Name,
+		Desired:   desired,
+		Ready:     ready,
+		Available: available,
+	}
+}
+
+// replicasOrDefault returns the replicas of a workload spec, which default to
+// one.
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
+
+// addPendingPVCs adds the PersistentVolumeClaims of namespace that are not
+// bound.
+func (s *ClusterSummary) addPendingPVCs(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	claims, err := clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var pending []PVCSummary
+	for _, claim := range claims.Items {
+		if claim.Status.Phase == corev1.ClaimBound {
+			continue
+		}
+		summary := PVCSummary{Namespace: claim.Namespace, Name: claim.Name, Created: claim.CreationTimestamp.UTC()}
+		if claim.Spec.StorageClassName != nil {
+			summary.StorageClass = *claim.Spec.StorageClassName
+		}
+		pending = append(pending, summary)
+	}
+	sort.SliceStable(pending, func(i, j int) bool { return pending[i].Created.Before(pending[j].Created) })
+	s.PendingPVCs = truncateSummary(s, "pendingPVCs", pending)
+	return nil
+}
+
+// addWarningEvents adds the most recent Warning events of namespace.
+func (s *ClusterSummary) addWarningEvents(ctx context.Context, clientset kubernetes.Interface, namespace string) error {
+	var warnings []EventSummary
+	err := listPages(ctx, func(options metav1.ListOptions) (string, error) {
+		options.FieldSelector = "type=" + corev1.EventTypeWarning
+		events, err := clientset.CoreV1().Events(namespace).List(ctx, options)
+		if err != nil {
+			return "", err
+		}
+		for _, event := range events.Items {
+			if event.Type != corev1.EventTypeWarning {
+				continue
+			}
+			warnings = append(warnings, EventSummary{
+				Namespace: event.Namespace,
+				Object:    event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+				Reason:    event.Reason,
+				Message:   event.Message,
+				Count:     event.Count,
+				LastSeen:  eventLastSeen(&event),
+			})
+		}
+		return events.Continue, nil
+	})
+	if err != nil {
+		return err
+	}
+
+	sort.SliceStable(warnings, func(i, j int) bool { return warnings[i].LastSeen.After(warnings[j].LastSeen) })
+	s.WarningEvents = truncateSummary(s, "warningEvents", warnings)
+	return nil
+}
+
+// eventLastSeen returns when event last occurred.
+func eventLastSeen(event *corev1.Event) time.Time {
+	switch {
+	case event.Series != nil && !event.Series.LastObservedTime.IsZero():
+		return event.Series.LastObservedTime.UTC()
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.UTC()
+	case !event.EventTime.IsZero():
+		return event.EventTime.UTC()
+	default:
+		return event.CreationTimestamp.UTC()
+	}
+}
+
+// addFailingAPIServices adds the APIServices that are not available.
+func (c *Client) addFailingAPIServices(ctx context.Context, s *ClusterSummary) error {
+	c.mu.RLock()
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	if dynamicClient == nil {
+		return fmt.Errorf("dynamic client is not initialized")
+	}
+
+	apiServices, err := dynamicClient.Resource(apiServicesGVR).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	var failing []APIServiceSummary
+	for _, apiService := range apiServices.Items {
+		conditions, _, _ := unstructured.NestedSlice(apiService.Object, "status", "conditions")
+		for _, condition := range conditions {
+			condition, _ := condition.(map[string]interface{})
+			if condition["type"] != "Available" || condition["status"] == string(metav1.ConditionTrue) {
+				continue
+			}
+			summary := APIServiceSummary{Name: apiService.GetName()}
+			summary.Reason, _ = condition["reason"].(string)
+			summary.Message, _ = condition["message"].(string)
+			serviceNamespace, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "namespace")
+			serviceName, _, _ := unstructured.NestedString(apiService.Object, "spec", "service", "name")
+			if serviceName != "" {
+				summary.Service = serviceNamespace + "/" + serviceName
+			}
+			failing = append(failing, summary)
+		}
+	}
+	s.FailingAPIServices = truncateSummary(s, "failingAPIServices", failing)
+	return nil
+}
+
+// addFailingWebhooks adds the admission webhooks whose service does not exist
+// or has no ready endpoints.
+func (s *ClusterSummary) addFailingWebhooks(ctx context.Context, clientset kubernetes.Interface) error {
+	admission := clientset.AdmissionregistrationV1()
+	validating, err := admission.ValidatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	mutating, err := admission.MutatingWebhookConfigurations().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return err
+	}
+
+	checker := &webhookServiceChecker{clientset: clientset, problems: map[string]string{}}
+	var failing []WebhookSummary
+	check := func(configuration, webhook string, clientConfig admissionregistrationv1.WebhookClientConfig,
+		failurePolicy *admissionregistrationv1.FailurePolicyType) {
+		if clientConfig.Service == nil {
+			return
+		}
+		service := clientConfig.Service.Namespace + "/" + clientConfig.Service.Name
+		problem := checker.problem(ctx, clientConfig.Service.Namespace, clientConfig.Service.Name)
+		if problem == "" {
+			return
+		}
+		summary := WebhookSummary{Configuration: configuration, Webhook: webhook, Service: service, Problem: problem}
+		if failurePolicy != nil {
+			summary.FailurePolicy = string(*failurePolicy)
+		}
+		failing = append(failing, summary)
+	}
+	for _, configuration := range validating.Items {
+		for _, webhook := range configuration.Webhooks {
+			check("ValidatingWebhookConfiguration/"+configuration.Name, webhook.Name, webhook.ClientConfig,
+				webhook.FailurePolicy)
+		}
+	}
+	for _, configuration := range mutating.Items {
+		for _, webhook := range configuration.Webhooks {
+			check("MutatingWebhookConfiguration/"+configuration.Name, webhook.Name, webhook.ClientConfig,
+				webhook.FailurePolicy)
+		}
+	}
+
+	s.FailingWebhooks = truncateSummary(s, "failingWebhooks", failing)
+	for _, err := range checker.errs {
+		s.Errors = append(s.Errors, fmt.Sprintf("failingWebhooks: %v", err))
+	}
+	return nil
+}
+
+// webhookServiceChecker checks the services of webhooks, once per service.
+// Services that cannot be checked, e.g. for lack of permissions, are not
+// reported as failing but recorded in errs.
+type webhookServiceChecker struct {
+	clientset kubernetes.Interface
+	problems  map[string]string
+	errs      []error
+}
+
+// problem returns why the service namespace/name cannot serve webhooks, or
+// an empty string if it has ready endpoints or cannot be checked.
+func (w *webhookServiceChecker) problem(ctx context.Context, namespace, name string) string {
+	key := namespace + "/" + name
+	if problem, ok := w.problems[key]; ok {
+		return problem
+	}
+
+	problem, err := w.serviceProblem(ctx, namespace, name)
+	if err != nil {
+		w.errs = append(w.errs, fmt.Errorf("failed to check service %s: %w", key, err))
+	}
+	w.problems[key] = problem
+	return problem
+}
+
+// serviceProblem returns why the service namespace/name cannot serve
+// webhooks, or an error if it cannot be checked.
+func (w *webhookServiceChecker) serviceProblem(ctx context.Context, namespace, name string) (string, error) {
+	_, err := w.clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		return fmt.Sprintf("service unavailable: %v", err), nil
+	case err != nil:
+		return "", err
+	}
+
+	endpointSlices, err := w.clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to list endpoint slices: %w", err)
+	}
+	if !hasReadyEndpoint(endpointSlices.Items) {
+		return "service has no ready endpoints", nil
+	}
+	return "", nil
+}
+
+// hasReadyEndpoint returns whether endpointSlices have a ready endpoint.
+func hasReadyEndpoint(endpointSlices []discoveryv1.EndpointSlice) bool {
+	for _, endpointSlice := range endpointSlices {
+		for _, endpoint := range endpointSlice.Endpoints {
+			if endpoint.Conditions.Ready == nil || *endpoint.Conditions.Ready {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// listPages calls list with the options of each page of a list, until it
+// returns an empty continue token.
+func listPages(ctx context.Context, list func(options metav1.ListOptions) (string, error)) error {
+	options := metav1.ListOptions{Limit: summaryPageSize}
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		next, err := list(options)
+		if err != nil {
+			return err
+		}
+		if next == "" {
+			return nil
+		}
+		options.Continue = next
+	}
+}
+
+// truncateSummary returns the first MaxSummaryItems items, counting those
+// left out in the Omitted entry of s named name.
+func truncateSummary[T any](s *ClusterSummary, name string, items []T) []T {
+	if len(items) <= MaxSummaryItems {
+		return items
+	}
+	s.Omitted[name] = len(items) - MaxSummaryItems
+	return items[:MaxSummaryItems]
+}
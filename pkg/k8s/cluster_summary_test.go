@@ -0,0 +1,318 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionregistrationv1 "k8s.io/api/admissionregistration/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func newClusterSummaryTestClient(objects ...runtime.Object) (*Client, *kubefake.Clientset) {
+	fail := admissionregistrationv1.Fail
+	now := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	objects = append(objects,
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+				{Type: corev1.NodeMemoryPressure, Status: corev1.ConditionFalse},
+			}},
+		},
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-2"},
+			Spec:       corev1.NodeSpec{Unschedulable: true},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionFalse, Reason: "KubeletNotReady"},
+				{Type: corev1.NodeDiskPressure, Status: corev1.ConditionTrue, Reason: "KubeletHasDiskPressure"},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{
+					{Name: "web", RestartCount: 7, State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+					}},
+					{Name: "sidecar", RestartCount: 1},
+				},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "shop"},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "worker", RestartCount: 2, LastTerminationState: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled"},
+				}}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "batch"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](3)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 2, UnavailableReplicas: 1},
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To[int32](2)},
+			Status:     appsv1.DeploymentStatus{ReadyReplicas: 2, AvailableReplicas: 2},
+		},
+		&appsv1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop"},
+			Status:     appsv1.StatefulSetStatus{ReadyReplicas: 0, AvailableReplicas: 0},
+		},
+		&appsv1.DaemonSet{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "kube-system"},
+			Status:     appsv1.DaemonSetStatus{DesiredNumberScheduled: 2, NumberReady: 2, NumberAvailable: 2},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "data", Namespace: "shop"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To("fast")},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: "logs", Namespace: "shop"},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimBound},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.1", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container",
+			Count:          12,
+			LastTimestamp:  metav1.NewTime(now),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "data.1", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "PersistentVolumeClaim", Name: "data"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "ProvisioningFailed",
+			LastTimestamp:  metav1.NewTime(now.Add(-time.Hour)),
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.2", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "web"},
+			Type:           corev1.EventTypeNormal,
+			Reason:         "Pulled",
+		},
+		&admissionregistrationv1.ValidatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "policy"},
+			Webhooks: []admissionregistrationv1.ValidatingWebhook{
+				{
+					Name:          "check.policy.example.com",
+					FailurePolicy: &fail,
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						Service: &admissionregistrationv1.ServiceReference{Namespace: "policy", Name: "webhook"},
+					},
+				},
+				{
+					Name: "external.policy.example.com",
+					ClientConfig: admissionregistrationv1.WebhookClientConfig{
+						URL: ptr.To("https://policy.example.com/check"),
+					},
+				},
+			},
+		},
+		&admissionregistrationv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "injector"},
+			Webhooks: []admissionregistrationv1.MutatingWebhook{{
+				Name: "inject.example.com",
+				ClientConfig: admissionregistrationv1.WebhookClientConfig{
+					Service: &admissionregistrationv1.ServiceReference{Namespace: "mesh", Name: "injector"},
+				},
+			}},
+		},
+		&corev1.Service{ObjectMeta: metav1.ObjectMeta{Name: "webhook", Namespace: "policy"}},
+		&discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "webhook-1", Namespace: "policy",
+				Labels: map[string]string{discoveryv1.LabelServiceName: "webhook"},
+			},
+			Endpoints: []discoveryv1.Endpoint{{Addresses: []string{"10.0.0.1"}, Conditions: discoveryv1.EndpointConditions{
+				Ready: ptr.To(false),
+			}}},
+		},
+	)
+	clientset := kubefake.NewSimpleClientset(objects...)
+
+	apiService := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": "v1beta1.metrics.k8s.io"},
+		"spec":       map[string]interface{}{"service": map[string]interface{}{"namespace": "kube-system", "name": "metrics-server"}},
+		"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{
+			"type": "Available", "status": "False", "reason": "MissingEndpoints", "message": "endpoints for service/metrics-server have no addresses",
+		}}},
+	}}
+	local := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apiregistration.k8s.io/v1",
+		"kind":       "APIService",
+		"metadata":   map[string]interface{}{"name": "v1.apps"},
+		"status": map[string]interface{}{"conditions": []interface{}{map[string]interface{}{
+			"type": "Available", "status": "True",
+		}}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{apiServicesGVR: "APIServiceList"}, apiService, local)
+
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.FakedServerVersion = &version.Info{GitVersion: "v1.34.1", Platform: "linux/amd64"}
+
+	client := &Client{}
+	client.SetClientset(clientset)
+	client.SetDynamicClient(dynamicClient)
+	client.SetDiscoveryClient(fakeDiscovery)
+	return client, clientset
+}
+
+func TestClusterSummary(t *testing.T) {
+	client, _ := newClusterSummaryTestClient()
+
+	summary, err := client.ClusterSummary(context.Background(), "")
+	require.NoError(t, err)
+	assert.Empty(t, summary.Errors)
+	assert.Equal(t, "v1.34.1", summary.ServerVersion)
+	assert.Equal(t, "linux/amd64", summary.Platform)
+
+	assert.Equal(t, &NodesSummary{
+		Total:         2,
+		Ready:         1,
+		Unschedulable: 1,
+		Problems: []NodeProblem{{
+			Name:       "node-2",
+			Conditions: []string{"Ready=False: KubeletNotReady", "DiskPressure=True: KubeletHasDiskPressure"},
+		}},
+	}, summary.Nodes)
+
+	assert.Equal(t, 3, summary.Pods.Total)
+	assert.Equal(t, map[string]int{"Running": 2, "Pending": 1}, summary.Pods.ByPhase)
+	assert.Equal(t, []PodRestarts{
+		{Namespace: "shop", Name: "web", Restarts: 8, Reason: "CrashLoopBackOff"},
+		{Namespace: "shop", Name: "worker", Restarts: 2, Reason: "OOMKilled"},
+	}, summary.Pods.TopRestarts)
+
+	assert.ElementsMatch(t, []WorkloadSummary{
+		{Kind: "Deployment", Namespace: "shop", Name: "web", Desired: 3, Ready: 2, Available: 2},
+		{Kind: "StatefulSet", Namespace: "shop", Name: "db", Desired: 1},
+	}, summary.FailingWorkloads)
+
+	require.Len(t, summary.PendingPVCs, 1)
+	assert.Equal(t, "data", summary.PendingPVCs[0].Name)
+	assert.Equal(t, "fast", summary.PendingPVCs[0].StorageClass)
+
+	require.Len(t, summary.WarningEvents, 2)
+	assert.Equal(t, "BackOff", summary.WarningEvents[0].Reason)
+	assert.Equal(t, "Pod/web", summary.WarningEvents[0].Object)
+	assert.Equal(t, int32(12), summary.WarningEvents[0].Count)
+	assert.Equal(t, "ProvisioningFailed", summary.WarningEvents[1].Reason)
+
+	assert.Equal(t, []APIServiceSummary{{
+		Name:    "v1beta1.metrics.k8s.io",
+		Service: "kube-system/metrics-server",
+		Reason:  "MissingEndpoints",
+		Message: "endpoints for service/metrics-server have no addresses",
+	}}, summary.FailingAPIServices)
+
+	require.Len(t, summary.FailingWebhooks, 2)
+	assert.Equal(t, WebhookSummary{
+		Configuration: "ValidatingWebhookConfiguration/policy",
+		Webhook:       "check.policy.example.com",
+		Service:       "policy/webhook",
+		FailurePolicy: "Fail",
+		Problem:       "service has no ready endpoints",
+	}, summary.FailingWebhooks[0])
+	assert.Equal(t, "MutatingWebhookConfiguration/injector", summary.FailingWebhooks[1].Configuration)
+	assert.Contains(t, summary.FailingWebhooks[1].Problem, "service unavailable")
+	assert.Nil(t, summary.Omitted)
+}
+
+func TestClusterSummaryNamespace(t *testing.T) {
+	client, _ := newClusterSummaryTestClient()
+
+	summary, err := client.ClusterSummary(context.Background(), "batch")
+	require.NoError(t, err)
+	assert.Equal(t, "batch", summary.Namespace)
+	assert.Equal(t, 1, summary.Pods.Total)
+	assert.Empty(t, summary.Pods.TopRestarts)
+	assert.Empty(t, summary.FailingWorkloads)
+	assert.Empty(t, summary.PendingPVCs)
+	assert.Empty(t, summary.WarningEvents)
+	// Cluster-scoped sections are not filtered
+	assert.Equal(t, 2, summary.Nodes.Total)
+}
+
+func TestClusterSummaryTruncated(t *testing.T) {
+	var pods []runtime.Object
+	for i := range MaxSummaryItems + 3 {
+		pods = append(pods, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("crash-%d", i), Namespace: "load"},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{Name: "app", RestartCount: int32(100 + i)}},
+			},
+		})
+	}
+	client, _ := newClusterSummaryTestClient(pods...)
+
+	summary, err := client.ClusterSummary(context.Background(), "load")
+	require.NoError(t, err)
+	require.Len(t, summary.Pods.TopRestarts, MaxSummaryItems)
+	assert.Equal(t, int32(100+MaxSummaryItems+2), summary.Pods.TopRestarts[0].Restarts)
+	assert.Equal(t, map[string]int{"pods.topRestarts": 3}, summary.Omitted)
+}
+
+func TestClusterSummaryPartialFailure(t *testing.T) {
+	client, clientset := newClusterSummaryTestClient()
+	clientset.PrependReactor("list", "nodes", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "nodes"}, "", fmt.Errorf("RBAC"))
+	})
+
+	summary, err := client.ClusterSummary(context.Background(), "")
+	require.NoError(t, err)
+	assert.Nil(t, summary.Nodes)
+	require.Len(t, summary.Errors, 1)
+	assert.Contains(t, summary.Errors[0], "nodes: ")
+	assert.Equal(t, 3, summary.Pods.Total)
+}
+
+func TestClusterSummaryWebhookServiceForbidden(t *testing.T) {
+	client, clientset := newClusterSummaryTestClient()
+	clientset.PrependReactor("get", "services", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetNamespace() != "policy" {
+			return false, nil, nil
+		}
+		return true, nil, apierrors.NewForbidden(schema.GroupResource{Resource: "services"}, "webhook", fmt.Errorf("RBAC"))
+	})
+
+	summary, err := client.ClusterSummary(context.Background(), "")
+	require.NoError(t, err)
+	// The webhook whose service cannot be read is not reported as failing
+	require.Len(t, summary.FailingWebhooks, 1)
+	assert.Equal(t, "MutatingWebhookConfiguration/injector", summary.FailingWebhooks[0].Configuration)
+	require.Len(t, summary.Errors, 1)
+	assert.Contains(t, summary.Errors[0], "failingWebhooks: failed to check service policy/webhook")
+	assert.Contains(t, summary.Errors[0], "forbidden")
+}
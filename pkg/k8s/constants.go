@@ -18,9 +18,13 @@ const (
 	fieldError      = "error"
 	fieldLogs       = "logs"
 	fieldTruncated  = "truncated"
+	fieldFollow     = "follow"
+	fieldExitCode   = "exitCode"
+	fieldDebug      = "debug"
 
-	apiVersionV1    = "v1"
-	kindPod         = "Pod"
-	resourcePods    = "pods"
-	subresourceExec = "exec"
+	apiVersionV1           = "v1"
+	kindPod                = "Pod"
+	resourcePods           = "pods"
+	subresourceExec        = "exec"
+	subresourcePortForward = "portforward"
 )
@@ -0,0 +1,258 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Caps on file copies to and from pods. Copies are buffered in memory, so
+// both directions are bounded; exceeding a cap fails the copy rather than
+// returning a silently truncated file.
+const (
+	// MaxCopyFromPodBytes caps the size of the tar stream read from a pod.
+	MaxCopyFromPodBytes int64 = 8 << 20 // 8 MiB
+	// MaxCopyToPodBytes caps the size of a file written into a pod.
+	MaxCopyToPodBytes int64 = 1 << 20 // 1 MiB
+	// maxCopyFromPodFiles caps the number of regular files returned when
+	// copying a directory out of a pod.
+	maxCopyFromPodFiles = 100
+	// maxCopyStderrBytes caps the stderr captured from the tar command.
+	maxCopyStderrBytes int64 = 16 << 10 // 16 KiB
+)
+
+// PodFile is a regular file copied out of a pod.
+type PodFile struct {
+	// Path is the absolute path of the file inside the container.
+	Path string
+	// Mode holds the permission bits of the file.
+	Mode int64
+	// Data is the content of the file.
+	Data []byte
+}
+
+// SanitizePodPath validates a path inside a container and returns its cleaned
+// form. Paths must be absolute and must not contain ".." components, so that
+// a caller cannot escape the directory it names (e.g. when the path is used as
+// the target of tar extraction).
+func SanitizePodPath(p string) (string, error) {
+	if p == "" {
+		return "", fmt.Errorf("path cannot be empty")
+	}
+	if strings.ContainsRune(p, 0) {
+		return "", fmt.Errorf("path contains a NUL byte")
+	}
+	if !strings.HasPrefix(p, "/") {
+		return "", fmt.Errorf("path must be absolute: %q", p)
+	}
+	if hasDotDot(p) {
+		return "", fmt.Errorf("path must not contain '..': %q", p)
+	}
+	cleaned := path.Clean(p)
+	if cleaned == "/" {
+		return "", fmt.Errorf("path must not be the root directory")
+	}
+	return cleaned, nil
+}
+
+// CopyFromPod copies a file or directory out of a pod container by running
+// `tar cf -` through the exec subresource, like `kubectl cp`. It returns the
+// regular files found under srcPath, with absolute paths. The container image
+// must provide a tar binary.
+func (c *Client) CopyFromPod(
+	ctx context.Context,
+	namespace, name, container, srcPath string,
+) ([]PodFile, error) {
+	if name == "" {
+		return nil, fmt.Errorf("pod name cannot be empty")
+	}
+	srcPath, err := SanitizePodPath(srcPath)
+	if err != nil {
+		return nil, err
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, MaxExecTimeout)
+	defer cancel()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	dir, base := path.Split(srcPath)
+	command := []string{"tar", "cf", "-", "-C", dir, base}
+
+	// Pipe the tar stream straight into the parser so that only the extracted
+	// files, not the whole archive, are held in memory.
+	reader, writer := io.Pipe()
+	stderr := newBoundedBuffer(maxCopyStderrBytes)
+	execErr := make(chan error, 1)
+	go func() {
+		err := c.streamExec(execCtx, namespace, name, container, command, nil, writer, stderr)
+		_ = writer.CloseWithError(err)
+		execErr <- err
+	}()
+
+	files, readErr := readTarFiles(reader, dir, MaxCopyFromPodBytes)
+	if readErr != nil {
+		// Stop the remote command rather than waiting for it to finish.
+		cancel()
+	}
+	// Unblock the exec goroutine if it is still writing.
+	_ = reader.CloseWithError(io.ErrClosedPipe)
+	err = <-execErr
+
+	if readErr != nil {
+		return nil, readErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy from pod: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no regular files found at %s", srcPath)
+	}
+
+	return files, nil
+}
+
+// readTarFiles extracts the regular files of a tar stream into memory. Entry
+// names are resolved against dir; entries that would resolve outside of it
+// are rejected. Non-regular entries (directories, links, devices) are skipped.
+// Reading more than maxBytes from the stream, or more than
+// maxCopyFromPodFiles files, fails.
+func readTarFiles(stream io.Reader, dir string, maxBytes int64) ([]PodFile, error) {
+	limited := &io.LimitedReader{R: stream, N: maxBytes + 1}
+	tr := tar.NewReader(limited)
+
+	var files []PodFile
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			if limited.N <= 0 {
+				return nil, fmt.Errorf("copy exceeds maximum size of %d bytes", maxBytes)
+			}
+			return nil, fmt.Errorf("failed to read tar stream: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if path.IsAbs(hdr.Name) || hasDotDot(hdr.Name) {
+			return nil, fmt.Errorf("tar entry escapes the source directory: %q", hdr.Name)
+		}
+		filePath := path.Join(dir, hdr.Name)
+		if len(files) >= maxCopyFromPodFiles {
+			return nil, fmt.Errorf("copy exceeds maximum of %d files", maxCopyFromPodFiles)
+		}
+
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			if limited.N <= 0 {
+				return nil, fmt.Errorf("copy exceeds maximum size of %d bytes", maxBytes)
+			}
+			return nil, fmt.Errorf("failed to read %s from tar stream: %w", hdr.Name, err)
+		}
+		files = append(files, PodFile{
+			Path: filePath,
+			Mode: hdr.Mode & 0o7777,
+			Data: data,
+		})
+	}
+
+	// tar pads archives to a whole number of records after the end-of-archive
+	// marker. Drain the padding so the remote command can exit cleanly.
+	if _, err := io.Copy(io.Discard, limited); err != nil {
+		return nil, fmt.Errorf("failed to read tar stream: %w", err)
+	}
+	if limited.N <= 0 {
+		return nil, fmt.Errorf("copy exceeds maximum size of %d bytes", maxBytes)
+	}
+
+	return files, nil
+}
+
+// hasDotDot reports whether any slash-separated component of p is "..".
+func hasDotDot(p string) bool {
+	for _, part := range strings.Split(p, "/") {
+		if part == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+// CopyToPod writes data to destPath inside a pod container by streaming a
+// single-file tar archive into `tar xf -` through the exec subresource, like
+// `kubectl cp`. The parent directory of destPath must already exist, and the
+// container image must provide a tar binary.
+func (c *Client) CopyToPod(
+	ctx context.Context,
+	namespace, name, container, destPath string,
+	data []byte,
+	mode int64,
+) error {
+	if name == "" {
+		return fmt.Errorf("pod name cannot be empty")
+	}
+	destPath, err := SanitizePodPath(destPath)
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > MaxCopyToPodBytes {
+		return fmt.Errorf("content exceeds maximum size of %d bytes", MaxCopyToPodBytes)
+	}
+
+	dir, base := path.Split(destPath)
+	archive, err := buildSingleFileTar(base, data, mode)
+	if err != nil {
+		return err
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, MaxExecTimeout)
+	defer cancel()
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// -o: don't try to restore the archive's owner, which fails for non-root
+	// users in most containers.
+	command := []string{"tar", "xof", "-", "-C", dir}
+	stderr := newBoundedBuffer(maxCopyStderrBytes)
+	if err := c.streamExec(execCtx, namespace, name, container, command, bytes.NewReader(archive), nil, stderr); err != nil {
+		return fmt.Errorf("failed to copy to pod: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return nil
+}
+
+// buildSingleFileTar returns a tar archive holding one regular file.
+func buildSingleFileTar(name string, data []byte, mode int64) ([]byte, error) {
+	if mode <= 0 {
+		mode = 0o644
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	hdr := &tar.Header{
+		Typeflag: tar.TypeReg,
+		Name:     name,
+		Mode:     mode & 0o7777,
+		Size:     int64(len(data)),
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return nil, fmt.Errorf("failed to write tar header: %w", err)
+	}
+	if _, err := tw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to write tar content: %w", err)
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close tar archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
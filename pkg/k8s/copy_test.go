@@ -0,0 +1,142 @@
+package k8s
+
+import (
+	"archive/tar"
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanitizePodPath(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		path     string
+		expected string
+		errorMsg string
+	}{
+		{name: "Absolute file", path: "/etc/nginx/nginx.conf", expected: "/etc/nginx/nginx.conf"},
+		{name: "Trailing slash", path: "/var/log/", expected: "/var/log"},
+		{name: "Duplicate slashes", path: "/var//log/./app.log", expected: "/var/log/app.log"},
+		{name: "Empty", path: "", errorMsg: "path cannot be empty"},
+		{name: "Relative", path: "etc/passwd", errorMsg: "path must be absolute"},
+		{name: "Traversal", path: "/tmp/../etc/shadow", errorMsg: "must not contain '..'"},
+		{name: "Root", path: "/", errorMsg: "root directory"},
+		{name: "NUL byte", path: "/tmp/a\x00b", errorMsg: "NUL byte"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			cleaned, err := SanitizePodPath(tc.path)
+			if tc.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, cleaned)
+		})
+	}
+}
+
+// buildTar writes a tar archive with the given entries, padded like GNU tar.
+func buildTar(t *testing.T, entries map[string]string, extra ...*tar.Header) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for name, content := range entries {
+		require.NoError(t, tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg, Name: name, Mode: 0o640, Size: int64(len(content)),
+		}))
+		_, err := tw.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	for _, hdr := range extra {
+		require.NoError(t, tw.WriteHeader(hdr))
+	}
+	require.NoError(t, tw.Close())
+	// Record padding that readTarFiles must drain.
+	buf.Write(make([]byte, 10240))
+	return buf.Bytes()
+}
+
+func TestReadTarFiles(t *testing.T) {
+	t.Parallel()
+
+	archive := buildTar(t, map[string]string{"conf/app.yaml": "key: value\n"},
+		&tar.Header{Typeflag: tar.TypeDir, Name: "conf/", Mode: 0o755},
+		&tar.Header{Typeflag: tar.TypeSymlink, Name: "conf/link", Linkname: "/etc/passwd"},
+	)
+
+	files, err := readTarFiles(bytes.NewReader(archive), "/etc/", 1<<20)
+	require.NoError(t, err)
+	require.Len(t, files, 1, "only regular files are returned")
+	assert.Equal(t, "/etc/conf/app.yaml", files[0].Path)
+	assert.Equal(t, "key: value\n", string(files[0].Data))
+	assert.Equal(t, int64(0o640), files[0].Mode)
+}
+
+func TestReadTarFiles_RejectsTraversal(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"../etc2/passwd", "/etc/passwd"} {
+		archive := buildTar(t, map[string]string{name: "root:x:0:0"})
+		_, err := readTarFiles(bytes.NewReader(archive), "/etc/", 1<<20)
+		require.Error(t, err, name)
+		assert.Contains(t, err.Error(), "escapes the source directory")
+	}
+}
+
+func TestReadTarFiles_RejectsOversizedStream(t *testing.T) {
+	t.Parallel()
+
+	archive := buildTar(t, map[string]string{"big.bin": strings.Repeat("x", 4096)})
+	_, err := readTarFiles(bytes.NewReader(archive), "/tmp/", 1024)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exceeds maximum size")
+}
+
+func TestBuildSingleFileTar(t *testing.T) {
+	t.Parallel()
+
+	archive, err := buildSingleFileTar("app.conf", []byte("listen 80;"), 0)
+	require.NoError(t, err)
+
+	tr := tar.NewReader(bytes.NewReader(archive))
+	hdr, err := tr.Next()
+	require.NoError(t, err)
+	assert.Equal(t, "app.conf", hdr.Name)
+	assert.Equal(t, int64(0o644), hdr.Mode, "mode defaults to 0644")
+	assert.Equal(t, int64(len("listen 80;")), hdr.Size)
+}
+
+func TestCopyToPod_Validation(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+	ctx := context.Background()
+
+	err := client.CopyToPod(ctx, "default", "", "", "/tmp/x", []byte("x"), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pod name cannot be empty")
+
+	err = client.CopyToPod(ctx, "default", "test-pod", "", "../x", []byte("x"), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "path must be absolute")
+
+	err = client.CopyToPod(ctx, "default", "test-pod", "", "/tmp/x", make([]byte, MaxCopyToPodBytes+1), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "content exceeds maximum size")
+
+	_, err = client.CopyFromPod(ctx, "default", "test-pod", "", "/tmp/../../etc")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not contain '..'")
+}
@@ -0,0 +1,370 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+// MaxDebugStartupTimeout is the maximum time to wait for a debug container
+// to start running (including pulling its image).
+const MaxDebugStartupTimeout = 2 * time.Minute
+
+const (
+	// defaultDebugStartupTimeout is used when no startup timeout is given.
+	defaultDebugStartupTimeout = 1 * time.Minute
+	// debugContainerLifetime is how long a debug container stays alive. The
+	// container only runs `sleep` so that commands can be executed in it;
+	// ephemeral containers cannot be removed from a pod, so they must exit
+	// on their own.
+	debugContainerLifetime = MaxDebugStartupTimeout + MaxExecTimeout + time.Minute
+	// debugCleanupTimeout bounds the deletion of a debug copy of a pod.
+	debugCleanupTimeout = 10 * time.Second
+	// debugContainerPrefix prefixes the names of debug containers and pod
+	// copies created by mkp.
+	debugContainerPrefix = "mkp-debug-"
+	// debugCopyOfAnnotation records the pod a debug copy was made from.
+	debugCopyOfAnnotation = "mkp.stacklok.com/debug-copy-of"
+	// subresourceEphemeralContainers is the pod subresource used to add
+	// ephemeral containers.
+	subresourceEphemeralContainers = "ephemeralcontainers"
+	// Debug modes reported in results.
+	debugModeEphemeral = "ephemeral"
+	debugModeCopy      = "copy"
+)
+
+// debugPollInterval is how often the pod is polled while waiting for a debug
+// container to start. It is a variable so that tests can shorten it.
+var debugPollInterval = time.Second
+
+// fatalWaitingReasons are container waiting reasons from which a debug
+// container will not recover without user intervention.
+var fatalWaitingReasons = map[string]bool{
+	"ErrImagePull":               true,
+	"ImagePullBackOff":           true,
+	"ErrImageNeverPull":          true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// DebugOptions configures a debug session started by DebugPod.
+type DebugOptions struct {
+	// Image is the image of the debug container. Callers are responsible for
+	// checking it against an allowlist.
+	Image string
+	// TargetContainer is the container whose process namespace the debug
+	// container joins. Optional.
+	TargetContainer string
+	// Command is executed in the debug container once it is running.
+	Command []string
+	// Timeout is the timeout of the command (see ExecInPod).
+	Timeout time.Duration
+	// StartupTimeout bounds the wait for the debug container to start
+	// running. Defaults to 1 minute and is capped at MaxDebugStartupTimeout.
+	StartupTimeout time.Duration
+	// CopyPod debugs a copy of the pod with the debug container added,
+	// instead of adding an ephemeral container to the pod itself. Use it
+	// when ephemeral containers are disallowed.
+	CopyPod bool
+	// KeepCopy keeps the copy of the pod after the command ran. Only used
+	// with CopyPod.
+	KeepCopy bool
+}
+
+// DebugPod starts a debug container for a pod, waits until it is running and
+// executes a command in it, like `kubectl debug`. By default the container is
+// added to the pod as an ephemeral container sharing the process namespace of
+// the target container. With CopyPod, a copy of the pod with the debug
+// container added (and a shared process namespace) is created instead, and
+// deleted once the command ran unless KeepCopy is set.
+//
+// The result has the same shape as ExecInPod, with a "debug" status field
+// describing the debug container.
+func (c *Client) DebugPod(
+	ctx context.Context,
+	namespace, name string,
+	opts DebugOptions,
+) (*unstructured.Unstructured, error) {
+	if name == "" {
+		return nil, fmt.Errorf("pod name cannot be empty")
+	}
+	if opts.Image == "" {
+		return nil, fmt.Errorf("debug image cannot be empty")
+	}
+	if len(opts.Command) == 0 {
+		return nil, fmt.Errorf("command cannot be empty")
+	}
+
+	startupTimeout := opts.StartupTimeout
+	if startupTimeout <= 0 {
+		startupTimeout = defaultDebugStartupTimeout
+	}
+	if startupTimeout > MaxDebugStartupTimeout {
+		startupTimeout = MaxDebugStartupTimeout
+	}
+
+	// Don't hold the lock while waiting: ExecInPod takes it again, and a
+	// refresh must not be blocked for the duration of the session.
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	if opts.TargetContainer != "" && !hasContainer(pod, opts.TargetContainer) {
+		return nil, fmt.Errorf("container %q not found in pod %s/%s", opts.TargetContainer, namespace, name)
+	}
+
+	container := debugContainer(debugContainerPrefix+utilrand.String(5), opts.Image)
+
+	debugInfo := map[string]interface{}{
+		fieldName: container.Name,
+		"image":   opts.Image,
+	}
+
+	var result *unstructured.Unstructured
+	if opts.CopyPod {
+		debugInfo["mode"] = debugModeCopy
+		result, err = c.debugPodCopy(ctx, clientset, pod, container, opts, startupTimeout, debugInfo)
+	} else {
+		debugInfo["mode"] = debugModeEphemeral
+		if opts.TargetContainer != "" {
+			debugInfo["targetContainer"] = opts.TargetContainer
+		}
+		result, err = c.debugPodEphemeral(ctx, clientset, pod, container, opts, startupTimeout)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := unstructured.SetNestedField(result.Object, debugInfo, fieldStatus, fieldDebug); err != nil {
+		return nil, fmt.Errorf("failed to set debug status: %w", err)
+	}
+	return result, nil
+}
+
+// debugPodEphemeral adds container to pod as an ephemeral container, waits
+// until it is running and executes the command in it.
+func (c *Client) debugPodEphemeral(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	pod *corev1.Pod,
+	container corev1.Container,
+	opts DebugOptions,
+	startupTimeout time.Duration,
+) (*unstructured.Unstructured, error) {
+	ephemeral := corev1.EphemeralContainer{
+		EphemeralContainerCommon: corev1.EphemeralContainerCommon(container),
+		TargetContainerName:      opts.TargetContainer,
+	}
+
+	// Ephemeral containers are merged by name, so a strategic merge patch
+	// holding only the new container adds it to the existing ones.
+	patch, err := json.Marshal(map[string]interface{}{
+		fieldSpec: map[string]interface{}{
+			"ephemeralContainers": []corev1.EphemeralContainer{ephemeral},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build ephemeral container patch: %w", err)
+	}
+
+	pods := clientset.CoreV1().Pods(pod.Namespace)
+	_, err = pods.Patch(ctx, pod.Name, k8stypes.StrategicMergePatchType, patch,
+		metav1.PatchOptions{}, subresourceEphemeralContainers)
+	if err != nil {
+		if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) || apierrors.IsInvalid(err) {
+			return nil, fmt.Errorf("failed to add ephemeral debug container (use copy mode if ephemeral "+
+				"containers are not allowed for this pod): %w", err)
+		}
+		return nil, fmt.Errorf("failed to add ephemeral debug container: %w", err)
+	}
+
+	err = waitForDebugContainer(ctx, clientset, pod.Namespace, pod.Name, startupTimeout,
+		func(p *corev1.Pod) []corev1.ContainerStatus {
+			return p.Status.EphemeralContainerStatuses
+		}, container.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExecInPod(ctx, pod.Namespace, pod.Name, opts.Command, container.Name, "", opts.Timeout)
+}
+
+// debugPodCopy creates a copy of pod with container added, waits until the
+// debug container is running and executes the command in it. The copy is
+// deleted afterwards unless opts.KeepCopy is set.
+func (c *Client) debugPodCopy(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	pod *corev1.Pod,
+	container corev1.Container,
+	opts DebugOptions,
+	startupTimeout time.Duration,
+	debugInfo map[string]interface{},
+) (*unstructured.Unstructured, error) {
+	pods := clientset.CoreV1().Pods(pod.Namespace)
+
+	created, err := pods.Create(ctx, debugPodCopySpec(pod, container), metav1.CreateOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create debug copy of pod: %w", err)
+	}
+	debugInfo["pod"] = created.Name
+	debugInfo["kept"] = opts.KeepCopy
+
+	if !opts.KeepCopy {
+		defer func() {
+			// Clean up even if the request was cancelled.
+			cleanupCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), debugCleanupTimeout)
+			defer cancel()
+			_ = pods.Delete(cleanupCtx, created.Name, metav1.DeleteOptions{})
+		}()
+	}
+
+	err = waitForDebugContainer(ctx, clientset, created.Namespace, created.Name, startupTimeout,
+		func(p *corev1.Pod) []corev1.ContainerStatus {
+			return p.Status.ContainerStatuses
+		}, container.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.ExecInPod(ctx, created.Namespace, created.Name, opts.Command, container.Name, "", opts.Timeout)
+}
+
+// debugContainer returns the spec of a debug container. It only sleeps, so
+// that commands can be executed in it, and exits on its own after
+// debugContainerLifetime.
+func debugContainer(name, image string) corev1.Container {
+	return corev1.Container{
+		Name:                     name,
+		Image:                    image,
+		Command:                  []string{"sleep", strconv.Itoa(int(debugContainerLifetime.Seconds()))},
+		ImagePullPolicy:          corev1.PullIfNotPresent,
+		TerminationMessagePolicy: corev1.TerminationMessageReadFile,
+	}
+}
+
+// debugPodCopySpec returns a copy of pod suitable for debugging, with
+// container added. The copy drops the labels and owner references of the
+// original, so that it neither receives Service traffic nor gets adopted by a
+// controller, and drops probes so that a misbehaving application is not
+// restarted while being debugged. All containers share a process namespace.
+func debugPodCopySpec(pod *corev1.Pod, container corev1.Container) *corev1.Pod {
+	spec := pod.Spec.DeepCopy()
+	spec.NodeName = ""
+	spec.EphemeralContainers = nil
+	shareProcessNamespace := true
+	spec.ShareProcessNamespace = &shareProcessNamespace
+	for i := range spec.Containers {
+		spec.Containers[i].LivenessProbe = nil
+		spec.Containers[i].ReadinessProbe = nil
+		spec.Containers[i].StartupProbe = nil
+	}
+	spec.Containers = append(spec.Containers, container)
+
+	// Keep the generated name within the limit for pod names.
+	suffix := "-" + container.Name
+	base := pod.Name
+	if maxBase := 253 - len(suffix); len(base) > maxBase {
+		base = base[:maxBase]
+	}
+
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      base + suffix,
+			Namespace: pod.Namespace,
+			Labels: map[string]string{
+				"app.kubernetes.io/managed-by": "mkp",
+			},
+			Annotations: map[string]string{
+				debugCopyOfAnnotation: pod.Name,
+			},
+		},
+		Spec: *spec,
+	}
+}
+
+// waitForDebugContainer polls a pod until the named container is running.
+// statuses selects the container statuses to look the container up in. It
+// fails early when the container terminated or cannot start.
+func waitForDebugContainer(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	namespace, name string,
+	timeout time.Duration,
+	statuses func(*corev1.Pod) []corev1.ContainerStatus,
+	container string,
+) error {
+	var lastState string
+	err := wait.PollUntilContextTimeout(ctx, debugPollInterval, timeout, true,
+		func(ctx context.Context) (bool, error) {
+			pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+			if err != nil {
+				return false, fmt.Errorf("failed to get pod: %w", err)
+			}
+			for _, status := range statuses(pod) {
+				if status.Name != container {
+					continue
+				}
+				running, state, err := debugContainerState(status)
+				lastState = state
+				return running, err
+			}
+			lastState = "pod phase " + string(pod.Status.Phase)
+			return false, nil
+		})
+	if err != nil {
+		if wait.Interrupted(err) {
+			return fmt.Errorf("timed out waiting for debug container %s to start (last state: %s)", container, lastState)
+		}
+		return err
+	}
+	return nil
+}
+
+// debugContainerState reports whether a container is running, along with a
+// short description of its state. It returns an error when the container
+// terminated or is stuck in a state it will not recover from.
+func debugContainerState(status corev1.ContainerStatus) (bool, string, error) {
+	switch {
+	case status.State.Running != nil:
+		return true, "running", nil
+	case status.State.Terminated != nil:
+		terminated := status.State.Terminated
+		return false, "terminated", fmt.Errorf("debug container %s terminated: %s (exit code %d) %s",
+			status.Name, terminated.Reason, terminated.ExitCode, terminated.Message)
+	case status.State.Waiting != nil:
+		waiting := status.State.Waiting
+		if fatalWaitingReasons[waiting.Reason] {
+			return false, waiting.Reason, fmt.Errorf("debug container %s cannot start: %s: %s",
+				status.Name, waiting.Reason, waiting.Message)
+		}
+		return false, "waiting: " + waiting.Reason, nil
+	}
+	return false, "unknown", nil
+}
+
+// hasContainer reports whether pod has a regular container with the given name.
+func hasContainer(pod *corev1.Pod, name string) bool {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,274 @@
+package k8s
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// debugTestPod returns a pod with a single "app" container.
+func debugTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pod",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "test"},
+		},
+		Spec: corev1.PodSpec{
+			NodeName: "node-1",
+			Containers: []corev1.Container{{
+				Name:          "app",
+				Image:         "distroless/app",
+				LivenessProbe: &corev1.Probe{},
+			}},
+		},
+	}
+}
+
+// setDebugContainerStatus makes the fake clientset report every debug
+// container of a pod in the given state when the pod is read.
+func setDebugContainerStatus(fakeClientset *kubefake.Clientset, state corev1.ContainerState) {
+	fakeClientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get := action.(k8stesting.GetAction)
+		obj, err := fakeClientset.Tracker().Get(action.GetResource(), get.GetNamespace(), get.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*corev1.Pod).DeepCopy()
+		for _, ec := range pod.Spec.EphemeralContainers {
+			pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses,
+				corev1.ContainerStatus{Name: ec.Name, State: state})
+		}
+		for _, c := range pod.Spec.Containers {
+			if strings.HasPrefix(c.Name, debugContainerPrefix) {
+				pod.Status.ContainerStatuses = append(pod.Status.ContainerStatuses,
+					corev1.ContainerStatus{Name: c.Name, State: state})
+			}
+		}
+		return true, pod, nil
+	})
+}
+
+// newDebugTestClient returns a client backed by a fake clientset holding
+// debugTestPod, with an exec mock recording the pod and container it ran in.
+func newDebugTestClient(t *testing.T) (*Client, *kubefake.Clientset, *[]string) {
+	t.Helper()
+	fakeClientset := kubefake.NewSimpleClientset(debugTestPod())
+	client := &Client{}
+	client.SetClientset(fakeClientset)
+
+	var execTargets []string
+	client.SetExecInPodFunc(func(_ context.Context, namespace, name string, command []string,
+		container string, _ string, _ time.Duration) (*unstructured.Unstructured, error) {
+		execTargets = append(execTargets, name+"/"+container)
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": name, "namespace": namespace},
+			"spec":       map[string]interface{}{"command": command},
+			"status":     map[string]interface{}{"stdout": "debug output", "stderr": "", "error": ""},
+		}}, nil
+	})
+	return client, fakeClientset, &execTargets
+}
+
+func TestDebugPodEphemeral(t *testing.T) {
+	client, fakeClientset, execTargets := newDebugTestClient(t)
+	setDebugContainerStatus(fakeClientset, corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+
+	result, err := client.DebugPod(context.Background(), "default", "test-pod", DebugOptions{
+		Image:           "busybox:1.37",
+		TargetContainer: "app",
+		Command:         []string{"ps", "aux"},
+	})
+	require.NoError(t, err)
+
+	stdout, _, _ := unstructured.NestedString(result.Object, "status", "stdout")
+	assert.Equal(t, "debug output", stdout)
+	mode, _, _ := unstructured.NestedString(result.Object, "status", "debug", "mode")
+	assert.Equal(t, debugModeEphemeral, mode)
+	containerName, _, _ := unstructured.NestedString(result.Object, "status", "debug", "name")
+	assert.True(t, strings.HasPrefix(containerName, debugContainerPrefix))
+
+	// The ephemeral container was added through the subresource.
+	var patched bool
+	for _, action := range fakeClientset.Actions() {
+		if action.GetVerb() == "patch" && action.GetSubresource() == subresourceEphemeralContainers {
+			patched = true
+		}
+	}
+	assert.True(t, patched, "expected a patch of the ephemeralcontainers subresource")
+
+	pod, err := fakeClientset.CoreV1().Pods("default").Get(context.Background(), "test-pod", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Len(t, pod.Spec.EphemeralContainers, 1)
+	ec := pod.Spec.EphemeralContainers[0]
+	assert.Equal(t, containerName, ec.Name)
+	assert.Equal(t, "busybox:1.37", ec.Image)
+	assert.Equal(t, "app", ec.TargetContainerName)
+
+	assert.Equal(t, []string{"test-pod/" + containerName}, *execTargets)
+}
+
+func TestDebugPodCopy(t *testing.T) {
+	client, fakeClientset, execTargets := newDebugTestClient(t)
+	setDebugContainerStatus(fakeClientset, corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+
+	var created *corev1.Pod
+	fakeClientset.PrependReactor("create", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		created = action.(k8stesting.CreateAction).GetObject().(*corev1.Pod).DeepCopy()
+		return false, nil, nil
+	})
+
+	result, err := client.DebugPod(context.Background(), "default", "test-pod", DebugOptions{
+		Image:   "busybox:1.37",
+		Command: []string{"ls"},
+		CopyPod: true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, created)
+
+	copyName, _, _ := unstructured.NestedString(result.Object, "status", "debug", "pod")
+	assert.Equal(t, created.Name, copyName)
+	assert.True(t, strings.HasPrefix(copyName, "test-pod-"+debugContainerPrefix))
+
+	// The copy is detached from the original and shares a process namespace.
+	assert.NotContains(t, created.Labels, "app")
+	assert.Equal(t, "test-pod", created.Annotations[debugCopyOfAnnotation])
+	assert.Empty(t, created.Spec.NodeName)
+	require.NotNil(t, created.Spec.ShareProcessNamespace)
+	assert.True(t, *created.Spec.ShareProcessNamespace)
+	require.Len(t, created.Spec.Containers, 2)
+	assert.Nil(t, created.Spec.Containers[0].LivenessProbe)
+	assert.Equal(t, "busybox:1.37", created.Spec.Containers[1].Image)
+
+	require.Len(t, *execTargets, 1)
+	assert.True(t, strings.HasPrefix((*execTargets)[0], copyName+"/"))
+
+	// The copy is deleted once the command ran.
+	_, err = fakeClientset.CoreV1().Pods("default").Get(context.Background(), copyName, metav1.GetOptions{})
+	assert.Error(t, err)
+}
+
+func TestDebugPodKeepCopy(t *testing.T) {
+	client, fakeClientset, _ := newDebugTestClient(t)
+	setDebugContainerStatus(fakeClientset, corev1.ContainerState{Running: &corev1.ContainerStateRunning{}})
+
+	result, err := client.DebugPod(context.Background(), "default", "test-pod", DebugOptions{
+		Image:    "busybox:1.37",
+		Command:  []string{"ls"},
+		CopyPod:  true,
+		KeepCopy: true,
+	})
+	require.NoError(t, err)
+
+	copyName, _, _ := unstructured.NestedString(result.Object, "status", "debug", "pod")
+	_, err = fakeClientset.CoreV1().Pods("default").Get(context.Background(), copyName, metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDebugPodImagePullFailure(t *testing.T) {
+	client, fakeClientset, execTargets := newDebugTestClient(t)
+	setDebugContainerStatus(fakeClientset, corev1.ContainerState{
+		Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull", Message: "not found"},
+	})
+
+	_, err := client.DebugPod(context.Background(), "default", "test-pod", DebugOptions{
+		Image:   "busybox:missing",
+		Command: []string{"ls"},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "ErrImagePull")
+	assert.Empty(t, *execTargets, "no command runs when the container fails to start")
+}
+
+func TestDebugPodStartupTimeout(t *testing.T) {
+	origInterval := debugPollInterval
+	debugPollInterval = 10 * time.Millisecond
+	defer func() { debugPollInterval = origInterval }()
+
+	client, _, _ := newDebugTestClient(t)
+
+	_, err := client.DebugPod(context.Background(), "default", "test-pod", DebugOptions{
+		Image:          "busybox:1.37",
+		Command:        []string{"ls"},
+		StartupTimeout: 50 * time.Millisecond,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "timed out waiting for debug container")
+}
+
+func TestDebugPodValidation(t *testing.T) {
+	client, _, _ := newDebugTestClient(t)
+
+	testCases := []struct {
+		name     string
+		pod      string
+		opts     DebugOptions
+		errorMsg string
+	}{
+		{
+			name:     "Missing image",
+			pod:      "test-pod",
+			opts:     DebugOptions{Command: []string{"ls"}},
+			errorMsg: "debug image cannot be empty",
+		},
+		{
+			name:     "Missing command",
+			pod:      "test-pod",
+			opts:     DebugOptions{Image: "busybox"},
+			errorMsg: "command cannot be empty",
+		},
+		{
+			name:     "Unknown target container",
+			pod:      "test-pod",
+			opts:     DebugOptions{Image: "busybox", Command: []string{"ls"}, TargetContainer: "sidecar"},
+			errorMsg: `container "sidecar" not found`,
+		},
+		{
+			name:     "Unknown pod",
+			pod:      "missing",
+			opts:     DebugOptions{Image: "busybox", Command: []string{"ls"}},
+			errorMsg: "failed to get pod",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.DebugPod(context.Background(), "default", tc.pod, tc.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
+
+func TestDebugContainerState(t *testing.T) {
+	running, _, err := debugContainerState(corev1.ContainerStatus{
+		State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+	})
+	assert.True(t, running)
+	assert.NoError(t, err)
+
+	running, state, err := debugContainerState(corev1.ContainerStatus{
+		State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+	})
+	assert.False(t, running)
+	assert.NoError(t, err)
+	assert.Equal(t, "waiting: ContainerCreating", state)
+
+	_, _, err = debugContainerState(corev1.ContainerStatus{
+		State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 127, Reason: "Error"}},
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "exit code 127")
+}
@@ -0,0 +1,240 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/dynamic"
+)
+
+// MaxDeleteCollectionItems is the largest number of objects a single
+// DeleteCollection call may delete.
+const MaxDeleteCollectionItems = 500
+
+// DeleteOptions configures the deletion of resources.
+type DeleteOptions struct {
+	// PropagationPolicy is how dependents are garbage collected: Foreground,
+	// Background or Orphan. Empty uses the resource's default.
+	PropagationPolicy string
+	// GracePeriodSeconds overrides the grace period of the deletion. Nil uses
+	// the resource's default.
+	GracePeriodSeconds *int64
+	// UID, if set, only deletes the object if it still has this UID, so that
+	// an object recreated under the same name is left alone.
+	UID string
+	// ResourceVersion, if set, only deletes the object if it was not
+	// modified since this resourceVersion.
+	ResourceVersion string
+}
+
+// DeletedObject identifies an object deleted, or that would be deleted, by a
+// DeleteCollection call.
+type DeletedObject struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	UID       string `json:"uid"`
+}
+
+// DeleteCollectionResult is the result of a DeleteCollection call.
+type DeleteCollectionResult struct {
+	Group         string          `json:"group"`
+	Version       string          `json:"version"`
+	Resource      string          `json:"resource"`
+	Namespace     string          `json:"namespace,omitempty"`
+	LabelSelector string          `json:"labelSelector"`
+	Preview       bool            `json:"preview,omitempty"`
+	Count         int             `json:"count"`
+	Items         []DeletedObject `json:"items"`
+	Message       string          `json:"message"`
+}
+
+// metaDeleteOptions converts opts to the API's DeleteOptions.
+func (opts DeleteOptions) metaDeleteOptions() (metav1.DeleteOptions, error) {
+	deleteOptions := metav1.DeleteOptions{GracePeriodSeconds: opts.GracePeriodSeconds}
+
+	if opts.PropagationPolicy != "" {
+		policy := metav1.DeletionPropagation(opts.PropagationPolicy)
+		switch policy {
+		case metav1.DeletePropagationForeground, metav1.DeletePropagationBackground, metav1.DeletePropagationOrphan:
+			deleteOptions.PropagationPolicy = &policy
+		default:
+			return metav1.DeleteOptions{}, fmt.Errorf("invalid propagation policy %q, must be one of %s, %s or %s",
+				opts.PropagationPolicy, metav1.DeletePropagationForeground, metav1.DeletePropagationBackground,
+				metav1.DeletePropagationOrphan)
+		}
+	}
+	if opts.GracePeriodSeconds != nil && *opts.GracePeriodSeconds < 0 {
+		return metav1.DeleteOptions{}, fmt.Errorf("grace period must not be negative")
+	}
+
+	if opts.UID != "" || opts.ResourceVersion != "" {
+		deleteOptions.Preconditions = &metav1.Preconditions{}
+		if opts.UID != "" {
+			uid := k8stypes.UID(opts.UID)
+			deleteOptions.Preconditions.UID = &uid
+		}
+		if opts.ResourceVersion != "" {
+			deleteOptions.Preconditions.ResourceVersion = &opts.ResourceVersion
+		}
+	}
+	return deleteOptions, nil
+}
+
+// DeleteResource deletes a resource with the given options. An empty
+// namespace addresses a cluster-scoped resource.
+func (c *Client) DeleteResource(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	namespace, name string,
+	opts DeleteOptions,
+) error {
+	if name == "" {
+		return fmt.Errorf("resource name cannot be empty")
+	}
+	deleteOptions, err := opts.metaDeleteOptions()
+	if err != nil {
+		return err
+	}
+
+	c.mu.RLock()
+	client := resourceInterface(c.dynamicClient, gvr, namespace)
+	c.mu.RUnlock()
+
+	return client.Delete(ctx, name, deleteOptions)
+}
+
+// DeleteCollection deletes the objects matching labelSelector, refusing to
+// delete anything when more than maxItems objects match. With preview set,
+// the matching objects are only returned. An empty namespace addresses a
+// cluster-scoped resource, or all namespaces of a namespaced resource.
+//
+// The objects are deleted with a single DeleteCollection request pinned to
+// the resourceVersion of the listing that was checked against maxItems, so
+// objects created in the meantime are not deleted. Resources that do not
+// support DeleteCollection are deleted one by one, guarded by their UID.
+func (c *Client) DeleteCollection(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	namespace, labelSelector string,
+	maxItems int,
+	preview bool,
+	opts DeleteOptions,
+) (*DeleteCollectionResult, error) {
+	if labelSelector == "" {
+		return nil, fmt.Errorf("label selector cannot be empty")
+	}
+	if _, err := labels.Parse(labelSelector); err != nil {
+		return nil, fmt.Errorf("invalid label selector: %w", err)
+	}
+	if maxItems <= 0 || maxItems > MaxDeleteCollectionItems {
+		return nil, fmt.Errorf("max items must be between 1 and %d", MaxDeleteCollectionItems)
+	}
+	if opts.UID != "" || opts.ResourceVersion != "" {
+		return nil, fmt.Errorf("UID and resourceVersion preconditions cannot be used with a label selector")
+	}
+	deleteOptions, err := opts.metaDeleteOptions()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	client := resourceInterface(dynamicClient, gvr, namespace)
+
+	list, err := listCapped(ctx, client, labelSelector, maxItems)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &DeleteCollectionResult{
+		Group:         gvr.Group,
+		Version:       gvr.Version,
+		Resource:      gvr.Resource,
+		Namespace:     namespace,
+		LabelSelector: labelSelector,
+		Preview:       preview,
+		Count:         len(list.Items),
+		Items:         make([]DeletedObject, 0, len(list.Items)),
+	}
+	for _, item := range list.Items {
+		result.Items = append(result.Items, DeletedObject{
+			Namespace: item.GetNamespace(),
+			Name:      item.GetName(),
+			UID:       string(item.GetUID()),
+		})
+	}
+
+	switch {
+	case preview:
+		result.Message = fmt.Sprintf("%d object(s) would be deleted", result.Count)
+		return result, nil
+	case result.Count == 0:
+		result.Message = "no objects match the label selector"
+		return result, nil
+	}
+
+	err = client.DeleteCollection(ctx, deleteOptions, metav1.ListOptions{
+		LabelSelector:        labelSelector,
+		ResourceVersion:      list.GetResourceVersion(),
+		ResourceVersionMatch: metav1.ResourceVersionMatchExact,
+	})
+	if apierrors.IsMethodNotSupported(err) {
+		err = deleteOneByOne(ctx, dynamicClient, gvr, result.Items, deleteOptions)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to delete collection: %w", err)
+	}
+
+	result.Message = fmt.Sprintf("%d object(s) deleted", result.Count)
+	return result, nil
+}
+
+// listCapped lists the objects matching labelSelector, failing when more
+// than maxItems objects match.
+func listCapped(
+	ctx context.Context,
+	client dynamic.ResourceInterface,
+	labelSelector string,
+	maxItems int,
+) (*unstructured.UnstructuredList, error) {
+	// Listing one more than the cap is enough to know whether it is exceeded.
+	list, err := client.List(ctx, metav1.ListOptions{LabelSelector: labelSelector, Limit: int64(maxItems) + 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+	if len(list.Items) > maxItems || list.GetContinue() != "" {
+		return nil, fmt.Errorf("more than %d objects match label selector %q; narrow the selector or raise max_items",
+			maxItems, labelSelector)
+	}
+	return list, nil
+}
+
+// deleteOneByOne deletes items one by one, for resources that do not support
+// DeleteCollection. Each delete is guarded by the UID of the item, so that an
+// object replaced in the meantime is left alone.
+func deleteOneByOne(
+	ctx context.Context,
+	dynamicClient dynamic.Interface,
+	gvr schema.GroupVersionResource,
+	items []DeletedObject,
+	deleteOptions metav1.DeleteOptions,
+) error {
+	for _, item := range items {
+		itemOptions := deleteOptions
+		uid := k8stypes.UID(item.UID)
+		itemOptions.Preconditions = &metav1.Preconditions{UID: &uid}
+		// A conflict means the object was replaced, and is left alone.
+		err := resourceInterface(dynamicClient, gvr, item.Namespace).Delete(ctx, item.Name, itemOptions)
+		if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+			return fmt.Errorf("failed to delete %s: %w", item.Name, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+var deleteJobsGVR = schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+
+func deleteTestJob(name string, labels map[string]interface{}) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "batch/v1",
+		"kind":       "Job",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"uid":       "uid-" + name,
+			"labels":    labels,
+		},
+	}}
+}
+
+func newDeleteTestClient(objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{deleteJobsGVR: "JobList"}, objects...)
+	client := &Client{}
+	client.SetDynamicClient(fakeDynamic)
+	return client, fakeDynamic
+}
+
+func TestDeleteResourceOptions(t *testing.T) {
+	client, fakeDynamic := newDeleteTestClient(deleteTestJob("report", nil))
+
+	var deleteOptions metav1.DeleteOptions
+	fakeDynamic.PrependReactor("delete", "jobs", func(action ktesting.Action) (bool, runtime.Object, error) {
+		deleteOptions = action.(ktesting.DeleteAction).GetDeleteOptions()
+		return false, nil, nil
+	})
+
+	gracePeriod := int64(0)
+	err := client.DeleteResource(context.Background(), deleteJobsGVR, "default", "report", DeleteOptions{
+		PropagationPolicy:  "Foreground",
+		GracePeriodSeconds: &gracePeriod,
+		UID:                "uid-report",
+		ResourceVersion:    "42",
+	})
+	require.NoError(t, err)
+
+	require.NotNil(t, deleteOptions.PropagationPolicy)
+	assert.Equal(t, metav1.DeletePropagationForeground, *deleteOptions.PropagationPolicy)
+	assert.Equal(t, &gracePeriod, deleteOptions.GracePeriodSeconds)
+	require.NotNil(t, deleteOptions.Preconditions)
+	assert.Equal(t, "uid-report", string(*deleteOptions.Preconditions.UID))
+	assert.Equal(t, "42", *deleteOptions.Preconditions.ResourceVersion)
+
+	_, err = fakeDynamic.Resource(deleteJobsGVR).Namespace("default").Get(context.Background(), "report", metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+}
+
+func TestDeleteResourceInvalidOptions(t *testing.T) {
+	client, _ := newDeleteTestClient()
+
+	err := client.DeleteResource(context.Background(), deleteJobsGVR, "default", "report", DeleteOptions{PropagationPolicy: "Eventually"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid propagation policy")
+
+	gracePeriod := int64(-1)
+	err = client.DeleteResource(context.Background(), deleteJobsGVR, "default", "report", DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "must not be negative")
+
+	err = client.DeleteResource(context.Background(), deleteJobsGVR, "default", "", DeleteOptions{})
+	require.Error(t, err)
+}
+
+func TestDeleteCollection(t *testing.T) {
+	client, fakeDynamic := newDeleteTestClient(
+		deleteTestJob("job-1", map[string]interface{}{"state": "complete"}),
+		deleteTestJob("job-2", map[string]interface{}{"state": "complete"}),
+		deleteTestJob("job-3", map[string]interface{}{"state": "running"}),
+	)
+
+	var listOptions metav1.ListOptions
+	var deleteOptions metav1.DeleteOptions
+	fakeDynamic.PrependReactor("delete-collection", "jobs", func(action ktesting.Action) (bool, runtime.Object, error) {
+		deleteAction := action.(ktesting.DeleteCollectionActionImpl)
+		listOptions = deleteAction.ListOptions
+		deleteOptions = deleteAction.DeleteOptions
+		return true, nil, nil
+	})
+
+	// Preview does not delete anything
+	result, err := client.DeleteCollection(context.Background(), deleteJobsGVR, "default", "state=complete", 10, true, DeleteOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.Preview)
+	assert.Equal(t, 2, result.Count)
+	assert.ElementsMatch(t, []DeletedObject{
+		{Namespace: "default", Name: "job-1", UID: "uid-job-1"},
+		{Namespace: "default", Name: "job-2", UID: "uid-job-2"},
+	}, result.Items)
+	assert.Empty(t, listOptions.LabelSelector)
+
+	result, err = client.DeleteCollection(context.Background(), deleteJobsGVR, "default", "state=complete", 2, false,
+		DeleteOptions{PropagationPolicy: "Background"})
+	require.NoError(t, err)
+	assert.False(t, result.Preview)
+	assert.Equal(t, 2, result.Count)
+	assert.Equal(t, "2 object(s) deleted", result.Message)
+	assert.Equal(t, "state=complete", listOptions.LabelSelector)
+	assert.Equal(t, metav1.ResourceVersionMatchExact, listOptions.ResourceVersionMatch)
+	require.NotNil(t, deleteOptions.PropagationPolicy)
+	assert.Equal(t, metav1.DeletePropagationBackground, *deleteOptions.PropagationPolicy)
+}
+
+func TestDeleteCollectionExceedsMaxItems(t *testing.T) {
+	client, fakeDynamic := newDeleteTestClient(
+		deleteTestJob("job-1", map[string]interface{}{"state": "complete"}),
+		deleteTestJob("job-2", map[string]interface{}{"state": "complete"}),
+	)
+	deleted := false
+	fakeDynamic.PrependReactor("delete-collection", "jobs", func(ktesting.Action) (bool, runtime.Object, error) {
+		deleted = true
+		return true, nil, nil
+	})
+
+	_, err := client.DeleteCollection(context.Background(), deleteJobsGVR, "default", "state=complete", 1, false, DeleteOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "more than 1 objects match")
+	assert.False(t, deleted)
+}
+
+func TestDeleteCollectionFallback(t *testing.T) {
+	client, fakeDynamic := newDeleteTestClient(
+		deleteTestJob("job-1", map[string]interface{}{"state": "complete"}),
+		deleteTestJob("job-2", map[string]interface{}{"state": "complete"}),
+		deleteTestJob("job-3", map[string]interface{}{"state": "running"}),
+	)
+	fakeDynamic.PrependReactor("delete-collection", "jobs", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewMethodNotSupported(deleteJobsGVR.GroupResource(), "deletecollection")
+	})
+	var preconditionUIDs []string
+	fakeDynamic.PrependReactor("delete", "jobs", func(action ktesting.Action) (bool, runtime.Object, error) {
+		options := action.(ktesting.DeleteAction).GetDeleteOptions()
+		preconditionUIDs = append(preconditionUIDs, string(*options.Preconditions.UID))
+		return false, nil, nil
+	})
+
+	result, err := client.DeleteCollection(context.Background(), deleteJobsGVR, "default", "state=complete", 5, false, DeleteOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Count)
+	assert.ElementsMatch(t, []string{"uid-job-1", "uid-job-2"}, preconditionUIDs)
+
+	list, err := fakeDynamic.Resource(deleteJobsGVR).Namespace("default").List(context.Background(), metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Items, 1)
+	assert.Equal(t, "job-3", list.Items[0].GetName())
+}
+
+func TestDeleteCollectionInvalidParameters(t *testing.T) {
+	client, _ := newDeleteTestClient()
+
+	testCases := []struct {
+		name     string
+		selector string
+		maxItems int
+		opts     DeleteOptions
+		errorMsg string
+	}{
+		{name: "Empty selector", maxItems: 1, errorMsg: "label selector cannot be empty"},
+		{name: "Invalid selector", selector: "a in (", maxItems: 1, errorMsg: "invalid label selector"},
+		{name: "Zero max items", selector: "a=b", errorMsg: "max items"},
+		{name: "Too many max items", selector: "a=b", maxItems: MaxDeleteCollectionItems + 1, errorMsg: "max items"},
+		{name: "UID precondition", selector: "a=b", maxItems: 1, opts: DeleteOptions{UID: "x"}, errorMsg: "preconditions"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.DeleteCollection(context.Background(), deleteJobsGVR, "default", tc.selector, tc.maxItems, false, tc.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
@@ -3,7 +3,10 @@ package k8s
 import (
 	"bytes"
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"strings"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -12,11 +15,23 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 // MaxExecTimeout is the maximum allowed timeout for exec operations to prevent abuse
 const MaxExecTimeout = 1 * time.Minute
 
+// MaxExecStdinBytes is the maximum size of the stdin payload that may be sent
+// to a command executed in a pod.
+const MaxExecStdinBytes int64 = 1 << 20 // 1 MiB
+
+// maxExecScriptBytes caps the size of a script run through a shell. Scripts
+// are passed as a single argv entry, so keep them well below ARG_MAX.
+const maxExecScriptBytes = 64 << 10 // 64 KiB
+
+// defaultExecShell is the shell used to run scripts when none is specified.
+const defaultExecShell = "/bin/sh"
+
 // maxExecStreamBytes caps the in-memory buffer for each of stdout/stderr from
 // pod exec, applied alongside MaxExecTimeout. Defence-in-depth against memory
 // exhaustion from a long-running command producing high-volume output. See
@@ -66,6 +81,7 @@ func (c *Client) defaultExecInPod(
 	namespace, name string,
 	command []string,
 	container string,
+	stdin string,
 	timeout time.Duration,
 ) (*unstructured.Unstructured, error) {
 	if name == "" {
@@ -76,6 +92,10 @@ func (c *Client) defaultExecInPod(
 		return nil, fmt.Errorf("command cannot be empty")
 	}
 
+	if int64(len(stdin)) > MaxExecStdinBytes {
+		return nil, fmt.Errorf("stdin exceeds maximum size of %d bytes", MaxExecStdinBytes)
+	}
+
 	// Default timeout is 15 seconds
 	if timeout <= 0 {
 		timeout = 15 * time.Second
@@ -90,47 +110,17 @@ func (c *Client) defaultExecInPod(
 	execCtx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	// Create the exec request
-	req := c.clientset.CoreV1().RESTClient().Post().
-		Resource(resourcePods).
-		Name(name).
-		Namespace(namespace).
-		SubResource(subresourceExec)
-
-	// Set query parameters
-	option := &corev1.PodExecOptions{
-		Command: command,
-		Stdin:   false,
-		Stdout:  true,
-		Stderr:  true,
-		TTY:     false,
-	}
-
-	// Set container if specified
-	if container != "" {
-		option.Container = container
-	}
-
-	req.VersionedParams(
-		option,
-		scheme.ParameterCodec,
-	)
-
 	// Bounded buffers for stdout and stderr (see maxExecStreamBytes).
 	stdout := newBoundedBuffer(maxExecStreamBytes)
 	stderr := newBoundedBuffer(maxExecStreamBytes)
 
-	// Create the SPDY executor
-	exec, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
-	if err != nil {
-		return nil, fmt.Errorf("failed to create SPDY executor: %w", err)
+	var stdinReader io.Reader
+	if stdin != "" {
+		stdinReader = strings.NewReader(stdin)
 	}
 
 	// Execute the command
-	err = exec.StreamWithContext(execCtx, remotecommand.StreamOptions{
-		Stdout: stdout,
-		Stderr: stderr,
-	})
+	err := c.streamExec(execCtx, namespace, name, container, command, stdinReader, stdout, stderr)
 
 	status := map[string]interface{}{
 		fieldStdout: stdout.String(),
@@ -159,43 +149,99 @@ func (c *Client) defaultExecInPod(
 
 	// Check if the command was killed due to timeout
 	if execCtx.Err() == context.DeadlineExceeded {
-		result.Object[fieldStatus].(map[string]interface{})[fieldError] = "command timed out"
+		status[fieldError] = "command timed out"
 		return result, nil
 	}
 
+	// A command that ran to completion always reports its exit code, so that
+	// callers can tell e.g. "grep found nothing" (1) from a real failure.
+	if exitCode, ok := execExitCode(err); ok {
+		status[fieldExitCode] = exitCode
+	}
+
 	// Check for other errors
 	if err != nil {
-		result.Object[fieldStatus].(map[string]interface{})[fieldError] = err.Error()
+		status[fieldError] = err.Error()
 		return result, nil
 	}
 
 	return result, nil
 }
 
+// streamExec runs command in the given pod container over SPDY, wiring the
+// supplied streams. stdin may be nil, in which case no stdin stream is
+// requested. It returns the error from the remote command, which wraps a
+// utilexec.CodeExitError when the command exited with a non-zero status.
+func (c *Client) streamExec(
+	ctx context.Context,
+	namespace, name, container string,
+	command []string,
+	stdin io.Reader,
+	stdout, stderr io.Writer,
+) error {
+	// Create the exec request
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource(resourcePods).
+		Name(name).
+		Namespace(namespace).
+		SubResource(subresourceExec)
+
+	// Set query parameters
+	option := &corev1.PodExecOptions{
+		Command: command,
+		Stdin:   stdin != nil,
+		Stdout:  stdout != nil,
+		Stderr:  stderr != nil,
+		TTY:     false,
+	}
+
+	// Set container if specified
+	if container != "" {
+		option.Container = container
+	}
+
+	req.VersionedParams(
+		option,
+		scheme.ParameterCodec,
+	)
+
+	// Create the SPDY executor
+	exec, err := remotecommand.NewSPDYExecutor(c.restConfig, "POST", req.URL())
+	if err != nil {
+		return fmt.Errorf("failed to create SPDY executor: %w", err)
+	}
+
+	return exec.StreamWithContext(ctx, remotecommand.StreamOptions{
+		Stdin:  stdin,
+		Stdout: stdout,
+		Stderr: stderr,
+	})
+}
+
+// execExitCode extracts the exit code of a completed remote command from the
+// error returned by the executor. A nil error means the command exited 0.
+// The boolean is false when err does not carry an exit status (e.g. the
+// stream could not be established), in which case the exit code is unknown.
+func execExitCode(err error) (int64, bool) {
+	if err == nil {
+		return 0, true
+	}
+	var exitErr utilexec.ExitError
+	if errors.As(err, &exitErr) && exitErr.Exited() {
+		return int64(exitErr.ExitStatus()), true
+	}
+	return 0, false
+}
+
 // handlePodExec handles the special case of executing commands in pods
 func (c *Client) handlePodExec(
 	ctx context.Context,
 	namespace, name string,
 	body map[string]interface{},
 ) (*unstructured.Unstructured, error) {
-	// Extract command from body
-	commandInterface, ok := body[fieldCommand]
-	if !ok {
-		return nil, fmt.Errorf("command is required for pod exec")
-	}
-
-	// Convert command to string slice
-	var command []string
-	switch cmd := commandInterface.(type) {
-	case []interface{}:
-		command = make([]string, len(cmd))
-		for i, v := range cmd {
-			command[i] = fmt.Sprintf("%v", v)
-		}
-	case string:
-		command = []string{cmd}
-	default:
-		return nil, fmt.Errorf("invalid command format: %T", commandInterface)
+	command, err := ParseExecCommand(body)
+	if err != nil {
+		return nil, err
 	}
 
 	// Extract container name
@@ -204,6 +250,19 @@ func (c *Client) handlePodExec(
 		container = fmt.Sprintf("%v", containerInterface)
 	}
 
+	// Extract stdin
+	stdin := ""
+	if stdinInterface, ok := body["stdin"]; ok {
+		s, ok := stdinInterface.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid stdin format: %T", stdinInterface)
+		}
+		if int64(len(s)) > MaxExecStdinBytes {
+			return nil, fmt.Errorf("stdin exceeds maximum size of %d bytes", MaxExecStdinBytes)
+		}
+		stdin = s
+	}
+
 	// Extract timeout
 	timeout := 15 * time.Second
 	if timeoutInterface, ok := body["timeout"]; ok {
@@ -221,7 +280,59 @@ func (c *Client) handlePodExec(
 		}
 	}
 
-	return c.ExecInPod(ctx, namespace, name, command, container, timeout)
+	return c.ExecInPod(ctx, namespace, name, command, container, stdin, timeout)
+}
+
+// ParseExecCommand builds the command to execute from a pod exec body. The
+// body either carries a 'command' (string or array) or a multi-line 'script'
+// that is run through 'shell' (default /bin/sh) with -c.
+func ParseExecCommand(body map[string]interface{}) ([]string, error) {
+	scriptInterface, hasScript := body["script"]
+	commandInterface, hasCommand := body[fieldCommand]
+
+	if hasScript && hasCommand {
+		return nil, fmt.Errorf("command and script are mutually exclusive")
+	}
+
+	if hasScript {
+		script, ok := scriptInterface.(string)
+		if !ok {
+			return nil, fmt.Errorf("invalid script format: %T", scriptInterface)
+		}
+		if strings.TrimSpace(script) == "" {
+			return nil, fmt.Errorf("script cannot be empty")
+		}
+		if len(script) > maxExecScriptBytes {
+			return nil, fmt.Errorf("script exceeds maximum size of %d bytes", maxExecScriptBytes)
+		}
+
+		shell := defaultExecShell
+		if shellInterface, ok := body["shell"]; ok {
+			shell = fmt.Sprintf("%v", shellInterface)
+		}
+		if shell == "" || strings.ContainsAny(shell, " \t\n") {
+			return nil, fmt.Errorf("invalid shell: %q", shell)
+		}
+		return []string{shell, "-c", script}, nil
+	}
+
+	if !hasCommand {
+		return nil, fmt.Errorf("command is required for pod exec")
+	}
+
+	// Convert command to string slice
+	switch cmd := commandInterface.(type) {
+	case []interface{}:
+		command := make([]string, len(cmd))
+		for i, v := range cmd {
+			command[i] = fmt.Sprintf("%v", v)
+		}
+		return command, nil
+	case string:
+		return []string{cmd}, nil
+	default:
+		return nil, fmt.Errorf("invalid command format: %T", commandInterface)
+	}
 }
 
 // PostResource posts to a resource or its subresource
@@ -13,6 +13,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubefake "k8s.io/client-go/kubernetes/fake"
+	utilexec "k8s.io/client-go/util/exec"
 )
 
 func TestPostResource(t *testing.T) {
@@ -25,7 +26,7 @@ func TestPostResource(t *testing.T) {
 
 	// Mock the ExecInPod method
 	mockExecCalled := false
-	mockExecFunc := func(_ context.Context, namespace, name string, command []string, _ string, _ time.Duration) (*unstructured.Unstructured, error) {
+	mockExecFunc := func(_ context.Context, namespace, name string, command []string, _ string, _ string, _ time.Duration) (*unstructured.Unstructured, error) {
 		// Mark that the function was called
 		mockExecCalled = true
 
@@ -195,7 +196,7 @@ func TestExecInPod(t *testing.T) {
 
 	// Mock the ExecInPod method
 	var capturedTimeout time.Duration
-	mockExecFunc := func(_ context.Context, namespace, name string, command []string, _ string, timeout time.Duration) (*unstructured.Unstructured, error) {
+	mockExecFunc := func(_ context.Context, namespace, name string, command []string, _ string, _ string, timeout time.Duration) (*unstructured.Unstructured, error) {
 		// Apply the same timeout adjustments as the real implementation
 		if timeout <= 0 {
 			timeout = 15 * time.Second
@@ -320,7 +321,7 @@ func TestExecInPod(t *testing.T) {
 
 			// Call the method
 			ctx := context.Background()
-			result, err := client.ExecInPod(ctx, tc.namespace, tc.podName, tc.command, tc.container, tc.timeout)
+			result, err := client.ExecInPod(ctx, tc.namespace, tc.podName, tc.command, tc.container, "", tc.timeout)
 
 			// Assert expectations
 			if tc.expectError {
@@ -438,3 +439,126 @@ func TestBoundedBuffer_AsIOWriter(t *testing.T) {
 	assert.Equal(t, 100, len(b.String()))
 	assert.True(t, b.Truncated())
 }
+
+func TestParseExecCommand(t *testing.T) {
+	t.Parallel()
+
+	testCases := []struct {
+		name     string
+		body     map[string]interface{}
+		expected []string
+		errorMsg string
+	}{
+		{
+			name:     "Array command",
+			body:     map[string]interface{}{"command": []interface{}{"ls", "-la"}},
+			expected: []string{"ls", "-la"},
+		},
+		{
+			name:     "String command",
+			body:     map[string]interface{}{"command": "ls"},
+			expected: []string{"ls"},
+		},
+		{
+			name:     "Script with default shell",
+			body:     map[string]interface{}{"script": "cd /tmp\nls"},
+			expected: []string{"/bin/sh", "-c", "cd /tmp\nls"},
+		},
+		{
+			name:     "Script with custom shell",
+			body:     map[string]interface{}{"script": "echo $0", "shell": "/bin/bash"},
+			expected: []string{"/bin/bash", "-c", "echo $0"},
+		},
+		{
+			name:     "Command and script",
+			body:     map[string]interface{}{"command": "ls", "script": "ls"},
+			errorMsg: "mutually exclusive",
+		},
+		{
+			name:     "Empty script",
+			body:     map[string]interface{}{"script": "  \n"},
+			errorMsg: "script cannot be empty",
+		},
+		{
+			name:     "Oversized script",
+			body:     map[string]interface{}{"script": strings.Repeat("x", maxExecScriptBytes+1)},
+			errorMsg: "script exceeds maximum size",
+		},
+		{
+			name:     "Shell with arguments",
+			body:     map[string]interface{}{"script": "ls", "shell": "sh -x"},
+			errorMsg: "invalid shell",
+		},
+		{
+			name:     "Missing command",
+			body:     map[string]interface{}{},
+			errorMsg: "command is required for pod exec",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			command, err := ParseExecCommand(tc.body)
+			if tc.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, command)
+		})
+	}
+}
+
+func TestHandlePodExecStdin(t *testing.T) {
+	client := &Client{}
+
+	var capturedStdin string
+	var capturedCommand []string
+	client.SetExecInPodFunc(func(_ context.Context, _, name string, command []string, _ string, stdin string, _ time.Duration) (*unstructured.Unstructured, error) {
+		capturedStdin = stdin
+		capturedCommand = command
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name},
+		}}, nil
+	})
+
+	podsGVR := schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+	_, err := client.PostResource(context.Background(), podsGVR, "default", "test-pod", "exec",
+		map[string]interface{}{"script": "cat > /tmp/x\nwc -c /tmp/x", "stdin": "hello"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", capturedStdin)
+	assert.Equal(t, []string{"/bin/sh", "-c", "cat > /tmp/x\nwc -c /tmp/x"}, capturedCommand)
+
+	_, err = client.PostResource(context.Background(), podsGVR, "default", "test-pod", "exec",
+		map[string]interface{}{"command": "cat", "stdin": strings.Repeat("x", int(MaxExecStdinBytes)+1)}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "stdin exceeds maximum size")
+
+	_, err = client.PostResource(context.Background(), podsGVR, "default", "test-pod", "exec",
+		map[string]interface{}{"command": "cat", "stdin": 42}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid stdin format")
+}
+
+func TestExecExitCode(t *testing.T) {
+	t.Parallel()
+
+	code, ok := execExitCode(nil)
+	assert.True(t, ok)
+	assert.Equal(t, int64(0), code)
+
+	code, ok = execExitCode(utilexec.CodeExitError{Err: fmt.Errorf("command terminated with exit code 1"), Code: 1})
+	assert.True(t, ok)
+	assert.Equal(t, int64(1), code)
+
+	wrapped := fmt.Errorf("stream: %w", utilexec.CodeExitError{Err: fmt.Errorf("exit 127"), Code: 127})
+	code, ok = execExitCode(wrapped)
+	assert.True(t, ok)
+	assert.Equal(t, int64(127), code)
+
+	_, ok = execExitCode(fmt.Errorf("unable to upgrade connection"))
+	assert.False(t, ok)
+}
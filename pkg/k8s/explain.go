@@ -0,0 +1,348 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// schemaRefPrefix prefixes references to the schemas of an OpenAPI v3
+// document.
+const schemaRefPrefix = "#/components/schemas/"
+
+// openAPISchema is a schema of an OpenAPI v3 document.
+type openAPISchema map[string]interface{}
+
+// openAPIDocument holds the schemas of the OpenAPI v3 document of a group
+// version.
+type openAPIDocument struct {
+	// url is the server relative URL of the document, which changes with its
+	// content.
+	url     string
+	schemas map[string]openAPISchema
+}
+
+// openAPICache caches the OpenAPI v3 documents of group versions.
+type openAPICache struct {
+	mu        sync.Mutex
+	documents map[string]*openAPIDocument
+}
+
+// newOpenAPICache returns an empty openAPICache.
+func newOpenAPICache() *openAPICache {
+	return &openAPICache{documents: map[string]*openAPIDocument{}}
+}
+
+// ExplainField describes a field of a schema.
+type ExplainField struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// ExplainResult is the schema of a kind or of one of its fields.
+type ExplainResult struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+	// Path is the dotted path of the field explained, empty for the kind.
+	Path        string         `json:"path,omitempty"`
+	Type        string         `json:"type"`
+	Description string         `json:"description,omitempty"`
+	Enum        []interface{}  `json:"enum,omitempty"`
+	Required    []string       `json:"required,omitempty"`
+	Fields      []ExplainField `json:"fields,omitempty"`
+}
+
+// ExplainResource returns the schema of a kind, or of a field of it, from the
+// OpenAPI v3 document of the cluster, like `kubectl explain`. The target is a
+// resource or kind, given by name, singular name, kind or short name,
+// optionally followed by a dotted field path, such as
+// "deployment.spec.strategy". apiVersion, if set, selects the group version.
+func (c *Client) ExplainResource(_ context.Context, target, apiVersion string) (*ExplainResult, error) {
+	name, fieldPath, _ := strings.Cut(target, ".")
+	if name == "" {
+		return nil, fmt.Errorf("resource cannot be empty")
+	}
+	var fields []string
+	if fieldPath != "" {
+		fields = strings.Split(fieldPath, ".")
+		if slices.Contains(fields, "") {
+			return nil, fmt.Errorf("invalid field path %q", fieldPath)
+		}
+	}
+
+	c.mu.RLock()
+	discoveryClient := c.discoveryClient
+	c.mu.RUnlock()
+	if discoveryClient == nil {
+		return nil, fmt.Errorf("discovery client is not initialized")
+	}
+
+	gvr := schema.GroupVersionResource{Resource: name}
+	if apiVersion != "" {
+		gv, err := schema.ParseGroupVersion(apiVersion)
+		if err != nil {
+			return nil, fmt.Errorf("invalid api version %q: %w", apiVersion, err)
+		}
+		gvr.Group, gvr.Version = gv.Group, gv.Version
+	}
+	mapper := restmapper.NewShortcutExpander(
+		restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient)),
+		discoveryClient, nil)
+	gvk, err := mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve resource %q: %w", name, err)
+	}
+
+	document, err := c.openAPIDocument(gvk.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	kindSchema := document.kindSchema(gvk)
+	if kindSchema == nil {
+		return nil, fmt.Errorf("no schema found for %s", gvk)
+	}
+
+	result := &ExplainResult{
+		Group:   gvk.Group,
+		Version: gvk.Version,
+		Kind:    gvk.Kind,
+		Path:    strings.Join(fields, "."),
+	}
+	current := kindSchema
+	for i, field := range fields {
+		properties, _ := document.objectSchema(current)["properties"].(map[string]interface{})
+		property, ok := properties[field].(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field %q does not exist in %s, available fields: %s",
+				strings.Join(fields[:i+1], "."), schemaPathName(gvk.Kind, fields[:i]), strings.Join(sortedKeys(properties), ", "))
+		}
+		current = property
+	}
+
+	result.Type = gvk.Kind
+	if len(fields) > 0 {
+		result.Type = document.typeName(current)
+	}
+	result.Description = schemaDescription(current, document)
+	result.Enum, _ = document.resolve(current)["enum"].([]interface{})
+	resolved := document.objectSchema(current)
+	required := schemaStrings(resolved["required"])
+	result.Required = required
+	properties, _ := resolved["properties"].(map[string]interface{})
+	for _, field := range sortedKeys(properties) {
+		property, _ := properties[field].(map[string]interface{})
+		result.Fields = append(result.Fields, ExplainField{
+			Name:        field,
+			Type:        document.typeName(property),
+			Description: schemaDescription(property, document),
+			Required:    slices.Contains(required, field),
+		})
+	}
+	return result, nil
+}
+
+// openAPIDocument returns the OpenAPI v3 document of gv, from cache if it is
+// still current.
+func (c *Client) openAPIDocument(gv schema.GroupVersion) (*openAPIDocument, error) {
+	c.mu.RLock()
+	discoveryClient := c.discoveryClient
+	cache := c.openAPIDocs
+	c.mu.RUnlock()
+
+	paths, err := discoveryClient.OpenAPIV3().Paths()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenAPI paths: %w", err)
+	}
+	path := "apis/" + gv.Group + "/" + gv.Version
+	if gv.Group == "" {
+		path = "api/" + gv.Version
+	}
+	groupVersion, ok := paths[path]
+	if !ok {
+		return nil, fmt.Errorf("no OpenAPI v3 document for %s", gv)
+	}
+
+	url := groupVersion.ServerRelativeURL()
+	if cache != nil {
+		cache.mu.Lock()
+		document := cache.documents[path]
+		cache.mu.Unlock()
+		if document != nil && document.url == url {
+			return document, nil
+		}
+	}
+
+	raw, err := groupVersion.Schema("application/json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get OpenAPI v3 document for %s: %w", gv, err)
+	}
+	var parsed struct {
+		Components struct {
+			Schemas map[string]openAPISchema `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(raw, &parsed); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI v3 document for %s: %w", gv, err)
+	}
+
+	document := &openAPIDocument{url: url, schemas: parsed.Components.Schemas}
+	if cache != nil {
+		cache.mu.Lock()
+		cache.documents[path] = document
+		cache.mu.Unlock()
+	}
+	return document, nil
+}
+
+// kindSchema returns the schema of gvk, or nil if there is none.
+func (d *openAPIDocument) kindSchema(gvk schema.GroupVersionKind) openAPISchema {
+	for _, name := range sortedKeys(d.schemas) {
+		kinds, _ := d.schemas[name]["x-kubernetes-group-version-kind"].([]interface{})
+		for _, kind := range kinds {
+			kind, _ := kind.(map[string]interface{})
+			if kind["group"] == gvk.Group && kind["version"] == gvk.Version && kind["kind"] == gvk.Kind {
+				return d.schemas[name]
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows the references of s to the schema they point to. A
+// reference may be wrapped in allOf, so that a description can be added.
+func (d *openAPIDocument) resolve(s openAPISchema) openAPISchema {
+	for range 10 {
+		if ref, ok := s["$ref"].(string); ok {
+			s = d.schemas[strings.TrimPrefix(ref, schemaRefPrefix)]
+			continue
+		}
+		if allOf, ok := s["allOf"].([]interface{}); ok && len(allOf) == 1 && s["properties"] == nil {
+			if inner, ok := allOf[0].(map[string]interface{}); ok {
+				s = inner
+				continue
+			}
+		}
+		break
+	}
+	return s
+}
+
+// objectSchema returns the schema holding the fields of s. The fields of
+// lists and maps are those of their items.
+func (d *openAPIDocument) objectSchema(s openAPISchema) openAPISchema {
+	s = d.resolve(s)
+	for range 10 {
+		items := schemaItems(s)
+		if items == nil {
+			break
+		}
+		s = d.resolve(items)
+	}
+	return s
+}
+
+// typeName returns the type of s, like `kubectl explain` shows it.
+func (d *openAPIDocument) typeName(s openAPISchema) string {
+	if ref := schemaRef(s); ref != "" {
+		resolved := d.resolve(s)
+		if typ, _ := resolved["type"].(string); typ != "" && typ != "object" {
+			return d.typeName(resolved)
+		}
+		// Short name of the referenced schema, e.g. DeploymentSpec
+		return ref[strings.LastIndex(ref, ".")+1:]
+	}
+	if s["x-kubernetes-int-or-string"] == true || s["format"] == "int-or-string" {
+		return "IntOrString"
+	}
+
+	typ, _ := s["type"].(string)
+	switch typ {
+	case "array":
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			return "[]" + d.typeName(items)
+		}
+		return "[]Object"
+	case "object":
+		if additional, ok := s["additionalProperties"].(map[string]interface{}); ok {
+			return "map[string]" + d.typeName(additional)
+		}
+		return "Object"
+	case "":
+		return "Object"
+	default:
+		return typ
+	}
+}
+
+// schemaRef returns the name of the schema s refers to, if any.
+func schemaRef(s openAPISchema) string {
+	if ref, ok := s["$ref"].(string); ok {
+		return strings.TrimPrefix(ref, schemaRefPrefix)
+	}
+	if allOf, ok := s["allOf"].([]interface{}); ok && len(allOf) == 1 {
+		if inner, ok := allOf[0].(map[string]interface{}); ok {
+			return schemaRef(inner)
+		}
+	}
+	return ""
+}
+
+// schemaDescription returns the description of s, falling back to that of
+// the schema it refers to.
+func schemaDescription(s openAPISchema, d *openAPIDocument) string {
+	if description, ok := s["description"].(string); ok && description != "" {
+		return description
+	}
+	description, _ := d.resolve(s)["description"].(string)
+	return description
+}
+
+// schemaItems returns the schema of the items of an array, or of the values
+// of a map, or nil if s is neither.
+func schemaItems(s openAPISchema) openAPISchema {
+	if items, ok := s["items"].(map[string]interface{}); ok && s["type"] == "array" {
+		return items
+	}
+	if additional, ok := s["additionalProperties"].(map[string]interface{}); ok && s["properties"] == nil {
+		return additional
+	}
+	return nil
+}
+
+// schemaStrings returns value as a list of strings.
+func schemaStrings(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var strs []string
+	for _, item := range items {
+		if str, ok := item.(string); ok {
+			strs = append(strs, str)
+		}
+	}
+	return strs
+}
+
+// schemaPathName returns the name of the field at path of kind, for errors.
+func schemaPathName(kind string, path []string) string {
+	return strings.Join(append([]string{kind}, path...), ".")
+}
+
+// sortedKeys returns the keys of m in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,223 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+	ktesting "k8s.io/client-go/testing"
+)
+
+// widgetsOpenAPI is the OpenAPI v3 document of a custom resource.
+const widgetsOpenAPI = `{
+  "components": {
+    "schemas": {
+      "com.example.v1.Widget": {
+        "type": "object",
+        "x-kubernetes-group-version-kind": [{"group": "example.com", "version": "v1", "kind": "Widget"}],
+        "properties": {
+          "apiVersion": {"type": "string"},
+          "kind": {"type": "string"},
+          "metadata": {"type": "object"},
+          "spec": {
+            "type": "object",
+            "description": "Desired state of the widget",
+            "required": ["size"],
+            "properties": {
+              "size": {"type": "string", "enum": ["small", "large"], "description": "Size of the widget"},
+              "port": {"x-kubernetes-int-or-string": true},
+              "labels": {"type": "object", "additionalProperties": {"type": "string"}},
+              "parts": {
+                "type": "array",
+                "items": {"type": "object", "properties": {"name": {"type": "string", "description": "Part name"}}}
+              }
+            }
+          }
+        }
+      }
+    }
+  }
+}`
+
+// explainTestGroupVersion serves a fixed OpenAPI v3 document, counting how
+// often it is fetched.
+type explainTestGroupVersion struct {
+	document string
+	fetches  *int
+}
+
+func (g explainTestGroupVersion) Schema(string) ([]byte, error) {
+	*g.fetches++
+	return []byte(g.document), nil
+}
+
+func (explainTestGroupVersion) ServerRelativeURL() string {
+	return "/openapi/v3/apis/example.com/v1?hash=1"
+}
+
+// explainTestOpenAPI serves the embedded test documents and that of widgets.
+type explainTestOpenAPI struct {
+	widgets explainTestGroupVersion
+}
+
+func (o explainTestOpenAPI) Paths() (map[string]openapi.GroupVersion, error) {
+	paths, err := openapitest.NewEmbeddedFileClient().Paths()
+	if err != nil {
+		return nil, err
+	}
+	paths["apis/example.com/v1"] = o.widgets
+	return paths, nil
+}
+
+// explainTestDiscovery is a fake discovery client serving OpenAPI v3
+// documents.
+type explainTestDiscovery struct {
+	*discoveryfake.FakeDiscovery
+	openAPI openapi.Client
+}
+
+func (d explainTestDiscovery) OpenAPIV3() openapi.Client {
+	return d.openAPI
+}
+
+func newExplainTestClient() (*Client, *int) {
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "pods", SingularName: "pod", Kind: "Pod", Namespaced: true}},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{
+				Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true,
+				ShortNames: []string{"deploy"},
+			}},
+		},
+		{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{{Name: "widgets", SingularName: "widget", Kind: "Widget", Namespaced: true}},
+		},
+	}
+
+	fetches := 0
+	client := &Client{openAPIDocs: newOpenAPICache()}
+	client.SetDiscoveryClient(explainTestDiscovery{
+		FakeDiscovery: fakeDiscovery,
+		openAPI:       explainTestOpenAPI{widgets: explainTestGroupVersion{document: widgetsOpenAPI, fetches: &fetches}},
+	})
+	return client, &fetches
+}
+
+func TestExplainResourceKind(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	for _, target := range []string{"deployments", "deployment", "Deployment", "deploy"} {
+		result, err := client.ExplainResource(context.Background(), target, "")
+		require.NoError(t, err, target)
+		assert.Equal(t, "apps", result.Group)
+		assert.Equal(t, "v1", result.Version)
+		assert.Equal(t, "Deployment", result.Kind)
+		assert.Empty(t, result.Path)
+		assert.Equal(t, "Deployment", result.Type)
+		assert.Contains(t, result.Description, "Deployment enables declarative updates")
+
+		fieldNames := make([]string, 0, len(result.Fields))
+		for _, field := range result.Fields {
+			fieldNames = append(fieldNames, field.Name)
+		}
+		assert.Equal(t, []string{"apiVersion", "kind", "metadata", "spec", "status"}, fieldNames)
+		assert.Equal(t, "DeploymentSpec", result.Fields[3].Type)
+	}
+}
+
+func TestExplainResourceFieldPath(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	result, err := client.ExplainResource(context.Background(), "deployment.spec.strategy", "apps/v1")
+	require.NoError(t, err)
+	assert.Equal(t, "spec.strategy", result.Path)
+	assert.Equal(t, "DeploymentStrategy", result.Type)
+	assert.Contains(t, result.Description, "deployment strategy")
+	fields := map[string]ExplainField{}
+	for _, field := range result.Fields {
+		fields[field.Name] = field
+	}
+	assert.Equal(t, "RollingUpdateDeployment", fields["rollingUpdate"].Type)
+	assert.Equal(t, "string", fields["type"].Type)
+
+	// Required fields, through a list
+	result, err = client.ExplainResource(context.Background(), "pods.spec.containers", "")
+	require.NoError(t, err)
+	assert.Equal(t, "[]Container", result.Type)
+	assert.Contains(t, result.Required, "name")
+	for _, field := range result.Fields {
+		if field.Name == "name" {
+			assert.True(t, field.Required)
+		}
+		if field.Name == "ports" {
+			assert.Equal(t, "[]ContainerPort", field.Type)
+		}
+	}
+
+	result, err = client.ExplainResource(context.Background(), "pod.spec.containers.resources.limits", "")
+	require.NoError(t, err)
+	assert.Equal(t, "map[string]Quantity", result.Type)
+}
+
+func TestExplainResourceCustomResource(t *testing.T) {
+	client, fetches := newExplainTestClient()
+
+	result, err := client.ExplainResource(context.Background(), "widget.spec", "")
+	require.NoError(t, err)
+	assert.Equal(t, "example.com", result.Group)
+	assert.Equal(t, "Desired state of the widget", result.Description)
+	assert.Equal(t, []string{"size"}, result.Required)
+	assert.Equal(t, []ExplainField{
+		{Name: "labels", Type: "map[string]string"},
+		{Name: "parts", Type: "[]Object"},
+		{Name: "port", Type: "IntOrString"},
+		{Name: "size", Type: "string", Description: "Size of the widget", Required: true},
+	}, result.Fields)
+
+	result, err = client.ExplainResource(context.Background(), "widgets.spec.size", "")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"small", "large"}, result.Enum)
+
+	result, err = client.ExplainResource(context.Background(), "widgets.spec.parts.name", "")
+	require.NoError(t, err)
+	assert.Equal(t, "Part name", result.Description)
+
+	// The document is cached while its URL does not change
+	assert.Equal(t, 1, *fetches)
+}
+
+func TestExplainResourceErrors(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	testCases := []struct {
+		name       string
+		target     string
+		apiVersion string
+		errorMsg   string
+	}{
+		{name: "Empty", target: "", errorMsg: "resource cannot be empty"},
+		{name: "Unknown resource", target: "gadgets", errorMsg: "failed to resolve resource"},
+		{name: "Unknown field", target: "deployment.spec.strategi", errorMsg: `field "spec.strategi" does not exist in Deployment.spec, available fields: `},
+		{name: "Empty field", target: "deployment..spec", errorMsg: "invalid field path"},
+		{name: "Wrong version", target: "deployments", apiVersion: "apps/v2", errorMsg: "failed to resolve resource"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.ExplainResource(context.Background(), tc.target, tc.apiVersion)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
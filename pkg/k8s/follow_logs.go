@@ -0,0 +1,168 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// MaxFollowLogsDuration is the maximum time a single follow_logs call may keep
+// a pod log stream open. Following holds a podLogReadSem slot for its whole
+// lifetime, so the cap also bounds how long one caller can pin a slot.
+const MaxFollowLogsDuration = 5 * time.Minute
+
+// defaultFollowLogsDuration is used when the caller does not request a duration.
+const defaultFollowLogsDuration = 30 * time.Second
+
+// followLogsReadBufferSize is the size of each read from the log stream. Every
+// non-empty read is surfaced to the caller as one chunk, so this also bounds
+// the size of a single progress notification.
+const followLogsReadBufferSize = 8 * 1024
+
+// Reasons reported in the follow summary for why streaming stopped.
+const (
+	FollowStopDurationElapsed = "duration elapsed"
+	FollowStopStreamEnded     = "stream ended"
+	FollowStopCancelled       = "cancelled"
+	FollowStopLimitReached    = "size limit reached"
+)
+
+// LogChunkFunc receives successive chunks of a followed pod log stream.
+// It is called synchronously from the read loop and should return quickly.
+type LogChunkFunc func(chunk string)
+
+// FollowPodLogs streams a pod's logs with Follow enabled for at most duration,
+// passing each chunk read to onChunk as it arrives. Streaming stops when the
+// duration elapses, the container exits, ctx is cancelled, or the effective
+// byte limit (see effectivePodLogLimit) is reached. The returned object holds
+// the collected logs together with a summary of the follow session.
+//
+// If duration is 0 or negative, the default (30s) is used. If it exceeds
+// MaxFollowLogsDuration, it is capped at MaxFollowLogsDuration.
+func (c *Client) FollowPodLogs(
+	ctx context.Context,
+	namespace, name string,
+	parameters map[string]string,
+	duration time.Duration,
+	onChunk LogChunkFunc,
+) (*unstructured.Unstructured, error) {
+	if name == "" {
+		return nil, fmt.Errorf("pod name cannot be empty")
+	}
+
+	if duration <= 0 {
+		duration = defaultFollowLogsDuration
+	}
+	if duration > MaxFollowLogsDuration {
+		duration = MaxFollowLogsDuration
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	release, err := c.acquirePodLogSlot(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+
+	// Start from a short tail so the caller sees some context, then follow.
+	defaultTailLines := int64(10)
+	defaultLimitBytes := int64(32 * 1024) // 32KB
+	podLogOpts := corev1.PodLogOptions{
+		TailLines:  &defaultTailLines,
+		LimitBytes: &defaultLimitBytes,
+	}
+	if parameters != nil {
+		podLogOpts = buildPodLogOpts(&podLogOpts, parameters)
+	}
+	podLogOpts.Follow = true
+	// Following the previous container instance makes no sense: it has exited.
+	podLogOpts.Previous = false
+
+	followCtx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	started := time.Now()
+	stream, err := clientset.CoreV1().Pods(namespace).GetLogs(name, &podLogOpts).Stream(followCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow pod logs: %w", err)
+	}
+	defer func() {
+		if closeErr := stream.Close(); closeErr != nil {
+			fmt.Printf("Error closing pod logs stream: %v\n", closeErr)
+		}
+	}()
+
+	logs := newBoundedBuffer(effectivePodLogLimit(podLogOpts.LimitBytes))
+	chunks := int64(0)
+	reason := ""
+	buf := make([]byte, followLogsReadBufferSize)
+	for reason == "" {
+		n, readErr := stream.Read(buf)
+		if n > 0 {
+			// boundedBuffer never returns an error; it only records truncation.
+			_, _ = logs.Write(buf[:n])
+			chunks++
+			if onChunk != nil {
+				onChunk(string(buf[:n]))
+			}
+			if logs.Truncated() {
+				reason = FollowStopLimitReached
+				break
+			}
+		}
+		if readErr != nil {
+			reason = followStopReason(ctx, followCtx, readErr)
+			if reason == "" {
+				return nil, fmt.Errorf("failed to read pod logs: %w", readErr)
+			}
+		}
+	}
+
+	result := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			fieldAPIVersion: apiVersionV1,
+			fieldKind:       kindPod,
+			fieldMetadata: map[string]interface{}{
+				fieldName:      name,
+				fieldNamespace: namespace,
+			},
+			fieldLogs: logs.String(),
+			fieldFollow: map[string]interface{}{
+				"container":       podLogOpts.Container,
+				"durationSeconds": int64(time.Since(started).Seconds()),
+				"bytes":           int64(logs.buf.Len()),
+				"chunks":          chunks,
+				"stopReason":      reason,
+			},
+		},
+	}
+	if logs.Truncated() {
+		result.Object[fieldTruncated] = true
+	}
+
+	return result, nil
+}
+
+// followStopReason maps the error that ended a followed stream to one of the
+// FollowStop* reasons. It returns "" for errors that are genuine read
+// failures rather than an expected end of the stream.
+func followStopReason(parent, followCtx context.Context, err error) string {
+	switch {
+	case parent.Err() != nil:
+		return FollowStopCancelled
+	case followCtx.Err() != nil:
+		return FollowStopDurationElapsed
+	case errors.Is(err, io.EOF):
+		return FollowStopStreamEnded
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,93 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestFollowPodLogs(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{podLogReadSem: make(chan struct{}, 1)}
+	client.SetClientset(kubefake.NewSimpleClientset())
+
+	var chunks []string
+	result, err := client.FollowPodLogs(context.Background(), "default", "test-pod", nil, time.Second,
+		func(chunk string) { chunks = append(chunks, chunk) })
+	require.NoError(t, err)
+
+	// The fake clientset serves a fixed "fake logs" body and then EOF.
+	logs, found, err := unstructured.NestedString(result.Object, "logs")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "fake logs", logs)
+	assert.Equal(t, []string{"fake logs"}, chunks)
+
+	reason, _, _ := unstructured.NestedString(result.Object, "follow", "stopReason")
+	assert.Equal(t, FollowStopStreamEnded, reason)
+	assert.Len(t, client.podLogReadSem, 0, "semaphore slot must be released")
+}
+
+func TestFollowPodLogs_LimitReached(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset())
+
+	result, err := client.FollowPodLogs(context.Background(), "default", "test-pod",
+		map[string]string{"limitBytes": "4"}, time.Second, nil)
+	require.NoError(t, err)
+
+	logs, _, _ := unstructured.NestedString(result.Object, "logs")
+	assert.Equal(t, "fake", logs)
+	reason, _, _ := unstructured.NestedString(result.Object, "follow", "stopReason")
+	assert.Equal(t, FollowStopLimitReached, reason)
+	truncated, _, _ := unstructured.NestedBool(result.Object, "truncated")
+	assert.True(t, truncated)
+}
+
+func TestFollowPodLogs_EmptyName(t *testing.T) {
+	t.Parallel()
+
+	client := &Client{}
+	_, err := client.FollowPodLogs(context.Background(), "default", "", nil, time.Second, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "pod name cannot be empty")
+}
+
+func TestFollowPodLogs_SemaphoreRespectsContextCancellation(t *testing.T) {
+	t.Parallel()
+
+	sem := make(chan struct{}, 1)
+	sem <- struct{}{}
+	client := &Client{podLogReadSem: sem}
+	client.SetClientset(kubefake.NewSimpleClientset())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := client.FollowPodLogs(ctx, "default", "test-pod", nil, time.Second, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "context canceled")
+}
+
+func TestFollowStopReason(t *testing.T) {
+	t.Parallel()
+
+	live := context.Background()
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	assert.Equal(t, FollowStopCancelled, followStopReason(cancelled, cancelled, io.EOF))
+	assert.Equal(t, FollowStopDurationElapsed, followStopReason(live, cancelled, errors.New("closed")))
+	assert.Equal(t, FollowStopStreamEnded, followStopReason(live, live, io.EOF))
+	assert.Equal(t, "", followStopReason(live, live, errors.New("connection reset")))
+}
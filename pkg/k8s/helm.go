@@ -0,0 +1,506 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// Storage drivers Helm keeps releases in.
+const (
+	HelmStorageSecret    = "secret"
+	HelmStorageConfigMap = "configmap"
+)
+
+const (
+	// MaxHelmHistory is the largest number of revisions GetHelmRelease
+	// returns in the history of a release, most recent first.
+	MaxHelmHistory = 50
+
+	// helmReleaseSecretType is the type of the Secrets Helm stores releases in.
+	helmReleaseSecretType = "helm.sh/release.v1"
+	// maxHelmReleaseSize bounds the decompressed size of a release.
+	maxHelmReleaseSize = 32 << 20
+	// helmRedacted replaces redacted values.
+	helmRedacted = "[REDACTED]"
+)
+
+// gzipMagic starts gzip compressed data.
+var gzipMagic = []byte{0x1f, 0x8b, 0x08}
+
+// sensitiveHelmKey matches the keys of values that are redacted.
+var sensitiveHelmKey = regexp.MustCompile(
+	`(?i)(password|passwd|secret|token|credential|api[_-]?key|private[_-]?key|access[_-]?key|auth)`)
+
+// manifestSeparator splits the documents of a release manifest.
+var manifestSeparator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// helmRelease is the part of a Helm release payload that is decoded.
+type helmRelease struct {
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Version   int    `json:"version"`
+	Info      struct {
+		FirstDeployed string `json:"first_deployed"`
+		LastDeployed  string `json:"last_deployed"`
+		Description   string `json:"description"`
+		Status        string `json:"status"`
+		Notes         string `json:"notes"`
+	} `json:"info"`
+	Chart struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion"`
+		} `json:"metadata"`
+		Values map[string]interface{} `json:"values"`
+	} `json:"chart"`
+	Config   map[string]interface{} `json:"config"`
+	Manifest string                 `json:"manifest"`
+}
+
+// HelmReleaseSummary summarizes a revision of a Helm release.
+type HelmReleaseSummary struct {
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	Revision     int    `json:"revision"`
+	Status       string `json:"status"`
+	Chart        string `json:"chart"`
+	ChartVersion string `json:"chartVersion"`
+	AppVersion   string `json:"appVersion,omitempty"`
+	Updated      string `json:"updated,omitempty"`
+	Description  string `json:"description,omitempty"`
+	// Error is set when the revision could not be decoded; only the name,
+	// namespace and revision are known then.
+	Error string `json:"error,omitempty"`
+}
+
+// HelmResource is an object of the manifest of a Helm release.
+type HelmResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+// HelmRelease is a revision of a Helm release with its history.
+type HelmRelease struct {
+	HelmReleaseSummary
+	FirstDeployed string `json:"firstDeployed,omitempty"`
+	Notes         string `json:"notes,omitempty"`
+	// History holds the revisions of the release, most recent first.
+	History []HelmReleaseSummary `json:"history"`
+	// Values are the values supplied to the release, or all values including
+	// the chart defaults, with sensitive values redacted.
+	Values    map[string]interface{} `json:"values"`
+	Resources []HelmResource         `json:"resources"`
+	// Manifest is the rendered manifest, with Secret data redacted.
+	Manifest string `json:"manifest,omitempty"`
+}
+
+// HelmReleaseOptions configures a GetHelmRelease call.
+type HelmReleaseOptions struct {
+	// Storage is the storage driver, HelmStorageSecret (the default) or
+	// HelmStorageConfigMap.
+	Storage string
+	// Revision selects a revision. Zero selects the latest.
+	Revision int
+	// AllValues returns the chart default values merged with the supplied
+	// values, rather than only the supplied ones.
+	AllValues bool
+	// IncludeManifest returns the rendered manifest.
+	IncludeManifest bool
+}
+
+// helmRecord is a stored revision of a release.
+type helmRecord struct {
+	namespace string
+	name      string
+	revision  int
+	data      []byte
+}
+
+// ListHelmReleases returns the latest revision of each Helm release in
+// namespace, or in all namespaces if namespace is empty. A release whose
+// latest revision cannot be decoded is reported with its error.
+func (c *Client) ListHelmReleases(ctx context.Context, namespace, storage string) ([]HelmReleaseSummary, error) {
+	records, err := c.helmRecords(ctx, namespace, storage, labels.Set{"owner": "helm"})
+	if err != nil {
+		return nil, err
+	}
+
+	latest := map[string]helmRecord{}
+	for _, record := range records {
+		key := record.namespace + "/" + record.name
+		if current, ok := latest[key]; !ok || record.revision > current.revision {
+			latest[key] = record
+		}
+	}
+
+	releases := make([]HelmReleaseSummary, 0, len(latest))
+	for _, record := range latest {
+		releases = append(releases, decodeHelmSummary(record))
+	}
+	sort.Slice(releases, func(i, j int) bool {
+		if releases[i].Namespace != releases[j].Namespace {
+			return releases[i].Namespace < releases[j].Namespace
+		}
+		return releases[i].Name < releases[j].Name
+	})
+	return releases, nil
+}
+
+// GetHelmRelease returns a revision of the Helm release name in namespace,
+// along with the history of the release. Revisions of the history that
+// cannot be decoded are reported with their error.
+func (c *Client) GetHelmRelease(ctx context.Context, namespace, name string, opts HelmReleaseOptions) (*HelmRelease, error) {
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("namespace and name are required")
+	}
+	records, err := c.helmRecords(ctx, namespace, opts.Storage, labels.Set{"owner": "helm", "name": name})
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, fmt.Errorf("release %s not found in namespace %s", name, namespace)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].revision > records[j].revision })
+
+	selected, ok := selectHelmRecord(records, opts.Revision)
+	if !ok {
+		return nil, fmt.Errorf("revision %d of release %s not found", opts.Revision, name)
+	}
+
+	release, err := decodeHelmRelease(selected.data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode release %s revision %d: %w", name, selected.revision, err)
+	}
+
+	result := &HelmRelease{
+		HelmReleaseSummary: helmReleaseSummary(release, selected),
+		FirstDeployed:      release.Info.FirstDeployed,
+		Notes:              release.Info.Notes,
+		History:            make([]HelmReleaseSummary, 0, min(len(records), MaxHelmHistory)),
+	}
+	for _, record := range records[:min(len(records), MaxHelmHistory)] {
+		if record.revision == selected.revision {
+			result.History = append(result.History, result.HelmReleaseSummary)
+		} else {
+			result.History = append(result.History, decodeHelmSummary(record))
+		}
+	}
+
+	if err := addHelmReleaseContent(result, release, opts); err != nil {
+		return nil, fmt.Errorf("failed to parse manifest of release %s: %w", name, err)
+	}
+	return result, nil
+}
+
+// selectHelmRecord returns the record of revision, or the first record if
+// revision is zero. records must not be empty.
+func selectHelmRecord(records []helmRecord, revision int) (helmRecord, bool) {
+	if revision <= 0 {
+		return records[0], true
+	}
+	for _, record := range records {
+		if record.revision == revision {
+			return record, true
+		}
+	}
+	return helmRecord{}, false
+}
+
+// addHelmReleaseContent sets the redacted values, the resources and, if opts
+// asks for it, the manifest of release in result.
+func addHelmReleaseContent(result *HelmRelease, release *helmRelease, opts HelmReleaseOptions) error {
+	values := release.Config
+	if opts.AllValues {
+		values = mergeHelmValues(release.Chart.Values, release.Config)
+	}
+	result.Values = redactHelmValues(values)
+
+	manifest, resources, err := parseHelmManifest(release.Manifest)
+	if err != nil {
+		return err
+	}
+	result.Resources = resources
+	if opts.IncludeManifest {
+		result.Manifest = manifest
+	}
+	return nil
+}
+
+// helmRecords returns the stored revisions of Helm releases matching
+// selector in namespace, listing them page by page.
+func (c *Client) helmRecords(ctx context.Context, namespace, storage string, selector labels.Set) ([]helmRecord, error) {
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	var records []helmRecord
+	switch storage {
+	case "", HelmStorageSecret:
+		err := listPages(ctx, func(options metav1.ListOptions) (string, error) {
+			options.LabelSelector = selector.String()
+			options.FieldSelector = "type=" + helmReleaseSecretType
+			secrets, err := clientset.CoreV1().Secrets(namespace).List(ctx, options)
+			if err != nil {
+				return "", err
+			}
+			for _, secret := range secrets.Items {
+				if secret.Type == helmReleaseSecretType {
+					records = appendHelmRecord(records, secret.ObjectMeta, secret.Data["release"])
+				}
+			}
+			return secrets.Continue, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list release secrets: %w", err)
+		}
+	case HelmStorageConfigMap:
+		err := listPages(ctx, func(options metav1.ListOptions) (string, error) {
+			options.LabelSelector = selector.String()
+			configMaps, err := clientset.CoreV1().ConfigMaps(namespace).List(ctx, options)
+			if err != nil {
+				return "", err
+			}
+			for _, configMap := range configMaps.Items {
+				records = appendHelmRecord(records, configMap.ObjectMeta, []byte(configMap.Data["release"]))
+			}
+			return configMaps.Continue, nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list release config maps: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("invalid storage %q, must be %s or %s", storage, HelmStorageSecret, HelmStorageConfigMap)
+	}
+	return records, nil
+}
+
+// appendHelmRecord appends the revision stored in the object with meta to
+// records, unless it is not a Helm release.
+func appendHelmRecord(records []helmRecord, meta metav1.ObjectMeta, data []byte) []helmRecord {
+	revision, err := strconv.Atoi(meta.Labels["version"])
+	if err != nil || meta.Labels["name"] == "" || len(data) == 0 {
+		return records
+	}
+	return append(records, helmRecord{
+		namespace: meta.Namespace,
+		name:      meta.Labels["name"],
+		revision:  revision,
+		data:      data,
+	})
+}
+
+// decodeHelmRelease decodes a release payload, which is base64 encoded and
+// usually gzip compressed JSON.
+func decodeHelmRelease(data []byte) (*helmRelease, error) {
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(data)))
+	n, err := base64.StdEncoding.Decode(decoded, data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base64: %w", err)
+	}
+	decoded = decoded[:n]
+
+	if bytes.HasPrefix(decoded, gzipMagic) {
+		reader, err := gzip.NewReader(bytes.NewReader(decoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip data: %w", err)
+		}
+		defer reader.Close()
+		decoded, err = io.ReadAll(io.LimitReader(reader, maxHelmReleaseSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip data: %w", err)
+		}
+		if len(decoded) > maxHelmReleaseSize {
+			return nil, fmt.Errorf("release exceeds %d bytes", maxHelmReleaseSize)
+		}
+	}
+
+	var release helmRelease
+	if err := json.Unmarshal(decoded, &release); err != nil {
+		return nil, fmt.Errorf("invalid release: %w", err)
+	}
+	return &release, nil
+}
+
+// decodeHelmSummary decodes and summarizes the revision stored in record. If
+// it cannot be decoded, the summary only identifies it and holds the error.
+func decodeHelmSummary(record helmRecord) HelmReleaseSummary {
+	release, err := decodeHelmRelease(record.data)
+	if err != nil {
+		return HelmReleaseSummary{
+			Name:      record.name,
+			Namespace: record.namespace,
+			Revision:  record.revision,
+			Error:     fmt.Sprintf("failed to decode release: %v", err),
+		}
+	}
+	return helmReleaseSummary(release, record)
+}
+
+// helmReleaseSummary summarizes release, stored in record.
+func helmReleaseSummary(release *helmRelease, record helmRecord) HelmReleaseSummary {
+	summary := HelmReleaseSummary{
+		Name:         release.Name,
+		Namespace:    release.Namespace,
+		Revision:     release.Version,
+		Status:       release.Info.Status,
+		Chart:        release.Chart.Metadata.Name,
+		ChartVersion: release.Chart.Metadata.Version,
+		AppVersion:   release.Chart.Metadata.AppVersion,
+		Updated:      release.Info.LastDeployed,
+		Description:  release.Info.Description,
+	}
+	if summary.Name == "" {
+		summary.Name = record.name
+	}
+	if summary.Namespace == "" {
+		summary.Namespace = record.namespace
+	}
+	if summary.Revision == 0 {
+		summary.Revision = record.revision
+	}
+	return summary
+}
+
+// mergeHelmValues returns the chart default values overridden by the supplied
+// values, like Helm does. A null supplied value removes the default.
+func mergeHelmValues(defaults, supplied map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(defaults)+len(supplied))
+	for key, value := range defaults {
+		merged[key] = value
+	}
+	for key, value := range supplied {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		suppliedMap, suppliedIsMap := value.(map[string]interface{})
+		defaultMap, defaultIsMap := merged[key].(map[string]interface{})
+		if suppliedIsMap && defaultIsMap {
+			merged[key] = mergeHelmValues(defaultMap, suppliedMap)
+		} else {
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// redactHelmValues returns a copy of values in which the values of sensitive
+// keys, such as passwords and tokens, are redacted.
+func redactHelmValues(values map[string]interface{}) map[string]interface{} {
+	redacted := make(map[string]interface{}, len(values))
+	for key, value := range values {
+		if sensitiveHelmKey.MatchString(key) {
+			redacted[key] = redactHelmValue(value)
+		} else {
+			redacted[key] = redactHelmNested(value)
+		}
+	}
+	return redacted
+}
+
+// redactHelmNested redacts the sensitive keys nested in value.
+func redactHelmNested(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		return redactHelmValues(value)
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = redactHelmNested(item)
+		}
+		return items
+	default:
+		return value
+	}
+}
+
+// redactHelmValue redacts value, the value of a sensitive key. Empty values
+// are kept, as they reveal nothing.
+func redactHelmValue(value interface{}) interface{} {
+	switch value := value.(type) {
+	case nil, bool:
+		return value
+	case string:
+		if value == "" {
+			return value
+		}
+		return helmRedacted
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(value))
+		for key, nested := range value {
+			redacted[key] = redactHelmValue(nested)
+		}
+		return redacted
+	case []interface{}:
+		items := make([]interface{}, len(value))
+		for i, item := range value {
+			items[i] = redactHelmValue(item)
+		}
+		return items
+	default:
+		return helmRedacted
+	}
+}
+
+// parseHelmManifest returns the manifest of a release with the data of its
+// Secrets redacted, and the objects it holds.
+func parseHelmManifest(manifest string) (string, []HelmResource, error) {
+	documents := manifestSeparator.Split(manifest, -1)
+	resources := []HelmResource{}
+	for i, document := range documents {
+		var object map[string]interface{}
+		decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(document), 4096)
+		if err := decoder.Decode(&object); err != nil {
+			if errors.Is(err, io.EOF) {
+				continue
+			}
+			return "", nil, err
+		}
+		if object == nil {
+			continue
+		}
+
+		resource := HelmResource{}
+		resource.APIVersion, _ = object["apiVersion"].(string)
+		resource.Kind, _ = object["kind"].(string)
+		if metadata, ok := object["metadata"].(map[string]interface{}); ok {
+			resource.Name, _ = metadata["name"].(string)
+			resource.Namespace, _ = metadata["namespace"].(string)
+		}
+		resources = append(resources, resource)
+
+		if resource.APIVersion == "v1" && resource.Kind == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				if data, ok := object[field].(map[string]interface{}); ok {
+					for key := range data {
+						data[key] = helmRedacted
+					}
+				}
+			}
+			redacted, err := yaml.Marshal(object)
+			if err != nil {
+				return "", nil, err
+			}
+			documents[i] = "\n" + string(redacted)
+		}
+	}
+	return strings.Join(documents, "---"), resources, nil
+}
@@ -0,0 +1,320 @@
+package k8s
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+const testHelmManifest = `---
+# Source: web/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-credentials
+stringData:
+  password: hunter2
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 2
+`
+
+// testHelmRelease returns a release payload like Helm stores it.
+func testHelmRelease(name string, version int, status, chartVersion string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":      name,
+		"namespace": "shop",
+		"version":   version,
+		"info": map[string]interface{}{
+			"first_deployed": "2026-01-01T10:00:00Z",
+			"last_deployed":  fmt.Sprintf("2026-01-0%dT10:00:00Z", version),
+			"description":    "Upgrade complete",
+			"status":         status,
+			"notes":          "Visit the shop",
+		},
+		"chart": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "version": chartVersion, "appVersion": "2.0"},
+			"values": map[string]interface{}{
+				"replicas": 1,
+				"image":    map[string]interface{}{"repository": "nginx", "tag": "1.27"},
+				"db":       map[string]interface{}{"host": "db", "password": ""},
+			},
+		},
+		"config": map[string]interface{}{
+			"image": map[string]interface{}{"tag": "1.28"},
+			"db":    map[string]interface{}{"password": "s3cret", "auth": map[string]interface{}{"user": "admin"}},
+			"extraEnv": []interface{}{
+				map[string]interface{}{"name": "API_TOKEN", "apiToken": "abc"},
+			},
+		},
+		"manifest": testHelmManifest,
+	}
+}
+
+// helmReleaseSecret returns a Secret storing release like Helm does.
+func helmReleaseSecret(t *testing.T, release map[string]interface{}) *corev1.Secret {
+	raw, err := json.Marshal(release)
+	require.NoError(t, err)
+	var compressed bytes.Buffer
+	writer := gzip.NewWriter(&compressed)
+	_, err = writer.Write(raw)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	name := release["name"].(string)
+	version := release["version"].(int)
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, version),
+			Namespace: release["namespace"].(string),
+			Labels: map[string]string{
+				"owner":   "helm",
+				"name":    name,
+				"status":  release["info"].(map[string]interface{})["status"].(string),
+				"version": strconv.Itoa(version),
+			},
+		},
+		Type: helmReleaseSecretType,
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(compressed.Bytes()))},
+	}
+}
+
+func newHelmTestClient(t *testing.T) *Client {
+	raw, err := json.Marshal(testHelmRelease("cache", 1, "deployed", "0.1.0"))
+	require.NoError(t, err)
+
+	objects := []runtime.Object{
+		helmReleaseSecret(t, testHelmRelease("web", 1, "superseded", "1.0.0")),
+		helmReleaseSecret(t, testHelmRelease("web", 2, "superseded", "1.1.0")),
+		helmReleaseSecret(t, testHelmRelease("web", 3, "deployed", "1.2.0")),
+		// An uncompressed release in a ConfigMap
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "sh.helm.release.v1.cache.v1",
+				Namespace: "shop",
+				Labels:    map[string]string{"owner": "helm", "name": "cache", "version": "1"},
+			},
+			Data: map[string]string{"release": base64.StdEncoding.EncodeToString(raw)},
+		},
+		// Not a release
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "shop", Labels: map[string]string{"owner": "helm"}},
+			Type:       corev1.SecretTypeOpaque,
+		},
+	}
+
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(objects...))
+	return client
+}
+
+func TestListHelmReleases(t *testing.T) {
+	client := newHelmTestClient(t)
+
+	releases, err := client.ListHelmReleases(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []HelmReleaseSummary{{
+		Name:         "web",
+		Namespace:    "shop",
+		Revision:     3,
+		Status:       "deployed",
+		Chart:        "web",
+		ChartVersion: "1.2.0",
+		AppVersion:   "2.0",
+		Updated:      "2026-01-03T10:00:00Z",
+		Description:  "Upgrade complete",
+	}}, releases)
+
+	releases, err = client.ListHelmReleases(context.Background(), "shop", HelmStorageConfigMap)
+	require.NoError(t, err)
+	require.Len(t, releases, 1)
+	assert.Equal(t, "cache", releases[0].Name)
+	assert.Equal(t, "0.1.0", releases[0].ChartVersion)
+
+	_, err = client.ListHelmReleases(context.Background(), "shop", "sql")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid storage")
+}
+
+func TestListHelmReleasesUndecodable(t *testing.T) {
+	broken := helmReleaseSecret(t, testHelmRelease("api", 4, "deployed", "3.0.0"))
+	broken.Data["release"] = []byte("not base64!")
+
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(
+		broken,
+		helmReleaseSecret(t, testHelmRelease("web", 1, "deployed", "1.0.0")),
+	))
+
+	releases, err := client.ListHelmReleases(context.Background(), "shop", "")
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, "api", releases[0].Name)
+	assert.Equal(t, 4, releases[0].Revision)
+	assert.Contains(t, releases[0].Error, "invalid base64")
+	assert.Equal(t, "web", releases[1].Name)
+	assert.Equal(t, "1.0.0", releases[1].ChartVersion)
+	assert.Empty(t, releases[1].Error)
+}
+
+func TestListHelmReleasesPaged(t *testing.T) {
+	pages := []*corev1.SecretList{
+		{
+			ListMeta: metav1.ListMeta{Continue: "page-2"},
+			Items:    []corev1.Secret{*helmReleaseSecret(t, testHelmRelease("web", 1, "superseded", "1.0.0"))},
+		},
+		{
+			Items: []corev1.Secret{*helmReleaseSecret(t, testHelmRelease("web", 2, "deployed", "1.1.0"))},
+		},
+	}
+	clientset := kubefake.NewSimpleClientset()
+	var continues []string
+	clientset.PrependReactor("list", "secrets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		options := action.(ktesting.ListActionImpl).GetListOptions()
+		assert.Positive(t, options.Limit)
+		continues = append(continues, options.Continue)
+		if options.Continue == "" {
+			return true, pages[0], nil
+		}
+		return true, pages[1], nil
+	})
+	client := &Client{}
+	client.SetClientset(clientset)
+
+	releases, err := client.ListHelmReleases(context.Background(), "", "")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"", "page-2"}, continues)
+	require.Len(t, releases, 1)
+	assert.Equal(t, 2, releases[0].Revision)
+}
+
+func TestGetHelmRelease(t *testing.T) {
+	client := newHelmTestClient(t)
+
+	release, err := client.GetHelmRelease(context.Background(), "shop", "web", HelmReleaseOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 3, release.Revision)
+	assert.Equal(t, "1.2.0", release.ChartVersion)
+	assert.Equal(t, "Visit the shop", release.Notes)
+	require.Len(t, release.History, 3)
+	assert.Equal(t, []int{3, 2, 1}, []int{release.History[0].Revision, release.History[1].Revision, release.History[2].Revision})
+	assert.Equal(t, "superseded", release.History[1].Status)
+	assert.Equal(t, "1.0.0", release.History[2].ChartVersion)
+
+	// Supplied values, redacted
+	assert.Equal(t, map[string]interface{}{
+		"image": map[string]interface{}{"tag": "1.28"},
+		"db":    map[string]interface{}{"password": "[REDACTED]", "auth": map[string]interface{}{"user": "[REDACTED]"}},
+		"extraEnv": []interface{}{
+			map[string]interface{}{"name": "API_TOKEN", "apiToken": "[REDACTED]"},
+		},
+	}, release.Values)
+
+	assert.Equal(t, []HelmResource{
+		{APIVersion: "v1", Kind: "Secret", Name: "web-credentials"},
+		{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "shop", Name: "web"},
+	}, release.Resources)
+	assert.Empty(t, release.Manifest)
+}
+
+func TestGetHelmReleaseOptions(t *testing.T) {
+	client := newHelmTestClient(t)
+
+	release, err := client.GetHelmRelease(context.Background(), "shop", "web", HelmReleaseOptions{
+		Revision:        2,
+		AllValues:       true,
+		IncludeManifest: true,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, 2, release.Revision)
+	assert.Equal(t, "1.1.0", release.ChartVersion)
+	assert.Len(t, release.History, 3)
+
+	// Chart defaults merged with the supplied values
+	assert.Equal(t, float64(1), release.Values["replicas"])
+	assert.Equal(t, map[string]interface{}{"repository": "nginx", "tag": "1.28"}, release.Values["image"])
+	assert.Equal(t, map[string]interface{}{
+		"host": "db", "password": "[REDACTED]", "auth": map[string]interface{}{"user": "[REDACTED]"},
+	}, release.Values["db"])
+
+	// Secret data is redacted from the manifest, other documents are kept as is
+	assert.NotContains(t, release.Manifest, "hunter2")
+	assert.Contains(t, release.Manifest, "password: '[REDACTED]'")
+	assert.Contains(t, release.Manifest, "# Source: web/templates/deployment.yaml")
+
+	release, err = client.GetHelmRelease(context.Background(), "shop", "cache", HelmReleaseOptions{Storage: HelmStorageConfigMap})
+	require.NoError(t, err)
+	assert.Equal(t, "cache", release.Name)
+}
+
+func TestGetHelmReleaseUndecodableHistory(t *testing.T) {
+	broken := helmReleaseSecret(t, testHelmRelease("web", 1, "superseded", "1.0.0"))
+	broken.Data["release"] = []byte("not base64!")
+
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(
+		broken,
+		helmReleaseSecret(t, testHelmRelease("web", 2, "deployed", "1.1.0")),
+	))
+
+	release, err := client.GetHelmRelease(context.Background(), "shop", "web", HelmReleaseOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, 2, release.Revision)
+	require.Len(t, release.History, 2)
+	assert.Empty(t, release.History[0].Error)
+	assert.Equal(t, 1, release.History[1].Revision)
+	assert.Contains(t, release.History[1].Error, "invalid base64")
+
+	_, err = client.GetHelmRelease(context.Background(), "shop", "web", HelmReleaseOptions{Revision: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to decode release web revision 1")
+}
+
+func TestGetHelmReleaseNotFound(t *testing.T) {
+	client := newHelmTestClient(t)
+
+	_, err := client.GetHelmRelease(context.Background(), "shop", "missing", HelmReleaseOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "release missing not found")
+
+	_, err = client.GetHelmRelease(context.Background(), "shop", "web", HelmReleaseOptions{Revision: 7})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "revision 7")
+}
+
+func TestDecodeHelmReleaseInvalid(t *testing.T) {
+	_, err := decodeHelmRelease([]byte("not base64!"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid base64")
+
+	_, err = decodeHelmRelease([]byte(base64.StdEncoding.EncodeToString([]byte("{"))))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid release")
+}
+
+func TestMergeHelmValues(t *testing.T) {
+	merged := mergeHelmValues(
+		map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 3}, "e": 4},
+		map[string]interface{}{"b": map[string]interface{}{"d": 5}, "e": nil, "f": 6},
+	)
+	assert.Equal(t, map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2, "d": 5}, "f": 6}, merged)
+}
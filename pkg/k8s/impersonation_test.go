@@ -89,6 +89,7 @@ func TestWithImpersonation(t *testing.T) {
 		require.NoError(t, err)
 		assert.NotNil(t, impersonated.getPodLogs)
 		assert.NotNil(t, impersonated.execInPod)
+		assert.NotNil(t, impersonated.portForward)
 	})
 
 	t.Run("shares parent pod-log semaphore", func(t *testing.T) {
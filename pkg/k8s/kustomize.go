@@ -0,0 +1,165 @@
+package k8s
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"sigs.k8s.io/kustomize/api/konfig"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+const (
+	// MaxKustomizationFiles is the largest number of files a kustomization
+	// passed to BuildKustomization may have.
+	MaxKustomizationFiles = 200
+	// MaxKustomizationSize is the largest total size in bytes of the files of a
+	// kustomization passed to BuildKustomization.
+	MaxKustomizationSize = 4 << 20
+)
+
+// kustomizeRoot is the directory of the in-memory file system holding the
+// files of a kustomization.
+const kustomizeRoot = "/kustomize"
+
+// gitUsernamePrefix matches the username of SCP-style git URLs, such as
+// git@example.com:org/repo, which kustomize clones.
+var gitUsernamePrefix = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9-]*@`)
+
+// BuildKustomization renders the kustomization in directory dir of files,
+// like `kustomize build`, and returns the resulting multi-document YAML.
+// Files maps paths relative to the root of the kustomization to their
+// content, and is held in memory.
+//
+// Kustomize fetches remote bases and URLs when referenced, so references
+// that are not local paths are rejected. Plugins and Helm charts are
+// disabled.
+func BuildKustomization(files map[string]string, dir string) (string, error) {
+	if len(files) == 0 {
+		return "", fmt.Errorf("no files")
+	}
+	if len(files) > MaxKustomizationFiles {
+		return "", fmt.Errorf("too many files: %d, at most %d are allowed", len(files), MaxKustomizationFiles)
+	}
+
+	fSys := filesys.MakeFsInMemory()
+	size := 0
+	for name, content := range files {
+		size += len(content)
+		if size > MaxKustomizationSize {
+			return "", fmt.Errorf("files exceed %d bytes", MaxKustomizationSize)
+		}
+
+		cleanName, err := kustomizationPath(name)
+		if err != nil {
+			return "", err
+		}
+		if slices.Contains(konfig.RecognizedKustomizationFileNames(), path.Base(cleanName)) {
+			if err := checkKustomization([]byte(content)); err != nil {
+				return "", fmt.Errorf("%s: %w", name, err)
+			}
+		}
+		if err := fSys.WriteFile(path.Join(kustomizeRoot, cleanName), []byte(content)); err != nil {
+			return "", fmt.Errorf("failed to write %s: %w", name, err)
+		}
+	}
+
+	if dir == "" {
+		dir = "."
+	}
+	cleanDir, err := kustomizationPath(dir)
+	if err != nil {
+		return "", err
+	}
+
+	kustomizer := krusty.MakeKustomizer(krusty.MakeDefaultOptions())
+	resMap, err := kustomizer.Run(fSys, path.Join(kustomizeRoot, cleanDir))
+	if err != nil {
+		return "", fmt.Errorf("failed to build kustomization: %w", err)
+	}
+	manifests, err := resMap.AsYaml()
+	if err != nil {
+		return "", fmt.Errorf("failed to encode kustomization: %w", err)
+	}
+	return string(manifests), nil
+}
+
+// kustomizationPath returns the cleaned form of name, a path relative to the
+// root of a kustomization.
+func kustomizationPath(name string) (string, error) {
+	cleanName := path.Clean(name)
+	if path.IsAbs(cleanName) || cleanName == ".." || strings.HasPrefix(cleanName, "../") {
+		return "", fmt.Errorf("invalid path %q: must be relative and within the kustomization", name)
+	}
+	return cleanName, nil
+}
+
+// checkKustomization returns an error if the kustomization file content
+// references a remote base or file.
+func checkKustomization(content []byte) error {
+	var kustomization types.Kustomization
+	if err := kustomization.Unmarshal(content); err != nil {
+		return fmt.Errorf("invalid kustomization: %w", err)
+	}
+	for _, ref := range kustomizationReferences(&kustomization) {
+		if isRemoteReference(ref) {
+			return fmt.Errorf("remote reference %q is not supported, only files passed in the kustomization", ref)
+		}
+	}
+	return nil
+}
+
+// kustomizationReferences returns the paths the kustomization refers to.
+// Entries that hold inline content rather than a path are included as well.
+func kustomizationReferences(k *types.Kustomization) []string {
+	refs := slices.Concat(k.Resources, k.Components, k.Bases, k.Crds,
+		k.Configurations, k.Generators, k.Transformers, k.Validators)
+	for _, patch := range k.PatchesStrategicMerge {
+		refs = append(refs, string(patch))
+	}
+	for _, patch := range slices.Concat(k.Patches, k.PatchesJson6902) {
+		refs = append(refs, patch.Path)
+	}
+	for _, replacement := range k.Replacements {
+		refs = append(refs, replacement.Path)
+	}
+	for _, generator := range k.ConfigMapGenerator {
+		refs = append(refs, kvPairReferences(generator.KvPairSources)...)
+	}
+	for _, generator := range k.SecretGenerator {
+		refs = append(refs, kvPairReferences(generator.KvPairSources)...)
+	}
+	return append(refs, k.OpenAPI["path"])
+}
+
+// kvPairReferences returns the files a ConfigMap or Secret generator reads.
+func kvPairReferences(sources types.KvPairSources) []string {
+	refs := slices.Concat(sources.EnvSources, []string{sources.EnvSource})
+	for _, source := range sources.FileSources {
+		// Files may be given as key=path.
+		if _, file, found := strings.Cut(source, "="); found {
+			source = file
+		}
+		refs = append(refs, source)
+	}
+	return refs
+}
+
+// isRemoteReference returns whether kustomize would fetch ref from a remote
+// location, either over HTTP or by cloning a git repository.
+func isRemoteReference(ref string) bool {
+	ref = strings.TrimSpace(ref)
+	if ref == "" || strings.Contains(ref, "\n") {
+		// Empty or inline content
+		return false
+	}
+	lower := strings.ToLower(ref)
+	return strings.Contains(lower, "://") ||
+		strings.HasPrefix(lower, "git::") ||
+		strings.HasPrefix(lower, "github.com") ||
+		gitUsernamePrefix.MatchString(ref)
+}
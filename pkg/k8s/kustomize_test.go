@@ -0,0 +1,153 @@
+package k8s
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+var testKustomization = map[string]string{
+	"base/kustomization.yaml": `resources:
+- deployment.yaml
+configMapGenerator:
+- name: settings
+  literals:
+  - mode=fast
+`,
+	"base/deployment.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+      - name: web
+        image: nginx:1.27
+`,
+	"overlays/prod/kustomization.yaml": `namespace: prod
+namePrefix: prod-
+resources:
+- ../../base
+patches:
+- path: replicas.yaml
+images:
+- name: nginx
+  newTag: "1.28"
+`,
+	"overlays/prod/replicas.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  replicas: 3
+`,
+}
+
+func TestBuildKustomization(t *testing.T) {
+	manifests, err := BuildKustomization(testKustomization, "overlays/prod")
+	require.NoError(t, err)
+
+	objects, err := DecodeManifests(manifests)
+	require.NoError(t, err)
+	require.Len(t, objects, 2)
+
+	var deployment *unstructured.Unstructured
+	for _, obj := range objects {
+		assert.Equal(t, "prod", obj.GetNamespace())
+		if obj.GetKind() == "Deployment" {
+			deployment = obj
+		}
+	}
+	require.NotNil(t, deployment)
+	assert.Equal(t, "prod-web", deployment.GetName())
+	replicas, _, _ := unstructured.NestedInt64(deployment.Object, "spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	assert.Contains(t, manifests, "image: nginx:1.28")
+	assert.Contains(t, manifests, "name: prod-settings-")
+
+	// The base alone
+	manifests, err = BuildKustomization(testKustomization, "base")
+	require.NoError(t, err)
+	assert.Contains(t, manifests, "name: web\n")
+}
+
+func TestBuildKustomizationInvalid(t *testing.T) {
+	testCases := []struct {
+		name     string
+		files    map[string]string
+		dir      string
+		errorMsg string
+	}{
+		{name: "No files", errorMsg: "no files"},
+		{
+			name:     "Path outside the kustomization",
+			files:    map[string]string{"../kustomization.yaml": "resources: []\n"},
+			errorMsg: "invalid path",
+		},
+		{
+			name:     "Absolute directory",
+			files:    map[string]string{"kustomization.yaml": "resources: []\n"},
+			dir:      "/etc",
+			errorMsg: "invalid path",
+		},
+		{
+			name:     "Missing kustomization",
+			files:    map[string]string{"deployment.yaml": "kind: Deployment\n"},
+			errorMsg: "failed to build kustomization",
+		},
+		{
+			name:     "Remote git base",
+			files:    map[string]string{"kustomization.yaml": "resources:\n- github.com/org/repo//deploy?ref=v1\n"},
+			errorMsg: "remote reference",
+		},
+		{
+			name:     "Remote file",
+			files:    map[string]string{"kustomization.yaml": "resources:\n- https://example.com/deploy.yaml\n"},
+			errorMsg: "remote reference",
+		},
+		{
+			name:     "SCP-style git base",
+			files:    map[string]string{"a/kustomization.yml": "components:\n- git@example.com:org/repo\n"},
+			errorMsg: "remote reference",
+		},
+		{
+			name: "Remote patch",
+			files: map[string]string{
+				"kustomization.yaml": "patches:\n- path: http://example.com/patch.yaml\n",
+			},
+			errorMsg: "remote reference",
+		},
+		{
+			name: "Remote generator file",
+			files: map[string]string{
+				"kustomization.yaml": "configMapGenerator:\n- name: x\n  files:\n  - key=https://example.com/x\n",
+			},
+			errorMsg: "remote reference",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := BuildKustomization(tc.files, tc.dir)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
+
+func TestIsRemoteReference(t *testing.T) {
+	for _, ref := range []string{"deployment.yaml", "../base", "github-config/app.yaml", "", "apiVersion: v1\nkind: ConfigMap\n"} {
+		assert.False(t, isRemoteReference(ref), ref)
+	}
+	for _, ref := range []string{
+		"https://example.com/app.yaml", "HTTP://example.com/app.yaml", "ssh://git@example.com/org/repo",
+		"file:///tmp/repo", "git::https://example.com/org/repo", "github.com/org/repo", "GitHub.com:org/repo",
+		"git@example.com:org/repo",
+	} {
+		assert.True(t, isRemoteReference(ref), ref)
+	}
+}
@@ -0,0 +1,318 @@
+package k8s
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/restmapper"
+)
+
+// MaxManifestObjects is the largest number of objects a single ApplyManifests
+// call may apply.
+const MaxManifestObjects = 200
+
+// Statuses of the objects of an ApplyManifests call.
+const (
+	ManifestStatusApplied = "applied"
+	ManifestStatusFailed  = "failed"
+	ManifestStatusSkipped = "skipped"
+)
+
+// crdEstablishTimeout is how long ApplyManifests waits for the kinds of
+// CustomResourceDefinitions applied in the same call to be served.
+var crdEstablishTimeout = 10 * time.Second
+
+// crdEstablishPollInterval is how often discovery is refreshed while waiting
+// for the kinds of new CustomResourceDefinitions.
+var crdEstablishPollInterval = time.Second
+
+// manifestKindOrder is the order in which kinds are applied, so that the
+// objects others depend on exist first. Other kinds are applied after these,
+// in the order of the manifests.
+var manifestKindOrder = []string{
+	"Namespace",
+	"CustomResourceDefinition",
+	"PriorityClass",
+	"ResourceQuota",
+	"LimitRange",
+	"ServiceAccount",
+	"Secret",
+	"ConfigMap",
+	"StorageClass",
+	"PersistentVolume",
+	"PersistentVolumeClaim",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"Role",
+	"RoleBinding",
+	"Service",
+}
+
+// ManifestResult is the result of applying one object of an ApplyManifests
+// call.
+type ManifestResult struct {
+	// Index is the position of the object in the manifests.
+	Index           int    `json:"index"`
+	APIVersion      string `json:"apiVersion"`
+	Kind            string `json:"kind"`
+	Namespace       string `json:"namespace,omitempty"`
+	Name            string `json:"name"`
+	Resource        string `json:"resource,omitempty"`
+	Status          string `json:"status"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Error           string `json:"error,omitempty"`
+}
+
+// ApplyManifestsResult is the result of an ApplyManifests call. Results are
+// in the order the objects were applied.
+type ApplyManifestsResult struct {
+	DryRun  bool             `json:"dryRun,omitempty"`
+	Applied int              `json:"applied"`
+	Failed  int              `json:"failed"`
+	Skipped int              `json:"skipped,omitempty"`
+	Results []ManifestResult `json:"results"`
+	Message string           `json:"message"`
+}
+
+// ApplyManifestsOptions configures an ApplyManifests call.
+type ApplyManifestsOptions struct {
+	// Namespace is the namespace of namespaced objects that do not set one.
+	// Empty means "default".
+	Namespace string
+	// DryRun applies the objects as a server-side dry run. Custom resources
+	// whose definition is in the same manifests cannot be dry run, as the
+	// definition is not created.
+	DryRun bool
+	// OnProgress, if set, is called with a short message whenever an object
+	// is applied or fails.
+	OnProgress func(message string)
+}
+
+// DecodeManifests decodes YAML or JSON manifests holding any number of
+// documents. Lists, such as a v1/List, are expanded into their items.
+// Every object must have an apiVersion, a kind and a name or generateName.
+func DecodeManifests(manifests string) ([]*unstructured.Unstructured, error) {
+	decoder := utilyaml.NewYAMLOrJSONDecoder(strings.NewReader(manifests), 4096)
+
+	var objects []*unstructured.Unstructured
+	for document := 1; ; document++ {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("document %d: %w", document, err)
+		}
+		raw = bytes.TrimSpace(raw)
+		if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+			continue
+		}
+
+		decoded, _, err := unstructured.UnstructuredJSONScheme.Decode(raw, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("document %d: %w", document, err)
+		}
+		switch decoded := decoded.(type) {
+		case *unstructured.UnstructuredList:
+			for i := range decoded.Items {
+				objects = append(objects, &decoded.Items[i])
+			}
+		case *unstructured.Unstructured:
+			objects = append(objects, decoded)
+		default:
+			return nil, fmt.Errorf("document %d: unexpected object %T", document, decoded)
+		}
+	}
+
+	for i, obj := range objects {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" {
+			return nil, fmt.Errorf("object %d: apiVersion and kind are required", i)
+		}
+		if obj.GetName() == "" && obj.GetGenerateName() == "" {
+			return nil, fmt.Errorf("object %d (%s): metadata.name is required", i, obj.GetKind())
+		}
+	}
+	return objects, nil
+}
+
+// ApplyManifests applies objects, resolving the resource of each object from
+// its apiVersion and kind through discovery. Namespaces and
+// CustomResourceDefinitions are applied first, followed by the kinds others
+// commonly depend on. A failing object does not stop the others from being
+// applied.
+func (c *Client) ApplyManifests(
+	ctx context.Context,
+	objects []*unstructured.Unstructured,
+	opts ApplyManifestsOptions,
+) (*ApplyManifestsResult, error) {
+	if len(objects) == 0 {
+		return nil, fmt.Errorf("no objects to apply")
+	}
+	if len(objects) > MaxManifestObjects {
+		return nil, fmt.Errorf("too many objects: %d, at most %d can be applied at once", len(objects), MaxManifestObjects)
+	}
+	defaultNamespace := opts.Namespace
+	if defaultNamespace == "" {
+		defaultNamespace = "default"
+	}
+
+	c.mu.RLock()
+	discoveryClient := c.discoveryClient
+	c.mu.RUnlock()
+	if discoveryClient == nil {
+		return nil, fmt.Errorf("discovery client is not initialized")
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+
+	order := make([]int, len(objects))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		return manifestKindRank(objects[order[a]]) < manifestKindRank(objects[order[b]])
+	})
+
+	result := &ApplyManifestsResult{DryRun: opts.DryRun, Results: make([]ManifestResult, 0, len(objects))}
+	crdsApplied := false
+	for _, index := range order {
+		obj := objects[index].DeepCopy()
+		objResult := ManifestResult{
+			Index:      index,
+			APIVersion: obj.GetAPIVersion(),
+			Kind:       obj.GetKind(),
+			Namespace:  obj.GetNamespace(),
+			Name:       obj.GetName(),
+		}
+
+		if ctx.Err() != nil {
+			objResult.Status = ManifestStatusSkipped
+			objResult.Error = ctx.Err().Error()
+			result.Skipped++
+			result.Results = append(result.Results, objResult)
+			continue
+		}
+
+		c.applyManifestObject(ctx, mapper, obj, defaultNamespace, crdsApplied, opts.DryRun, &objResult)
+		if objResult.Status == ManifestStatusFailed {
+			result.Failed++
+		} else {
+			result.Applied++
+			if isCustomResourceDefinition(obj) && !opts.DryRun {
+				crdsApplied = true
+			}
+		}
+		if opts.OnProgress != nil {
+			opts.OnProgress(fmt.Sprintf("%s %s: %s", strings.ToLower(objResult.Kind), objResult.Name, objResult.Status))
+		}
+		result.Results = append(result.Results, objResult)
+	}
+
+	result.Message = fmt.Sprintf("%d of %d object(s) applied", result.Applied, len(objects))
+	if result.Failed > 0 {
+		result.Message += fmt.Sprintf(", %d failed", result.Failed)
+	}
+	if result.Skipped > 0 {
+		result.Message += fmt.Sprintf(", %d skipped", result.Skipped)
+	}
+	if opts.DryRun {
+		result.Message += " (dry run)"
+	}
+	return result, nil
+}
+
+// applyManifestObject resolves the resource of obj, defaults its namespace to
+// defaultNamespace if it is namespaced, and applies it, recording the outcome
+// in objResult.
+func (c *Client) applyManifestObject(
+	ctx context.Context,
+	mapper *restmapper.DeferredDiscoveryRESTMapper,
+	obj *unstructured.Unstructured,
+	defaultNamespace string,
+	crdsApplied, dryRun bool,
+	objResult *ManifestResult,
+) {
+	objResult.Status = ManifestStatusFailed
+
+	mapping, err := resolveManifestMapping(ctx, mapper, obj, crdsApplied)
+	if err != nil {
+		objResult.Error = err.Error()
+		return
+	}
+	objResult.Resource = mapping.Resource.Resource
+
+	namespace := ""
+	if mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+		namespace = obj.GetNamespace()
+		if namespace == "" {
+			namespace = defaultNamespace
+		}
+	}
+	obj.SetNamespace(namespace)
+	objResult.Namespace = namespace
+
+	applied, err := c.ApplyResource(ctx, mapping.Resource, namespace, obj, ApplyOptions{DryRun: dryRun})
+	if err != nil {
+		objResult.Error = err.Error()
+		return
+	}
+	objResult.Name = applied.GetName()
+	objResult.ResourceVersion = applied.GetResourceVersion()
+	objResult.Status = ManifestStatusApplied
+}
+
+// resolveManifestMapping returns the REST mapping of obj. When
+// CustomResourceDefinitions were applied before, discovery is refreshed and,
+// if the kind is not served yet, polled until it is.
+func resolveManifestMapping(
+	ctx context.Context,
+	mapper *restmapper.DeferredDiscoveryRESTMapper,
+	obj *unstructured.Unstructured,
+	crdsApplied bool,
+) (*meta.RESTMapping, error) {
+	gvk := obj.GroupVersionKind()
+	mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err == nil || !meta.IsNoMatchError(err) || !crdsApplied {
+		return mapping, err
+	}
+
+	deadline := time.Now().Add(crdEstablishTimeout)
+	for {
+		mapper.Reset()
+		mapping, err = mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err == nil || !meta.IsNoMatchError(err) || time.Now().After(deadline) {
+			return mapping, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(crdEstablishPollInterval):
+		}
+	}
+}
+
+// manifestKindRank returns the position of the kind of obj in the apply order.
+func manifestKindRank(obj runtime.Object) int {
+	if rank := slices.Index(manifestKindOrder, obj.GetObjectKind().GroupVersionKind().Kind); rank >= 0 {
+		return rank
+	}
+	return len(manifestKindOrder)
+}
+
+// isCustomResourceDefinition returns whether obj is a CustomResourceDefinition.
+func isCustomResourceDefinition(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	return gvk.Group == "apiextensions.k8s.io" && gvk.Kind == "CustomResourceDefinition"
+}
@@ -0,0 +1,235 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+const testManifests = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: shop
+spec:
+  replicas: 2
+---
+# The namespace is applied before the objects in it
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+---
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: settings
+  data:
+    mode: fast
+`
+
+func newManifestsTestClient() (*Client, *dynamicfake.FakeDynamicClient, *discoveryfake.FakeDiscovery) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace"},
+				{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+			},
+		},
+		{
+			GroupVersion: "apiextensions.k8s.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "customresourcedefinitions", Kind: "CustomResourceDefinition"},
+			},
+		},
+	}
+
+	client := &Client{}
+	client.SetDynamicClient(fakeDynamic)
+	client.SetDiscoveryClient(fakeDiscovery)
+	return client, fakeDynamic, fakeDiscovery
+}
+
+func TestDecodeManifests(t *testing.T) {
+	objects, err := DecodeManifests(testManifests)
+	require.NoError(t, err)
+	require.Len(t, objects, 3)
+	assert.Equal(t, "Deployment", objects[0].GetKind())
+	assert.Equal(t, "Namespace", objects[1].GetKind())
+	assert.Equal(t, "ConfigMap", objects[2].GetKind())
+
+	// Numbers are decoded as integers
+	replicas, found, err := unstructured.NestedInt64(objects[0].Object, "spec", "replicas")
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, int64(2), replicas)
+
+	// JSON is accepted as well
+	objects, err = DecodeManifests(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "settings"}}`)
+	require.NoError(t, err)
+	require.Len(t, objects, 1)
+	assert.Equal(t, "settings", objects[0].GetName())
+}
+
+func TestDecodeManifestsInvalid(t *testing.T) {
+	testCases := []struct {
+		name      string
+		manifests string
+		errorMsg  string
+	}{
+		{name: "Missing kind", manifests: "apiVersion: v1\nmetadata:\n  name: x\n", errorMsg: "document 1"},
+		{name: "Missing name", manifests: "apiVersion: v1\nkind: ConfigMap\n", errorMsg: "metadata.name is required"},
+		{name: "Invalid YAML", manifests: "apiVersion: v1\nkind: [\n", errorMsg: "document 1"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := DecodeManifests(tc.manifests)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
+
+func TestApplyManifests(t *testing.T) {
+	client, fakeDynamic, _ := newManifestsTestClient()
+
+	objects, err := DecodeManifests(testManifests + `---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: mystery
+`)
+	require.NoError(t, err)
+
+	var progress []string
+	result, err := client.ApplyManifests(context.Background(), objects, ApplyManifestsOptions{
+		Namespace:  "team",
+		OnProgress: func(message string) { progress = append(progress, message) },
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, 3, result.Applied)
+	assert.Equal(t, 1, result.Failed)
+	assert.Equal(t, "3 of 4 object(s) applied, 1 failed", result.Message)
+	assert.Len(t, progress, 4)
+
+	// Namespaces first, then the kinds others depend on, then manifest order
+	require.Len(t, result.Results, 4)
+	assert.Equal(t, ManifestResult{
+		Index: 1, APIVersion: "v1", Kind: "Namespace", Name: "shop", Resource: "namespaces", Status: ManifestStatusApplied,
+	}, result.Results[0])
+	assert.Equal(t, ManifestResult{
+		Index: 2, APIVersion: "v1", Kind: "ConfigMap", Namespace: "team", Name: "settings", Resource: "configmaps",
+		Status: ManifestStatusApplied,
+	}, result.Results[1])
+	assert.Equal(t, ManifestResult{
+		Index: 0, APIVersion: "apps/v1", Kind: "Deployment", Namespace: "shop", Name: "web", Resource: "deployments",
+		Status: ManifestStatusApplied,
+	}, result.Results[2])
+	assert.Equal(t, 3, result.Results[3].Index)
+	assert.Equal(t, ManifestStatusFailed, result.Results[3].Status)
+	assert.Contains(t, result.Results[3].Error, "no matches for kind")
+
+	configMapsGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	_, err = fakeDynamic.Resource(configMapsGVR).Namespace("team").Get(context.Background(), "settings", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestApplyManifestsWithCustomResourceDefinition(t *testing.T) {
+	oldTimeout, oldInterval := crdEstablishTimeout, crdEstablishPollInterval
+	crdEstablishTimeout, crdEstablishPollInterval = time.Second, 10*time.Millisecond
+	defer func() { crdEstablishTimeout, crdEstablishPollInterval = oldTimeout, oldInterval }()
+
+	client, fakeDynamic, fakeDiscovery := newManifestsTestClient()
+
+	// The custom resource is served once its definition is created
+	fakeDynamic.PrependReactor("create", "customresourcedefinitions", func(ktesting.Action) (bool, runtime.Object, error) {
+		fakeDiscovery.Resources = append(fakeDiscovery.Resources, &metav1.APIResourceList{
+			GroupVersion: "example.com/v1",
+			APIResources: []metav1.APIResource{{Name: "widgets", Kind: "Widget", Namespaced: true}},
+		})
+		return false, nil, nil
+	})
+
+	objects, err := DecodeManifests(`
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+`)
+	require.NoError(t, err)
+
+	result, err := client.ApplyManifests(context.Background(), objects, ApplyManifestsOptions{})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Applied, "%+v", result.Results)
+	assert.Equal(t, "CustomResourceDefinition", result.Results[0].Kind)
+	assert.Equal(t, "widgets", result.Results[1].Resource)
+	assert.Equal(t, "default", result.Results[1].Namespace)
+}
+
+func TestApplyManifestsInvalid(t *testing.T) {
+	client, _, _ := newManifestsTestClient()
+
+	_, err := client.ApplyManifests(context.Background(), nil, ApplyManifestsOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no objects")
+
+	objects, err := DecodeManifests("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: x\n")
+	require.NoError(t, err)
+	for len(objects) <= MaxManifestObjects {
+		objects = append(objects, objects[0])
+	}
+	_, err = client.ApplyManifests(context.Background(), objects, ApplyManifestsOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many objects")
+}
+
+func TestApplyManifestsDryRun(t *testing.T) {
+	client, fakeDynamic, _ := newManifestsTestClient()
+
+	var dryRun [][]string
+	fakeDynamic.PrependReactor("create", "*", func(action ktesting.Action) (bool, runtime.Object, error) {
+		dryRun = append(dryRun, action.(ktesting.CreateActionImpl).CreateOptions.DryRun)
+		return true, action.(ktesting.CreateAction).GetObject(), nil
+	})
+
+	objects, err := DecodeManifests(testManifests)
+	require.NoError(t, err)
+	result, err := client.ApplyManifests(context.Background(), objects, ApplyManifestsOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.Equal(t, 3, result.Applied)
+	assert.Equal(t, "3 of 3 object(s) applied (dry run)", result.Message)
+	assert.Equal(t, [][]string{{metav1.DryRunAll}, {metav1.DryRunAll}, {metav1.DryRunAll}}, dryRun)
+}
@@ -0,0 +1,294 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxSecurityPods is the largest number of pods with findings returned by
+	// NamespaceSecurity.
+	MaxSecurityPods = 50
+	// podSecurityLabelPrefix is the prefix of the Pod Security Admission
+	// labels of namespaces.
+	podSecurityLabelPrefix = "pod-security.kubernetes.io/"
+)
+
+// NamespaceSecurity is the security posture of a namespace.
+type NamespaceSecurity struct {
+	Namespace string `json:"namespace"`
+	// PodSecurity are the Pod Security Admission labels of the namespace,
+	// e.g. enforce: restricted.
+	PodSecurity     map[string]string `json:"podSecurity,omitempty"`
+	NetworkPolicies []string          `json:"networkPolicies"`
+	// DefaultDenyIngress and DefaultDenyEgress are whether a NetworkPolicy
+	// selecting all pods isolates them, so that only the traffic policies
+	// allow is permitted.
+	DefaultDenyIngress bool `json:"defaultDenyIngress"`
+	DefaultDenyEgress  bool `json:"defaultDenyEgress"`
+	// Pods are the pods with findings.
+	Pods      []PodSecurityFindings `json:"pods"`
+	TotalPods int                   `json:"totalPods"`
+	Truncated bool                  `json:"truncated,omitempty"`
+	// RoleBindings are the RoleBindings of the namespace granting broad or
+	// sensitive permissions.
+	RoleBindings []RoleBindingFindings `json:"roleBindings"`
+}
+
+// PodSecurityFindings are the weaknesses of the security settings of a pod.
+type PodSecurityFindings struct {
+	Name           string   `json:"name"`
+	ServiceAccount string   `json:"serviceAccount,omitempty"`
+	Findings       []string `json:"findings"`
+}
+
+// RoleBindingFindings are the broad or sensitive permissions a RoleBinding
+// grants.
+type RoleBindingFindings struct {
+	Name     string   `json:"name"`
+	Role     string   `json:"role"`
+	Subjects []string `json:"subjects"`
+	Findings []string `json:"findings"`
+}
+
+// sensitivePermissions are the permissions RoleBindings are flagged for.
+var sensitivePermissions = []struct {
+	query   RBACQuery
+	finding string
+}{
+	{RBACQuery{Verb: "get", Resource: "secrets"}, "reads secrets"},
+	{RBACQuery{Verb: "create", Resource: "pods", Subresource: "exec"}, "executes commands in pods"},
+	{RBACQuery{Verb: "create", Resource: "pods"}, "creates pods"},
+	{RBACQuery{Verb: "escalate", Group: rbacv1.GroupName, Resource: "roles"}, "escalates roles"},
+	{RBACQuery{Verb: "bind", Group: rbacv1.GroupName, Resource: "roles"}, "binds roles"},
+	{RBACQuery{Verb: "impersonate", Resource: "serviceaccounts"}, "impersonates service accounts"},
+}
+
+// NamespaceSecurity audits the security posture of a namespace: its Pod
+// Security Admission labels, whether NetworkPolicies isolate its pods by
+// default, the security settings of its pods, and the RoleBindings granting
+// broad or sensitive permissions in it.
+func (c *Client) NamespaceSecurity(ctx context.Context, namespace string) (*NamespaceSecurity, error) {
+	if namespace == "" {
+		return nil, fmt.Errorf("namespace cannot be empty")
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	ns, err := clientset.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get namespace: %w", err)
+	}
+	result := &NamespaceSecurity{
+		Namespace:       namespace,
+		NetworkPolicies: []string{},
+		Pods:            []PodSecurityFindings{},
+		RoleBindings:    []RoleBindingFindings{},
+	}
+	for key, value := range ns.Labels {
+		if name, ok := strings.CutPrefix(key, podSecurityLabelPrefix); ok {
+			if result.PodSecurity == nil {
+				result.PodSecurity = map[string]string{}
+			}
+			result.PodSecurity[name] = value
+		}
+	}
+
+	if err := result.addNetworkPolicies(ctx, clientset); err != nil {
+		return nil, err
+	}
+	if err := result.addPods(ctx, clientset); err != nil {
+		return nil, err
+	}
+	if result.RoleBindings, err = c.roleBindingFindings(ctx, namespace); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// addNetworkPolicies adds the NetworkPolicies of the namespace, and whether
+// they isolate all of its pods.
+func (s *NamespaceSecurity) addNetworkPolicies(ctx context.Context, clientset kubernetes.Interface) error {
+	policies, err := clientset.NetworkingV1().NetworkPolicies(s.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list network policies: %w", err)
+	}
+	for i := range policies.Items {
+		policy := &policies.Items[i]
+		s.NetworkPolicies = append(s.NetworkPolicies, policy.Name)
+		if len(policy.Spec.PodSelector.MatchLabels) == 0 && len(policy.Spec.PodSelector.MatchExpressions) == 0 {
+			s.DefaultDenyIngress = s.DefaultDenyIngress || policyHasType(policy, networkingv1.PolicyTypeIngress)
+			s.DefaultDenyEgress = s.DefaultDenyEgress || policyHasType(policy, networkingv1.PolicyTypeEgress)
+		}
+	}
+	sort.Strings(s.NetworkPolicies)
+	return nil
+}
+
+// addPods adds the pods of the namespace with findings.
+func (s *NamespaceSecurity) addPods(ctx context.Context, clientset kubernetes.Interface) error {
+	pods, err := clientset.CoreV1().Pods(s.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list pods: %w", err)
+	}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if isPodTerminated(pod) {
+			continue
+		}
+		s.TotalPods++
+		if findings := podSecurityFindings(pod); len(findings) > 0 {
+			s.Pods = append(s.Pods, PodSecurityFindings{
+				Name:           pod.Name,
+				ServiceAccount: pod.Spec.ServiceAccountName,
+				Findings:       findings,
+			})
+		}
+	}
+	sort.Slice(s.Pods, func(i, j int) bool { return s.Pods[i].Name < s.Pods[j].Name })
+	if len(s.Pods) > MaxSecurityPods {
+		s.Pods, s.Truncated = s.Pods[:MaxSecurityPods], true
+	}
+	return nil
+}
+
+// roleBindingFindings returns the RoleBindings of namespace granting broad or
+// sensitive permissions.
+func (c *Client) roleBindingFindings(ctx context.Context, namespace string) ([]RoleBindingFindings, error) {
+	snapshot, err := c.rbacSnapshot(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []RoleBindingFindings{}
+	for _, binding := range snapshot.roleBindings {
+		rules := snapshot.roleRules(binding.RoleRef, binding.Namespace)
+		var findings []string
+		if slices.ContainsFunc(rules, func(rule rbacv1.PolicyRule) bool {
+			return slices.Contains(rule.Verbs, rbacv1.VerbAll) && slices.Contains(rule.Resources, rbacv1.ResourceAll)
+		}) {
+			findings = append(findings, "grants all verbs on all resources")
+		}
+		for _, permission := range sensitivePermissions {
+			if slices.ContainsFunc(rules, func(rule rbacv1.PolicyRule) bool { return ruleAllows(rule, permission.query) }) {
+				findings = append(findings, permission.finding)
+			}
+		}
+		if len(findings) == 0 {
+			continue
+		}
+
+		var subjects []string
+		for _, subject := range binding.Subjects {
+			subjects = append(subjects, rbacSubject(subject, binding.Namespace).String())
+		}
+		result = append(result, RoleBindingFindings{
+			Name:     binding.Name,
+			Role:     binding.RoleRef.Kind + "/" + binding.RoleRef.Name,
+			Subjects: subjects,
+			Findings: findings,
+		})
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result, nil
+}
+
+// podSecurityFindings returns the weaknesses of the security settings of pod
+// and its containers.
+func podSecurityFindings(pod *corev1.Pod) []string {
+	var findings []string
+	if pod.Spec.HostNetwork {
+		findings = append(findings, "uses the host network")
+	}
+	if pod.Spec.HostPID {
+		findings = append(findings, "uses the host PID namespace")
+	}
+	if pod.Spec.HostIPC {
+		findings = append(findings, "uses the host IPC namespace")
+	}
+	for _, volume := range pod.Spec.Volumes {
+		if volume.HostPath != nil {
+			findings = append(findings, fmt.Sprintf("mounts host path %s as volume %s", volume.HostPath.Path, volume.Name))
+		}
+	}
+
+	podContext := pod.Spec.SecurityContext
+	if podContext == nil {
+		podContext = &corev1.PodSecurityContext{}
+	}
+	for _, container := range slices.Concat(pod.Spec.InitContainers, pod.Spec.Containers) {
+		for _, finding := range containerSecurityFindings(&container, podContext) {
+			findings = append(findings, "container "+container.Name+" "+finding)
+		}
+	}
+	return findings
+}
+
+// containerSecurityFindings returns the weaknesses of the security settings of
+// container, given those of its pod.
+func containerSecurityFindings(container *corev1.Container, podContext *corev1.PodSecurityContext) []string {
+	securityContext := container.SecurityContext
+	if securityContext == nil {
+		securityContext = &corev1.SecurityContext{}
+	}
+
+	var findings []string
+	if ptrTrue(securityContext.Privileged) {
+		findings = append(findings, "is privileged")
+	}
+	if securityContext.AllowPrivilegeEscalation == nil || *securityContext.AllowPrivilegeEscalation {
+		findings = append(findings, "allows privilege escalation")
+	}
+	if finding := rootFinding(securityContext, podContext); finding != "" {
+		findings = append(findings, finding)
+	}
+	if securityContext.Capabilities != nil && len(securityContext.Capabilities.Add) > 0 {
+		capabilities := make([]string, 0, len(securityContext.Capabilities.Add))
+		for _, capability := range securityContext.Capabilities.Add {
+			capabilities = append(capabilities, string(capability))
+		}
+		findings = append(findings, "adds capabilities "+strings.Join(capabilities, ", "))
+	}
+	if !ptrTrue(securityContext.ReadOnlyRootFilesystem) {
+		findings = append(findings, "has a writable root filesystem")
+	}
+	if securityContext.SeccompProfile == nil && podContext.SeccompProfile == nil {
+		findings = append(findings, "has no seccomp profile")
+	}
+	return findings
+}
+
+// rootFinding returns whether a container runs, or may run, as root given its
+// security context and that of its pod.
+func rootFinding(securityContext *corev1.SecurityContext, podContext *corev1.PodSecurityContext) string {
+	runAsUser := securityContext.RunAsUser
+	if runAsUser == nil {
+		runAsUser = podContext.RunAsUser
+	}
+	switch {
+	case runAsUser != nil && *runAsUser == 0:
+		return "runs as root"
+	case runAsUser == nil && !ptrTrue(securityContext.RunAsNonRoot) && !ptrTrue(podContext.RunAsNonRoot):
+		return "may run as root"
+	default:
+		return ""
+	}
+}
+
+// ptrTrue returns whether b is set and true.
+func ptrTrue(b *bool) bool {
+	return b != nil && *b
+}
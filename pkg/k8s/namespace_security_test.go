@@ -0,0 +1,124 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func TestNamespaceSecurity(t *testing.T) {
+	hardened := &corev1.SecurityContext{
+		AllowPrivilegeEscalation: ptr.To(false),
+		RunAsNonRoot:             ptr.To(true),
+		ReadOnlyRootFilesystem:   ptr.To(true),
+		SeccompProfile:           &corev1.SeccompProfile{Type: corev1.SeccompProfileTypeRuntimeDefault},
+	}
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{
+			"pod-security.kubernetes.io/enforce": "baseline",
+			"team":                               "shop",
+		}}},
+		&networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "default-deny", Namespace: "shop"},
+			Spec:       networkingv1.NetworkPolicySpec{PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeIngress}},
+		},
+		&networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "allow-web", Namespace: "shop"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "hardened", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app", SecurityContext: hardened}}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent", Namespace: "shop"},
+			Spec: corev1.PodSpec{
+				ServiceAccountName: "agent",
+				HostNetwork:        true,
+				SecurityContext:    &corev1.PodSecurityContext{RunAsUser: ptr.To(int64(0))},
+				Volumes: []corev1.Volume{{Name: "root", VolumeSource: corev1.VolumeSource{
+					HostPath: &corev1.HostPathVolumeSource{Path: "/"},
+				}}},
+				Containers: []corev1.Container{{Name: "agent", SecurityContext: &corev1.SecurityContext{
+					Privileged:   ptr.To(true),
+					Capabilities: &corev1.Capabilities{Add: []corev1.Capability{"NET_ADMIN", "SYS_TIME"}},
+				}}},
+			},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "job"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewer", Namespace: "shop"},
+			Rules:      []rbacv1.PolicyRule{{Verbs: []string{"get", "list"}, APIGroups: []string{""}, Resources: []string{"pods"}}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "ops-admin", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", Name: "admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "agent"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{Kind: "Role", Name: "viewer"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "developers"}},
+		},
+	))
+
+	result, err := client.NamespaceSecurity(context.Background(), "shop")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"enforce": "baseline"}, result.PodSecurity)
+	assert.Equal(t, []string{"allow-web", "default-deny"}, result.NetworkPolicies)
+	assert.True(t, result.DefaultDenyIngress)
+	assert.False(t, result.DefaultDenyEgress)
+
+	assert.Equal(t, 2, result.TotalPods)
+	require.Len(t, result.Pods, 1)
+	assert.Equal(t, "agent", result.Pods[0].Name)
+	assert.Equal(t, "agent", result.Pods[0].ServiceAccount)
+	assert.Equal(t, []string{
+		"uses the host network",
+		"mounts host path / as volume root",
+		"container agent is privileged",
+		"container agent allows privilege escalation",
+		"container agent runs as root",
+		"container agent adds capabilities NET_ADMIN, SYS_TIME",
+		"container agent has a writable root filesystem",
+		"container agent has no seccomp profile",
+	}, result.Pods[0].Findings)
+
+	require.Len(t, result.RoleBindings, 1)
+	assert.Equal(t, "ops-admin", result.RoleBindings[0].Name)
+	assert.Equal(t, "ClusterRole/admin", result.RoleBindings[0].Role)
+	assert.Equal(t, []string{"ServiceAccount/shop/agent"}, result.RoleBindings[0].Subjects)
+	assert.Contains(t, result.RoleBindings[0].Findings, "grants all verbs on all resources")
+	assert.Contains(t, result.RoleBindings[0].Findings, "reads secrets")
+
+	_, err = client.NamespaceSecurity(context.Background(), "missing")
+	assert.ErrorContains(t, err, "failed to get namespace")
+}
+
+func TestContainerSecurityFindingsMayRunAsRoot(t *testing.T) {
+	findings := containerSecurityFindings(&corev1.Container{Name: "app"}, &corev1.PodSecurityContext{})
+	assert.Contains(t, findings, "may run as root")
+
+	findings = containerSecurityFindings(&corev1.Container{Name: "app"}, &corev1.PodSecurityContext{RunAsNonRoot: ptr.To(true)})
+	assert.NotContains(t, findings, "may run as root")
+}
@@ -0,0 +1,400 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// NetworkEndpoint is the source or destination of the traffic checked by
+// CheckNetworkPolicies: a pod, or the pods of a namespace with labels.
+type NetworkEndpoint struct {
+	Namespace string `json:"namespace"`
+	// Pod is the name of the pod. Labels and IP are then those of the pod.
+	Pod    string            `json:"pod,omitempty"`
+	Labels map[string]string `json:"labels,omitempty"`
+	IP     string            `json:"ip,omitempty"`
+}
+
+// NetworkPolicyQuery is the traffic checked by CheckNetworkPolicies.
+type NetworkPolicyQuery struct {
+	Source NetworkEndpoint
+	// Destination must be a pod, whose container ports resolve the named
+	// ports of the policies.
+	Destination NetworkEndpoint
+	Port        int32
+	// Protocol is TCP, UDP or SCTP, TCP if empty.
+	Protocol string
+}
+
+// NetworkPolicyResult is the result of CheckNetworkPolicies.
+type NetworkPolicyResult struct {
+	Source      NetworkEndpoint `json:"source"`
+	Destination NetworkEndpoint `json:"destination"`
+	Port        int32           `json:"port"`
+	Protocol    string          `json:"protocol"`
+	// Allowed is set when both the egress of the source and the ingress of
+	// the destination allow the traffic.
+	Allowed bool            `json:"allowed"`
+	Egress  PolicyDirection `json:"egress"`
+	Ingress PolicyDirection `json:"ingress"`
+	Notes   []string        `json:"notes,omitempty"`
+}
+
+// PolicyDirection is the verdict of the policies on the egress of the source
+// or the ingress of the destination.
+type PolicyDirection struct {
+	Allowed bool `json:"allowed"`
+	// Isolated is set when policies select the pod for the direction, so
+	// that only the traffic they allow is.
+	Isolated bool   `json:"isolated"`
+	Reason   string `json:"reason"`
+	// Policies are the policies selecting the pod for the direction.
+	Policies []PolicyVerdict `json:"policies,omitempty"`
+}
+
+// PolicyVerdict is whether a NetworkPolicy allows the traffic.
+type PolicyVerdict struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Allows    bool   `json:"allows"`
+	// Rules are the indexes of the rules allowing the traffic.
+	Rules []int `json:"rules,omitempty"`
+}
+
+// CheckNetworkPolicies evaluates whether the NetworkPolicies allow traffic
+// from the source to the port of the destination pod, for the egress of the
+// source and the ingress of the destination, and which policies decided it.
+// The evaluation follows the NetworkPolicy semantics over the listed
+// NetworkPolicy, Pod and Namespace objects: no traffic is sent, and policies
+// of other APIs, such as those of a CNI plugin, are not considered.
+func (c *Client) CheckNetworkPolicies(ctx context.Context, query NetworkPolicyQuery) (*NetworkPolicyResult, error) {
+	protocol, err := query.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	destinationPod, err := clientset.CoreV1().Pods(query.Destination.Namespace).Get(ctx,
+		query.Destination.Pod, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get destination pod: %w", err)
+	}
+	source := query.Source
+	if source.Pod != "" {
+		sourcePod, err := clientset.CoreV1().Pods(source.Namespace).Get(ctx, source.Pod, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get source pod: %w", err)
+		}
+		source = podEndpoint(sourcePod)
+	}
+
+	namespaceList, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	e := &policyEvaluator{namespaces: map[string]labels.Set{}, port: query.Port, protocol: protocol}
+	for _, namespace := range namespaceList.Items {
+		e.namespaces[namespace.Name] = namespaceLabels(namespace.Name, namespace.Labels)
+	}
+
+	result := &NetworkPolicyResult{
+		Source:      source,
+		Destination: podEndpoint(destinationPod),
+		Port:        query.Port,
+		Protocol:    string(protocol),
+	}
+
+	egressPolicies, err := clientset.NetworkingV1().NetworkPolicies(source.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network policies of namespace %s: %w", source.Namespace, err)
+	}
+	ingressPolicies, err := clientset.NetworkingV1().NetworkPolicies(destinationPod.Namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list network policies of namespace %s: %w", destinationPod.Namespace, err)
+	}
+
+	e.destination = destinationPod
+	result.Egress = e.evaluate(egressPolicies.Items, networkingv1.PolicyTypeEgress, source, result.Destination)
+	result.Ingress = e.evaluate(ingressPolicies.Items, networkingv1.PolicyTypeIngress, result.Destination, source)
+	result.Allowed = result.Egress.Allowed && result.Ingress.Allowed
+	result.Notes = e.notes
+	if destinationPod.Spec.HostNetwork {
+		result.Notes = append(result.Notes,
+			"the destination pod uses the host network, which most network plugins do not isolate")
+	}
+	return result, nil
+}
+
+// validate checks query and returns its protocol.
+func (q NetworkPolicyQuery) validate() (corev1.Protocol, error) {
+	if q.Destination.Namespace == "" || q.Destination.Pod == "" {
+		return "", fmt.Errorf("destination namespace and pod cannot be empty")
+	}
+	if q.Source.Namespace == "" {
+		return "", fmt.Errorf("source namespace cannot be empty")
+	}
+	if q.Port <= 0 || q.Port > 65535 {
+		return "", fmt.Errorf("port must be between 1 and 65535")
+	}
+	switch protocol := corev1.Protocol(strings.ToUpper(q.Protocol)); protocol {
+	case "":
+		return corev1.ProtocolTCP, nil
+	case corev1.ProtocolTCP, corev1.ProtocolUDP, corev1.ProtocolSCTP:
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("unsupported protocol %q", q.Protocol)
+	}
+}
+
+// policyEvaluator evaluates NetworkPolicies for a port of a destination pod.
+type policyEvaluator struct {
+	// namespaces holds the labels of each namespace.
+	namespaces  map[string]labels.Set
+	destination *corev1.Pod
+	port        int32
+	protocol    corev1.Protocol
+	notes       []string
+}
+
+// note records a note of the result, once.
+func (e *policyEvaluator) note(format string, args ...interface{}) {
+	if note := fmt.Sprintf(format, args...); !slices.Contains(e.notes, note) {
+		e.notes = append(e.notes, note)
+	}
+}
+
+// evaluate returns the verdict of policies, of the namespace of selected, on
+// the traffic of policyType between selected and peer.
+func (e *policyEvaluator) evaluate(
+	policies []networkingv1.NetworkPolicy,
+	policyType networkingv1.PolicyType,
+	selected, peer NetworkEndpoint,
+) PolicyDirection {
+	direction := strings.ToLower(string(policyType))
+	sort.Slice(policies, func(i, j int) bool { return policies[i].Name < policies[j].Name })
+
+	verdict := PolicyDirection{}
+	var deciding []string
+	for i := range policies {
+		policy := &policies[i]
+		if !policyHasType(policy, policyType) || !selectorMatches(&policy.Spec.PodSelector, selected.Labels) {
+			continue
+		}
+		verdict.Isolated = true
+		policyVerdict := PolicyVerdict{Namespace: policy.Namespace, Name: policy.Name}
+		for j, rule := range policyRules(policy, policyType) {
+			if e.peersMatch(policy.Namespace, rule.peers, peer) && e.portsMatch(rule.ports) {
+				policyVerdict.Rules = append(policyVerdict.Rules, j)
+			}
+		}
+		policyVerdict.Allows = len(policyVerdict.Rules) > 0
+		if policyVerdict.Allows {
+			verdict.Allowed = true
+			deciding = append(deciding, fmt.Sprintf("%s (rules %v)", policy.Name, policyVerdict.Rules))
+		}
+		verdict.Policies = append(verdict.Policies, policyVerdict)
+	}
+
+	switch {
+	case !verdict.Isolated:
+		verdict.Allowed = true
+		verdict.Reason = fmt.Sprintf("no NetworkPolicy selects %s for %s, so all %s traffic is allowed",
+			endpointName(selected), direction, direction)
+	case verdict.Allowed:
+		verdict.Reason = fmt.Sprintf("allowed by %s", strings.Join(deciding, ", "))
+	default:
+		names := make([]string, 0, len(verdict.Policies))
+		for _, policy := range verdict.Policies {
+			names = append(names, policy.Name)
+		}
+		verdict.Reason = fmt.Sprintf("denied: %s select %s for %s and none of their rules allows %s on port %d/%s",
+			strings.Join(names, ", "), endpointName(selected), direction, endpointName(peer), e.port, e.protocol)
+	}
+	return verdict
+}
+
+// policyRule is an ingress or egress rule of a NetworkPolicy.
+type policyRule struct {
+	peers []networkingv1.NetworkPolicyPeer
+	ports []networkingv1.NetworkPolicyPort
+}
+
+// policyRules returns the rules of policy for policyType.
+func policyRules(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) []policyRule {
+	var rules []policyRule
+	if policyType == networkingv1.PolicyTypeIngress {
+		for _, rule := range policy.Spec.Ingress {
+			rules = append(rules, policyRule{peers: rule.From, ports: rule.Ports})
+		}
+		return rules
+	}
+	for _, rule := range policy.Spec.Egress {
+		rules = append(rules, policyRule{peers: rule.To, ports: rule.Ports})
+	}
+	return rules
+}
+
+// policyHasType returns whether policy applies to policyType. Policies
+// without policy types apply to ingress, and to egress if they have egress
+// rules.
+func policyHasType(policy *networkingv1.NetworkPolicy, policyType networkingv1.PolicyType) bool {
+	if len(policy.Spec.PolicyTypes) == 0 {
+		return policyType == networkingv1.PolicyTypeIngress || len(policy.Spec.Egress) > 0
+	}
+	return slices.Contains(policy.Spec.PolicyTypes, policyType)
+}
+
+// peersMatch returns whether peer matches any of peers of a rule of a policy
+// of policyNamespace. A rule without peers matches all peers.
+func (e *policyEvaluator) peersMatch(policyNamespace string, peers []networkingv1.NetworkPolicyPeer, peer NetworkEndpoint) bool {
+	if len(peers) == 0 {
+		return true
+	}
+	for _, p := range peers {
+		if p.IPBlock != nil {
+			if e.ipBlockMatches(p.IPBlock, peer) {
+				return true
+			}
+			continue
+		}
+		if p.NamespaceSelector == nil {
+			// Pods of the namespace of the policy
+			if peer.Namespace == policyNamespace && selectorMatches(p.PodSelector, peer.Labels) {
+				return true
+			}
+			continue
+		}
+		if selectorMatches(p.NamespaceSelector, e.namespaces[peer.Namespace]) &&
+			(p.PodSelector == nil || selectorMatches(p.PodSelector, peer.Labels)) {
+			return true
+		}
+	}
+	return false
+}
+
+// ipBlockMatches returns whether the IP of peer is in block.
+func (e *policyEvaluator) ipBlockMatches(block *networkingv1.IPBlock, peer NetworkEndpoint) bool {
+	ip := net.ParseIP(peer.IP)
+	if ip == nil {
+		e.note("ipBlock peers were not evaluated for %s, whose IP is unknown", endpointName(peer))
+		return false
+	}
+	if _, cidr, err := net.ParseCIDR(block.CIDR); err != nil || !cidr.Contains(ip) {
+		return false
+	}
+	for _, except := range block.Except {
+		if _, cidr, err := net.ParseCIDR(except); err == nil && cidr.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
+
+// portsMatch returns whether the port of the destination matches any of
+// ports of a rule. A rule without ports matches all ports.
+func (e *policyEvaluator) portsMatch(ports []networkingv1.NetworkPolicyPort) bool {
+	if len(ports) == 0 {
+		return true
+	}
+	for _, port := range ports {
+		protocol := corev1.ProtocolTCP
+		if port.Protocol != nil {
+			protocol = *port.Protocol
+		}
+		if protocol != e.protocol {
+			continue
+		}
+		switch {
+		case port.Port == nil:
+			return true
+		case port.Port.Type == intstr.String:
+			if e.namedPort(port.Port.StrVal) == e.port {
+				return true
+			}
+		case port.EndPort != nil:
+			if e.port >= port.Port.IntVal && e.port <= *port.EndPort {
+				return true
+			}
+		case port.Port.IntVal == e.port:
+			return true
+		}
+	}
+	return false
+}
+
+// namedPort returns the number of the named port of the destination pod for
+// the protocol, or 0 if it does not declare it.
+func (e *policyEvaluator) namedPort(name string) int32 {
+	for _, container := range e.destination.Spec.Containers {
+		for _, port := range container.Ports {
+			protocol := port.Protocol
+			if protocol == "" {
+				protocol = corev1.ProtocolTCP
+			}
+			if port.Name == name && protocol == e.protocol {
+				return port.ContainerPort
+			}
+		}
+	}
+	return 0
+}
+
+// selectorMatches returns whether selector matches set. A nil or empty
+// selector matches everything.
+func selectorMatches(selector *metav1.LabelSelector, set labels.Set) bool {
+	if selector == nil {
+		return true
+	}
+	s, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return false
+	}
+	return s.Matches(set)
+}
+
+// namespaceLabels returns the labels of a namespace, including the
+// kubernetes.io/metadata.name label the API server sets.
+func namespaceLabels(name string, namespaceLabels map[string]string) labels.Set {
+	set := labels.Set{corev1.LabelMetadataName: name}
+	for key, value := range namespaceLabels {
+		set[key] = value
+	}
+	return set
+}
+
+// podEndpoint returns the NetworkEndpoint of pod.
+func podEndpoint(pod *corev1.Pod) NetworkEndpoint {
+	return NetworkEndpoint{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		Labels:    pod.Labels,
+		IP:        pod.Status.PodIP,
+	}
+}
+
+// endpointName returns a description of endpoint for messages.
+func endpointName(endpoint NetworkEndpoint) string {
+	if endpoint.Pod != "" {
+		return "pod " + endpoint.Namespace + "/" + endpoint.Pod
+	}
+	if len(endpoint.Labels) == 0 {
+		return "a pod without labels of namespace " + endpoint.Namespace
+	}
+	return fmt.Sprintf("a pod with labels %s of namespace %s", labels.Set(endpoint.Labels), endpoint.Namespace)
+}
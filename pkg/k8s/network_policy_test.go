@@ -0,0 +1,238 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func networkPolicyTestPod(namespace, name, ip string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "app",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{PodIP: ip},
+	}
+}
+
+func newNetworkPolicyTestClient(policies ...runtime.Object) *Client {
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop", Labels: map[string]string{"team": "shop"}}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+		networkPolicyTestPod("shop", "web", "10.0.1.1", map[string]string{"app": "web"}),
+		networkPolicyTestPod("shop", "db", "10.0.1.2", map[string]string{"app": "db"}),
+		networkPolicyTestPod("monitoring", "prometheus", "10.0.2.1", map[string]string{"app": "prometheus"}),
+	}
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(append(objects, policies...)...))
+	return client
+}
+
+func networkPolicy(namespace, name string, spec networkingv1.NetworkPolicySpec) *networkingv1.NetworkPolicy {
+	return &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}, Spec: spec}
+}
+
+// dbIngress allows the web pods to reach the db pods on port 8080, denying
+// all other ingress to them.
+var dbIngress = networkPolicy("shop", "db-ingress", networkingv1.NetworkPolicySpec{
+	PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+	Ingress: []networkingv1.NetworkPolicyIngressRule{{
+		From: []networkingv1.NetworkPolicyPeer{{
+			PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+		}},
+		Ports: []networkingv1.NetworkPolicyPort{{Port: ptr.To(intstr.FromString("http"))}},
+	}},
+})
+
+func TestCheckNetworkPoliciesWithoutPolicies(t *testing.T) {
+	client := newNetworkPolicyTestClient()
+
+	result, err := client.CheckNetworkPolicies(context.Background(), NetworkPolicyQuery{
+		Source:      NetworkEndpoint{Namespace: "shop", Pod: "web"},
+		Destination: NetworkEndpoint{Namespace: "shop", Pod: "db"},
+		Port:        8080,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, "TCP", result.Protocol)
+	assert.Equal(t, "10.0.1.1", result.Source.IP)
+	assert.Equal(t, map[string]string{"app": "db"}, result.Destination.Labels)
+	assert.False(t, result.Ingress.Isolated)
+	assert.Contains(t, result.Ingress.Reason, "no NetworkPolicy selects pod shop/db for ingress")
+	assert.Contains(t, result.Egress.Reason, "no NetworkPolicy selects pod shop/web for egress")
+}
+
+func TestCheckNetworkPoliciesIngress(t *testing.T) {
+	client := newNetworkPolicyTestClient(dbIngress,
+		networkPolicy("shop", "allow-monitoring", networkingv1.NetworkPolicySpec{
+			Ingress: []networkingv1.NetworkPolicyIngressRule{{
+				From: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{
+						"kubernetes.io/metadata.name": "monitoring",
+					}},
+				}},
+				Ports: []networkingv1.NetworkPolicyPort{{
+					Port: ptr.To(intstr.FromInt32(9000)), EndPort: ptr.To[int32](9100),
+				}},
+			}},
+		}),
+	)
+
+	tests := []struct {
+		name     string
+		source   NetworkEndpoint
+		port     int32
+		protocol string
+		allowed  bool
+		reason   string
+	}{
+		{
+			name:    "named port allowed",
+			source:  NetworkEndpoint{Namespace: "shop", Pod: "web"},
+			port:    8080,
+			allowed: true,
+			reason:  "allowed by db-ingress (rules [0])",
+		},
+		{
+			name:     "other protocol",
+			source:   NetworkEndpoint{Namespace: "shop", Pod: "web"},
+			port:     8080,
+			protocol: "udp",
+			reason:   "denied: allow-monitoring, db-ingress select pod shop/db for ingress",
+		},
+		{
+			name:   "other pod",
+			source: NetworkEndpoint{Namespace: "shop", Labels: map[string]string{"app": "cart"}},
+			port:   8080,
+			reason: "none of their rules allows a pod with labels app=cart of namespace shop on port 8080/TCP",
+		},
+		{
+			name:    "port range of another namespace",
+			source:  NetworkEndpoint{Namespace: "monitoring", Pod: "prometheus"},
+			port:    9090,
+			allowed: true,
+			reason:  "allowed by allow-monitoring (rules [0])",
+		},
+		{
+			name:   "pod selector of the policy namespace only",
+			source: NetworkEndpoint{Namespace: "monitoring", Labels: map[string]string{"app": "web"}},
+			port:   8080,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := client.CheckNetworkPolicies(context.Background(), NetworkPolicyQuery{
+				Source:      tt.source,
+				Destination: NetworkEndpoint{Namespace: "shop", Pod: "db"},
+				Port:        tt.port,
+				Protocol:    tt.protocol,
+			})
+			require.NoError(t, err)
+			assert.True(t, result.Egress.Allowed)
+			assert.True(t, result.Ingress.Isolated)
+			assert.Equal(t, tt.allowed, result.Allowed)
+			assert.Equal(t, tt.allowed, result.Ingress.Allowed)
+			assert.Contains(t, result.Ingress.Reason, tt.reason)
+			assert.Len(t, result.Ingress.Policies, 2)
+		})
+	}
+}
+
+func TestCheckNetworkPoliciesEgress(t *testing.T) {
+	client := newNetworkPolicyTestClient(
+		// web may only reach the pods of namespaces of team shop, and 10.0.0.0/16
+		// except 10.0.2.0/24
+		networkPolicy("shop", "web-egress", networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			PolicyTypes: []networkingv1.PolicyType{networkingv1.PolicyTypeEgress},
+			Egress: []networkingv1.NetworkPolicyEgressRule{
+				{To: []networkingv1.NetworkPolicyPeer{{
+					NamespaceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "shop"}},
+					PodSelector:       &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				}}},
+				{To: []networkingv1.NetworkPolicyPeer{{
+					IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/16", Except: []string{"10.0.2.0/24"}},
+				}}},
+			},
+		}),
+	)
+	check := func(destination NetworkEndpoint) *NetworkPolicyResult {
+		result, err := client.CheckNetworkPolicies(context.Background(), NetworkPolicyQuery{
+			Source:      NetworkEndpoint{Namespace: "shop", Pod: "web"},
+			Destination: destination,
+			Port:        8080,
+		})
+		require.NoError(t, err)
+		assert.True(t, result.Ingress.Allowed)
+		return result
+	}
+
+	result := check(NetworkEndpoint{Namespace: "shop", Pod: "db"})
+	assert.True(t, result.Allowed)
+	assert.Equal(t, []PolicyVerdict{{Namespace: "shop", Name: "web-egress", Allows: true, Rules: []int{0, 1}}},
+		result.Egress.Policies)
+
+	result = check(NetworkEndpoint{Namespace: "monitoring", Pod: "prometheus"})
+	assert.False(t, result.Allowed)
+	assert.Contains(t, result.Egress.Reason, "denied: web-egress select pod shop/web for egress")
+}
+
+func TestCheckNetworkPoliciesIPBlockWithoutIP(t *testing.T) {
+	client := newNetworkPolicyTestClient(networkPolicy("shop", "db-cidr", networkingv1.NetworkPolicySpec{
+		PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+		Ingress: []networkingv1.NetworkPolicyIngressRule{{
+			From: []networkingv1.NetworkPolicyPeer{{IPBlock: &networkingv1.IPBlock{CIDR: "10.0.0.0/8"}}},
+		}},
+	}))
+
+	result, err := client.CheckNetworkPolicies(context.Background(), NetworkPolicyQuery{
+		Source:      NetworkEndpoint{Namespace: "shop", Labels: map[string]string{"app": "web"}},
+		Destination: NetworkEndpoint{Namespace: "shop", Pod: "db"},
+		Port:        5432,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	require.Len(t, result.Notes, 1)
+	assert.Contains(t, result.Notes[0], "ipBlock peers were not evaluated")
+
+	result, err = client.CheckNetworkPolicies(context.Background(), NetworkPolicyQuery{
+		Source:      NetworkEndpoint{Namespace: "shop", Labels: map[string]string{"app": "web"}, IP: "10.1.2.3"},
+		Destination: NetworkEndpoint{Namespace: "shop", Pod: "db"},
+		Port:        5432,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Empty(t, result.Notes)
+}
+
+func TestCheckNetworkPoliciesInvalid(t *testing.T) {
+	client := newNetworkPolicyTestClient()
+	source := NetworkEndpoint{Namespace: "shop", Pod: "web"}
+	destination := NetworkEndpoint{Namespace: "shop", Pod: "db"}
+
+	for _, tt := range []struct {
+		query NetworkPolicyQuery
+		err   string
+	}{
+		{NetworkPolicyQuery{Source: source, Port: 80}, "destination namespace and pod cannot be empty"},
+		{NetworkPolicyQuery{Destination: destination, Port: 80}, "source namespace cannot be empty"},
+		{NetworkPolicyQuery{Source: source, Destination: destination}, "port must be between"},
+		{NetworkPolicyQuery{Source: source, Destination: destination, Port: 80, Protocol: "ICMP"}, "unsupported protocol"},
+		{NetworkPolicyQuery{Source: NetworkEndpoint{Namespace: "shop", Pod: "missing"}, Destination: destination, Port: 80},
+			"failed to get source pod"},
+	} {
+		_, err := client.CheckNetworkPolicies(context.Background(), tt.query)
+		assert.ErrorContains(t, err, tt.err)
+	}
+}
@@ -0,0 +1,344 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// MaxDrainTimeout is the maximum time a drain may take.
+	MaxDrainTimeout = 10 * time.Minute
+	// defaultDrainTimeout is used when draining without a timeout.
+	defaultDrainTimeout = 2 * time.Minute
+	// mirrorPodAnnotation marks the API server copies of static pods.
+	mirrorPodAnnotation = "kubernetes.io/config.mirror"
+)
+
+// drainPollInterval is how often a blocked eviction is retried, and how often
+// evicted pods are checked for deletion. It is a variable so that tests can
+// shorten it.
+var drainPollInterval = 2 * time.Second
+
+// CordonResult is the result of a CordonNode call.
+type CordonResult struct {
+	Name          string `json:"name"`
+	Unschedulable bool   `json:"unschedulable"`
+	Changed       bool   `json:"changed"`
+}
+
+// DrainOptions configures a DrainNode call.
+type DrainOptions struct {
+	// GracePeriodSeconds overrides the termination grace period of evicted
+	// pods. Nil uses each pod's own grace period.
+	GracePeriodSeconds *int64
+	// Timeout bounds the whole drain. Defaults to 2 minutes and is capped at
+	// MaxDrainTimeout.
+	Timeout time.Duration
+	// Force evicts pods that are not managed by a controller, which will not
+	// be recreated elsewhere.
+	Force bool
+	// DeleteEmptyDirData evicts pods using emptyDir volumes, whose data is
+	// lost.
+	DeleteEmptyDirData bool
+	// DryRun only reports which pods would be evicted, without cordoning the
+	// node or evicting anything.
+	DryRun bool
+	// OnProgress, if set, is called with a short message whenever a pod is
+	// evicted, blocked or fails.
+	OnProgress func(message string)
+}
+
+// DrainPod is a pod handled by a DrainNode call.
+type DrainPod struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// DrainResult is the result of a DrainNode call.
+type DrainResult struct {
+	Node       string     `json:"node"`
+	Cordoned   bool       `json:"cordoned"`
+	DryRun     bool       `json:"dryRun,omitempty"`
+	Evicted    []DrainPod `json:"evicted"`
+	Skipped    []DrainPod `json:"skipped,omitempty"`
+	Failed     []DrainPod `json:"failed,omitempty"`
+	Completed  bool       `json:"completed"`
+	Message    string     `json:"message"`
+	DurationMs int64      `json:"durationMs"`
+}
+
+// CordonNode marks a node as unschedulable (cordon) or schedulable
+// (uncordon), like `kubectl cordon` and `kubectl uncordon`.
+func (c *Client) CordonNode(ctx context.Context, name string, unschedulable bool) (*CordonResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("node name cannot be empty")
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	node, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get node: %w", err)
+	}
+
+	result := &CordonResult{Name: name, Unschedulable: unschedulable}
+	if node.Spec.Unschedulable == unschedulable {
+		return result, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		fieldSpec: map[string]interface{}{"unschedulable": unschedulable},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build cordon patch: %w", err)
+	}
+	_, err = clientset.CoreV1().Nodes().Patch(ctx, name, k8stypes.StrategicMergePatchType, patch, metav1.PatchOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to patch node: %w", err)
+	}
+	result.Changed = true
+	return result, nil
+}
+
+// DrainNode cordons a node and evicts its pods through the eviction API, like
+// `kubectl drain`. DaemonSet and mirror pods are skipped, and pods that are
+// not managed by a controller or use emptyDir volumes are only evicted when
+// opts allows it. Evictions refused because of a PodDisruptionBudget are
+// retried until the timeout. Pods that could not be evicted are reported in
+// the result rather than as an error.
+func (c *Client) DrainNode(ctx context.Context, name string, opts DrainOptions) (*DrainResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("node name cannot be empty")
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultDrainTimeout
+	}
+	if timeout > MaxDrainTimeout {
+		timeout = MaxDrainTimeout
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	start := time.Now()
+	result := &DrainResult{Node: name, DryRun: opts.DryRun, Evicted: []DrainPod{}}
+
+	if opts.DryRun {
+		if _, err := clientset.CoreV1().Nodes().Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to get node: %w", err)
+		}
+	} else {
+		if _, err := c.CordonNode(ctx, name, true); err != nil {
+			return nil, err
+		}
+		result.Cordoned = true
+	}
+
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("spec.nodeName", name).String(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods on node: %w", err)
+	}
+
+	toEvict := classifyDrainPods(pods.Items, name, opts, result)
+
+	if opts.DryRun {
+		for _, pod := range toEvict {
+			result.Evicted = append(result.Evicted, DrainPod{Namespace: pod.Namespace, Name: pod.Name})
+		}
+		result.Completed = len(result.Failed) == 0
+		result.Message = fmt.Sprintf("dry run: %d pod(s) would be evicted, %d skipped, %d refused",
+			len(result.Evicted), len(result.Skipped), len(result.Failed))
+		result.DurationMs = time.Since(start).Milliseconds()
+		return result, nil
+	}
+
+	drainCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	reasons := evictPods(drainCtx, clientset, toEvict, opts)
+
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	for i, pod := range toEvict {
+		drainPod := DrainPod{Namespace: pod.Namespace, Name: pod.Name, Reason: reasons[i]}
+		if reasons[i] == "" {
+			result.Evicted = append(result.Evicted, drainPod)
+		} else {
+			result.Failed = append(result.Failed, drainPod)
+		}
+	}
+
+	result.Completed = len(result.Failed) == 0
+	if result.Completed {
+		result.Message = fmt.Sprintf("node %q drained: %d pod(s) evicted, %d skipped",
+			name, len(result.Evicted), len(result.Skipped))
+	} else {
+		result.Message = fmt.Sprintf("node %q cordoned but not fully drained: %d pod(s) evicted, %d skipped, %d failed",
+			name, len(result.Evicted), len(result.Skipped), len(result.Failed))
+	}
+	result.DurationMs = time.Since(start).Milliseconds()
+	return result, nil
+}
+
+// classifyDrainPods sorts the pods of node name according to drainFilter,
+// recording skipped and refused pods in result, and returns the pods to evict.
+func classifyDrainPods(pods []corev1.Pod, name string, opts DrainOptions, result *DrainResult) []corev1.Pod {
+	var toEvict []corev1.Pod
+	for _, pod := range pods {
+		// Field selectors are not honoured by every client; filter again.
+		if pod.Spec.NodeName != name {
+			continue
+		}
+		drainPod := DrainPod{Namespace: pod.Namespace, Name: pod.Name}
+		skip, refuse := drainFilter(&pod, opts)
+		switch {
+		case skip != "":
+			drainPod.Reason = skip
+			result.Skipped = append(result.Skipped, drainPod)
+		case refuse != "":
+			drainPod.Reason = refuse
+			result.Failed = append(result.Failed, drainPod)
+		default:
+			toEvict = append(toEvict, pod)
+		}
+	}
+	return toEvict
+}
+
+// evictPods evicts all pods concurrently, as kubectl does, so that a pod
+// blocked by a PodDisruptionBudget does not hold up the others. It returns
+// the reason each pod could not be evicted, empty for evicted pods.
+func evictPods(ctx context.Context, clientset kubernetes.Interface, pods []corev1.Pod, opts DrainOptions) []string {
+	var mu sync.Mutex
+	progress := func(message string) {
+		if opts.OnProgress == nil {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		opts.OnProgress(message)
+	}
+	reasons := make([]string, len(pods))
+	var wg sync.WaitGroup
+	for i := range pods {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			reasons[i] = evictAndWait(ctx, clientset, &pods[i], opts.GracePeriodSeconds, progress)
+		}(i)
+	}
+	wg.Wait()
+	return reasons
+}
+
+// drainFilter decides how a drain handles pod. It returns a non-empty skip
+// reason for pods that are left alone, or a non-empty refuse reason for pods
+// that block the drain unless opts allows evicting them.
+func drainFilter(pod *corev1.Pod, opts DrainOptions) (skip, refuse string) {
+	if _, ok := pod.Annotations[mirrorPodAnnotation]; ok {
+		return "mirror pod (static pods cannot be evicted)", ""
+	}
+	if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+		// Finished pods are evicted without further checks, like kubectl.
+		return "", ""
+	}
+	controller := metav1.GetControllerOf(pod)
+	if controller != nil && controller.Kind == "DaemonSet" {
+		return "managed by DaemonSet " + controller.Name, ""
+	}
+	if controller == nil && !opts.Force {
+		return "", "not managed by a controller (set force to evict it)"
+	}
+	if !opts.DeleteEmptyDirData {
+		for _, volume := range pod.Spec.Volumes {
+			if volume.EmptyDir != nil {
+				return "", fmt.Sprintf("uses emptyDir volume %q (set delete_emptydir_data to evict it)", volume.Name)
+			}
+		}
+	}
+	return "", ""
+}
+
+// evictAndWait evicts pod, retrying while a PodDisruptionBudget refuses the
+// eviction, then waits until the pod is gone. It returns an empty string on
+// success, or the reason the pod could not be evicted.
+func evictAndWait(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	pod *corev1.Pod,
+	gracePeriodSeconds *int64,
+	progress func(string),
+) string {
+	podName := pod.Namespace + "/" + pod.Name
+	eviction := &policyv1.Eviction{
+		ObjectMeta: metav1.ObjectMeta{Name: pod.Name, Namespace: pod.Namespace},
+		DeleteOptions: &metav1.DeleteOptions{
+			GracePeriodSeconds: gracePeriodSeconds,
+			Preconditions:      &metav1.Preconditions{UID: &pod.UID},
+		},
+	}
+
+	var lastErr error
+	err := wait.PollUntilContextCancel(ctx, drainPollInterval, true, func(ctx context.Context) (bool, error) {
+		lastErr = clientset.PolicyV1().Evictions(pod.Namespace).Evict(ctx, eviction)
+		switch {
+		case lastErr == nil, apierrors.IsNotFound(lastErr):
+			return true, nil
+		case apierrors.IsTooManyRequests(lastErr):
+			// A PodDisruptionBudget does not allow the eviction yet.
+			progress(fmt.Sprintf("eviction of %s blocked: %v", podName, lastErr))
+			return false, nil
+		default:
+			return false, lastErr
+		}
+	})
+	if err != nil {
+		if lastErr != nil && apierrors.IsTooManyRequests(lastErr) {
+			return fmt.Sprintf("eviction blocked until timeout: %v", lastErr)
+		}
+		if lastErr != nil {
+			return fmt.Sprintf("eviction failed: %v", lastErr)
+		}
+		return fmt.Sprintf("eviction failed: %v", err)
+	}
+
+	err = wait.PollUntilContextCancel(ctx, drainPollInterval, true, func(ctx context.Context) (bool, error) {
+		current, err := clientset.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		// A pod with the same name but a different UID is a replacement.
+		return current.UID != pod.UID, nil
+	})
+	if err != nil {
+		return fmt.Sprintf("evicted but not deleted before timeout: %v", err)
+	}
+
+	progress(fmt.Sprintf("evicted %s", podName))
+	return ""
+}
@@ -0,0 +1,208 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	policyv1 "k8s.io/api/policy/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+)
+
+func drainTestPod(name, node string, controllerKind string, mutate ...func(*corev1.Pod)) *corev1.Pod {
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			UID:       k8stypes.UID("uid-" + name),
+		},
+		Spec:   corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+	if controllerKind != "" {
+		isController := true
+		pod.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "apps/v1",
+			Kind:       controllerKind,
+			Name:       "owner",
+			Controller: &isController,
+		}}
+	}
+	for _, m := range mutate {
+		m(pod)
+	}
+	return pod
+}
+
+// newDrainTestClient returns a client whose evictions delete the pod, except
+// for pods listed in blocked, which are refused as if by a
+// PodDisruptionBudget the given number of times (-1 for always).
+func newDrainTestClient(t *testing.T, blocked map[string]int, objects ...runtime.Object) (*Client, *kubefake.Clientset) {
+	t.Helper()
+	oldInterval := drainPollInterval
+	drainPollInterval = 10 * time.Millisecond
+	t.Cleanup(func() { drainPollInterval = oldInterval })
+
+	clientset := kubefake.NewSimpleClientset(objects...)
+	clientset.PrependReactor("create", "pods", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "eviction" {
+			return false, nil, nil
+		}
+		eviction := action.(ktesting.CreateAction).GetObject().(*policyv1.Eviction)
+		if remaining, ok := blocked[eviction.Name]; ok && remaining != 0 {
+			blocked[eviction.Name] = remaining - 1
+			return true, nil, apierrors.NewTooManyRequests("Cannot evict pod as it would violate the pod's disruption budget.", 0)
+		}
+		gvr := corev1.SchemeGroupVersion.WithResource("pods")
+		return true, nil, clientset.Tracker().Delete(gvr, eviction.Namespace, eviction.Name)
+	})
+
+	client := &Client{}
+	client.SetClientset(clientset)
+	return client, clientset
+}
+
+func TestCordonNode(t *testing.T) {
+	client, clientset := newDrainTestClient(t, nil, &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+
+	result, err := client.CordonNode(context.Background(), "node-1", true)
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.True(t, result.Unschedulable)
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+
+	result, err = client.CordonNode(context.Background(), "node-1", true)
+	require.NoError(t, err)
+	assert.False(t, result.Changed)
+
+	result, err = client.CordonNode(context.Background(), "node-1", false)
+	require.NoError(t, err)
+	assert.True(t, result.Changed)
+	assert.False(t, result.Unschedulable)
+
+	_, err = client.CordonNode(context.Background(), "missing", true)
+	require.Error(t, err)
+}
+
+func TestDrainNode(t *testing.T) {
+	blocked := map[string]int{"guarded": 2}
+	client, clientset := newDrainTestClient(t, blocked,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		drainTestPod("web", "node-1", "ReplicaSet"),
+		drainTestPod("guarded", "node-1", "ReplicaSet"),
+		drainTestPod("logs", "node-1", "DaemonSet"),
+		drainTestPod("static", "node-1", "", func(p *corev1.Pod) {
+			p.Annotations = map[string]string{mirrorPodAnnotation: "hash"}
+		}),
+		drainTestPod("bare", "node-1", ""),
+		drainTestPod("cache", "node-1", "ReplicaSet", func(p *corev1.Pod) {
+			p.Spec.Volumes = []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		}),
+		drainTestPod("elsewhere", "node-2", "ReplicaSet"),
+	)
+
+	var progress []string
+	result, err := client.DrainNode(context.Background(), "node-1", DrainOptions{
+		Timeout:    5 * time.Second,
+		OnProgress: func(message string) { progress = append(progress, message) },
+	})
+	require.NoError(t, err)
+
+	assert.True(t, result.Cordoned)
+	assert.False(t, result.Completed)
+	assert.ElementsMatch(t, []DrainPod{
+		{Namespace: "default", Name: "web"},
+		{Namespace: "default", Name: "guarded"},
+	}, result.Evicted)
+	assert.Len(t, result.Skipped, 2)
+	require.Len(t, result.Failed, 2)
+	for _, failed := range result.Failed {
+		switch failed.Name {
+		case "bare":
+			assert.Contains(t, failed.Reason, "not managed by a controller")
+		case "cache":
+			assert.Contains(t, failed.Reason, "emptyDir")
+		default:
+			t.Errorf("unexpected failed pod %s", failed.Name)
+		}
+	}
+	assert.Equal(t, 0, blocked["guarded"])
+	assert.Contains(t, progress, "evicted default/guarded")
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, node.Spec.Unschedulable)
+
+	_, err = clientset.CoreV1().Pods("default").Get(context.Background(), "elsewhere", metav1.GetOptions{})
+	assert.NoError(t, err)
+	_, err = clientset.CoreV1().Pods("default").Get(context.Background(), "logs", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
+
+func TestDrainNodeForce(t *testing.T) {
+	client, _ := newDrainTestClient(t, nil,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		drainTestPod("bare", "node-1", ""),
+		drainTestPod("cache", "node-1", "ReplicaSet", func(p *corev1.Pod) {
+			p.Spec.Volumes = []corev1.Volume{{Name: "tmp", VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}}}}
+		}),
+	)
+
+	result, err := client.DrainNode(context.Background(), "node-1", DrainOptions{
+		Timeout:            5 * time.Second,
+		Force:              true,
+		DeleteEmptyDirData: true,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Completed)
+	assert.Len(t, result.Evicted, 2)
+	assert.Empty(t, result.Failed)
+	assert.Contains(t, result.Message, "drained")
+}
+
+func TestDrainNodeBlockedByDisruptionBudget(t *testing.T) {
+	client, _ := newDrainTestClient(t, map[string]int{"guarded": -1},
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		drainTestPod("guarded", "node-1", "ReplicaSet"),
+	)
+
+	result, err := client.DrainNode(context.Background(), "node-1", DrainOptions{Timeout: 200 * time.Millisecond})
+	require.NoError(t, err)
+	assert.False(t, result.Completed)
+	assert.Empty(t, result.Evicted)
+	require.Len(t, result.Failed, 1)
+	assert.Contains(t, result.Failed[0].Reason, "blocked until timeout")
+	assert.Contains(t, result.Failed[0].Reason, "disruption budget")
+}
+
+func TestDrainNodeDryRun(t *testing.T) {
+	client, clientset := newDrainTestClient(t, nil,
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		drainTestPod("web", "node-1", "ReplicaSet"),
+		drainTestPod("logs", "node-1", "DaemonSet"),
+	)
+
+	result, err := client.DrainNode(context.Background(), "node-1", DrainOptions{DryRun: true})
+	require.NoError(t, err)
+	assert.True(t, result.DryRun)
+	assert.False(t, result.Cordoned)
+	assert.Equal(t, []DrainPod{{Namespace: "default", Name: "web"}}, result.Evicted)
+	assert.Len(t, result.Skipped, 1)
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, node.Spec.Unschedulable)
+	_, err = clientset.CoreV1().Pods("default").Get(context.Background(), "web", metav1.GetOptions{})
+	assert.NoError(t, err)
+}
@@ -0,0 +1,207 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// MaxObjectEvents is the largest number of events returned by
+	// ObjectEvents.
+	MaxObjectEvents = 20
+	// DefaultLogTailLines is the default number of lines returned by
+	// PodLogTail.
+	DefaultLogTailLines = 50
+)
+
+// PodStatus is a condensed status of a pod, for troubleshooting.
+type PodStatus struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	Node      string `json:"node,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+	Message   string `json:"message,omitempty"`
+	// Conditions are the conditions of the pod that are not true.
+	Conditions     []string          `json:"conditions,omitempty"`
+	InitContainers []ContainerStatus `json:"initContainers,omitempty"`
+	Containers     []ContainerStatus `json:"containers"`
+}
+
+// ContainerStatus is a condensed status of a container of a pod.
+type ContainerStatus struct {
+	Name     string `json:"name"`
+	Image    string `json:"image"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	// State is the current state of the container, e.g. "waiting:
+	// CrashLoopBackOff".
+	State string `json:"state"`
+	// LastTermination is how the previous instance of the container
+	// terminated, if it restarted.
+	LastTermination string `json:"lastTermination,omitempty"`
+}
+
+// PodStatus returns the condensed status of a pod.
+func (c *Client) PodStatus(ctx context.Context, namespace, name string) (*PodStatus, error) {
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("namespace and name cannot be empty")
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+
+	status := &PodStatus{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		Phase:      string(pod.Status.Phase),
+		Node:       pod.Spec.NodeName,
+		Reason:     pod.Status.Reason,
+		Message:    pod.Status.Message,
+		Containers: []ContainerStatus{},
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Status != corev1.ConditionTrue {
+			status.Conditions = append(status.Conditions,
+				joinNonEmpty(string(condition.Type)+"="+string(condition.Status), condition.Reason, condition.Message))
+		}
+	}
+	for _, container := range pod.Status.InitContainerStatuses {
+		status.InitContainers = append(status.InitContainers, containerStatus(&container))
+	}
+	for _, container := range pod.Status.ContainerStatuses {
+		status.Containers = append(status.Containers, containerStatus(&container))
+	}
+	return status, nil
+}
+
+// containerStatus returns the condensed status of a container.
+func containerStatus(status *corev1.ContainerStatus) ContainerStatus {
+	result := ContainerStatus{
+		Name:     status.Name,
+		Image:    status.Image,
+		Ready:    status.Ready,
+		Restarts: status.RestartCount,
+		State:    containerState(&status.State),
+	}
+	if status.LastTerminationState.Terminated != nil {
+		result.LastTermination = containerState(&status.LastTerminationState)
+	}
+	return result
+}
+
+// containerState formats the state of a container.
+func containerState(state *corev1.ContainerState) string {
+	switch {
+	case state.Running != nil:
+		return "running since " + state.Running.StartedAt.UTC().Format(time.RFC3339)
+	case state.Waiting != nil:
+		return joinNonEmpty("waiting", state.Waiting.Reason, state.Waiting.Message)
+	case state.Terminated != nil:
+		terminated := state.Terminated
+		result := "terminated: " + terminated.Reason + " (exit code " + strconv.Itoa(int(terminated.ExitCode))
+		if terminated.Signal != 0 {
+			result += ", signal " + strconv.Itoa(int(terminated.Signal))
+		}
+		result += ")"
+		if !terminated.FinishedAt.IsZero() {
+			result += " at " + terminated.FinishedAt.UTC().Format(time.RFC3339)
+		}
+		if terminated.Message != "" {
+			result += ": " + terminated.Message
+		}
+		return result
+	default:
+		return "unknown"
+	}
+}
+
+// joinNonEmpty joins the parts that are not empty with colons.
+func joinNonEmpty(parts ...string) string {
+	var nonEmpty []string
+	for _, part := range parts {
+		if part != "" {
+			nonEmpty = append(nonEmpty, part)
+		}
+	}
+	return strings.Join(nonEmpty, ": ")
+}
+
+// ObjectEvents returns the most recent events of an object, newest first.
+func (c *Client) ObjectEvents(ctx context.Context, kind, namespace, name string) ([]EventSummary, error) {
+	if kind == "" || name == "" {
+		return nil, fmt.Errorf("kind and name cannot be empty")
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	events, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=" + kind + ",involvedObject.name=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	result := []EventSummary{}
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != kind || event.InvolvedObject.Name != name {
+			continue
+		}
+		result = append(result, EventSummary{
+			Namespace: event.Namespace,
+			Object:    event.InvolvedObject.Kind + "/" + event.InvolvedObject.Name,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Count:     event.Count,
+			LastSeen:  eventLastSeen(&event),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastSeen.After(result[j].LastSeen) })
+	return result[:min(len(result), MaxObjectEvents)], nil
+}
+
+// PodLogTail returns the last lines of the logs of a container of a pod, or
+// of its previous instance if previous is true. The container may be empty
+// for pods with a single container.
+func (c *Client) PodLogTail(ctx context.Context, namespace, name, container string, previous bool, lines int) (string, error) {
+	getPodLogs := c.GetPodLogs()
+	if getPodLogs == nil {
+		return "", fmt.Errorf("pod logs are not available")
+	}
+	if lines <= 0 {
+		lines = DefaultLogTailLines
+	}
+
+	parameters := map[string]string{
+		"tailLines": strconv.Itoa(lines),
+		"previous":  strconv.FormatBool(previous),
+	}
+	if container != "" {
+		parameters["container"] = container
+	}
+	result, err := getPodLogs(ctx, namespace, name, parameters)
+	if err != nil {
+		return "", err
+	}
+	logs, _ := result.Object[fieldLogs].(string)
+	return logs, nil
+}
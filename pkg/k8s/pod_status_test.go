@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestPodStatus(t *testing.T) {
+	finished := metav1.NewTime(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC))
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec:       corev1.PodSpec{NodeName: "node-a"},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			Conditions: []corev1.PodCondition{
+				{Type: corev1.PodScheduled, Status: corev1.ConditionTrue},
+				{Type: corev1.PodReady, Status: corev1.ConditionFalse, Reason: "ContainersNotReady"},
+			},
+			InitContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "migrate",
+				Image: "migrate:1",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}},
+			}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "app",
+				Image:        "web:1",
+				RestartCount: 4,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+					Reason: "CrashLoopBackOff", Message: "back-off 1m20s restarting failed container",
+				}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					Reason: "Error", ExitCode: 1, FinishedAt: finished,
+				}},
+			}},
+		},
+	}))
+
+	status, err := client.PodStatus(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "Running", status.Phase)
+	assert.Equal(t, "node-a", status.Node)
+	assert.Equal(t, []string{"Ready=False: ContainersNotReady"}, status.Conditions)
+	require.Len(t, status.InitContainers, 1)
+	assert.Equal(t, "terminated: Completed (exit code 0)", status.InitContainers[0].State)
+	require.Len(t, status.Containers, 1)
+	assert.Equal(t, ContainerStatus{
+		Name:            "app",
+		Image:           "web:1",
+		Restarts:        4,
+		State:           "waiting: CrashLoopBackOff: back-off 1m20s restarting failed container",
+		LastTermination: "terminated: Error (exit code 1) at 2026-01-02T03:04:05Z",
+	}, status.Containers[0])
+
+	_, err = client.PodStatus(context.Background(), "shop", "missing")
+	assert.ErrorContains(t, err, "failed to get pod")
+	_, err = client.PodStatus(context.Background(), "", "web")
+	assert.Error(t, err)
+}
+
+func TestObjectEvents(t *testing.T) {
+	now := time.Now()
+	event := func(name, kind, object string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Namespace: "shop", Name: object},
+			Reason:         name,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(
+		event("Pulled", "Pod", "web", now.Add(-time.Minute)),
+		event("BackOff", "Pod", "web", now),
+		event("ScalingReplicaSet", "Deployment", "web", now),
+		event("Killing", "Pod", "db", now),
+	))
+
+	events, err := client.ObjectEvents(context.Background(), "Pod", "shop", "web")
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	assert.Equal(t, "BackOff", events[0].Reason)
+	assert.Equal(t, "Pod/web", events[0].Object)
+	assert.Equal(t, "Pulled", events[1].Reason)
+
+	events, err = client.ObjectEvents(context.Background(), "Pod", "shop", "missing")
+	require.NoError(t, err)
+	assert.Empty(t, events)
+}
+
+func TestPodLogTail(t *testing.T) {
+	client := &Client{}
+	var parameters map[string]string
+	client.SetPodLogsFunc(func(_ context.Context, namespace, name string, p map[string]string) (*unstructured.Unstructured, error) {
+		parameters = p
+		if name == "missing" {
+			return nil, fmt.Errorf("pods %q not found", name)
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{fieldLogs: "panic: boom\n"}}, nil
+	})
+
+	logs, err := client.PodLogTail(context.Background(), "shop", "web", "app", true, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "panic: boom\n", logs)
+	assert.Equal(t, map[string]string{"container": "app", "previous": "true", "tailLines": "50"}, parameters)
+
+	_, err = client.PodLogTail(context.Background(), "shop", "missing", "", false, 10)
+	assert.Error(t, err)
+	assert.Equal(t, map[string]string{"previous": "false", "tailLines": "10"}, parameters)
+}
@@ -0,0 +1,423 @@
+package k8s
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/portforward"
+	"k8s.io/client-go/transport/spdy"
+)
+
+const (
+	// MaxHTTPProbeTimeout is the maximum timeout of an HTTP probe request.
+	MaxHTTPProbeTimeout = 20 * time.Second
+	// MaxHTTPProbeBodyBytes is the maximum number of body bytes returned by
+	// an HTTP probe.
+	MaxHTTPProbeBodyBytes int64 = 1 << 20 // 1 MiB
+	// defaultHTTPProbeTimeout is used when no timeout is given.
+	defaultHTTPProbeTimeout = 5 * time.Second
+	// defaultHTTPProbeBodyBytes is used when no body limit is given.
+	defaultHTTPProbeBodyBytes int64 = 16 << 10 // 16 KiB
+)
+
+// HTTPProbeOptions configures an HTTP probe.
+type HTTPProbeOptions struct {
+	// Port is the port to probe, as a number or a name. For pods it is a
+	// container port; for services, a service port. May be empty when the
+	// target exposes a single port.
+	Port string
+	// Path is the request path, optionally with a query string. Defaults to "/".
+	Path string
+	// HTTPS probes over TLS. Certificates are not verified, as they are
+	// rarely valid for a forwarded localhost address.
+	HTTPS bool
+	// Timeout bounds the request, including reading the body. Defaults to 5s
+	// and is capped at MaxHTTPProbeTimeout.
+	Timeout time.Duration
+	// MaxBodyBytes caps the returned body. Defaults to 16KiB and is capped at
+	// MaxHTTPProbeBodyBytes.
+	MaxBodyBytes int64
+}
+
+// HTTPProbeTarget describes what an HTTP probe was sent to.
+type HTTPProbeTarget struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Pod       string `json:"pod"`
+	Port      int32  `json:"port"`
+}
+
+// HTTPProbeResult is the result of an HTTP probe.
+type HTTPProbeResult struct {
+	Target       HTTPProbeTarget     `json:"target"`
+	URL          string              `json:"url"`
+	StatusCode   int                 `json:"statusCode"`
+	Status       string              `json:"status"`
+	Headers      map[string][]string `json:"headers"`
+	Body         string              `json:"body"`
+	BodyEncoding string              `json:"bodyEncoding"`
+	BodyBytes    int                 `json:"bodyBytes"`
+	Truncated    bool                `json:"truncated,omitempty"`
+	DurationMs   int64               `json:"durationMs"`
+}
+
+// ProbePodHTTP forwards a local port to a pod port and performs a single HTTP
+// GET against it, like `kubectl port-forward` followed by `curl`. The forward
+// is torn down before returning. Redirects are not followed.
+func (c *Client) ProbePodHTTP(
+	ctx context.Context,
+	namespace, name string,
+	opts HTTPProbeOptions,
+) (*HTTPProbeResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("pod name cannot be empty")
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	port, err := resolvePodPort(pod, opts.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.probeHTTP(ctx, HTTPProbeTarget{
+		Kind:      kindPod,
+		Namespace: namespace,
+		Name:      name,
+		Pod:       name,
+		Port:      port,
+	}, opts)
+}
+
+// ProbeServiceHTTP performs an HTTP probe against a ready pod backing a
+// service. The pod and target port are taken from the service's
+// EndpointSlices, so named target ports are resolved like kube-proxy does.
+func (c *Client) ProbeServiceHTTP(
+	ctx context.Context,
+	namespace, name string,
+	opts HTTPProbeOptions,
+) (*HTTPProbeResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("service name cannot be empty")
+	}
+
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	svc, err := clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get service: %w", err)
+	}
+	servicePort, err := resolveServicePort(svc, opts.Port)
+	if err != nil {
+		return nil, err
+	}
+
+	slices, err := clientset.DiscoveryV1().EndpointSlices(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices: %w", err)
+	}
+	pod, port, err := readyServiceBackend(slices.Items, servicePort.Name)
+	if err != nil {
+		return nil, fmt.Errorf("service %s/%s: %w", namespace, name, err)
+	}
+
+	return c.probeHTTP(ctx, HTTPProbeTarget{
+		Kind:      "Service",
+		Namespace: namespace,
+		Name:      name,
+		Pod:       pod,
+		Port:      port,
+	}, opts)
+}
+
+// probeHTTP forwards a local port to target and performs the HTTP GET.
+func (c *Client) probeHTTP(
+	ctx context.Context,
+	target HTTPProbeTarget,
+	opts HTTPProbeOptions,
+) (*HTTPProbeResult, error) {
+	requestURI, err := parseProbePath(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultHTTPProbeTimeout
+	}
+	if timeout > MaxHTTPProbeTimeout {
+		timeout = MaxHTTPProbeTimeout
+	}
+	maxBodyBytes := opts.MaxBodyBytes
+	if maxBodyBytes <= 0 {
+		maxBodyBytes = defaultHTTPProbeBodyBytes
+	}
+	if maxBodyBytes > MaxHTTPProbeBodyBytes {
+		maxBodyBytes = MaxHTTPProbeBodyBytes
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	c.mu.RLock()
+	forward := c.portForward
+	c.mu.RUnlock()
+
+	localPort, stop, err := forward(probeCtx, target.Namespace, target.Pod, target.Port)
+	if err != nil {
+		return nil, fmt.Errorf("failed to forward port %d of pod %s/%s: %w", target.Port, target.Namespace, target.Pod, err)
+	}
+	defer stop()
+
+	scheme := "http"
+	if opts.HTTPS {
+		scheme = "https"
+	}
+	probeURL := fmt.Sprintf("%s://%s%s", scheme, net.JoinHostPort("127.0.0.1", strconv.Itoa(int(localPort))), requestURI)
+
+	req, err := http.NewRequestWithContext(probeCtx, http.MethodGet, probeURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	httpClient := &http.Client{
+		Transport: &http.Transport{
+			// The forward only reaches a single pod port; never use a proxy.
+			Proxy:             nil,
+			DisableKeepAlives: true,
+			//nolint:gosec // G402 -- pod certificates are not issued for the forwarded localhost address
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+		// Don't follow redirects: they may point outside of the pod.
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// A body that fails part way (e.g. a slow stream hitting the timeout) is
+	// still returned, marked as truncated.
+	body, readErr := io.ReadAll(io.LimitReader(resp.Body, maxBodyBytes+1))
+	if readErr != nil && len(body) == 0 {
+		return nil, fmt.Errorf("failed to read response body: %w", readErr)
+	}
+	truncated := int64(len(body)) > maxBodyBytes
+	if truncated {
+		body = body[:maxBodyBytes]
+	}
+
+	result := &HTTPProbeResult{
+		Target:     target,
+		URL:        fmt.Sprintf("%s://%s:%d%s", scheme, target.Pod, target.Port, requestURI),
+		StatusCode: resp.StatusCode,
+		Status:     resp.Status,
+		Headers:    resp.Header,
+		BodyBytes:  len(body),
+		Truncated:  truncated || readErr != nil,
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if utf8.Valid(body) {
+		result.Body = string(body)
+		result.BodyEncoding = "text"
+	} else {
+		result.Body = fmt.Sprintf("<%d bytes of binary content omitted>", len(body))
+		result.BodyEncoding = "binary"
+	}
+
+	return result, nil
+}
+
+// parseProbePath validates a probe path and returns the request URI. Only a
+// path and query are allowed, so that the request cannot be redirected to
+// another host.
+func parseProbePath(p string) (string, error) {
+	if p == "" {
+		return "/", nil
+	}
+	u, err := url.Parse(p)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", p, err)
+	}
+	if u.Scheme != "" || u.Host != "" || u.User != nil || !strings.HasPrefix(p, "/") || strings.HasPrefix(p, "//") {
+		return "", fmt.Errorf("invalid path %q: must be an absolute path such as /healthz", p)
+	}
+	return u.RequestURI(), nil
+}
+
+// resolvePodPort resolves a container port of pod given as a number or a
+// name. An empty port selects the only port declared by the pod's containers.
+func resolvePodPort(pod *corev1.Pod, port string) (int32, error) {
+	if pod.Status.Phase != "" && pod.Status.Phase != corev1.PodRunning {
+		return 0, fmt.Errorf("pod %s/%s is not running (phase %s)", pod.Namespace, pod.Name, pod.Status.Phase)
+	}
+
+	var declared []corev1.ContainerPort
+	for _, container := range pod.Spec.Containers {
+		declared = append(declared, container.Ports...)
+	}
+
+	if port == "" {
+		if len(declared) != 1 {
+			return 0, fmt.Errorf("pod %s/%s declares %d ports, port is required", pod.Namespace, pod.Name, len(declared))
+		}
+		return declared[0].ContainerPort, nil
+	}
+
+	parsed := intstr.Parse(port)
+	if parsed.Type == intstr.Int {
+		if parsed.IntVal <= 0 || parsed.IntVal > 65535 {
+			return 0, fmt.Errorf("invalid port %q", port)
+		}
+		// Undeclared ports may still be open, so numeric ports are not checked.
+		return parsed.IntVal, nil
+	}
+	for _, p := range declared {
+		if p.Name == port {
+			return p.ContainerPort, nil
+		}
+	}
+	return 0, fmt.Errorf("pod %s/%s has no port named %q", pod.Namespace, pod.Name, port)
+}
+
+// resolveServicePort finds a service port by number or name. An empty port
+// selects the service's only port.
+func resolveServicePort(svc *corev1.Service, port string) (*corev1.ServicePort, error) {
+	if port == "" {
+		if len(svc.Spec.Ports) != 1 {
+			return nil, fmt.Errorf("service %s/%s has %d ports, port is required",
+				svc.Namespace, svc.Name, len(svc.Spec.Ports))
+		}
+		return &svc.Spec.Ports[0], nil
+	}
+
+	parsed := intstr.Parse(port)
+	for i, p := range svc.Spec.Ports {
+		if (parsed.Type == intstr.Int && p.Port == parsed.IntVal) || (parsed.Type == intstr.String && p.Name == port) {
+			return &svc.Spec.Ports[i], nil
+		}
+	}
+	return nil, fmt.Errorf("service %s/%s has no port %q", svc.Namespace, svc.Name, port)
+}
+
+// readyServiceBackend picks the first ready pod endpoint from a service's
+// EndpointSlices and returns it with the target port of the named service
+// port.
+func readyServiceBackend(slices []discoveryv1.EndpointSlice, portName string) (string, int32, error) {
+	for _, slice := range slices {
+		var port *int32
+		for _, p := range slice.Ports {
+			if p.Name != nil && *p.Name == portName && p.Port != nil {
+				port = p.Port
+				break
+			}
+		}
+		if port == nil {
+			continue
+		}
+		for _, endpoint := range slice.Endpoints {
+			if endpoint.Conditions.Ready != nil && !*endpoint.Conditions.Ready {
+				continue
+			}
+			if endpoint.TargetRef == nil || endpoint.TargetRef.Kind != kindPod {
+				continue
+			}
+			return endpoint.TargetRef.Name, *port, nil
+		}
+	}
+	return "", 0, fmt.Errorf("no ready pod endpoints")
+}
+
+// defaultPortForward is the default implementation of PortForwardFunc. It
+// forwards an ephemeral port on 127.0.0.1 to the pod port over SPDY.
+func (c *Client) defaultPortForward(
+	ctx context.Context,
+	namespace, name string,
+	port int32,
+) (uint16, func(), error) {
+	c.mu.RLock()
+	restConfig := c.restConfig
+	req := c.clientset.CoreV1().RESTClient().Post().
+		Resource(resourcePods).
+		Namespace(namespace).
+		Name(name).
+		SubResource(subresourcePortForward)
+	c.mu.RUnlock()
+
+	transport, upgrader, err := spdy.RoundTripperFor(restConfig)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create SPDY round tripper: %w", err)
+	}
+	dialer := spdy.NewDialer(upgrader, &http.Client{Transport: transport}, http.MethodPost, req.URL())
+
+	stopCh := make(chan struct{})
+	readyCh := make(chan struct{})
+	var once sync.Once
+	stop := func() { once.Do(func() { close(stopCh) }) }
+
+	forwarder, err := portforward.NewOnAddresses(dialer, []string{"127.0.0.1"},
+		[]string{fmt.Sprintf("0:%d", port)}, stopCh, readyCh, io.Discard, io.Discard)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to create port forwarder: %w", err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- forwarder.ForwardPorts()
+	}()
+
+	select {
+	case <-readyCh:
+	case err := <-errCh:
+		stop()
+		if err == nil {
+			err = fmt.Errorf("port forward closed before becoming ready")
+		}
+		return 0, nil, err
+	case <-ctx.Done():
+		stop()
+		return 0, nil, ctx.Err()
+	}
+
+	ports, err := forwarder.GetPorts()
+	if err != nil {
+		stop()
+		return 0, nil, fmt.Errorf("failed to get forwarded port: %w", err)
+	}
+	if len(ports) == 0 {
+		stop()
+		return 0, nil, fmt.Errorf("no forwarded port")
+	}
+
+	return ports[0].Local, stop, nil
+}
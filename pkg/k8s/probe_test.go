@@ -0,0 +1,213 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+// newProbeTestClient returns a client whose port forwards all lead to server.
+// The forwarded pod ports are recorded in forwarded.
+func newProbeTestClient(t *testing.T, server *httptest.Server, objects ...runtime.Object) (*Client, *[]string) {
+	t.Helper()
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(objects...))
+
+	_, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	localPort, err := strconv.Atoi(portString)
+	require.NoError(t, err)
+
+	var forwarded []string
+	client.SetPortForwardFunc(func(_ context.Context, namespace, name string, port int32) (uint16, func(), error) {
+		forwarded = append(forwarded, fmt.Sprintf("%s/%s:%d", namespace, name, port))
+		return uint16(localPort), func() {}, nil
+	})
+	return client, &forwarded
+}
+
+func probeTestPod() *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{
+			Name:  "web",
+			Ports: []corev1.ContainerPort{{Name: "http", ContainerPort: 8080}},
+		}}},
+		Status: corev1.PodStatus{Phase: corev1.PodRunning},
+	}
+}
+
+func TestProbePodHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Path", r.URL.RequestURI())
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	client, forwarded := newProbeTestClient(t, server, probeTestPod())
+
+	result, err := client.ProbePodHTTP(context.Background(), "default", "web-0", HTTPProbeOptions{
+		Port: "http",
+		Path: "/healthz?verbose=1",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, result.StatusCode)
+	assert.Equal(t, "ok", result.Body)
+	assert.Equal(t, "text", result.BodyEncoding)
+	assert.Equal(t, "/healthz?verbose=1", result.Headers["X-Path"][0])
+	assert.Equal(t, "http://web-0:8080/healthz?verbose=1", result.URL)
+	assert.Equal(t, []string{"default/web-0:8080"}, *forwarded)
+}
+
+func TestProbePodHTTPTruncatesBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(strings.Repeat("x", 100)))
+	}))
+	defer server.Close()
+
+	client, _ := newProbeTestClient(t, server, probeTestPod())
+
+	result, err := client.ProbePodHTTP(context.Background(), "default", "web-0", HTTPProbeOptions{
+		Port:         "8080",
+		MaxBodyBytes: 10,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Truncated)
+	assert.Equal(t, 10, result.BodyBytes)
+	assert.Equal(t, strings.Repeat("x", 10), result.Body)
+}
+
+func TestProbePodHTTPDoesNotFollowRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://example.com/", http.StatusFound)
+	}))
+	defer server.Close()
+
+	client, _ := newProbeTestClient(t, server, probeTestPod())
+
+	result, err := client.ProbePodHTTP(context.Background(), "default", "web-0", HTTPProbeOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusFound, result.StatusCode)
+	assert.Equal(t, "http://example.com/", result.Headers["Location"][0])
+}
+
+func TestProbeServiceHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	portName := "metrics"
+	targetPort := int32(9090)
+	ready, notReady := true, false
+	svc := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default"},
+		Spec: corev1.ServiceSpec{Ports: []corev1.ServicePort{
+			{Name: "http", Port: 80, TargetPort: intstr.FromString("http")},
+			{Name: portName, Port: 9000, TargetPort: intstr.FromString("metrics")},
+		}},
+	}
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-abc",
+			Namespace: "default",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: "web"},
+		},
+		Ports: []discoveryv1.EndpointPort{{Name: &portName, Port: &targetPort}},
+		Endpoints: []discoveryv1.Endpoint{
+			{
+				Conditions: discoveryv1.EndpointConditions{Ready: &notReady},
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "web-0"},
+			},
+			{
+				Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: "web-1"},
+			},
+		},
+	}
+
+	client, forwarded := newProbeTestClient(t, server, svc, slice)
+
+	result, err := client.ProbeServiceHTTP(context.Background(), "default", "web", HTTPProbeOptions{
+		Port: "9000",
+		Path: "/metrics",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusServiceUnavailable, result.StatusCode)
+	assert.Equal(t, "Service", result.Target.Kind)
+	assert.Equal(t, "web-1", result.Target.Pod, "not-ready endpoints are skipped")
+	assert.Equal(t, []string{"default/web-1:9090"}, *forwarded)
+
+	_, err = client.ProbeServiceHTTP(context.Background(), "default", "web", HTTPProbeOptions{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "port is required")
+
+	_, err = client.ProbeServiceHTTP(context.Background(), "default", "web", HTTPProbeOptions{Port: "http"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "no ready pod endpoints")
+}
+
+func TestParseProbePath(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected string
+		errorMsg string
+	}{
+		{path: "", expected: "/"},
+		{path: "/healthz", expected: "/healthz"},
+		{path: "/metrics?name[]=a", expected: "/metrics?name[]=a"},
+		{path: "healthz", errorMsg: "must be an absolute path"},
+		{path: "http://example.com/", errorMsg: "must be an absolute path"},
+		{path: "//example.com/", errorMsg: "must be an absolute path"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.path, func(t *testing.T) {
+			requestURI, err := parseProbePath(tc.path)
+			if tc.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, requestURI)
+		})
+	}
+}
+
+func TestResolvePodPort(t *testing.T) {
+	pod := probeTestPod()
+
+	port, err := resolvePodPort(pod, "")
+	require.NoError(t, err)
+	assert.Equal(t, int32(8080), port)
+
+	port, err = resolvePodPort(pod, "9100")
+	require.NoError(t, err)
+	assert.Equal(t, int32(9100), port, "undeclared numeric ports are allowed")
+
+	_, err = resolvePodPort(pod, "grpc")
+	require.Error(t, err)
+
+	_, err = resolvePodPort(pod, "70000")
+	require.Error(t, err)
+
+	pod.Status.Phase = corev1.PodPending
+	_, err = resolvePodPort(pod, "8080")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not running")
+}
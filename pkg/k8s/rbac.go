@@ -0,0 +1,454 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	// DefaultRBACLimit is the default number of entries returned by WhoCan
+	// and SubjectPermissions.
+	DefaultRBACLimit = 100
+	// MaxRBACLimit is the largest number of entries returned by WhoCan and
+	// SubjectPermissions.
+	MaxRBACLimit = 500
+)
+
+// RBACSubject is a user, group or service account RBAC bindings refer to.
+type RBACSubject struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// String returns the subject as Kind/name, or Kind/namespace/name.
+func (s RBACSubject) String() string {
+	if s.Namespace != "" {
+		return s.Kind + "/" + s.Namespace + "/" + s.Name
+	}
+	return s.Kind + "/" + s.Name
+}
+
+// RBACQuery is an action WhoCan finds the subjects allowed to perform.
+type RBACQuery struct {
+	Verb        string `json:"verb"`
+	Group       string `json:"group"`
+	Resource    string `json:"resource"`
+	Subresource string `json:"subresource,omitempty"`
+	Name        string `json:"name,omitempty"`
+	// Namespace is the namespace of the resource. When empty, bindings of all
+	// namespaces are considered and the namespace of each grant is reported.
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RBACGrant is a binding granting a subject an action.
+type RBACGrant struct {
+	Subject RBACSubject `json:"subject"`
+	// Namespace is the namespace the grant applies to, empty if cluster-wide.
+	Namespace string `json:"namespace,omitempty"`
+	Binding   string `json:"binding"`
+	Role      string `json:"role"`
+	// ResourceNames restricts the grant to the resources of these names.
+	ResourceNames []string `json:"resourceNames,omitempty"`
+}
+
+// WhoCanResult is the result of WhoCan.
+type WhoCanResult struct {
+	Query  RBACQuery   `json:"query"`
+	Grants []RBACGrant `json:"grants"`
+	// Total is the number of grants found, of which at most the limit are
+	// returned.
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// RBACPermission is a rule granted to a subject.
+type RBACPermission struct {
+	// Namespace is the namespace the rule applies to, empty if cluster-wide.
+	Namespace string `json:"namespace,omitempty"`
+	Binding   string `json:"binding"`
+	Role      string `json:"role"`
+	// Via is the group the subject was granted the rule through, if not
+	// directly.
+	Via             string   `json:"via,omitempty"`
+	Verbs           []string `json:"verbs"`
+	APIGroups       []string `json:"apiGroups,omitempty"`
+	Resources       []string `json:"resources,omitempty"`
+	ResourceNames   []string `json:"resourceNames,omitempty"`
+	NonResourceURLs []string `json:"nonResourceURLs,omitempty"`
+}
+
+// SubjectPermissionsResult is the result of SubjectPermissions.
+type SubjectPermissionsResult struct {
+	Subject RBACSubject `json:"subject"`
+	// Groups are the groups the subject implicitly belongs to, whose
+	// bindings are included.
+	Groups      []string         `json:"groups,omitempty"`
+	Permissions []RBACPermission `json:"permissions"`
+	// Total is the number of rules found, of which at most the limit are
+	// returned.
+	Total     int  `json:"total"`
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// rbacSnapshot holds the RBAC objects WhoCan and SubjectPermissions evaluate.
+type rbacSnapshot struct {
+	clusterRoles        map[string]*rbacv1.ClusterRole
+	clusterRoleBindings []rbacv1.ClusterRoleBinding
+	roles               map[string]*rbacv1.Role
+	roleBindings        []rbacv1.RoleBinding
+	// aggregated caches the rules of ClusterRoles, including aggregated ones.
+	aggregated map[string][]rbacv1.PolicyRule
+}
+
+// WhoCan returns the subjects bound to a role allowing query, like
+// `kubectl who-can`, reading the Roles, ClusterRoles and their bindings.
+// Wildcards in rules are honored and the rules of aggregated ClusterRoles are
+// resolved from the ClusterRoles they select. At most limit grants are
+// returned.
+func (c *Client) WhoCan(ctx context.Context, query RBACQuery, limit int) (*WhoCanResult, error) {
+	if query.Verb == "" {
+		return nil, fmt.Errorf("verb cannot be empty")
+	}
+	if query.Resource == "" {
+		return nil, fmt.Errorf("resource cannot be empty")
+	}
+	limit = rbacLimit(limit)
+
+	snapshot, err := c.rbacSnapshot(ctx, query.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &WhoCanResult{Query: query, Grants: []RBACGrant{}}
+	add := func(subjects []rbacv1.Subject, namespace, binding string, roleRef rbacv1.RoleRef) {
+		rules := snapshot.roleRules(roleRef, namespace)
+		var resourceNames []string
+		allowed := false
+		for _, rule := range rules {
+			if !ruleAllows(rule, query) {
+				continue
+			}
+			if len(rule.ResourceNames) == 0 {
+				allowed, resourceNames = true, nil
+				break
+			}
+			allowed = true
+			resourceNames = append(resourceNames, rule.ResourceNames...)
+		}
+		if !allowed {
+			return
+		}
+		for _, subject := range subjects {
+			result.Total++
+			if len(result.Grants) >= limit {
+				continue
+			}
+			result.Grants = append(result.Grants, RBACGrant{
+				Subject:       rbacSubject(subject, namespace),
+				Namespace:     namespace,
+				Binding:       binding,
+				Role:          roleRef.Kind + "/" + roleRef.Name,
+				ResourceNames: resourceNames,
+			})
+		}
+	}
+	for _, binding := range snapshot.clusterRoleBindings {
+		add(binding.Subjects, "", "ClusterRoleBinding/"+binding.Name, binding.RoleRef)
+	}
+	for _, binding := range snapshot.roleBindings {
+		add(binding.Subjects, binding.Namespace, "RoleBinding/"+binding.Namespace+"/"+binding.Name, binding.RoleRef)
+	}
+
+	result.Truncated = result.Total > len(result.Grants)
+	sort.SliceStable(result.Grants, func(i, j int) bool {
+		return result.Grants[i].Subject.String() < result.Grants[j].Subject.String()
+	})
+	return result, nil
+}
+
+// SubjectPermissions returns the rules granted to subject, directly or
+// through the groups it implicitly belongs to, by the bindings of namespace
+// and the ClusterRoleBindings. When namespace is empty, the bindings of all
+// namespaces are considered. At most limit rules are returned.
+func (c *Client) SubjectPermissions(
+	ctx context.Context,
+	subject RBACSubject,
+	namespace string,
+	limit int,
+) (*SubjectPermissionsResult, error) {
+	switch subject.Kind {
+	case rbacv1.UserKind, rbacv1.GroupKind:
+		subject.Namespace = ""
+	case rbacv1.ServiceAccountKind:
+		if subject.Namespace == "" {
+			return nil, fmt.Errorf("namespace of the service account cannot be empty")
+		}
+	default:
+		return nil, fmt.Errorf("invalid subject kind %q: must be User, Group or ServiceAccount", subject.Kind)
+	}
+	if subject.Name == "" {
+		return nil, fmt.Errorf("subject name cannot be empty")
+	}
+	limit = rbacLimit(limit)
+
+	snapshot, err := c.rbacSnapshot(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SubjectPermissionsResult{
+		Subject:     subject,
+		Groups:      implicitGroups(subject),
+		Permissions: []RBACPermission{},
+	}
+	add := func(subjects []rbacv1.Subject, bindingNamespace, binding string, roleRef rbacv1.RoleRef) {
+		via, ok := matchSubject(subjects, subject, result.Groups, bindingNamespace)
+		if !ok {
+			return
+		}
+		for _, rule := range snapshot.roleRules(roleRef, bindingNamespace) {
+			result.Total++
+			if len(result.Permissions) >= limit {
+				continue
+			}
+			result.Permissions = append(result.Permissions, RBACPermission{
+				Namespace:       bindingNamespace,
+				Binding:         binding,
+				Role:            roleRef.Kind + "/" + roleRef.Name,
+				Via:             via,
+				Verbs:           rule.Verbs,
+				APIGroups:       rule.APIGroups,
+				Resources:       rule.Resources,
+				ResourceNames:   rule.ResourceNames,
+				NonResourceURLs: rule.NonResourceURLs,
+			})
+		}
+	}
+	for _, binding := range snapshot.clusterRoleBindings {
+		add(binding.Subjects, "", "ClusterRoleBinding/"+binding.Name, binding.RoleRef)
+	}
+	for _, binding := range snapshot.roleBindings {
+		add(binding.Subjects, binding.Namespace, "RoleBinding/"+binding.Namespace+"/"+binding.Name, binding.RoleRef)
+	}
+
+	result.Truncated = result.Total > len(result.Permissions)
+	return result, nil
+}
+
+// rbacSnapshot lists the ClusterRoles and ClusterRoleBindings, and the Roles
+// and RoleBindings of namespace, or of all namespaces if it is empty.
+func (c *Client) rbacSnapshot(ctx context.Context, namespace string) (*rbacSnapshot, error) {
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	rbac := clientset.RbacV1()
+
+	clusterRoles, err := rbac.ClusterRoles().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster roles: %w", err)
+	}
+	clusterRoleBindings, err := rbac.ClusterRoleBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster role bindings: %w", err)
+	}
+	roles, err := rbac.Roles(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list roles: %w", err)
+	}
+	roleBindings, err := rbac.RoleBindings(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list role bindings: %w", err)
+	}
+
+	snapshot := &rbacSnapshot{
+		clusterRoles:        map[string]*rbacv1.ClusterRole{},
+		clusterRoleBindings: clusterRoleBindings.Items,
+		roles:               map[string]*rbacv1.Role{},
+		roleBindings:        roleBindings.Items,
+		aggregated:          map[string][]rbacv1.PolicyRule{},
+	}
+	for i := range clusterRoles.Items {
+		snapshot.clusterRoles[clusterRoles.Items[i].Name] = &clusterRoles.Items[i]
+	}
+	for i := range roles.Items {
+		snapshot.roles[roles.Items[i].Namespace+"/"+roles.Items[i].Name] = &roles.Items[i]
+	}
+	return snapshot, nil
+}
+
+// roleRules returns the rules of the role roleRef refers to from a binding in
+// namespace.
+func (s *rbacSnapshot) roleRules(roleRef rbacv1.RoleRef, namespace string) []rbacv1.PolicyRule {
+	switch roleRef.Kind {
+	case "ClusterRole":
+		return s.clusterRoleRules(roleRef.Name, map[string]bool{})
+	case "Role":
+		if role, ok := s.roles[namespace+"/"+roleRef.Name]; ok {
+			return role.Rules
+		}
+	}
+	return nil
+}
+
+// clusterRoleRules returns the rules of the ClusterRole name, along with
+// those of the ClusterRoles it aggregates. The aggregation controller copies
+// these into the rules of the ClusterRole, but they are resolved here in case
+// it has not yet.
+func (s *rbacSnapshot) clusterRoleRules(name string, visiting map[string]bool) []rbacv1.PolicyRule {
+	if rules, ok := s.aggregated[name]; ok {
+		return rules
+	}
+	role, ok := s.clusterRoles[name]
+	if !ok || visiting[name] {
+		return nil
+	}
+	visiting[name] = true
+
+	rules := slices.Clone(role.Rules)
+	if role.AggregationRule != nil {
+		for _, selector := range role.AggregationRule.ClusterRoleSelectors {
+			labelSelector, err := metav1.LabelSelectorAsSelector(&selector)
+			if err != nil {
+				continue
+			}
+			for _, other := range sortedKeys(s.clusterRoles) {
+				if other == name || !labelSelector.Matches(labels.Set(s.clusterRoles[other].Labels)) {
+					continue
+				}
+				for _, rule := range s.clusterRoleRules(other, visiting) {
+					if !slices.ContainsFunc(rules, func(existing rbacv1.PolicyRule) bool {
+						return policyRulesEqual(existing, rule)
+					}) {
+						rules = append(rules, rule)
+					}
+				}
+			}
+		}
+	}
+	s.aggregated[name] = rules
+	return rules
+}
+
+// ruleAllows returns whether rule allows query, ignoring resource names.
+func ruleAllows(rule rbacv1.PolicyRule, query RBACQuery) bool {
+	if !containsOrWildcard(rule.Verbs, query.Verb) || !containsOrWildcard(rule.APIGroups, query.Group) {
+		return false
+	}
+	if query.Name != "" && len(rule.ResourceNames) > 0 && !slices.Contains(rule.ResourceNames, query.Name) {
+		return false
+	}
+
+	resource := query.Resource
+	if query.Subresource != "" {
+		resource += "/" + query.Subresource
+	}
+	for _, ruleResource := range rule.Resources {
+		if ruleResource == rbacv1.ResourceAll || ruleResource == resource {
+			return true
+		}
+		// */subresource matches the subresource of any resource.
+		if query.Subresource != "" && ruleResource == "*/"+query.Subresource {
+			return true
+		}
+	}
+	return false
+}
+
+// containsOrWildcard returns whether values contains value or the wildcard.
+func containsOrWildcard(values []string, value string) bool {
+	return slices.Contains(values, "*") || slices.Contains(values, value)
+}
+
+// policyRulesEqual returns whether a and b are the same rule.
+func policyRulesEqual(a, b rbacv1.PolicyRule) bool {
+	return slices.Equal(a.Verbs, b.Verbs) && slices.Equal(a.APIGroups, b.APIGroups) &&
+		slices.Equal(a.Resources, b.Resources) && slices.Equal(a.ResourceNames, b.ResourceNames) &&
+		slices.Equal(a.NonResourceURLs, b.NonResourceURLs)
+}
+
+// rbacSubject converts subject, from a binding in namespace, to an
+// RBACSubject. Service accounts default to the namespace of the binding.
+func rbacSubject(subject rbacv1.Subject, namespace string) RBACSubject {
+	result := RBACSubject{Kind: subject.Kind, Name: subject.Name}
+	if subject.Kind == rbacv1.ServiceAccountKind {
+		result.Namespace = subject.Namespace
+		if result.Namespace == "" {
+			result.Namespace = namespace
+		}
+	}
+	return result
+}
+
+// implicitGroups returns the groups the API server puts subject in.
+func implicitGroups(subject RBACSubject) []string {
+	switch subject.Kind {
+	case rbacv1.ServiceAccountKind:
+		return []string{"system:authenticated", "system:serviceaccounts", "system:serviceaccounts:" + subject.Namespace}
+	case rbacv1.UserKind:
+		return []string{"system:authenticated"}
+	default:
+		return nil
+	}
+}
+
+// matchSubject returns whether subjects, of a binding in namespace, include
+// subject or one of groups, and the group it matched through if not subject
+// itself.
+func matchSubject(subjects []rbacv1.Subject, subject RBACSubject, groups []string, namespace string) (string, bool) {
+	via, matched := "", false
+	for _, candidate := range subjects {
+		if rbacSubject(candidate, namespace) == subject {
+			return "", true
+		}
+		if candidate.Kind == rbacv1.GroupKind && slices.Contains(groups, candidate.Name) && !matched {
+			via, matched = candidate.Name, true
+		}
+	}
+	return via, matched
+}
+
+// rbacLimit returns limit bounded to MaxRBACLimit, or DefaultRBACLimit if it
+// is not positive.
+func rbacLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultRBACLimit
+	}
+	return min(limit, MaxRBACLimit)
+}
+
+// ParseRBACSubject parses a subject given as Kind/name, or
+// ServiceAccount/namespace/name. The kind is case-insensitive and may be
+// abbreviated to sa for service accounts.
+func ParseRBACSubject(value string) (RBACSubject, error) {
+	parts := strings.Split(value, "/")
+	if len(parts) < 2 {
+		return RBACSubject{}, fmt.Errorf("invalid subject %q: must be Kind/name or ServiceAccount/namespace/name", value)
+	}
+	var subject RBACSubject
+	switch strings.ToLower(parts[0]) {
+	case "user":
+		subject.Kind = rbacv1.UserKind
+	case "group":
+		subject.Kind = rbacv1.GroupKind
+	case "serviceaccount", "sa":
+		subject.Kind = rbacv1.ServiceAccountKind
+		if len(parts) != 3 {
+			return RBACSubject{}, fmt.Errorf("invalid subject %q: must be ServiceAccount/namespace/name", value)
+		}
+		subject.Namespace, subject.Name = parts[1], parts[2]
+		return subject, nil
+	default:
+		return RBACSubject{}, fmt.Errorf("invalid subject kind %q: must be User, Group or ServiceAccount", parts[0])
+	}
+	// User and group names may contain slashes
+	subject.Name = strings.Join(parts[1:], "/")
+	return subject, nil
+}
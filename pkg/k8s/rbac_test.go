@@ -0,0 +1,263 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+)
+
+func newRBACTestClient(extra ...runtime.Object) *Client {
+	objects := []runtime.Object{
+		// Aggregated ClusterRole, whose rules the controller has not filled in
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "monitoring"},
+			AggregationRule: &rbacv1.AggregationRule{ClusterRoleSelectors: []metav1.LabelSelector{
+				{MatchLabels: map[string]string{"aggregate-to-monitoring": "true"}},
+			}},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-reader", Labels: map[string]string{"aggregate-to-monitoring": "true"}},
+			Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get", "list", "watch"}, APIGroups: []string{""}, Resources: []string{"pods", "pods/log"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster-admin"},
+			Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"*"}, APIGroups: []string{"*"}, Resources: []string{"*"}},
+				{Verbs: []string{"*"}, NonResourceURLs: []string{"*"}},
+			},
+		},
+		&rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{Name: "scaler"},
+			Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"update"}, APIGroups: []string{"apps"}, Resources: []string{"*/scale"}},
+			},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "admins"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "cluster-admin"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+		},
+		&rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "prometheus"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "monitoring"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.ServiceAccountKind, Name: "prometheus", Namespace: "monitoring"}},
+		},
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "secret-reader", Namespace: "shop"},
+			Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"secrets"}, ResourceNames: []string{"tls"}},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployer", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "scaler"},
+			Subjects: []rbacv1.Subject{
+				{Kind: rbacv1.ServiceAccountKind, Name: "deployer"},
+				{Kind: rbacv1.UserKind, Name: "alice"},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "tls", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "secret-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:shop"}},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "viewers", Namespace: "blog"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "pod-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "bob"}},
+		},
+	}
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(append(objects, extra...)...))
+	return client
+}
+
+func grantSubjects(grants []RBACGrant) []string {
+	subjects := make([]string, 0, len(grants))
+	for _, grant := range grants {
+		subjects = append(subjects, grant.Subject.String())
+	}
+	return subjects
+}
+
+func TestWhoCan(t *testing.T) {
+	client := newRBACTestClient()
+
+	testCases := []struct {
+		name     string
+		query    RBACQuery
+		expected []string
+	}{
+		{
+			name:     "Aggregated role in all namespaces",
+			query:    RBACQuery{Verb: "list", Resource: "pods"},
+			expected: []string{"Group/system:masters", "ServiceAccount/monitoring/prometheus", "User/bob"},
+		},
+		{
+			name:     "Namespace",
+			query:    RBACQuery{Verb: "list", Resource: "pods", Namespace: "shop"},
+			expected: []string{"Group/system:masters", "ServiceAccount/monitoring/prometheus"},
+		},
+		{
+			name:     "Subresource",
+			query:    RBACQuery{Verb: "get", Resource: "pods", Subresource: "log", Namespace: "blog"},
+			expected: []string{"Group/system:masters", "ServiceAccount/monitoring/prometheus", "User/bob"},
+		},
+		{
+			name:  "Wildcard subresource",
+			query: RBACQuery{Verb: "update", Group: "apps", Resource: "deployments", Subresource: "scale", Namespace: "shop"},
+			expected: []string{
+				"Group/system:masters", "ServiceAccount/shop/deployer", "User/alice",
+			},
+		},
+		{
+			name:     "Wildcard verb",
+			query:    RBACQuery{Verb: "delete", Group: "apps", Resource: "deployments"},
+			expected: []string{"Group/system:masters"},
+		},
+		{
+			name:     "Resource name",
+			query:    RBACQuery{Verb: "get", Resource: "secrets", Name: "db", Namespace: "shop"},
+			expected: []string{"Group/system:masters"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := client.WhoCan(context.Background(), tc.query, 0)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, grantSubjects(result.Grants))
+			assert.Equal(t, len(tc.expected), result.Total)
+			assert.False(t, result.Truncated)
+		})
+	}
+}
+
+func TestWhoCanGrantDetails(t *testing.T) {
+	client := newRBACTestClient()
+
+	result, err := client.WhoCan(context.Background(), RBACQuery{Verb: "get", Resource: "secrets", Namespace: "shop"}, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []RBACGrant{
+		{
+			Subject: RBACSubject{Kind: rbacv1.GroupKind, Name: "system:masters"},
+			Binding: "ClusterRoleBinding/admins",
+			Role:    "ClusterRole/cluster-admin",
+		},
+		{
+			Subject:       RBACSubject{Kind: rbacv1.GroupKind, Name: "system:serviceaccounts:shop"},
+			Namespace:     "shop",
+			Binding:       "RoleBinding/shop/tls",
+			Role:          "Role/secret-reader",
+			ResourceNames: []string{"tls"},
+		},
+	}, result.Grants)
+}
+
+func TestWhoCanLimit(t *testing.T) {
+	var bindings []runtime.Object
+	for i := range 5 {
+		bindings = append(bindings, &rbacv1.ClusterRoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("readers-%d", i)},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: "pod-reader"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: fmt.Sprintf("user-%d", i)}},
+		})
+	}
+	client := newRBACTestClient(bindings...)
+
+	result, err := client.WhoCan(context.Background(), RBACQuery{Verb: "get", Resource: "pods"}, 3)
+	require.NoError(t, err)
+	assert.Len(t, result.Grants, 3)
+	assert.Equal(t, 8, result.Total)
+	assert.True(t, result.Truncated)
+
+	_, err = client.WhoCan(context.Background(), RBACQuery{Resource: "pods"}, 0)
+	require.Error(t, err)
+	_, err = client.WhoCan(context.Background(), RBACQuery{Verb: "get"}, 0)
+	require.Error(t, err)
+}
+
+func TestSubjectPermissions(t *testing.T) {
+	client := newRBACTestClient()
+
+	// Through the aggregated ClusterRole
+	result, err := client.SubjectPermissions(context.Background(),
+		RBACSubject{Kind: rbacv1.ServiceAccountKind, Name: "prometheus", Namespace: "monitoring"}, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []RBACPermission{{
+		Binding:   "ClusterRoleBinding/prometheus",
+		Role:      "ClusterRole/monitoring",
+		Verbs:     []string{"get", "list", "watch"},
+		APIGroups: []string{""},
+		Resources: []string{"pods", "pods/log"},
+	}}, result.Permissions)
+
+	// Directly, and through an implicit group
+	result, err = client.SubjectPermissions(context.Background(),
+		RBACSubject{Kind: rbacv1.ServiceAccountKind, Name: "deployer", Namespace: "shop"}, "shop", 0)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"system:authenticated", "system:serviceaccounts", "system:serviceaccounts:shop"}, result.Groups)
+	require.Len(t, result.Permissions, 2)
+	assert.Equal(t, "RoleBinding/shop/deployer", result.Permissions[0].Binding)
+	assert.Empty(t, result.Permissions[0].Via)
+	assert.Equal(t, "RoleBinding/shop/tls", result.Permissions[1].Binding)
+	assert.Equal(t, "system:serviceaccounts:shop", result.Permissions[1].Via)
+	assert.Equal(t, []string{"tls"}, result.Permissions[1].ResourceNames)
+
+	// Groups
+	result, err = client.SubjectPermissions(context.Background(),
+		RBACSubject{Kind: rbacv1.GroupKind, Name: "system:masters"}, "", 1)
+	require.NoError(t, err)
+	assert.Len(t, result.Permissions, 1)
+	assert.Equal(t, 2, result.Total)
+	assert.True(t, result.Truncated)
+
+	// A user bound in another namespace only
+	result, err = client.SubjectPermissions(context.Background(), RBACSubject{Kind: rbacv1.UserKind, Name: "bob"}, "shop", 0)
+	require.NoError(t, err)
+	assert.Empty(t, result.Permissions)
+}
+
+func TestSubjectPermissionsInvalid(t *testing.T) {
+	client := newRBACTestClient()
+
+	for _, subject := range []RBACSubject{
+		{Kind: "Robot", Name: "r2"},
+		{Kind: rbacv1.UserKind},
+		{Kind: rbacv1.ServiceAccountKind, Name: "default"},
+	} {
+		_, err := client.SubjectPermissions(context.Background(), subject, "", 0)
+		assert.Error(t, err, subject.String())
+	}
+}
+
+func TestParseRBACSubject(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected RBACSubject
+	}{
+		{value: "User/alice", expected: RBACSubject{Kind: rbacv1.UserKind, Name: "alice"}},
+		{value: "user/oidc:https://issuer/alice", expected: RBACSubject{Kind: rbacv1.UserKind, Name: "oidc:https://issuer/alice"}},
+		{value: "Group/system:masters", expected: RBACSubject{Kind: rbacv1.GroupKind, Name: "system:masters"}},
+		{value: "sa/shop/deployer", expected: RBACSubject{Kind: rbacv1.ServiceAccountKind, Namespace: "shop", Name: "deployer"}},
+	}
+	for _, tc := range testCases {
+		subject, err := ParseRBACSubject(tc.value)
+		require.NoError(t, err, tc.value)
+		assert.Equal(t, tc.expected, subject)
+	}
+
+	for _, value := range []string{"alice", "Robot/r2", "ServiceAccount/deployer"} {
+		_, err := ParseRBACSubject(value)
+		assert.Error(t, err, value)
+	}
+}
@@ -0,0 +1,567 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apiequality "k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+)
+
+// Kinds of workloads supported by the rollout operations.
+const (
+	RolloutKindDeployment  = "deployment"
+	RolloutKindStatefulSet = "statefulset"
+	RolloutKindDaemonSet   = "daemonset"
+)
+
+const (
+	// restartedAtAnnotation is the pod template annotation set to restart a
+	// workload, as `kubectl rollout restart` does.
+	restartedAtAnnotation = "kubectl.kubernetes.io/restartedAt"
+	// changeCauseAnnotation records the reason for a change of a workload.
+	changeCauseAnnotation = "kubernetes.io/change-cause"
+	// deploymentRevisionAnnotation holds the revision of a Deployment and of
+	// its ReplicaSets.
+	deploymentRevisionAnnotation = "deployment.kubernetes.io/revision"
+	// timedOutReason is the reason of the Progressing condition of a
+	// Deployment that exceeded its progress deadline.
+	timedOutReason = "ProgressDeadlineExceeded"
+)
+
+// RolloutRevision is an entry of the rollout history of a workload.
+type RolloutRevision struct {
+	Revision    int64     `json:"revision"`
+	Name        string    `json:"name"`
+	ChangeCause string    `json:"changeCause,omitempty"`
+	Images      []string  `json:"images,omitempty"`
+	Created     time.Time `json:"created"`
+	Current     bool      `json:"current,omitempty"`
+}
+
+// RolloutResult is the result of a rollout operation.
+type RolloutResult struct {
+	Kind      string            `json:"kind"`
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Action    string            `json:"action"`
+	Done      *bool             `json:"done,omitempty"`
+	Message   string            `json:"message"`
+	Revision  int64             `json:"revision,omitempty"`
+	History   []RolloutRevision `json:"history,omitempty"`
+}
+
+// NormalizeRolloutKind maps the kind, resource or short name of a workload
+// (e.g. "Deployment", "deployments", "deploy") to one of the RolloutKind
+// constants.
+func NormalizeRolloutKind(kind string) (string, error) {
+	switch strings.ToLower(kind) {
+	case "deployment", "deployments", "deploy":
+		return RolloutKindDeployment, nil
+	case "statefulset", "statefulsets", "sts":
+		return RolloutKindStatefulSet, nil
+	case "daemonset", "daemonsets", "ds":
+		return RolloutKindDaemonSet, nil
+	}
+	return "", fmt.Errorf("unsupported kind %q: must be deployment, statefulset or daemonset", kind)
+}
+
+// RolloutStatus reports the progress of the rollout of a workload, like
+// `kubectl rollout status` without watching.
+func (c *Client) RolloutStatus(ctx context.Context, kind, namespace, name string) (*RolloutResult, error) {
+	obj, err := c.getWorkload(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	done, message, err := WorkloadRolloutStatus(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &RolloutResult{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Action:    "status",
+		Done:      &done,
+		Message:   message,
+	}, nil
+}
+
+// WorkloadRolloutStatus computes whether the rollout of a Deployment,
+// StatefulSet or DaemonSet is complete, with a human-readable message, using
+// the same rules as `kubectl rollout status`. It returns an error when the
+// rollout failed (a Deployment exceeded its progress deadline) or cannot be
+// tracked (the update strategy is OnDelete).
+func WorkloadRolloutStatus(obj interface{}) (bool, string, error) {
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		return deploymentRolloutStatus(w)
+	case *appsv1.StatefulSet:
+		return statefulSetRolloutStatus(w)
+	case *appsv1.DaemonSet:
+		return daemonSetRolloutStatus(w)
+	}
+	return false, "", fmt.Errorf("unsupported workload type %T", obj)
+}
+
+func deploymentRolloutStatus(d *appsv1.Deployment) (bool, string, error) {
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, "Waiting for deployment spec update to be observed...", nil
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Reason == timedOutReason {
+			return false, "", fmt.Errorf("deployment %q exceeded its progress deadline", d.Name)
+		}
+	}
+
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	switch {
+	case d.Status.UpdatedReplicas < replicas:
+		return false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d out of %d new replicas have been updated...",
+			d.Name, d.Status.UpdatedReplicas, replicas), nil
+	case d.Status.Replicas > d.Status.UpdatedReplicas:
+		return false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d old replicas are pending termination...",
+			d.Name, d.Status.Replicas-d.Status.UpdatedReplicas), nil
+	case d.Status.AvailableReplicas < d.Status.UpdatedReplicas:
+		return false, fmt.Sprintf("Waiting for deployment %q rollout to finish: %d of %d updated replicas are available...",
+			d.Name, d.Status.AvailableReplicas, d.Status.UpdatedReplicas), nil
+	}
+	return true, fmt.Sprintf("deployment %q successfully rolled out", d.Name), nil
+}
+
+func statefulSetRolloutStatus(s *appsv1.StatefulSet) (bool, string, error) {
+	if s.Spec.UpdateStrategy.Type != appsv1.RollingUpdateStatefulSetStrategyType {
+		return false, "", fmt.Errorf("rollout status is only available for %s strategy type",
+			appsv1.RollingUpdateStatefulSetStrategyType)
+	}
+	if s.Status.ObservedGeneration == 0 || s.Generation > s.Status.ObservedGeneration {
+		return false, "Waiting for statefulset spec update to be observed...", nil
+	}
+
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	if s.Status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("Waiting for %d pods to be ready...", replicas-s.Status.ReadyReplicas), nil
+	}
+	if rollingUpdate := s.Spec.UpdateStrategy.RollingUpdate; rollingUpdate != nil && rollingUpdate.Partition != nil &&
+		*rollingUpdate.Partition > 0 {
+		if s.Status.UpdatedReplicas < replicas-*rollingUpdate.Partition {
+			return false, fmt.Sprintf("Waiting for partitioned roll out to finish: %d out of %d new pods have been updated...",
+				s.Status.UpdatedReplicas, replicas-*rollingUpdate.Partition), nil
+		}
+		return true, fmt.Sprintf("partitioned roll out complete: %d new pods have been updated...",
+			s.Status.UpdatedReplicas), nil
+	}
+	if s.Status.UpdateRevision != s.Status.CurrentRevision {
+		return false, fmt.Sprintf("waiting for statefulset rolling update to complete %d pods at revision %s...",
+			s.Status.UpdatedReplicas, s.Status.UpdateRevision), nil
+	}
+	return true, fmt.Sprintf("statefulset rolling update complete %d pods at revision %s...",
+		s.Status.CurrentReplicas, s.Status.CurrentRevision), nil
+}
+
+func daemonSetRolloutStatus(d *appsv1.DaemonSet) (bool, string, error) {
+	if d.Spec.UpdateStrategy.Type != appsv1.RollingUpdateDaemonSetStrategyType {
+		return false, "", fmt.Errorf("rollout status is only available for %s strategy type",
+			appsv1.RollingUpdateDaemonSetStrategyType)
+	}
+	if d.Generation > d.Status.ObservedGeneration {
+		return false, "Waiting for daemon set spec update to be observed...", nil
+	}
+	if d.Status.UpdatedNumberScheduled < d.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d out of %d new pods have been updated...",
+			d.Name, d.Status.UpdatedNumberScheduled, d.Status.DesiredNumberScheduled), nil
+	}
+	if d.Status.NumberAvailable < d.Status.DesiredNumberScheduled {
+		return false, fmt.Sprintf("Waiting for daemon set %q rollout to finish: %d of %d updated pods are available...",
+			d.Name, d.Status.NumberAvailable, d.Status.DesiredNumberScheduled), nil
+	}
+	return true, fmt.Sprintf("daemon set %q successfully rolled out", d.Name), nil
+}
+
+// RolloutRestart restarts the pods of a workload by setting the restartedAt
+// annotation of its pod template, like `kubectl rollout restart`.
+func (c *Client) RolloutRestart(ctx context.Context, kind, namespace, name string) (*RolloutResult, error) {
+	obj, err := c.getWorkload(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := obj.(*appsv1.Deployment); ok && d.Spec.Paused {
+		return nil, fmt.Errorf("can't restart paused deployment (run rollout resume first)")
+	}
+
+	now := time.Now().Format(time.RFC3339)
+	patch, err := json.Marshal(map[string]interface{}{
+		fieldSpec: map[string]interface{}{
+			"template": map[string]interface{}{
+				fieldMetadata: map[string]interface{}{
+					"annotations": map[string]string{restartedAtAnnotation: now},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to build restart patch: %w", err)
+	}
+	if err := c.patchWorkload(ctx, kind, namespace, name, k8stypes.StrategicMergePatchType, patch); err != nil {
+		return nil, fmt.Errorf("failed to restart %s: %w", kind, err)
+	}
+
+	return &RolloutResult{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Action:    "restart",
+		Message:   fmt.Sprintf("%s %q restarted at %s", kind, name, now),
+	}, nil
+}
+
+// RolloutPause pauses (paused true) or resumes (paused false) the rollout of
+// a Deployment. Other workloads do not support pausing.
+func (c *Client) RolloutPause(ctx context.Context, kind, namespace, name string, paused bool) (*RolloutResult, error) {
+	action := "resume"
+	if paused {
+		action = "pause"
+	}
+	if kind != RolloutKindDeployment {
+		return nil, fmt.Errorf("%s is not supported for %s, only for deployments", action, kind)
+	}
+
+	obj, err := c.getWorkload(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RolloutResult{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Action:    action,
+	}
+	if obj.(*appsv1.Deployment).Spec.Paused == paused {
+		result.Message = fmt.Sprintf("deployment %q is already %sd", name, action)
+		return result, nil
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"paused":%t}}`, paused))
+	if err := c.patchWorkload(ctx, kind, namespace, name, k8stypes.StrategicMergePatchType, patch); err != nil {
+		return nil, fmt.Errorf("failed to %s deployment: %w", action, err)
+	}
+	result.Message = fmt.Sprintf("deployment %q %sd", name, action)
+	return result, nil
+}
+
+// RolloutHistory lists the revisions of a workload, oldest first: the
+// ReplicaSets of a Deployment, or the ControllerRevisions of a StatefulSet or
+// DaemonSet.
+func (c *Client) RolloutHistory(ctx context.Context, kind, namespace, name string) (*RolloutResult, error) {
+	obj, err := c.getWorkload(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := c.workloadHistory(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RolloutResult{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Action:    "history",
+		Message:   fmt.Sprintf("%d revisions", len(history)),
+	}
+	for _, revision := range history {
+		result.History = append(result.History, revision.RolloutRevision)
+		if revision.Current {
+			result.Revision = revision.Revision
+		}
+	}
+	return result, nil
+}
+
+// RolloutUndo rolls a workload back to a previous revision, like `kubectl
+// rollout undo`. A toRevision of 0 selects the revision before the current
+// one.
+func (c *Client) RolloutUndo(
+	ctx context.Context,
+	kind, namespace, name string,
+	toRevision int64,
+) (*RolloutResult, error) {
+	obj, err := c.getWorkload(ctx, kind, namespace, name)
+	if err != nil {
+		return nil, err
+	}
+	if d, ok := obj.(*appsv1.Deployment); ok && d.Spec.Paused {
+		return nil, fmt.Errorf("can't roll back paused deployment (run rollout resume first)")
+	}
+
+	history, err := c.workloadHistory(ctx, obj)
+	if err != nil {
+		return nil, err
+	}
+	target, err := undoTarget(history, toRevision)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RolloutResult{
+		Kind:      kind,
+		Namespace: namespace,
+		Name:      name,
+		Action:    "undo",
+		Revision:  target.Revision,
+	}
+	if target.Current {
+		result.Message = fmt.Sprintf("skipped rollback (current template already matches revision %d)", target.Revision)
+		return result, nil
+	}
+
+	var patchType k8stypes.PatchType
+	var patch []byte
+	if target.template != nil {
+		// Deployments: replace the pod template with the one of the
+		// ReplicaSet, without the label added by the controller.
+		template := target.template.DeepCopy()
+		delete(template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+		patchType = k8stypes.JSONPatchType
+		patch, err = json.Marshal([]map[string]interface{}{
+			{"op": "replace", "path": "/spec/template", "value": template},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to build rollback patch: %w", err)
+		}
+	} else {
+		// ControllerRevisions store the template as a strategic merge patch.
+		patchType = k8stypes.StrategicMergePatchType
+		patch = target.data
+	}
+
+	if err := c.patchWorkload(ctx, kind, namespace, name, patchType, patch); err != nil {
+		return nil, fmt.Errorf("failed to roll back %s: %w", kind, err)
+	}
+	result.Message = fmt.Sprintf("%s %q rolled back to revision %d", kind, name, target.Revision)
+	return result, nil
+}
+
+// workloadRevision is a revision of a workload along with what is needed to
+// roll back to it.
+type workloadRevision struct {
+	RolloutRevision
+	// template is the pod template of a Deployment revision.
+	template *corev1.PodTemplateSpec
+	// data is the strategic merge patch of a ControllerRevision.
+	data []byte
+}
+
+// undoTarget selects the revision to roll back to.
+func undoTarget(history []workloadRevision, toRevision int64) (*workloadRevision, error) {
+	if toRevision > 0 {
+		for i := range history {
+			if history[i].Revision == toRevision {
+				return &history[i], nil
+			}
+		}
+		return nil, fmt.Errorf("unable to find specified revision %d in history", toRevision)
+	}
+
+	// The previous revision is the newest one that is not current.
+	for i := len(history) - 1; i >= 0; i-- {
+		if !history[i].Current {
+			return &history[i], nil
+		}
+	}
+	return nil, fmt.Errorf("no rollout history found")
+}
+
+// workloadHistory returns the revisions of a workload sorted by revision.
+func (c *Client) workloadHistory(ctx context.Context, obj interface{}) ([]workloadRevision, error) {
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+
+	var history []workloadRevision
+	switch w := obj.(type) {
+	case *appsv1.Deployment:
+		selector, err := metav1.LabelSelectorAsSelector(w.Spec.Selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deployment selector: %w", err)
+		}
+		replicaSets, err := clientset.AppsV1().ReplicaSets(w.Namespace).List(ctx, metav1.ListOptions{
+			LabelSelector: selector.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list replica sets: %w", err)
+		}
+		for i := range replicaSets.Items {
+			rs := &replicaSets.Items[i]
+			if !metav1.IsControlledBy(rs, w) {
+				continue
+			}
+			revision, _ := strconv.ParseInt(rs.Annotations[deploymentRevisionAnnotation], 10, 64)
+			history = append(history, workloadRevision{
+				RolloutRevision: RolloutRevision{
+					Revision:    revision,
+					Name:        rs.Name,
+					ChangeCause: rs.Annotations[changeCauseAnnotation],
+					Images:      containerImages(rs.Spec.Template.Spec.Containers),
+					Created:     rs.CreationTimestamp.Time,
+					Current:     equalIgnoreHash(&rs.Spec.Template, &w.Spec.Template),
+				},
+				template: &rs.Spec.Template,
+			})
+		}
+
+	case *appsv1.StatefulSet, *appsv1.DaemonSet:
+		owner := obj.(metav1.Object)
+		var selector *metav1.LabelSelector
+		currentRevision := ""
+		if sts, ok := obj.(*appsv1.StatefulSet); ok {
+			selector = sts.Spec.Selector
+			currentRevision = sts.Status.UpdateRevision
+		} else {
+			selector = obj.(*appsv1.DaemonSet).Spec.Selector
+		}
+		labelSelector, err := metav1.LabelSelectorAsSelector(selector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector: %w", err)
+		}
+		revisions, err := clientset.AppsV1().ControllerRevisions(owner.GetNamespace()).List(ctx, metav1.ListOptions{
+			LabelSelector: labelSelector.String(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list controller revisions: %w", err)
+		}
+		var newest int64
+		for i := range revisions.Items {
+			cr := &revisions.Items[i]
+			if !metav1.IsControlledBy(cr, owner) {
+				continue
+			}
+			newest = max(newest, cr.Revision)
+			history = append(history, workloadRevision{
+				RolloutRevision: RolloutRevision{
+					Revision:    cr.Revision,
+					Name:        cr.Name,
+					ChangeCause: cr.Annotations[changeCauseAnnotation],
+					Images:      controllerRevisionImages(cr.Data.Raw),
+					Created:     cr.CreationTimestamp.Time,
+					Current:     cr.Name == currentRevision,
+				},
+				data: cr.Data.Raw,
+			})
+		}
+		// DaemonSets don't report their current revision: it is the newest.
+		if currentRevision == "" {
+			for i := range history {
+				history[i].Current = history[i].Revision == newest
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported workload type %T", obj)
+	}
+
+	sort.Slice(history, func(i, j int) bool { return history[i].Revision < history[j].Revision })
+	return history, nil
+}
+
+// equalIgnoreHash reports whether two pod templates are equal, ignoring the
+// pod-template-hash label the Deployment controller adds to ReplicaSets.
+func equalIgnoreHash(a, b *corev1.PodTemplateSpec) bool {
+	a, b = a.DeepCopy(), b.DeepCopy()
+	delete(a.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	delete(b.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+	return apiequality.Semantic.DeepEqual(a, b)
+}
+
+// containerImages returns the images of containers.
+func containerImages(containers []corev1.Container) []string {
+	images := make([]string, 0, len(containers))
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+// controllerRevisionImages extracts the container images from the data of a
+// ControllerRevision, which holds a patch of the pod template.
+func controllerRevisionImages(data []byte) []string {
+	var revision struct {
+		Spec struct {
+			Template corev1.PodTemplateSpec `json:"template"`
+		} `json:"spec"`
+	}
+	if err := json.Unmarshal(data, &revision); err != nil {
+		return nil
+	}
+	return containerImages(revision.Spec.Template.Spec.Containers)
+}
+
+// getWorkload gets a Deployment, StatefulSet or DaemonSet.
+func (c *Client) getWorkload(ctx context.Context, kind, namespace, name string) (interface{}, error) {
+	if name == "" {
+		return nil, fmt.Errorf("resource name cannot be empty")
+	}
+
+	c.mu.RLock()
+	apps := c.clientset.AppsV1()
+	c.mu.RUnlock()
+
+	var obj interface{}
+	var err error
+	switch kind {
+	case RolloutKindDeployment:
+		obj, err = apps.Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	case RolloutKindStatefulSet:
+		obj, err = apps.StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	case RolloutKindDaemonSet:
+		obj, err = apps.DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	default:
+		return nil, fmt.Errorf("unsupported kind %q", kind)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", kind, err)
+	}
+	return obj, nil
+}
+
+// patchWorkload patches a Deployment, StatefulSet or DaemonSet.
+func (c *Client) patchWorkload(
+	ctx context.Context,
+	kind, namespace, name string,
+	patchType k8stypes.PatchType,
+	patch []byte,
+) error {
+	c.mu.RLock()
+	apps := c.clientset.AppsV1()
+	c.mu.RUnlock()
+
+	var err error
+	switch kind {
+	case RolloutKindDeployment:
+		_, err = apps.Deployments(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	case RolloutKindStatefulSet:
+		_, err = apps.StatefulSets(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	case RolloutKindDaemonSet:
+		_, err = apps.DaemonSets(namespace).Patch(ctx, name, patchType, patch, metav1.PatchOptions{})
+	default:
+		err = fmt.Errorf("unsupported kind %q", kind)
+	}
+	return err
+}
@@ -0,0 +1,307 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func podTemplate(image string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"app": "web"}},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "web", Image: image}}},
+	}
+}
+
+// rolloutTestObjects returns a Deployment "web" at revision 2 (image v2) with
+// the ReplicaSets of revisions 1 and 2.
+func rolloutTestObjects() []runtime.Object {
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "web",
+			Namespace:   "default",
+			UID:         types.UID("web-uid"),
+			Annotations: map[string]string{deploymentRevisionAnnotation: "2"},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: ptr.To(int32(2)),
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+			Template: podTemplate("web:v2"),
+		},
+	}
+
+	replicaSet := func(name, revision, image, cause string) *appsv1.ReplicaSet {
+		template := podTemplate(image)
+		template.Labels[appsv1.DefaultDeploymentUniqueLabelKey] = name
+		controller := true
+		return &appsv1.ReplicaSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web-" + name,
+				Namespace: "default",
+				Labels:    template.Labels,
+				Annotations: map[string]string{
+					deploymentRevisionAnnotation: revision,
+					changeCauseAnnotation:        cause,
+				},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1", Kind: "Deployment", Name: "web", UID: "web-uid", Controller: &controller,
+				}},
+			},
+			Spec: appsv1.ReplicaSetSpec{Template: template},
+		}
+	}
+
+	return []runtime.Object{
+		deployment,
+		replicaSet("aaa", "1", "web:v1", "initial"),
+		replicaSet("bbb", "2", "web:v2", "bump to v2"),
+	}
+}
+
+func newRolloutTestClient(objects ...runtime.Object) (*Client, *kubefake.Clientset) {
+	fakeClientset := kubefake.NewSimpleClientset(objects...)
+	client := &Client{}
+	client.SetClientset(fakeClientset)
+	return client, fakeClientset
+}
+
+func TestNormalizeRolloutKind(t *testing.T) {
+	for input, expected := range map[string]string{
+		"Deployment":   RolloutKindDeployment,
+		"deploy":       RolloutKindDeployment,
+		"statefulsets": RolloutKindStatefulSet,
+		"ds":           RolloutKindDaemonSet,
+	} {
+		kind, err := NormalizeRolloutKind(input)
+		require.NoError(t, err)
+		assert.Equal(t, expected, kind)
+	}
+
+	_, err := NormalizeRolloutKind("cronjob")
+	assert.Error(t, err)
+}
+
+func TestDeploymentRolloutStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		status   appsv1.DeploymentStatus
+		done     bool
+		message  string
+		errorMsg string
+	}{
+		{
+			name:    "Not observed",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 1},
+			message: "spec update to be observed",
+		},
+		{
+			name:    "Updating",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 1, Replicas: 3},
+			message: "1 out of 2 new replicas have been updated",
+		},
+		{
+			name:    "Terminating old replicas",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, Replicas: 3},
+			message: "1 old replicas are pending termination",
+		},
+		{
+			name:    "Becoming available",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, Replicas: 2, AvailableReplicas: 1},
+			message: "1 of 2 updated replicas are available",
+		},
+		{
+			name:    "Complete",
+			status:  appsv1.DeploymentStatus{ObservedGeneration: 2, UpdatedReplicas: 2, Replicas: 2, AvailableReplicas: 2},
+			done:    true,
+			message: "successfully rolled out",
+		},
+		{
+			name: "Deadline exceeded",
+			status: appsv1.DeploymentStatus{ObservedGeneration: 2, Conditions: []appsv1.DeploymentCondition{{
+				Type: appsv1.DeploymentProgressing, Reason: timedOutReason,
+			}}},
+			errorMsg: "exceeded its progress deadline",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			d := &appsv1.Deployment{
+				ObjectMeta: metav1.ObjectMeta{Name: "web", Generation: 2},
+				Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(2))},
+				Status:     tc.status,
+			}
+			done, message, err := WorkloadRolloutStatus(d)
+			if tc.errorMsg != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tc.errorMsg)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tc.done, done)
+			assert.Contains(t, message, tc.message)
+		})
+	}
+}
+
+func TestStatefulSetAndDaemonSetRolloutStatus(t *testing.T) {
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Generation: 1},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:       ptr.To(int32(3)),
+			UpdateStrategy: appsv1.StatefulSetUpdateStrategy{Type: appsv1.RollingUpdateStatefulSetStrategyType},
+		},
+		Status: appsv1.StatefulSetStatus{
+			ObservedGeneration: 1, ReadyReplicas: 3, UpdatedReplicas: 1,
+			CurrentRevision: "db-1", UpdateRevision: "db-2",
+		},
+	}
+	done, message, err := WorkloadRolloutStatus(sts)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Contains(t, message, "waiting for statefulset rolling update to complete")
+
+	sts.Status.CurrentRevision = "db-2"
+	done, _, err = WorkloadRolloutStatus(sts)
+	require.NoError(t, err)
+	assert.True(t, done)
+
+	sts.Spec.UpdateStrategy.Type = appsv1.OnDeleteStatefulSetStrategyType
+	_, _, err = WorkloadRolloutStatus(sts)
+	assert.Error(t, err)
+
+	ds := &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "agent", Generation: 1},
+		Spec: appsv1.DaemonSetSpec{
+			UpdateStrategy: appsv1.DaemonSetUpdateStrategy{Type: appsv1.RollingUpdateDaemonSetStrategyType},
+		},
+		Status: appsv1.DaemonSetStatus{
+			ObservedGeneration: 1, DesiredNumberScheduled: 3, UpdatedNumberScheduled: 3, NumberAvailable: 2,
+		},
+	}
+	done, message, err = WorkloadRolloutStatus(ds)
+	require.NoError(t, err)
+	assert.False(t, done)
+	assert.Contains(t, message, "2 of 3 updated pods are available")
+}
+
+func TestRolloutRestart(t *testing.T) {
+	client, fakeClientset := newRolloutTestClient(rolloutTestObjects()...)
+
+	result, err := client.RolloutRestart(context.Background(), RolloutKindDeployment, "default", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "restart", result.Action)
+
+	d, err := fakeClientset.AppsV1().Deployments("default").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEmpty(t, d.Spec.Template.Annotations[restartedAtAnnotation])
+	assert.Equal(t, "web:v2", d.Spec.Template.Spec.Containers[0].Image, "the rest of the template is kept")
+}
+
+func TestRolloutPauseResume(t *testing.T) {
+	client, fakeClientset := newRolloutTestClient(rolloutTestObjects()...)
+	ctx := context.Background()
+
+	result, err := client.RolloutPause(ctx, RolloutKindDeployment, "default", "web", true)
+	require.NoError(t, err)
+	assert.Contains(t, result.Message, "paused")
+	d, err := fakeClientset.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.True(t, d.Spec.Paused)
+
+	_, err = client.RolloutRestart(ctx, RolloutKindDeployment, "default", "web")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "paused")
+
+	result, err = client.RolloutPause(ctx, RolloutKindDeployment, "default", "web", false)
+	require.NoError(t, err)
+	assert.Contains(t, result.Message, "resumed")
+	d, err = fakeClientset.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, d.Spec.Paused)
+
+	_, err = client.RolloutPause(ctx, RolloutKindStatefulSet, "default", "db", true)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "only for deployments")
+}
+
+func TestRolloutHistoryAndUndo(t *testing.T) {
+	client, fakeClientset := newRolloutTestClient(rolloutTestObjects()...)
+	ctx := context.Background()
+
+	history, err := client.RolloutHistory(ctx, RolloutKindDeployment, "default", "web")
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.Equal(t, int64(1), history.History[0].Revision)
+	assert.Equal(t, "initial", history.History[0].ChangeCause)
+	assert.Equal(t, []string{"web:v1"}, history.History[0].Images)
+	assert.True(t, history.History[1].Current)
+	assert.Equal(t, int64(2), history.Revision)
+
+	// Rolling back to the current revision is a no-op.
+	result, err := client.RolloutUndo(ctx, RolloutKindDeployment, "default", "web", 2)
+	require.NoError(t, err)
+	assert.Contains(t, result.Message, "skipped rollback")
+
+	_, err = client.RolloutUndo(ctx, RolloutKindDeployment, "default", "web", 7)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "unable to find specified revision 7")
+
+	// The default target is the previous revision.
+	result, err = client.RolloutUndo(ctx, RolloutKindDeployment, "default", "web", 0)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Revision)
+
+	d, err := fakeClientset.AppsV1().Deployments("default").Get(ctx, "web", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "web:v1", d.Spec.Template.Spec.Containers[0].Image)
+	assert.NotContains(t, d.Spec.Template.Labels, appsv1.DefaultDeploymentUniqueLabelKey)
+}
+
+func TestStatefulSetHistoryAndUndo(t *testing.T) {
+	controller := true
+	owner := []metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "StatefulSet", Name: "db", UID: "db-uid", Controller: &controller}}
+	revision := func(name string, number int64, image string) *appsv1.ControllerRevision {
+		return &appsv1.ControllerRevision{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: name, Namespace: "default", Labels: map[string]string{"app": "db"}, OwnerReferences: owner,
+			},
+			Revision: number,
+			Data: runtime.RawExtension{Raw: []byte(`{"spec":{"template":{"spec":{"containers":[` +
+				`{"name":"db","image":"` + image + `"}]}}}}`)},
+		}
+	}
+	sts := &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "default", UID: "db-uid"},
+		Spec: appsv1.StatefulSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+			Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "db", Image: "db:2"}}}},
+		},
+		Status: appsv1.StatefulSetStatus{UpdateRevision: "db-2"},
+	}
+	client, fakeClientset := newRolloutTestClient(sts, revision("db-1", 1, "db:1"), revision("db-2", 2, "db:2"))
+	ctx := context.Background()
+
+	history, err := client.RolloutHistory(ctx, RolloutKindStatefulSet, "default", "db")
+	require.NoError(t, err)
+	require.Len(t, history.History, 2)
+	assert.Equal(t, []string{"db:1"}, history.History[0].Images)
+	assert.Equal(t, int64(2), history.Revision)
+
+	result, err := client.RolloutUndo(ctx, RolloutKindStatefulSet, "default", "db", 1)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), result.Revision)
+
+	updated, err := fakeClientset.AppsV1().StatefulSets("default").Get(ctx, "db", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "db:1", updated.Spec.Template.Spec.Containers[0].Image)
+}
@@ -0,0 +1,271 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// MaxScaleWaitTimeout is the maximum time to wait for a scaled resource
+	// to become ready.
+	MaxScaleWaitTimeout = 5 * time.Minute
+	// defaultScaleWaitTimeout is used when waiting without a timeout.
+	defaultScaleWaitTimeout = 1 * time.Minute
+	// subresourceScale is the scale subresource.
+	subresourceScale = "scale"
+)
+
+// scalePollInterval is how often a scaled resource is polled while waiting
+// for it to become ready. It is a variable so that tests can shorten it.
+var scalePollInterval = 2 * time.Second
+
+// ScaleOptions configures a ScaleResource call. At most one of Replicas and
+// Delta may be set; when neither is, the current scale is returned unchanged.
+type ScaleOptions struct {
+	// Replicas is the desired absolute number of replicas.
+	Replicas *int64
+	// Delta is added to the current number of replicas.
+	Delta int64
+	// Wait waits until the resource has the desired number of ready
+	// replicas.
+	Wait bool
+	// Timeout bounds the wait. Defaults to 1 minute and is capped at
+	// MaxScaleWaitTimeout.
+	Timeout time.Duration
+	// OnProgress, if set, is called with a short message on every poll
+	// while waiting.
+	OnProgress func(message string)
+}
+
+// ScaleResult is the result of a ScaleResource call.
+type ScaleResult struct {
+	Group            string `json:"group"`
+	Version          string `json:"version"`
+	Resource         string `json:"resource"`
+	Namespace        string `json:"namespace,omitempty"`
+	Name             string `json:"name"`
+	PreviousReplicas int64  `json:"previousReplicas"`
+	Replicas         int64  `json:"replicas"`
+	CurrentReplicas  int64  `json:"currentReplicas"`
+	ReadyReplicas    *int64 `json:"readyReplicas,omitempty"`
+	Selector         string `json:"selector,omitempty"`
+	Waited           bool   `json:"waited,omitempty"`
+	Ready            bool   `json:"ready"`
+	Message          string `json:"message,omitempty"`
+}
+
+// ScalableResources returns the resources served with a scale subresource,
+// as found in the subresource entries of ListAPIResources.
+func (c *Client) ScalableResources(ctx context.Context) ([]schema.GroupVersionResource, error) {
+	lists, err := c.ListAPIResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var scalable []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, apiResource := range list.APIResources {
+			if resource, ok := strings.CutSuffix(apiResource.Name, "/"+subresourceScale); ok {
+				scalable = append(scalable, gv.WithResource(resource))
+			}
+		}
+	}
+	return scalable, nil
+}
+
+// ScaleResource reads and updates the scale subresource of a resource, for
+// Deployments, StatefulSets, ReplicaSets and any custom resource exposing
+// scale. The resource must be listed by ScalableResources. An empty
+// namespace addresses a cluster-scoped resource.
+func (c *Client) ScaleResource(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	namespace, name string,
+	opts ScaleOptions,
+) (*ScaleResult, error) {
+	if name == "" {
+		return nil, fmt.Errorf("resource name cannot be empty")
+	}
+	if opts.Replicas != nil && opts.Delta != 0 {
+		return nil, fmt.Errorf("replicas and delta are mutually exclusive")
+	}
+
+	scalable, err := c.ScalableResources(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !containsGVR(scalable, gvr) {
+		return nil, fmt.Errorf("resource %s does not have a scale subresource", gvr.String())
+	}
+
+	c.mu.RLock()
+	client := resourceInterface(c.dynamicClient, gvr, namespace)
+	c.mu.RUnlock()
+
+	scale, err := client.Get(ctx, name, metav1.GetOptions{}, subresourceScale)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get scale: %w", err)
+	}
+	previous, _, _ := unstructured.NestedInt64(scale.Object, fieldSpec, "replicas")
+
+	desired, err := desiredReplicas(previous, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if desired != previous {
+		// Update rather than patch, so that the resourceVersion read above
+		// guards a relative change against concurrent scaling.
+		if err := unstructured.SetNestedField(scale.Object, desired, fieldSpec, "replicas"); err != nil {
+			return nil, fmt.Errorf("failed to set replicas: %w", err)
+		}
+		scale, err = client.Update(ctx, scale, metav1.UpdateOptions{}, subresourceScale)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update scale: %w", err)
+		}
+	}
+
+	result := &ScaleResult{
+		Group:            gvr.Group,
+		Version:          gvr.Version,
+		Resource:         gvr.Resource,
+		Namespace:        namespace,
+		Name:             name,
+		PreviousReplicas: previous,
+		Replicas:         desired,
+	}
+	result.Selector, _, _ = unstructured.NestedString(scale.Object, fieldStatus, "selector")
+
+	if !opts.Wait {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get resource: %w", err)
+		}
+		result.Ready, result.Message = scaleReadiness(gvr, obj, scale, desired, result)
+		return result, nil
+	}
+
+	if err := waitForScale(ctx, client, gvr, name, desired, opts, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// desiredReplicas returns the number of replicas opts asks for, given the
+// previous number of replicas.
+func desiredReplicas(previous int64, opts ScaleOptions) (int64, error) {
+	desired := previous
+	switch {
+	case opts.Replicas != nil:
+		desired = *opts.Replicas
+	case opts.Delta != 0:
+		desired = previous + opts.Delta
+	}
+	if desired < 0 {
+		return 0, fmt.Errorf("cannot scale to %d replicas", desired)
+	}
+	return desired, nil
+}
+
+// waitForScale polls the resource name until it has the desired number of
+// ready replicas or the timeout of opts expires, filling in the readiness of
+// result. A timeout is reported in the message of result rather than as an
+// error.
+func waitForScale(
+	ctx context.Context,
+	client dynamic.ResourceInterface,
+	gvr schema.GroupVersionResource,
+	name string,
+	desired int64,
+	opts ScaleOptions,
+	result *ScaleResult,
+) error {
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = defaultScaleWaitTimeout
+	}
+	timeout = min(timeout, MaxScaleWaitTimeout)
+
+	result.Waited = true
+	err := wait.PollUntilContextTimeout(ctx, scalePollInterval, timeout, true, func(ctx context.Context) (bool, error) {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return false, fmt.Errorf("failed to get resource: %w", err)
+		}
+		currentScale, err := client.Get(ctx, name, metav1.GetOptions{}, subresourceScale)
+		if err != nil {
+			return false, fmt.Errorf("failed to get scale: %w", err)
+		}
+		result.Ready, result.Message = scaleReadiness(gvr, obj, currentScale, desired, result)
+		if opts.OnProgress != nil {
+			opts.OnProgress(result.Message)
+		}
+		return result.Ready, nil
+	})
+	if err != nil && !wait.Interrupted(err) {
+		return err
+	}
+	if err != nil {
+		result.Message = fmt.Sprintf("timed out after %s: %s", timeout, result.Message)
+	}
+	return nil
+}
+
+// scaleReadiness reports whether a scaled resource has reached the desired
+// number of ready replicas and describes its state, filling in the current
+// and ready replica counts of result. Built-in workloads report
+// readyReplicas; custom resources are considered ready once the replica
+// count of their scale subresource matches.
+func scaleReadiness(
+	gvr schema.GroupVersionResource,
+	obj, scale *unstructured.Unstructured,
+	desired int64,
+	result *ScaleResult,
+) (bool, string) {
+	current, _, _ := unstructured.NestedInt64(scale.Object, fieldStatus, "replicas")
+	result.CurrentReplicas = current
+
+	if generation := obj.GetGeneration(); generation > 0 {
+		observed, found, _ := unstructured.NestedInt64(obj.Object, fieldStatus, "observedGeneration")
+		if found && observed < generation {
+			return false, "waiting for the controller to observe the new scale"
+		}
+	}
+
+	ready, hasReady, _ := unstructured.NestedInt64(obj.Object, fieldStatus, "readyReplicas")
+	// Built-in workloads omit readyReplicas when it is zero.
+	if hasReady || gvr.Group == "apps" {
+		result.ReadyReplicas = &ready
+		if current == desired && ready == desired {
+			return true, fmt.Sprintf("%d/%d replicas ready", ready, desired)
+		}
+		return false, fmt.Sprintf("%d/%d replicas ready, %d current", ready, desired, current)
+	}
+
+	if current == desired {
+		return true, fmt.Sprintf("%d/%d replicas", current, desired)
+	}
+	return false, fmt.Sprintf("%d/%d replicas", current, desired)
+}
+
+// containsGVR reports whether gvrs contains gvr.
+func containsGVR(gvrs []schema.GroupVersionResource, gvr schema.GroupVersionResource) bool {
+	for _, candidate := range gvrs {
+		if candidate == gvr {
+			return true
+		}
+	}
+	return false
+}
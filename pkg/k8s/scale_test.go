@@ -0,0 +1,193 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+var deploymentsGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+// newScaleTestClient returns a client serving a Deployment "web" with the
+// given replica counts. The fake dynamic client translates the scale
+// subresource to and from the Deployment. When controller is true, status
+// follows spec immediately, as if the pods became ready at once.
+func newScaleTestClient(t *testing.T, replicas, ready int64, controller bool) (*Client, *dynamicfake.FakeDynamicClient) {
+	t.Helper()
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "generation": int64(1)},
+		"spec":       map[string]interface{}{"replicas": replicas},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(1),
+			"replicas":           replicas,
+			"readyReplicas":      ready,
+		},
+	}}
+	fakeDynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), deployment)
+	tracker := fakeDynamic.Tracker()
+
+	getDeployment := func() *unstructured.Unstructured {
+		obj, err := tracker.Get(deploymentsGVR, "default", "web")
+		require.NoError(t, err)
+		return obj.(*unstructured.Unstructured)
+	}
+
+	fakeDynamic.PrependReactor("get", "deployments", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != subresourceScale {
+			return false, nil, nil
+		}
+		d := getDeployment()
+		specReplicas, _, _ := unstructured.NestedInt64(d.Object, "spec", "replicas")
+		statusReplicas, _, _ := unstructured.NestedInt64(d.Object, "status", "replicas")
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "resourceVersion": "1"},
+			"spec":       map[string]interface{}{"replicas": specReplicas},
+			"status":     map[string]interface{}{"replicas": statusReplicas, "selector": "app=web"},
+		}}, nil
+	})
+	fakeDynamic.PrependReactor("update", "deployments", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != subresourceScale {
+			return false, nil, nil
+		}
+		scale := action.(ktesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		desired, _, _ := unstructured.NestedInt64(scale.Object, "spec", "replicas")
+
+		d := getDeployment().DeepCopy()
+		_ = unstructured.SetNestedField(d.Object, desired, "spec", "replicas")
+		d.SetGeneration(d.GetGeneration() + 1)
+		if controller {
+			_ = unstructured.SetNestedField(d.Object, d.GetGeneration(), "status", "observedGeneration")
+			_ = unstructured.SetNestedField(d.Object, desired, "status", "replicas")
+			_ = unstructured.SetNestedField(d.Object, desired, "status", "readyReplicas")
+		}
+		require.NoError(t, tracker.Update(deploymentsGVR, d, "default"))
+		return true, scale, nil
+	})
+
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true},
+				{Name: "deployments/scale", Kind: "Scale", Group: "autoscaling", Version: "v1", Namespaced: true},
+				{Name: "daemonsets", Kind: "DaemonSet", Namespaced: true},
+			},
+		},
+	}
+
+	client := &Client{}
+	client.SetDynamicClient(fakeDynamic)
+	client.SetDiscoveryClient(fakeDiscovery)
+	return client, fakeDynamic
+}
+
+func TestScalableResources(t *testing.T) {
+	client, _ := newScaleTestClient(t, 1, 1, false)
+
+	scalable, err := client.ScalableResources(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []schema.GroupVersionResource{deploymentsGVR}, scalable)
+}
+
+func TestScaleResource(t *testing.T) {
+	testCases := []struct {
+		name     string
+		opts     ScaleOptions
+		expected int64
+	}{
+		{name: "Absolute", opts: ScaleOptions{Replicas: ptr.To(int64(5))}, expected: 5},
+		{name: "Delta up", opts: ScaleOptions{Delta: 2}, expected: 4},
+		{name: "Delta down", opts: ScaleOptions{Delta: -2}, expected: 0},
+		{name: "Read only", opts: ScaleOptions{}, expected: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, fakeDynamic := newScaleTestClient(t, 2, 2, false)
+
+			result, err := client.ScaleResource(context.Background(), deploymentsGVR, "default", "web", tc.opts)
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), result.PreviousReplicas)
+			assert.Equal(t, tc.expected, result.Replicas)
+			assert.Equal(t, "app=web", result.Selector)
+
+			d, err := fakeDynamic.Resource(deploymentsGVR).Namespace("default").Get(
+				context.Background(), "web", metav1.GetOptions{})
+			require.NoError(t, err)
+			replicas, _, _ := unstructured.NestedInt64(d.Object, "spec", "replicas")
+			assert.Equal(t, tc.expected, replicas)
+		})
+	}
+}
+
+func TestScaleResourceWait(t *testing.T) {
+	origInterval := scalePollInterval
+	scalePollInterval = 10 * time.Millisecond
+	defer func() { scalePollInterval = origInterval }()
+
+	t.Run("Becomes ready", func(t *testing.T) {
+		client, _ := newScaleTestClient(t, 1, 1, true)
+
+		var progress []string
+		result, err := client.ScaleResource(context.Background(), deploymentsGVR, "default", "web", ScaleOptions{
+			Replicas:   ptr.To(int64(3)),
+			Wait:       true,
+			OnProgress: func(message string) { progress = append(progress, message) },
+		})
+		require.NoError(t, err)
+		assert.True(t, result.Waited)
+		assert.True(t, result.Ready)
+		require.NotNil(t, result.ReadyReplicas)
+		assert.Equal(t, int64(3), *result.ReadyReplicas)
+		assert.NotEmpty(t, progress)
+	})
+
+	t.Run("Times out", func(t *testing.T) {
+		client, _ := newScaleTestClient(t, 1, 1, false)
+
+		result, err := client.ScaleResource(context.Background(), deploymentsGVR, "default", "web", ScaleOptions{
+			Replicas: ptr.To(int64(3)),
+			Wait:     true,
+			Timeout:  50 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		assert.False(t, result.Ready)
+		assert.Contains(t, result.Message, "timed out")
+	})
+}
+
+func TestScaleResourceErrors(t *testing.T) {
+	client, _ := newScaleTestClient(t, 1, 1, false)
+
+	_, err := client.ScaleResource(context.Background(),
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "daemonsets"},
+		"default", "agent", ScaleOptions{Replicas: ptr.To(int64(2))})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not have a scale subresource")
+
+	_, err = client.ScaleResource(context.Background(), deploymentsGVR, "default", "web", ScaleOptions{Delta: -5})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot scale to -4 replicas")
+
+	_, err = client.ScaleResource(context.Background(), deploymentsGVR, "default", "web",
+		ScaleOptions{Replicas: ptr.To(int64(1)), Delta: 1})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "mutually exclusive")
+}
@@ -0,0 +1,760 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+)
+
+const (
+	// MaxSchedulingNodes is the largest number of nodes listed by WhyPending.
+	MaxSchedulingNodes = 100
+	// maxSchedulingEvents is the largest number of FailedScheduling events
+	// listed by WhyPending.
+	maxSchedulingEvents = 5
+	// defaultSchedulerName is the name of the default scheduler.
+	defaultSchedulerName = "default-scheduler"
+)
+
+const (
+	// SchedulingReasonNodeName is a node other than the one the pod requests.
+	SchedulingReasonNodeName = "NodeName"
+	// SchedulingReasonUnschedulable is a cordoned node.
+	SchedulingReasonUnschedulable = "NodeUnschedulable"
+	// SchedulingReasonNodeSelector is a node without the labels of the node
+	// selector of the pod.
+	SchedulingReasonNodeSelector = "NodeSelector"
+	// SchedulingReasonNodeAffinity is a node not matching the required node
+	// affinity of the pod.
+	SchedulingReasonNodeAffinity = "NodeAffinity"
+	// SchedulingReasonTaint is a node with a taint the pod does not tolerate.
+	SchedulingReasonTaint = "Taint"
+	// SchedulingReasonResources is a node without enough free resources for
+	// the requests of the pod.
+	SchedulingReasonResources = "InsufficientResources"
+	// SchedulingReasonPodAffinity is a node not in the topology domain of the
+	// pods the pod requires affinity with.
+	SchedulingReasonPodAffinity = "PodAffinity"
+	// SchedulingReasonPodAntiAffinity is a node in the topology domain of pods
+	// the pod has required anti-affinity with, or that have required
+	// anti-affinity with the pod.
+	SchedulingReasonPodAntiAffinity = "PodAntiAffinity"
+	// SchedulingReasonVolume is a node a volume of the pod cannot be attached
+	// to, because of the node affinity of its PersistentVolume or the allowed
+	// topologies of its StorageClass.
+	SchedulingReasonVolume = "VolumeTopology"
+)
+
+// SchedulingDiagnosis is the result of WhyPending.
+type SchedulingDiagnosis struct {
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	Phase     string `json:"phase"`
+	// NodeName is the node the pod is scheduled to, if any.
+	NodeName string `json:"nodeName,omitempty"`
+	// Condition is the message of the PodScheduled condition of the pod.
+	Condition string `json:"condition,omitempty"`
+	// Events are the most recent FailedScheduling events of the pod.
+	Events []SchedulingEvent `json:"events,omitempty"`
+	// Requests are the effective requests of the pod, which nodes must have
+	// free.
+	Requests map[string]string `json:"requests,omitempty"`
+	// PodIssues are problems preventing the pod from scheduling on any node.
+	PodIssues []string `json:"podIssues,omitempty"`
+	// Nodes are the verdicts of the nodes, those fitting the pod first.
+	Nodes      []NodeVerdict `json:"nodes"`
+	FitNodes   int           `json:"fitNodes"`
+	TotalNodes int           `json:"totalNodes"`
+	Truncated  bool          `json:"truncated,omitempty"`
+	// ReasonCounts counts the nodes rejecting the pod for each reason.
+	ReasonCounts map[string]int `json:"reasonCounts,omitempty"`
+	// Notes are remarks on the limits of the diagnosis.
+	Notes []string `json:"notes,omitempty"`
+}
+
+// SchedulingEvent is a FailedScheduling event of a pod.
+type SchedulingEvent struct {
+	Message  string    `json:"message"`
+	Count    int32     `json:"count,omitempty"`
+	LastSeen time.Time `json:"lastSeen"`
+}
+
+// NodeVerdict is whether a node fits a pod.
+type NodeVerdict struct {
+	Name    string             `json:"name"`
+	Fits    bool               `json:"fits"`
+	Reasons []SchedulingReason `json:"reasons,omitempty"`
+}
+
+// SchedulingReason is why a node does not fit a pod.
+type SchedulingReason struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// WhyPending explains why a pod cannot be scheduled. Besides the
+// FailedScheduling events and the PodScheduled condition of the pod, it
+// evaluates each node independently of the scheduler against the node name,
+// node selector and required node affinity of the pod, the taints of the
+// node, the resources the pod requests versus those allocatable and not
+// requested by the pods of the node, required pod affinity and
+// anti-affinity, and the topology of the volumes of the pod. Problems
+// affecting all nodes, such as unbound PersistentVolumeClaims and
+// scheduling gates, are reported separately.
+func (c *Client) WhyPending(ctx context.Context, namespace, name string) (*SchedulingDiagnosis, error) {
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("namespace and name cannot be empty")
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	pod, err := clientset.CoreV1().Pods(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pod: %w", err)
+	}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	diagnosis := &SchedulingDiagnosis{
+		Namespace:  pod.Namespace,
+		Name:       pod.Name,
+		Phase:      string(pod.Status.Phase),
+		NodeName:   pod.Spec.NodeName,
+		Nodes:      []NodeVerdict{},
+		TotalNodes: len(nodes.Items),
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodScheduled && condition.Status != corev1.ConditionTrue {
+			diagnosis.Condition = strings.TrimSpace(condition.Reason + ": " + condition.Message)
+		}
+	}
+	if diagnosis.Events, err = schedulingEvents(ctx, clientset, pod); err != nil {
+		return nil, err
+	}
+
+	e, err := newSchedulingEvaluator(ctx, clientset, pod, nodes.Items, pods.Items)
+	if err != nil {
+		return nil, err
+	}
+	diagnosis.Requests = formatRequests(e.requests)
+	diagnosis.PodIssues = e.podIssues
+	diagnosis.Notes = e.notes()
+	e.addVerdicts(diagnosis, nodes.Items)
+	return diagnosis, nil
+}
+
+// schedulingEvents returns the most recent FailedScheduling events of pod.
+func schedulingEvents(ctx context.Context, clientset kubernetes.Interface, pod *corev1.Pod) ([]SchedulingEvent, error) {
+	events, err := clientset.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: "involvedObject.kind=Pod,involvedObject.name=" + pod.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events: %w", err)
+	}
+
+	var result []SchedulingEvent
+	for _, event := range events.Items {
+		if event.InvolvedObject.Kind != kindPod || event.InvolvedObject.Name != pod.Name ||
+			event.Reason != "FailedScheduling" {
+			continue
+		}
+		result = append(result, SchedulingEvent{
+			Message:  event.Message,
+			Count:    event.Count,
+			LastSeen: eventLastSeen(&event),
+		})
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].LastSeen.After(result[j].LastSeen) })
+	return result[:min(len(result), maxSchedulingEvents)], nil
+}
+
+// schedulingEvaluator evaluates whether nodes fit a pod.
+type schedulingEvaluator struct {
+	pod   *corev1.Pod
+	nodes map[string]*corev1.Node
+	// requests are the effective requests of the pod, in milli-units.
+	requests map[corev1.ResourceName]int64
+	// requested are the requests of the pods of each node, in milli-units.
+	requested map[string]map[corev1.ResourceName]int64
+	// scheduled are the pods scheduled to a node, other than the pod.
+	scheduled []*corev1.Pod
+	// namespaces holds the labels of each namespace, when pod affinity terms
+	// select namespaces by label.
+	namespaces map[string]labels.Set
+	// volumeTerms are the node selector terms the volumes of the pod require,
+	// by volume.
+	volumeTerms map[string][]corev1.NodeSelectorTerm
+	podIssues   []string
+}
+
+// newSchedulingEvaluator returns a schedulingEvaluator for pod, getting the
+// namespaces and volumes its checks need.
+func newSchedulingEvaluator(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	pod *corev1.Pod,
+	nodes []corev1.Node,
+	pods []corev1.Pod,
+) (*schedulingEvaluator, error) {
+	e := &schedulingEvaluator{
+		pod:      pod,
+		nodes:    map[string]*corev1.Node{},
+		requests: podRequests(pod),
+	}
+	for i := range nodes {
+		e.nodes[nodes[i].Name] = &nodes[i]
+	}
+	e.addPods(pods)
+	if e.needsNamespaces() {
+		namespaces, err := clientset.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to list namespaces: %w", err)
+		}
+		e.namespaces = map[string]labels.Set{}
+		for _, namespace := range namespaces.Items {
+			e.namespaces[namespace.Name] = namespaceLabels(namespace.Name, namespace.Labels)
+		}
+	}
+	for _, gate := range pod.Spec.SchedulingGates {
+		e.podIssues = append(e.podIssues, fmt.Sprintf("scheduling gate %s prevents the pod from being scheduled", gate.Name))
+	}
+	if err := e.addVolumes(ctx, clientset); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// addVerdicts adds the verdicts of nodes to diagnosis, those fitting the pod
+// first.
+func (e *schedulingEvaluator) addVerdicts(diagnosis *SchedulingDiagnosis, nodes []corev1.Node) {
+	diagnosis.ReasonCounts = map[string]int{}
+	for i := range nodes {
+		verdict := e.evaluate(&nodes[i])
+		if verdict.Fits {
+			diagnosis.FitNodes++
+		}
+		for _, reasonType := range sets.List(reasonTypes(verdict.Reasons)) {
+			diagnosis.ReasonCounts[reasonType]++
+		}
+		diagnosis.Nodes = append(diagnosis.Nodes, verdict)
+	}
+	if len(diagnosis.ReasonCounts) == 0 {
+		diagnosis.ReasonCounts = nil
+	}
+
+	sort.SliceStable(diagnosis.Nodes, func(i, j int) bool {
+		a, b := diagnosis.Nodes[i], diagnosis.Nodes[j]
+		if a.Fits != b.Fits {
+			return a.Fits
+		}
+		return a.Name < b.Name
+	})
+	if len(diagnosis.Nodes) > MaxSchedulingNodes {
+		diagnosis.Nodes, diagnosis.Truncated = diagnosis.Nodes[:MaxSchedulingNodes], true
+	}
+}
+
+// addPods records the requests of the pods scheduled to each node.
+func (e *schedulingEvaluator) addPods(pods []corev1.Pod) {
+	e.requested = map[string]map[corev1.ResourceName]int64{}
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Spec.NodeName == "" || isPodTerminated(pod) || pod.UID == e.pod.UID {
+			continue
+		}
+		e.scheduled = append(e.scheduled, pod)
+		requested := e.requested[pod.Spec.NodeName]
+		if requested == nil {
+			requested = map[corev1.ResourceName]int64{}
+			e.requested[pod.Spec.NodeName] = requested
+		}
+		for name, value := range podRequests(pod) {
+			requested[name] += value
+		}
+	}
+}
+
+// addVolumes checks the PersistentVolumeClaims of the pod, recording those
+// that prevent it from scheduling and the topology their volumes require.
+func (e *schedulingEvaluator) addVolumes(ctx context.Context, clientset kubernetes.Interface) error {
+	e.volumeTerms = map[string][]corev1.NodeSelectorTerm{}
+	for _, volume := range e.pod.Spec.Volumes {
+		claimName := ""
+		switch {
+		case volume.PersistentVolumeClaim != nil:
+			claimName = volume.PersistentVolumeClaim.ClaimName
+		case volume.Ephemeral != nil:
+			claimName = e.pod.Name + "-" + volume.Name
+		default:
+			continue
+		}
+
+		claim, err := clientset.CoreV1().PersistentVolumeClaims(e.pod.Namespace).Get(ctx, claimName, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			e.podIssues = append(e.podIssues, fmt.Sprintf("PersistentVolumeClaim %s of volume %s does not exist",
+				claimName, volume.Name))
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to get PersistentVolumeClaim %s: %w", claimName, err)
+		}
+
+		if claim.Spec.VolumeName != "" {
+			volumeTerms, err := persistentVolumeTerms(ctx, clientset, claim.Spec.VolumeName)
+			if err != nil {
+				return err
+			}
+			e.volumeTerms[volume.Name] = volumeTerms
+			continue
+		}
+		if err := e.addUnboundClaim(ctx, clientset, volume.Name, claim); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addUnboundClaim checks a PersistentVolumeClaim of the pod that is not bound.
+// Claims of a StorageClass binding volumes when a pod is scheduled constrain
+// the nodes to its allowed topologies; others prevent the pod from
+// scheduling until they are bound.
+func (e *schedulingEvaluator) addUnboundClaim(
+	ctx context.Context,
+	clientset kubernetes.Interface,
+	volume string,
+	claim *corev1.PersistentVolumeClaim,
+) error {
+	if claim.Spec.StorageClassName != nil && *claim.Spec.StorageClassName != "" {
+		class, err := clientset.StorageV1().StorageClasses().Get(ctx, *claim.Spec.StorageClassName, metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get StorageClass %s: %w", *claim.Spec.StorageClassName, err)
+		}
+		if err == nil && class.VolumeBindingMode != nil && *class.VolumeBindingMode == storagev1.VolumeBindingWaitForFirstConsumer {
+			for _, topology := range class.AllowedTopologies {
+				term := corev1.NodeSelectorTerm{}
+				for _, expression := range topology.MatchLabelExpressions {
+					term.MatchExpressions = append(term.MatchExpressions, corev1.NodeSelectorRequirement{
+						Key:      expression.Key,
+						Operator: corev1.NodeSelectorOpIn,
+						Values:   expression.Values,
+					})
+				}
+				e.volumeTerms[volume] = append(e.volumeTerms[volume], term)
+			}
+			return nil
+		}
+		if apierrors.IsNotFound(err) {
+			e.podIssues = append(e.podIssues, fmt.Sprintf("StorageClass %s of PersistentVolumeClaim %s does not exist",
+				*claim.Spec.StorageClassName, claim.Name))
+			return nil
+		}
+	}
+	e.podIssues = append(e.podIssues, fmt.Sprintf(
+		"PersistentVolumeClaim %s of volume %s is %s and not bound to a volume: the pod cannot be scheduled until it is",
+		claim.Name, volume, claim.Status.Phase))
+	return nil
+}
+
+// persistentVolumeTerms returns the node selector terms of the required node
+// affinity of a PersistentVolume.
+func persistentVolumeTerms(ctx context.Context, clientset kubernetes.Interface, name string) ([]corev1.NodeSelectorTerm, error) {
+	volume, err := clientset.CoreV1().PersistentVolumes().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get PersistentVolume %s: %w", name, err)
+	}
+	if volume.Spec.NodeAffinity == nil || volume.Spec.NodeAffinity.Required == nil {
+		return nil, nil
+	}
+	return volume.Spec.NodeAffinity.Required.NodeSelectorTerms, nil
+}
+
+// needsNamespaces returns whether the pod affinity terms of the pod, or of
+// the scheduled pods, select namespaces by label.
+func (e *schedulingEvaluator) needsNamespaces() bool {
+	for _, pod := range append([]*corev1.Pod{e.pod}, e.scheduled...) {
+		affinityTerms, antiAffinityTerms := requiredPodAffinityTerms(pod)
+		for _, term := range slices.Concat(affinityTerms, antiAffinityTerms) {
+			if term.NamespaceSelector != nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// notes returns the remarks on the limits of the diagnosis of the pod.
+func (e *schedulingEvaluator) notes() []string {
+	var notes []string
+	if e.pod.Spec.NodeName != "" {
+		notes = append(notes, fmt.Sprintf("the pod is already scheduled to node %s", e.pod.Spec.NodeName))
+	}
+	if name := e.pod.Spec.SchedulerName; name != "" && name != defaultSchedulerName {
+		notes = append(notes, fmt.Sprintf("the pod is scheduled by %s, whose rules may differ", name))
+	}
+	for _, constraint := range e.pod.Spec.TopologySpreadConstraints {
+		if constraint.WhenUnsatisfiable == corev1.DoNotSchedule {
+			notes = append(notes, "topology spread constraints are not evaluated: check the events")
+			break
+		}
+	}
+	return notes
+}
+
+// evaluate returns whether node fits the pod and why not.
+func (e *schedulingEvaluator) evaluate(node *corev1.Node) NodeVerdict {
+	verdict := NodeVerdict{Name: node.Name, Reasons: e.nodeReasons(node)}
+	for _, message := range e.insufficientResources(node) {
+		verdict.Reasons = append(verdict.Reasons, SchedulingReason{Type: SchedulingReasonResources, Message: message})
+	}
+	verdict.Reasons = append(verdict.Reasons, e.podAffinityReasons(node)...)
+	for _, volume := range sets.List(sets.KeySet(e.volumeTerms)) {
+		if terms := e.volumeTerms[volume]; len(terms) > 0 && !nodeSelectorTermsMatch(terms, node) {
+			verdict.Reasons = append(verdict.Reasons, SchedulingReason{
+				Type:    SchedulingReasonVolume,
+				Message: fmt.Sprintf("volume %s cannot be attached to the node because of its topology", volume),
+			})
+		}
+	}
+	verdict.Fits = len(verdict.Reasons) == 0
+	return verdict
+}
+
+// nodeReasons returns why the node name, node selector, node affinity and
+// tolerations of the pod reject node.
+func (e *schedulingEvaluator) nodeReasons(node *corev1.Node) []SchedulingReason {
+	var reasons []SchedulingReason
+	add := func(reasonType, format string, args ...interface{}) {
+		reasons = append(reasons, SchedulingReason{Type: reasonType, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if e.pod.Spec.NodeName != "" && e.pod.Spec.NodeName != node.Name {
+		add(SchedulingReasonNodeName, "the pod requests node %s", e.pod.Spec.NodeName)
+	}
+	if node.Spec.Unschedulable && !tolerates(e.pod.Spec.Tolerations, &corev1.Taint{
+		Key: corev1.TaintNodeUnschedulable, Effect: corev1.TaintEffectNoSchedule,
+	}) {
+		add(SchedulingReasonUnschedulable, "the node is cordoned")
+	}
+	if selector := labels.SelectorFromSet(e.pod.Spec.NodeSelector); !selector.Matches(labels.Set(node.Labels)) {
+		add(SchedulingReasonNodeSelector, "the node does not have the labels %s of the node selector", selector)
+	}
+	if affinity := e.pod.Spec.Affinity; affinity != nil && affinity.NodeAffinity != nil &&
+		affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution != nil &&
+		!nodeSelectorTermsMatch(affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms, node) {
+		add(SchedulingReasonNodeAffinity, "the node does not match the required node affinity")
+	}
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectPreferNoSchedule && !tolerates(e.pod.Spec.Tolerations, taint) {
+			add(SchedulingReasonTaint, "the pod does not tolerate the taint %s", taint.ToString())
+		}
+	}
+	return reasons
+}
+
+// insufficientResources returns the resources the node has not enough of for
+// the requests of the pod.
+func (e *schedulingEvaluator) insufficientResources(node *corev1.Node) []string {
+	requested := e.requested[node.Name]
+	var messages []string
+	for _, name := range sets.List(sets.KeySet(e.requests)) {
+		allocatable, ok := node.Status.Allocatable[name]
+		var free int64
+		if ok {
+			free = allocatable.MilliValue() - requested[name]
+		}
+		if e.requests[name] <= free {
+			continue
+		}
+		if !ok {
+			messages = append(messages, fmt.Sprintf("the node does not provide %s", name))
+			continue
+		}
+		messages = append(messages, fmt.Sprintf("insufficient %s: the pod requests %s, %s free of %s allocatable",
+			name, formatMilliValue(name, e.requests[name]), formatMilliValue(name, max(free, 0)),
+			formatMilliValue(name, allocatable.MilliValue())))
+	}
+	return messages
+}
+
+// podAffinityReasons returns why the required pod affinity and anti-affinity
+// of the pod, and the required anti-affinity of the scheduled pods, reject
+// node.
+func (e *schedulingEvaluator) podAffinityReasons(node *corev1.Node) []SchedulingReason {
+	var reasons []SchedulingReason
+	affinityTerms, antiAffinityTerms := requiredPodAffinityTerms(e.pod)
+	for _, term := range affinityTerms {
+		domain, ok := node.Labels[term.TopologyKey]
+		if !ok {
+			reasons = append(reasons, SchedulingReason{Type: SchedulingReasonPodAffinity,
+				Message: fmt.Sprintf("the node has no topology label %s of the pod affinity", term.TopologyKey)})
+			continue
+		}
+		matching := e.matchingPods(e.pod, term)
+		if len(matching) == 0 && e.termMatches(e.pod, term, e.pod) {
+			// The first pod of a group with affinity to itself can go anywhere
+			continue
+		}
+		if !e.inDomain(matching, term.TopologyKey, domain) {
+			reasons = append(reasons, SchedulingReason{Type: SchedulingReasonPodAffinity,
+				Message: fmt.Sprintf("no pod matching the pod affinity runs in the %s=%s domain of the node",
+					term.TopologyKey, domain)})
+		}
+	}
+	for _, term := range antiAffinityTerms {
+		domain, ok := node.Labels[term.TopologyKey]
+		if ok && e.inDomain(e.matchingPods(e.pod, term), term.TopologyKey, domain) {
+			reasons = append(reasons, SchedulingReason{Type: SchedulingReasonPodAntiAffinity,
+				Message: fmt.Sprintf("pods matching the pod anti-affinity run in the %s=%s domain of the node",
+					term.TopologyKey, domain)})
+		}
+	}
+
+	// The anti-affinity of the scheduled pods is symmetric
+	for _, other := range e.scheduled {
+		_, otherTerms := requiredPodAffinityTerms(other)
+		for _, term := range otherTerms {
+			domain, ok := node.Labels[term.TopologyKey]
+			if ok && e.termMatches(other, term, e.pod) && e.inDomain([]*corev1.Pod{other}, term.TopologyKey, domain) {
+				reasons = append(reasons, SchedulingReason{Type: SchedulingReasonPodAntiAffinity,
+					Message: fmt.Sprintf("pod %s/%s has anti-affinity with the pod in the %s=%s domain of the node",
+						other.Namespace, other.Name, term.TopologyKey, domain)})
+			}
+		}
+	}
+	return reasons
+}
+
+// matchingPods returns the scheduled pods matching term of owner.
+func (e *schedulingEvaluator) matchingPods(owner *corev1.Pod, term corev1.PodAffinityTerm) []*corev1.Pod {
+	var matching []*corev1.Pod
+	for _, pod := range e.scheduled {
+		if e.termMatches(owner, term, pod) {
+			matching = append(matching, pod)
+		}
+	}
+	return matching
+}
+
+// termMatches returns whether pod matches the pod affinity term of owner.
+func (e *schedulingEvaluator) termMatches(owner *corev1.Pod, term corev1.PodAffinityTerm, pod *corev1.Pod) bool {
+	if term.LabelSelector == nil {
+		return false
+	}
+	switch {
+	case len(term.Namespaces) == 0 && term.NamespaceSelector == nil:
+		if pod.Namespace != owner.Namespace {
+			return false
+		}
+	case !slices.Contains(term.Namespaces, pod.Namespace) &&
+		(term.NamespaceSelector == nil || !selectorMatches(term.NamespaceSelector, e.namespaces[pod.Namespace])):
+		return false
+	}
+	return selectorMatches(term.LabelSelector, pod.Labels)
+}
+
+// inDomain returns whether any of pods runs on a node whose topologyKey label
+// is domain.
+func (e *schedulingEvaluator) inDomain(pods []*corev1.Pod, topologyKey, domain string) bool {
+	for _, pod := range pods {
+		if node := e.nodes[pod.Spec.NodeName]; node != nil {
+			if value, ok := node.Labels[topologyKey]; ok && value == domain {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// requiredPodAffinityTerms returns the required pod affinity and
+// anti-affinity terms of pod.
+func requiredPodAffinityTerms(pod *corev1.Pod) ([]corev1.PodAffinityTerm, []corev1.PodAffinityTerm) {
+	affinity := pod.Spec.Affinity
+	if affinity == nil {
+		return nil, nil
+	}
+	var affinityTerms, antiAffinityTerms []corev1.PodAffinityTerm
+	if affinity.PodAffinity != nil {
+		affinityTerms = affinity.PodAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+	if affinity.PodAntiAffinity != nil {
+		antiAffinityTerms = affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	}
+	return affinityTerms, antiAffinityTerms
+}
+
+// nodeSelectorTermsMatch returns whether node matches any of terms.
+func nodeSelectorTermsMatch(terms []corev1.NodeSelectorTerm, node *corev1.Node) bool {
+	for _, term := range terms {
+		if len(term.MatchExpressions) == 0 && len(term.MatchFields) == 0 {
+			// An empty term matches no nodes
+			continue
+		}
+		matches := true
+		for _, requirement := range term.MatchExpressions {
+			value, exists := node.Labels[requirement.Key]
+			matches = matches && nodeRequirementMatches(requirement, value, exists)
+		}
+		for _, requirement := range term.MatchFields {
+			// metadata.name is the only supported field
+			matches = matches && requirement.Key == "metadata.name" && nodeRequirementMatches(requirement, node.Name, true)
+		}
+		if matches {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeRequirementMatches returns whether a node label, or field, of value
+// matches requirement.
+func nodeRequirementMatches(requirement corev1.NodeSelectorRequirement, value string, exists bool) bool {
+	switch requirement.Operator {
+	case corev1.NodeSelectorOpIn:
+		return exists && slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpNotIn:
+		return !exists || !slices.Contains(requirement.Values, value)
+	case corev1.NodeSelectorOpExists:
+		return exists
+	case corev1.NodeSelectorOpDoesNotExist:
+		return !exists
+	case corev1.NodeSelectorOpGt, corev1.NodeSelectorOpLt:
+		if !exists || len(requirement.Values) != 1 {
+			return false
+		}
+		actual, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return false
+		}
+		bound, err := strconv.ParseInt(requirement.Values[0], 10, 64)
+		if err != nil {
+			return false
+		}
+		if requirement.Operator == corev1.NodeSelectorOpGt {
+			return actual > bound
+		}
+		return actual < bound
+	default:
+		return false
+	}
+}
+
+// tolerates returns whether tolerations tolerate taint.
+func tolerates(tolerations []corev1.Toleration, taint *corev1.Taint) bool {
+	for i := range tolerations {
+		if tolerations[i].ToleratesTaint(klog.Background(), taint, false) {
+			return true
+		}
+	}
+	return false
+}
+
+// podRequests returns the effective requests of pod, in milli-units, as the
+// scheduler computes them. Sidecars, the init containers that always restart,
+// keep running along with the containers, so their requests add up. Each
+// other init container runs along with the sidecars started before it, and
+// the pod requests the largest of what its containers and each init container
+// need, plus its overhead. A pod always requests one of the pods a node
+// allows.
+func podRequests(pod *corev1.Pod) map[corev1.ResourceName]int64 {
+	requests := map[corev1.ResourceName]int64{}
+	for _, container := range pod.Spec.Containers {
+		addMilliValues(requests, container.Resources.Requests)
+	}
+
+	sidecars := map[corev1.ResourceName]int64{}
+	initRequests := map[corev1.ResourceName]int64{}
+	for i := range pod.Spec.InitContainers {
+		container := &pod.Spec.InitContainers[i]
+		running := maps.Clone(sidecars)
+		addMilliValues(running, container.Resources.Requests)
+		if isSidecar(container) {
+			addMilliValues(requests, container.Resources.Requests)
+			sidecars = running
+		}
+		for name, value := range running {
+			initRequests[name] = max(initRequests[name], value)
+		}
+	}
+	for name, value := range initRequests {
+		requests[name] = max(requests[name], value)
+	}
+
+	addMilliValues(requests, pod.Spec.Overhead)
+	requests[corev1.ResourcePods] = 1000
+	return requests
+}
+
+// addMilliValues adds the quantities of resources to values, in milli-units.
+func addMilliValues(values map[corev1.ResourceName]int64, resources corev1.ResourceList) {
+	for name, quantity := range resources {
+		values[name] += quantity.MilliValue()
+	}
+}
+
+// isSidecar returns whether the init container container is a sidecar, which
+// keeps running along with the containers of its pod.
+func isSidecar(container *corev1.Container) bool {
+	return container.RestartPolicy != nil && *container.RestartPolicy == corev1.ContainerRestartPolicyAlways
+}
+
+// formatRequests formats the requests of a pod for a SchedulingDiagnosis.
+func formatRequests(requests map[corev1.ResourceName]int64) map[string]string {
+	formatted := make(map[string]string, len(requests))
+	for name, value := range requests {
+		if name != corev1.ResourcePods {
+			formatted[string(name)] = formatMilliValue(name, value)
+		}
+	}
+	return formatted
+}
+
+// formatMilliValue formats a quantity of resource name given in milli-units,
+// CPU in millicores and memory in mebibytes like `kubectl top`.
+func formatMilliValue(name corev1.ResourceName, value int64) string {
+	switch name {
+	case corev1.ResourceCPU:
+		return formatCPU(value)
+	case corev1.ResourceMemory:
+		return formatMemory(value / 1000)
+	default:
+		return resource.NewMilliQuantity(value, resource.DecimalSI).String()
+	}
+}
+
+// reasonTypes returns the distinct types of reasons.
+func reasonTypes(reasons []SchedulingReason) sets.Set[string] {
+	types := sets.New[string]()
+	for _, reason := range reasons {
+		types.Insert(reason.Type)
+	}
+	return types
+}
@@ -0,0 +1,416 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func schedulingTestNode(name, zone, cpu string) *corev1.Node {
+	return &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"zone": zone}},
+		Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse(cpu),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+			corev1.ResourcePods:   resource.MustParse("110"),
+		}},
+	}
+}
+
+func schedulingTestPod(name, nodeName, cpu string, labels map[string]string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", UID: types.UID("uid-" + name), Labels: labels},
+		Spec: corev1.PodSpec{
+			NodeName: nodeName,
+			Containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(cpu),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				}},
+			}},
+		},
+		Status: corev1.PodStatus{Phase: corev1.PodPending},
+	}
+}
+
+// newSchedulingTestClient returns a client for a cluster of three nodes in
+// zones a, b and c: node-a has 500m CPU free, node-b is tainted and node-c is
+// cordoned.
+func newSchedulingTestClient(pending *corev1.Pod, objects ...runtime.Object) *Client {
+	tainted := schedulingTestNode("node-b", "b", "4")
+	tainted.Spec.Taints = []corev1.Taint{{Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule}}
+	cordoned := schedulingTestNode("node-c", "c", "4")
+	cordoned.Spec.Unschedulable = true
+	objects = append(objects,
+		schedulingTestNode("node-a", "a", "2"), tainted, cordoned,
+		schedulingTestPod("cache", "node-a", "1500m", map[string]string{"app": "cache"}),
+		pending,
+	)
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(objects...))
+	return client
+}
+
+func schedulingVerdict(t *testing.T, diagnosis *SchedulingDiagnosis, node string) NodeVerdict {
+	t.Helper()
+	for _, verdict := range diagnosis.Nodes {
+		if verdict.Name == node {
+			return verdict
+		}
+	}
+	require.Failf(t, "node not found", "no verdict for node %s", node)
+	return NodeVerdict{}
+}
+
+func schedulingReasonTypes(verdict NodeVerdict) []string {
+	var result []string
+	for _, reason := range verdict.Reasons {
+		result = append(result, reason.Type)
+	}
+	return result
+}
+
+func TestWhyPending(t *testing.T) {
+	pending := schedulingTestPod("web", "", "1", map[string]string{"app": "web"})
+	pending.Status.Conditions = []corev1.PodCondition{{
+		Type:    corev1.PodScheduled,
+		Status:  corev1.ConditionFalse,
+		Reason:  "Unschedulable",
+		Message: "0/3 nodes are available",
+	}}
+	now := time.Now()
+	event := func(name, reason string, lastSeen time.Time) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web"},
+			Reason:         reason,
+			Message:        name,
+			Count:          2,
+			LastTimestamp:  metav1.NewTime(lastSeen),
+		}
+	}
+	client := newSchedulingTestClient(pending,
+		event("older", "FailedScheduling", now.Add(-time.Hour)),
+		event("newer", "FailedScheduling", now),
+		event("pulled", "Pulled", now),
+	)
+
+	diagnosis, err := client.WhyPending(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "Pending", diagnosis.Phase)
+	assert.Equal(t, "Unschedulable: 0/3 nodes are available", diagnosis.Condition)
+	require.Len(t, diagnosis.Events, 2)
+	assert.Equal(t, "newer", diagnosis.Events[0].Message)
+	assert.Equal(t, int32(2), diagnosis.Events[0].Count)
+	assert.Equal(t, map[string]string{"cpu": "1000m", "memory": "1024Mi"}, diagnosis.Requests)
+	assert.Empty(t, diagnosis.PodIssues)
+
+	assert.Equal(t, 3, diagnosis.TotalNodes)
+	assert.Equal(t, 0, diagnosis.FitNodes)
+	assert.Equal(t, map[string]int{
+		SchedulingReasonResources:     1,
+		SchedulingReasonTaint:         1,
+		SchedulingReasonUnschedulable: 1,
+	}, diagnosis.ReasonCounts)
+	require.Len(t, diagnosis.Nodes, 3)
+	assert.Equal(t, "node-a", diagnosis.Nodes[0].Name)
+
+	nodeA := schedulingVerdict(t, diagnosis, "node-a")
+	require.Len(t, nodeA.Reasons, 1)
+	assert.Equal(t, "insufficient cpu: the pod requests 1000m, 500m free of 2000m allocatable", nodeA.Reasons[0].Message)
+	nodeB := schedulingVerdict(t, diagnosis, "node-b")
+	require.Len(t, nodeB.Reasons, 1)
+	assert.Contains(t, nodeB.Reasons[0].Message, "dedicated=gpu:NoSchedule")
+	assert.Equal(t, []string{SchedulingReasonUnschedulable}, schedulingReasonTypes(schedulingVerdict(t, diagnosis, "node-c")))
+}
+
+func TestWhyPendingTolerations(t *testing.T) {
+	pending := schedulingTestPod("web", "", "1", nil)
+	pending.Spec.Tolerations = []corev1.Toleration{
+		{Key: "dedicated", Operator: corev1.TolerationOpEqual, Value: "gpu", Effect: corev1.TaintEffectNoSchedule},
+		{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists},
+	}
+	client := newSchedulingTestClient(pending)
+
+	diagnosis, err := client.WhyPending(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, 2, diagnosis.FitNodes)
+	assert.Equal(t, map[string]int{SchedulingReasonResources: 1}, diagnosis.ReasonCounts)
+	require.Len(t, diagnosis.Nodes, 3)
+	assert.Equal(t, []string{"node-b", "node-c", "node-a"},
+		[]string{diagnosis.Nodes[0].Name, diagnosis.Nodes[1].Name, diagnosis.Nodes[2].Name})
+	assert.True(t, diagnosis.Nodes[0].Fits)
+	assert.False(t, diagnosis.Nodes[2].Fits)
+}
+
+func TestWhyPendingNodeSelectionAndAffinity(t *testing.T) {
+	pending := schedulingTestPod("web", "", "100m", nil)
+	pending.Spec.NodeSelector = map[string]string{"zone": "a"}
+	pending.Spec.Affinity = &corev1.Affinity{NodeAffinity: &corev1.NodeAffinity{
+		RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+			NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+				MatchExpressions: []corev1.NodeSelectorRequirement{{
+					Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{"a", "b"},
+				}},
+			}},
+		},
+	}}
+	client := newSchedulingTestClient(pending)
+
+	diagnosis, err := client.WhyPending(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, 1, diagnosis.FitNodes)
+	assert.True(t, schedulingVerdict(t, diagnosis, "node-a").Fits)
+	assert.Equal(t, []string{SchedulingReasonNodeSelector, SchedulingReasonTaint},
+		schedulingReasonTypes(schedulingVerdict(t, diagnosis, "node-b")))
+	assert.Equal(t, []string{SchedulingReasonUnschedulable, SchedulingReasonNodeSelector, SchedulingReasonNodeAffinity},
+		schedulingReasonTypes(schedulingVerdict(t, diagnosis, "node-c")))
+}
+
+func TestWhyPendingPodAffinity(t *testing.T) {
+	cacheTerm := corev1.PodAffinityTerm{
+		LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "cache"}},
+		TopologyKey:   "zone",
+	}
+	tolerateAll := []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+
+	t.Run("affinity", func(t *testing.T) {
+		pending := schedulingTestPod("web", "", "100m", nil)
+		pending.Spec.Tolerations = tolerateAll
+		pending.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{cacheTerm},
+		}}
+		diagnosis, err := newSchedulingTestClient(pending).WhyPending(context.Background(), "shop", "web")
+		require.NoError(t, err)
+		assert.True(t, schedulingVerdict(t, diagnosis, "node-a").Fits)
+		assert.Equal(t, map[string]int{SchedulingReasonPodAffinity: 2}, diagnosis.ReasonCounts)
+	})
+
+	t.Run("anti-affinity", func(t *testing.T) {
+		pending := schedulingTestPod("web", "", "100m", nil)
+		pending.Spec.Tolerations = tolerateAll
+		pending.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{cacheTerm},
+		}}
+		diagnosis, err := newSchedulingTestClient(pending).WhyPending(context.Background(), "shop", "web")
+		require.NoError(t, err)
+		assert.Equal(t, 2, diagnosis.FitNodes)
+		assert.Equal(t, []string{SchedulingReasonPodAntiAffinity}, schedulingReasonTypes(schedulingVerdict(t, diagnosis, "node-a")))
+	})
+
+	t.Run("symmetric anti-affinity", func(t *testing.T) {
+		pending := schedulingTestPod("web", "", "100m", map[string]string{"app": "web"})
+		pending.Spec.Tolerations = tolerateAll
+		lonely := schedulingTestPod("lonely", "node-b", "100m", nil)
+		lonely.Spec.Affinity = &corev1.Affinity{PodAntiAffinity: &corev1.PodAntiAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				TopologyKey:   "zone",
+			}},
+		}}
+		diagnosis, err := newSchedulingTestClient(pending, lonely).WhyPending(context.Background(), "shop", "web")
+		require.NoError(t, err)
+		nodeB := schedulingVerdict(t, diagnosis, "node-b")
+		require.Len(t, nodeB.Reasons, 1)
+		assert.Equal(t, SchedulingReasonPodAntiAffinity, nodeB.Reasons[0].Type)
+		assert.Contains(t, nodeB.Reasons[0].Message, "pod shop/lonely")
+	})
+
+	t.Run("first pod of a group", func(t *testing.T) {
+		pending := schedulingTestPod("web", "", "100m", map[string]string{"app": "web"})
+		pending.Spec.Tolerations = tolerateAll
+		pending.Spec.Affinity = &corev1.Affinity{PodAffinity: &corev1.PodAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: []corev1.PodAffinityTerm{{
+				LabelSelector:     &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+				NamespaceSelector: &metav1.LabelSelector{},
+				TopologyKey:       "zone",
+			}},
+		}}
+		diagnosis, err := newSchedulingTestClient(pending).WhyPending(context.Background(), "shop", "web")
+		require.NoError(t, err)
+		assert.Equal(t, 3, diagnosis.FitNodes)
+	})
+}
+
+func TestWhyPendingVolumes(t *testing.T) {
+	pending := schedulingTestPod("web", "", "100m", nil)
+	pending.Spec.Tolerations = []corev1.Toleration{{Operator: corev1.TolerationOpExists}}
+	for _, claim := range []string{"missing", "immediate", "bound", "delayed"} {
+		pending.Spec.Volumes = append(pending.Spec.Volumes, corev1.Volume{
+			Name: claim,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: claim},
+			},
+		})
+	}
+	claim := func(name, class, volume string) *corev1.PersistentVolumeClaim {
+		phase := corev1.ClaimPending
+		if volume != "" {
+			phase = corev1.ClaimBound
+		}
+		return &corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop"},
+			Spec:       corev1.PersistentVolumeClaimSpec{StorageClassName: ptr.To(class), VolumeName: volume},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		}
+	}
+	zoneTerm := func(zone string) corev1.NodeSelectorTerm {
+		return corev1.NodeSelectorTerm{MatchExpressions: []corev1.NodeSelectorRequirement{{
+			Key: "zone", Operator: corev1.NodeSelectorOpIn, Values: []string{zone},
+		}}}
+	}
+	client := newSchedulingTestClient(pending,
+		claim("immediate", "standard", ""),
+		claim("bound", "standard", "pv-bound"),
+		claim("delayed", "local", ""),
+		&storagev1.StorageClass{ObjectMeta: metav1.ObjectMeta{Name: "standard"}},
+		&storagev1.StorageClass{
+			ObjectMeta:        metav1.ObjectMeta{Name: "local"},
+			VolumeBindingMode: ptr.To(storagev1.VolumeBindingWaitForFirstConsumer),
+			AllowedTopologies: []corev1.TopologySelectorTerm{{
+				MatchLabelExpressions: []corev1.TopologySelectorLabelRequirement{{Key: "zone", Values: []string{"a", "b"}}},
+			}},
+		},
+		&corev1.PersistentVolume{
+			ObjectMeta: metav1.ObjectMeta{Name: "pv-bound"},
+			Spec: corev1.PersistentVolumeSpec{NodeAffinity: &corev1.VolumeNodeAffinity{
+				Required: &corev1.NodeSelector{NodeSelectorTerms: []corev1.NodeSelectorTerm{zoneTerm("a"), zoneTerm("c")}},
+			}},
+		},
+	)
+
+	diagnosis, err := client.WhyPending(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	require.Len(t, diagnosis.PodIssues, 2)
+	assert.Contains(t, diagnosis.PodIssues[0], "PersistentVolumeClaim missing of volume missing does not exist")
+	assert.Contains(t, diagnosis.PodIssues[1], "PersistentVolumeClaim immediate of volume immediate is Pending")
+	assert.True(t, schedulingVerdict(t, diagnosis, "node-a").Fits)
+	nodeB := schedulingVerdict(t, diagnosis, "node-b")
+	require.Len(t, nodeB.Reasons, 1)
+	assert.Contains(t, nodeB.Reasons[0].Message, "volume bound")
+	nodeC := schedulingVerdict(t, diagnosis, "node-c")
+	require.Len(t, nodeC.Reasons, 1)
+	assert.Contains(t, nodeC.Reasons[0].Message, "volume delayed")
+	assert.Equal(t, map[string]int{SchedulingReasonVolume: 2}, diagnosis.ReasonCounts)
+}
+
+func TestWhyPendingNotes(t *testing.T) {
+	pending := schedulingTestPod("web", "node-a", "100m", nil)
+	pending.Spec.SchedulerName = "custom"
+	pending.Spec.SchedulingGates = []corev1.PodSchedulingGate{{Name: "example.com/quota"}}
+	pending.Spec.TopologySpreadConstraints = []corev1.TopologySpreadConstraint{{
+		MaxSkew: 1, TopologyKey: "zone", WhenUnsatisfiable: corev1.DoNotSchedule,
+	}}
+	client := newSchedulingTestClient(pending)
+
+	diagnosis, err := client.WhyPending(context.Background(), "shop", "web")
+	require.NoError(t, err)
+	assert.Equal(t, "node-a", diagnosis.NodeName)
+	assert.Equal(t, []string{"scheduling gate example.com/quota prevents the pod from being scheduled"}, diagnosis.PodIssues)
+	require.Len(t, diagnosis.Notes, 3)
+	assert.Contains(t, diagnosis.Notes[0], "already scheduled to node node-a")
+	assert.Contains(t, diagnosis.Notes[1], "scheduled by custom")
+	assert.Contains(t, diagnosis.Notes[2], "topology spread constraints")
+	assert.Equal(t, []string{SchedulingReasonNodeName, SchedulingReasonTaint}, schedulingReasonTypes(schedulingVerdict(t, diagnosis, "node-b")))
+}
+
+func TestWhyPendingErrors(t *testing.T) {
+	client := newSchedulingTestClient(schedulingTestPod("web", "", "100m", nil))
+
+	_, err := client.WhyPending(context.Background(), "", "web")
+	assert.Error(t, err)
+	_, err = client.WhyPending(context.Background(), "shop", "missing")
+	assert.ErrorContains(t, err, "failed to get pod")
+	_, err = (&Client{}).WhyPending(context.Background(), "shop", "web")
+	assert.ErrorContains(t, err, "clientset is not initialized")
+}
+
+func TestNodeRequirementMatches(t *testing.T) {
+	requirement := func(operator corev1.NodeSelectorOperator, values ...string) corev1.NodeSelectorRequirement {
+		return corev1.NodeSelectorRequirement{Key: "key", Operator: operator, Values: values}
+	}
+	tests := []struct {
+		name        string
+		requirement corev1.NodeSelectorRequirement
+		value       string
+		exists      bool
+		expected    bool
+	}{
+		{"in", requirement(corev1.NodeSelectorOpIn, "a", "b"), "b", true, true},
+		{"in missing", requirement(corev1.NodeSelectorOpIn, "a"), "", false, false},
+		{"not in", requirement(corev1.NodeSelectorOpNotIn, "a"), "a", true, false},
+		{"not in missing", requirement(corev1.NodeSelectorOpNotIn, "a"), "", false, true},
+		{"exists", requirement(corev1.NodeSelectorOpExists), "", true, true},
+		{"does not exist", requirement(corev1.NodeSelectorOpDoesNotExist), "", true, false},
+		{"gt", requirement(corev1.NodeSelectorOpGt, "4"), "8", true, true},
+		{"lt", requirement(corev1.NodeSelectorOpLt, "4"), "8", true, false},
+		{"gt not integer", requirement(corev1.NodeSelectorOpGt, "4"), "eight", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, nodeRequirementMatches(tt.requirement, tt.value, tt.exists))
+		})
+	}
+}
+
+func TestPodRequests(t *testing.T) {
+	pod := schedulingTestPod("web", "", "500m", nil)
+	pod.Spec.Containers = append(pod.Spec.Containers, corev1.Container{
+		Name: "sidecar",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU: resource.MustParse("250m"),
+		}},
+	})
+	pod.Spec.InitContainers = []corev1.Container{{
+		Name: "init",
+		Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("512Mi"),
+		}},
+	}}
+	pod.Spec.Overhead = corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}
+
+	requests := podRequests(pod)
+	assert.Equal(t, int64(1100), requests[corev1.ResourceCPU])
+	assert.Equal(t, map[string]string{"cpu": "1100m", "memory": "1024Mi"}, formatRequests(requests))
+}
+
+func TestPodRequestsSidecars(t *testing.T) {
+	cpu := func(value string) corev1.ResourceRequirements {
+		return corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(value)}}
+	}
+
+	// A sidecar runs along with the containers
+	pod := schedulingTestPod("web", "", "1", nil)
+	pod.Spec.InitContainers = []corev1.Container{
+		{Name: "proxy", RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways), Resources: cpu("1")},
+	}
+	assert.Equal(t, int64(2000), podRequests(pod)[corev1.ResourceCPU])
+
+	// An init container runs along with the sidecars started before it
+	pod.Spec.InitContainers = []corev1.Container{
+		{Name: "proxy", RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways), Resources: cpu("1")},
+		{Name: "migrate", Resources: cpu("2")},
+		{Name: "agent", RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways), Resources: cpu("500m")},
+	}
+	assert.Equal(t, int64(3000), podRequests(pod)[corev1.ResourceCPU])
+
+	// Init containers started before a sidecar do not run along with it
+	pod.Spec.InitContainers = []corev1.Container{
+		{Name: "migrate", Resources: cpu("2")},
+		{Name: "proxy", RestartPolicy: ptr.To(corev1.ContainerRestartPolicyAlways), Resources: cpu("1")},
+	}
+	assert.Equal(t, int64(2000), podRequests(pod)[corev1.ResourceCPU])
+}
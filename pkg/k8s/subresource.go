@@ -86,18 +86,11 @@ func (c *Client) defaultGetPodLogs(
 	parameters map[string]string) (*unstructured.Unstructured, error) {
 	// We need to use the CoreV1 client for logs, as the dynamic client doesn't handle logs properly
 
-	// Bound concurrent in-flight reads so that the per-request memory cap
-	// (maxPodLogLimitBytes) translates into a predictable aggregate cap.
-	// Respect context cancellation while waiting — a slow client should not
-	// pin a slot.
-	if c.podLogReadSem != nil {
-		select {
-		case c.podLogReadSem <- struct{}{}:
-			defer func() { <-c.podLogReadSem }()
-		case <-ctx.Done():
-			return nil, fmt.Errorf("waiting to read pod logs: %w", ctx.Err())
-		}
+	release, err := c.acquirePodLogSlot(ctx)
+	if err != nil {
+		return nil, err
 	}
+	defer release()
 
 	// Set reasonable defaults for LLM context window
 	// Default to last 100 lines and 32KB limit to avoid overwhelming the LLM context
@@ -152,6 +145,23 @@ func (c *Client) defaultGetPodLogs(
 	return result, nil
 }
 
+// acquirePodLogSlot bounds concurrent in-flight pod-log reads so that the
+// per-request memory cap (maxPodLogLimitBytes) translates into a predictable
+// aggregate cap. It respects context cancellation while waiting — a slow
+// client should not pin a slot. The returned release func must be called
+// once the read is done.
+func (c *Client) acquirePodLogSlot(ctx context.Context) (func(), error) {
+	if c.podLogReadSem == nil {
+		return func() {}, nil
+	}
+	select {
+	case c.podLogReadSem <- struct{}{}:
+		return func() { <-c.podLogReadSem }, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("waiting to read pod logs: %w", ctx.Err())
+	}
+}
+
 // effectivePodLogLimit returns the per-request byte cap used when reading a
 // pod log stream. It is the smaller of the caller-requested LimitBytes (after
 // clamping in buildPodLogOpts) and the absolute server-side ceiling.
@@ -0,0 +1,519 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+)
+
+const (
+	// DefaultTopLimit is the default number of rows returned by TopPods and
+	// TopNodes.
+	DefaultTopLimit = 50
+	// MaxTopLimit is the largest number of rows returned by TopPods and
+	// TopNodes.
+	MaxTopLimit = 500
+
+	// TopSortCPU sorts the rows of TopPods and TopNodes by CPU usage.
+	TopSortCPU = "cpu"
+	// TopSortMemory sorts the rows of TopPods and TopNodes by memory usage.
+	TopSortMemory = "memory"
+)
+
+var (
+	podMetricsGVR  = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}
+	nodeMetricsGVR = schema.GroupVersionResource{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}
+)
+
+// metricsUnavailableMessage explains why TopPods and TopNodes report no usage.
+const metricsUnavailableMessage = "the metrics.k8s.io API is not available, so usage is not reported: " +
+	"metrics-server is probably not installed or not ready. Rows are sorted by requests instead"
+
+// TopOptions selects and orders the rows returned by TopPods and TopNodes.
+type TopOptions struct {
+	// Namespace selects the pods of a namespace. Empty selects all
+	// namespaces. It does not apply to nodes.
+	Namespace string
+	// LabelSelector selects the pods, or the nodes, by label.
+	LabelSelector string
+	// Node selects the pods running on a node, or a single node.
+	Node string
+	// SortBy is TopSortCPU or TopSortMemory. Rows are sorted by decreasing
+	// usage, or by decreasing requests when metrics are unavailable.
+	SortBy string
+	// Limit is the number of rows to return, DefaultTopLimit if not positive.
+	Limit int
+}
+
+// PodUsage is the resource usage of a pod, with its requests and limits. CPU
+// is in millicores, e.g. "250m", and memory in mebibytes, e.g. "128Mi".
+// Requests and limits are the sums over the containers of the pod; a limit
+// is empty when a container has none, as the pod is then unbounded.
+type PodUsage struct {
+	Namespace     string `json:"namespace"`
+	Name          string `json:"name"`
+	Node          string `json:"node,omitempty"`
+	CPUUsage      string `json:"cpuUsage,omitempty"`
+	CPURequest    string `json:"cpuRequest,omitempty"`
+	CPULimit      string `json:"cpuLimit,omitempty"`
+	MemoryUsage   string `json:"memoryUsage,omitempty"`
+	MemoryRequest string `json:"memoryRequest,omitempty"`
+	MemoryLimit   string `json:"memoryLimit,omitempty"`
+	// The percentages of usage against the requests and limits, when both
+	// are known.
+	CPURequestPercent    *int64 `json:"cpuRequestPercent,omitempty"`
+	CPULimitPercent      *int64 `json:"cpuLimitPercent,omitempty"`
+	MemoryRequestPercent *int64 `json:"memoryRequestPercent,omitempty"`
+	MemoryLimitPercent   *int64 `json:"memoryLimitPercent,omitempty"`
+
+	usage, requests, limits podResources
+}
+
+// TopPodsResult is the result of TopPods.
+type TopPodsResult struct {
+	// MetricsAvailable is false when the metrics API could not be read, in
+	// which case Message explains why and the rows only hold requests and
+	// limits.
+	MetricsAvailable bool       `json:"metricsAvailable"`
+	Message          string     `json:"message,omitempty"`
+	Pods             []PodUsage `json:"pods"`
+	// TotalCPUUsage and TotalMemoryUsage sum the usage of all the pods
+	// found, including those beyond the limit.
+	TotalCPUUsage    string `json:"totalCpuUsage,omitempty"`
+	TotalMemoryUsage string `json:"totalMemoryUsage,omitempty"`
+	Total            int    `json:"total"`
+	Truncated        bool   `json:"truncated,omitempty"`
+}
+
+// NodeUsage is the resource usage of a node, with its allocatable resources
+// and the requests of the pods running on it, in the units of PodUsage.
+type NodeUsage struct {
+	Name              string `json:"name"`
+	CPUUsage          string `json:"cpuUsage,omitempty"`
+	CPUAllocatable    string `json:"cpuAllocatable"`
+	CPURequests       string `json:"cpuRequests"`
+	MemoryUsage       string `json:"memoryUsage,omitempty"`
+	MemoryAllocatable string `json:"memoryAllocatable"`
+	MemoryRequests    string `json:"memoryRequests"`
+	Pods              int    `json:"pods"`
+	// The percentages of usage and requests against the allocatable
+	// resources.
+	CPUPercent            *int64 `json:"cpuPercent,omitempty"`
+	CPURequestsPercent    *int64 `json:"cpuRequestsPercent,omitempty"`
+	MemoryPercent         *int64 `json:"memoryPercent,omitempty"`
+	MemoryRequestsPercent *int64 `json:"memoryRequestsPercent,omitempty"`
+
+	usage, requests podResources
+}
+
+// TopNodesResult is the result of TopNodes.
+type TopNodesResult struct {
+	// MetricsAvailable is false when the metrics API could not be read, in
+	// which case Message explains why and the rows only hold allocatable
+	// resources and requests.
+	MetricsAvailable bool        `json:"metricsAvailable"`
+	Message          string      `json:"message,omitempty"`
+	Nodes            []NodeUsage `json:"nodes"`
+	Total            int         `json:"total"`
+	Truncated        bool        `json:"truncated,omitempty"`
+}
+
+// podResources is an amount of CPU, in millicores, and memory, in bytes.
+// Bounded is false for the limits of a pod with a container without limits.
+type podResources struct {
+	cpu, memory                 int64
+	cpuBounded, memoryBounded   bool
+	cpuSet, memorySet, measured bool
+}
+
+// TopPods returns the CPU and memory usage of pods from the metrics.k8s.io
+// API, like `kubectl top pods`, joined with the requests and limits of their
+// containers. When the metrics API is not available, the pods are returned
+// with their requests and limits only, and the result explains why.
+func (c *Client) TopPods(ctx context.Context, opts TopOptions) (*TopPodsResult, error) {
+	if err := validateTopOptions(opts); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	if clientset == nil || dynamicClient == nil {
+		return nil, fmt.Errorf("clients are not initialized")
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: opts.LabelSelector}
+	pods, err := clientset.CoreV1().Pods(opts.Namespace).List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	usage, available, err := listMetrics(ctx, dynamicClient.Resource(podMetricsGVR).Namespace(opts.Namespace),
+		listOptions, podMetricsUsage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pod metrics: %w", err)
+	}
+	result := &TopPodsResult{MetricsAvailable: available, Pods: []PodUsage{}}
+	if !available {
+		result.Message = metricsUnavailableMessage
+	}
+
+	var totalCPU, totalMemory int64
+	for _, pod := range pods.Items {
+		if (opts.Node != "" && pod.Spec.NodeName != opts.Node) || isPodTerminated(&pod) {
+			continue
+		}
+		row := PodUsage{Namespace: pod.Namespace, Name: pod.Name, Node: pod.Spec.NodeName}
+		row.requests, row.limits = podRequestsAndLimits(&pod)
+		if result.MetricsAvailable {
+			var ok bool
+			if row.usage, ok = usage[metricsKey(pod.Namespace, pod.Name)]; !ok {
+				// Pods that are not running have no metrics
+				continue
+			}
+			totalCPU += row.usage.cpu
+			totalMemory += row.usage.memory
+		}
+		row.format()
+		result.Pods = append(result.Pods, row)
+	}
+	if result.MetricsAvailable {
+		result.TotalCPUUsage, result.TotalMemoryUsage = formatCPU(totalCPU), formatMemory(totalMemory)
+	}
+
+	result.Total = len(result.Pods)
+	result.Pods, result.Truncated = sortTop(result.Pods, opts, available, func(row PodUsage) (podResources, podResources) {
+		return row.usage, row.requests
+	})
+	return result, nil
+}
+
+// TopNodes returns the CPU and memory usage of nodes from the metrics.k8s.io
+// API, like `kubectl top nodes`, along with their allocatable resources and
+// the requests of the pods running on them. When the metrics API is not
+// available, the nodes are returned without usage, and the result explains
+// why.
+func (c *Client) TopNodes(ctx context.Context, opts TopOptions) (*TopNodesResult, error) {
+	if err := validateTopOptions(opts); err != nil {
+		return nil, err
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	if clientset == nil || dynamicClient == nil {
+		return nil, fmt.Errorf("clients are not initialized")
+	}
+
+	listOptions := metav1.ListOptions{LabelSelector: opts.LabelSelector}
+	nodes, err := clientset.CoreV1().Nodes().List(ctx, listOptions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list nodes: %w", err)
+	}
+	pods, err := clientset.CoreV1().Pods("").List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+
+	usage, available, err := listMetrics(ctx, dynamicClient.Resource(nodeMetricsGVR), listOptions, nodeMetricsUsage)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list node metrics: %w", err)
+	}
+	result := &TopNodesResult{MetricsAvailable: available, Nodes: []NodeUsage{}}
+	if !available {
+		result.Message = metricsUnavailableMessage
+	}
+
+	rows := map[string]*NodeUsage{}
+	for _, node := range nodes.Items {
+		if opts.Node != "" && node.Name != opts.Node {
+			continue
+		}
+		rows[node.Name] = &NodeUsage{Name: node.Name, usage: usage[metricsKey("", node.Name)]}
+	}
+	addNodeRequests(rows, pods.Items)
+
+	for _, node := range nodes.Items {
+		row := rows[node.Name]
+		if row == nil {
+			continue
+		}
+		row.format(node.Status.Allocatable)
+		result.Nodes = append(result.Nodes, *row)
+	}
+
+	result.Total = len(result.Nodes)
+	result.Nodes, result.Truncated = sortTop(result.Nodes, opts, available, func(row NodeUsage) (podResources, podResources) {
+		return row.usage, row.requests
+	})
+	return result, nil
+}
+
+// validateTopOptions checks the options of TopPods and TopNodes.
+func validateTopOptions(opts TopOptions) error {
+	switch opts.SortBy {
+	case "", TopSortCPU, TopSortMemory:
+	default:
+		return fmt.Errorf("invalid sort %q: must be %s or %s", opts.SortBy, TopSortCPU, TopSortMemory)
+	}
+	if opts.Limit > MaxTopLimit {
+		return fmt.Errorf("limit must not exceed %d", MaxTopLimit)
+	}
+	return nil
+}
+
+// isMetricsUnavailable returns whether err means the metrics API is not
+// served, e.g. because metrics-server is not installed or not ready.
+func isMetricsUnavailable(err error) bool {
+	return apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) || meta.IsNoMatchError(err)
+}
+
+// listMetrics lists the metrics of resource and returns their usage by
+// metricsKey. Available is false when the metrics API is not served.
+func listMetrics(
+	ctx context.Context,
+	resource dynamic.ResourceInterface,
+	listOptions metav1.ListOptions,
+	usageOf func(unstructured.Unstructured) podResources,
+) (usage map[string]podResources, available bool, err error) {
+	metrics, err := resource.List(ctx, listOptions)
+	if err != nil {
+		if isMetricsUnavailable(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	usage = make(map[string]podResources, len(metrics.Items))
+	for _, item := range metrics.Items {
+		usage[metricsKey(item.GetNamespace(), item.GetName())] = usageOf(item)
+	}
+	return usage, true, nil
+}
+
+// metricsKey returns the key of the metrics of an object in the map returned
+// by listMetrics.
+func metricsKey(namespace, name string) string {
+	return namespace + "/" + name
+}
+
+// podMetricsUsage returns the usage summed over the containers of a
+// PodMetrics.
+func podMetricsUsage(item unstructured.Unstructured) podResources {
+	usage := podResources{measured: true}
+	containers, _, _ := unstructured.NestedSlice(item.Object, "containers")
+	for _, container := range containers {
+		container, ok := container.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		values, _, _ := unstructured.NestedStringMap(container, "usage")
+		cpu, memory := parseUsage(values)
+		usage.cpu += cpu
+		usage.memory += memory
+	}
+	return usage
+}
+
+// nodeMetricsUsage returns the usage of a NodeMetrics.
+func nodeMetricsUsage(item unstructured.Unstructured) podResources {
+	values, _, _ := unstructured.NestedStringMap(item.Object, "usage")
+	cpu, memory := parseUsage(values)
+	return podResources{cpu: cpu, memory: memory, measured: true}
+}
+
+// parseUsage returns the CPU, in millicores, and memory, in bytes, of a usage
+// of the metrics API. Values that do not parse count as zero.
+func parseUsage(values map[string]string) (int64, int64) {
+	var cpu, memory int64
+	if quantity, err := resource.ParseQuantity(values[string(corev1.ResourceCPU)]); err == nil {
+		cpu = quantity.MilliValue()
+	}
+	if quantity, err := resource.ParseQuantity(values[string(corev1.ResourceMemory)]); err == nil {
+		memory = quantity.Value()
+	}
+	return cpu, memory
+}
+
+// podRequestsAndLimits returns the requests and limits of pod, summed over
+// its containers and sidecars, the init containers that keep running along
+// with them.
+func podRequestsAndLimits(pod *corev1.Pod) (podResources, podResources) {
+	containers := slices.Clone(pod.Spec.Containers)
+	for _, container := range pod.Spec.InitContainers {
+		if isSidecar(&container) {
+			containers = append(containers, container)
+		}
+	}
+
+	requests := podResources{}
+	limits := podResources{cpuBounded: true, memoryBounded: true}
+	for _, container := range containers {
+		if quantity, ok := container.Resources.Requests[corev1.ResourceCPU]; ok {
+			requests.cpu += quantity.MilliValue()
+			requests.cpuSet = true
+		}
+		if quantity, ok := container.Resources.Requests[corev1.ResourceMemory]; ok {
+			requests.memory += quantity.Value()
+			requests.memorySet = true
+		}
+		if quantity, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			limits.cpu += quantity.MilliValue()
+		} else {
+			limits.cpuBounded = false
+		}
+		if quantity, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			limits.memory += quantity.Value()
+		} else {
+			limits.memoryBounded = false
+		}
+	}
+	return requests, limits
+}
+
+// format sets the fields of row from its usage, requests and limits.
+func (row *PodUsage) format() {
+	if row.requests.cpuSet {
+		row.CPURequest = formatCPU(row.requests.cpu)
+	}
+	if row.requests.memorySet {
+		row.MemoryRequest = formatMemory(row.requests.memory)
+	}
+	if row.limits.cpuBounded {
+		row.CPULimit = formatCPU(row.limits.cpu)
+	}
+	if row.limits.memoryBounded {
+		row.MemoryLimit = formatMemory(row.limits.memory)
+	}
+	if !row.usage.measured {
+		return
+	}
+	row.CPUUsage = formatCPU(row.usage.cpu)
+	row.MemoryUsage = formatMemory(row.usage.memory)
+	if row.requests.cpuSet {
+		row.CPURequestPercent = percent(row.usage.cpu, row.requests.cpu)
+	}
+	if row.requests.memorySet {
+		row.MemoryRequestPercent = percent(row.usage.memory, row.requests.memory)
+	}
+	if row.limits.cpuBounded {
+		row.CPULimitPercent = percent(row.usage.cpu, row.limits.cpu)
+	}
+	if row.limits.memoryBounded {
+		row.MemoryLimitPercent = percent(row.usage.memory, row.limits.memory)
+	}
+}
+
+// format sets the fields of row from its usage and requests, and the
+// allocatable resources of its node.
+func (row *NodeUsage) format(allocatable corev1.ResourceList) {
+	allocatableCPU := allocatable.Cpu().MilliValue()
+	allocatableMemory := allocatable.Memory().Value()
+	row.CPUAllocatable = formatCPU(allocatableCPU)
+	row.MemoryAllocatable = formatMemory(allocatableMemory)
+	row.CPURequests = formatCPU(row.requests.cpu)
+	row.MemoryRequests = formatMemory(row.requests.memory)
+	row.CPURequestsPercent = percent(row.requests.cpu, allocatableCPU)
+	row.MemoryRequestsPercent = percent(row.requests.memory, allocatableMemory)
+	if row.usage.measured {
+		row.CPUUsage = formatCPU(row.usage.cpu)
+		row.MemoryUsage = formatMemory(row.usage.memory)
+		row.CPUPercent = percent(row.usage.cpu, allocatableCPU)
+		row.MemoryPercent = percent(row.usage.memory, allocatableMemory)
+	}
+}
+
+// addNodeRequests adds the requests of the pods that did not terminate to
+// the rows of their nodes.
+func addNodeRequests(rows map[string]*NodeUsage, pods []corev1.Pod) {
+	for _, pod := range pods {
+		row := rows[pod.Spec.NodeName]
+		if row == nil || isPodTerminated(&pod) {
+			continue
+		}
+		requests, _ := podRequestsAndLimits(&pod)
+		row.requests.cpu += requests.cpu
+		row.requests.memory += requests.memory
+		row.Pods++
+	}
+}
+
+// sortTop sorts rows by decreasing usage, or by decreasing requests when
+// usage is not available, and returns the first of them up to the limit of
+// opts, along with whether others were left out.
+func sortTop[T any](
+	rows []T,
+	opts TopOptions,
+	byUsage bool,
+	resources func(T) (usage, requests podResources),
+) ([]T, bool) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		usageA, requestsA := resources(rows[i])
+		usageB, requestsB := resources(rows[j])
+		if byUsage {
+			return topLess(opts.SortBy, usageB, usageA)
+		}
+		return topLess(opts.SortBy, requestsB, requestsA)
+	})
+	if limit := topLimit(opts.Limit); len(rows) > limit {
+		return rows[:limit], true
+	}
+	return rows, false
+}
+
+// isPodTerminated returns whether all the containers of pod have terminated,
+// so that it no longer uses resources.
+func isPodTerminated(pod *corev1.Pod) bool {
+	return pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed
+}
+
+// topLess returns whether a sorts before b by sortBy, CPU by default.
+func topLess(sortBy string, a, b podResources) bool {
+	if sortBy == TopSortMemory {
+		if a.memory != b.memory {
+			return a.memory < b.memory
+		}
+		return a.cpu < b.cpu
+	}
+	if a.cpu != b.cpu {
+		return a.cpu < b.cpu
+	}
+	return a.memory < b.memory
+}
+
+// topLimit returns the number of rows to return for limit.
+func topLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultTopLimit
+	}
+	return min(limit, MaxTopLimit)
+}
+
+// percent returns value as a rounded percentage of total, or nil if total is
+// not positive.
+func percent(value, total int64) *int64 {
+	if total <= 0 {
+		return nil
+	}
+	p := (value*100 + total/2) / total
+	return &p
+}
+
+// formatCPU formats millicores like `kubectl top`.
+func formatCPU(millicores int64) string {
+	return fmt.Sprintf("%dm", millicores)
+}
+
+// formatMemory formats bytes in mebibytes like `kubectl top`.
+func formatMemory(bytes int64) string {
+	return fmt.Sprintf("%dMi", bytes/(1024*1024))
+}
@@ -0,0 +1,308 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+	"k8s.io/utils/ptr"
+)
+
+func topTestContainer(name, cpuRequest, memoryRequest, cpuLimit, memoryLimit string) corev1.Container {
+	container := corev1.Container{Name: name, Resources: corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{},
+		Limits:   corev1.ResourceList{},
+	}}
+	for _, quantity := range []struct {
+		list  corev1.ResourceList
+		name  corev1.ResourceName
+		value string
+	}{
+		{container.Resources.Requests, corev1.ResourceCPU, cpuRequest},
+		{container.Resources.Requests, corev1.ResourceMemory, memoryRequest},
+		{container.Resources.Limits, corev1.ResourceCPU, cpuLimit},
+		{container.Resources.Limits, corev1.ResourceMemory, memoryLimit},
+	} {
+		if quantity.value != "" {
+			quantity.list[quantity.name] = resource.MustParse(quantity.value)
+		}
+	}
+	return container
+}
+
+// podMetrics returns a PodMetrics, labeled like its pod as by metrics-server.
+func podMetrics(namespace, name string, labels map[string]interface{}, usage ...[2]string) *unstructured.Unstructured {
+	var containers []interface{}
+	for i, u := range usage {
+		containers = append(containers, map[string]interface{}{
+			"name":  []string{"app", "sidecar"}[i],
+			"usage": map[string]interface{}{"cpu": u[0], "memory": u[1]},
+		})
+	}
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "PodMetrics",
+		"metadata":   map[string]interface{}{"name": name, "namespace": namespace, "labels": labels},
+		"containers": containers,
+	}}
+}
+
+func nodeMetrics(name, cpu, memory string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "metrics.k8s.io/v1beta1",
+		"kind":       "NodeMetrics",
+		"metadata":   map[string]interface{}{"name": name},
+		"usage":      map[string]interface{}{"cpu": cpu, "memory": memory},
+	}}
+}
+
+func newTopTestClient(t *testing.T, metricsAvailable bool) *Client {
+	t.Helper()
+	node := func(name, cpu, memory string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{"pool": "default"}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(cpu),
+				corev1.ResourceMemory: resource.MustParse(memory),
+			}},
+		}
+	}
+	clientset := kubefake.NewSimpleClientset(
+		node("node-1", "4", "8Gi"),
+		node("node-2", "2", "4Gi"),
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Labels: map[string]string{"app": "web"}},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{
+				topTestContainer("app", "200m", "256Mi", "1", "512Mi"),
+				topTestContainer("sidecar", "50m", "64Mi", "", "128Mi"),
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop", Labels: map[string]string{"app": "api"}},
+			Spec: corev1.PodSpec{NodeName: "node-2", Containers: []corev1.Container{
+				topTestContainer("app", "500m", "1Gi", "", ""),
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "besteffort", Namespace: "batch"},
+			Spec:       corev1.PodSpec{NodeName: "node-2", Containers: []corev1.Container{{Name: "app"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "done", Namespace: "batch"},
+			Spec: corev1.PodSpec{NodeName: "node-2", Containers: []corev1.Container{
+				topTestContainer("app", "1", "1Gi", "", ""),
+			}},
+			Status: corev1.PodStatus{Phase: corev1.PodSucceeded},
+		},
+	)
+
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{podMetricsGVR: "PodMetricsList", nodeMetricsGVR: "NodeMetricsList"})
+	// The resources of the metrics kinds cannot be guessed from their names
+	for _, metrics := range []*unstructured.Unstructured{
+		podMetrics("shop", "web", map[string]interface{}{"app": "web"}, [2]string{"150m", "200Mi"}, [2]string{"10m", "40Mi"}),
+		podMetrics("shop", "api", map[string]interface{}{"app": "api"}, [2]string{"900m", "300Mi"}),
+		podMetrics("batch", "besteffort", nil, [2]string{"5m", "16Mi"}),
+	} {
+		_, err := dynamicClient.Resource(podMetricsGVR).Namespace(metrics.GetNamespace()).
+			Create(context.Background(), metrics, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	for _, metrics := range []*unstructured.Unstructured{
+		nodeMetrics("node-1", "1", "2Gi"),
+		nodeMetrics("node-2", "1500m", "1Gi"),
+	} {
+		_, err := dynamicClient.Resource(nodeMetricsGVR).Create(context.Background(), metrics, metav1.CreateOptions{})
+		require.NoError(t, err)
+	}
+	if !metricsAvailable {
+		dynamicClient.PrependReactor("list", "*", func(ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "")
+		})
+	}
+
+	client := &Client{}
+	client.SetClientset(clientset)
+	client.SetDynamicClient(dynamicClient)
+	return client
+}
+
+func TestTopPods(t *testing.T) {
+	client := newTopTestClient(t, true)
+
+	result, err := client.TopPods(context.Background(), TopOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.MetricsAvailable)
+	assert.Empty(t, result.Message)
+	assert.Equal(t, 3, result.Total)
+	assert.False(t, result.Truncated)
+	assert.Equal(t, "1065m", result.TotalCPUUsage)
+	assert.Equal(t, "556Mi", result.TotalMemoryUsage)
+
+	require.Len(t, result.Pods, 3)
+	assert.Equal(t, []string{"api", "web", "besteffort"},
+		[]string{result.Pods[0].Name, result.Pods[1].Name, result.Pods[2].Name})
+
+	assert.Equal(t, PodUsage{
+		Namespace:            "shop",
+		Name:                 "web",
+		Node:                 "node-1",
+		CPUUsage:             "160m",
+		CPURequest:           "250m",
+		MemoryUsage:          "240Mi",
+		MemoryRequest:        "320Mi",
+		MemoryLimit:          "640Mi",
+		CPURequestPercent:    ptr.To[int64](64),
+		MemoryRequestPercent: ptr.To[int64](75),
+		MemoryLimitPercent:   ptr.To[int64](38),
+	}, withoutTopResources(result.Pods[1]))
+
+	assert.Equal(t, int64(180), *result.Pods[0].CPURequestPercent)
+	assert.Nil(t, result.Pods[0].CPULimitPercent)
+	assert.Empty(t, result.Pods[2].CPURequest)
+	assert.Nil(t, result.Pods[2].CPURequestPercent)
+}
+
+func TestTopPodsFilters(t *testing.T) {
+	client := newTopTestClient(t, true)
+
+	result, err := client.TopPods(context.Background(), TopOptions{Namespace: "shop", SortBy: TopSortMemory})
+	require.NoError(t, err)
+	require.Len(t, result.Pods, 2)
+	assert.Equal(t, "api", result.Pods[0].Name)
+
+	result, err = client.TopPods(context.Background(), TopOptions{LabelSelector: "app=web"})
+	require.NoError(t, err)
+	require.Len(t, result.Pods, 1)
+	assert.Equal(t, "web", result.Pods[0].Name)
+
+	result, err = client.TopPods(context.Background(), TopOptions{Node: "node-2", Limit: 1})
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Total)
+	assert.True(t, result.Truncated)
+	require.Len(t, result.Pods, 1)
+	assert.Equal(t, "api", result.Pods[0].Name)
+
+	_, err = client.TopPods(context.Background(), TopOptions{SortBy: "disk"})
+	assert.ErrorContains(t, err, "invalid sort")
+	_, err = client.TopPods(context.Background(), TopOptions{Limit: MaxTopLimit + 1})
+	assert.ErrorContains(t, err, "limit must not exceed")
+}
+
+func TestTopPodsWithoutMetrics(t *testing.T) {
+	client := newTopTestClient(t, false)
+
+	result, err := client.TopPods(context.Background(), TopOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.MetricsAvailable)
+	assert.Contains(t, result.Message, "metrics-server")
+	assert.Empty(t, result.TotalCPUUsage)
+
+	// Sorted by requests, without the terminated pod
+	require.Len(t, result.Pods, 3)
+	assert.Equal(t, "api", result.Pods[0].Name)
+	assert.Equal(t, "500m", result.Pods[0].CPURequest)
+	assert.Empty(t, result.Pods[0].CPUUsage)
+	assert.Nil(t, result.Pods[0].CPURequestPercent)
+}
+
+func TestTopNodes(t *testing.T) {
+	client := newTopTestClient(t, true)
+
+	result, err := client.TopNodes(context.Background(), TopOptions{})
+	require.NoError(t, err)
+	assert.True(t, result.MetricsAvailable)
+	assert.Equal(t, 2, result.Total)
+	require.Len(t, result.Nodes, 2)
+
+	assert.Equal(t, NodeUsage{
+		Name:                  "node-2",
+		CPUUsage:              "1500m",
+		CPUAllocatable:        "2000m",
+		CPURequests:           "500m",
+		MemoryUsage:           "1024Mi",
+		MemoryAllocatable:     "4096Mi",
+		MemoryRequests:        "1024Mi",
+		Pods:                  2,
+		CPUPercent:            ptr.To[int64](75),
+		CPURequestsPercent:    ptr.To[int64](25),
+		MemoryPercent:         ptr.To[int64](25),
+		MemoryRequestsPercent: ptr.To[int64](25),
+	}, withoutNodeResources(result.Nodes[0]))
+	assert.Equal(t, "node-1", result.Nodes[1].Name)
+	assert.Equal(t, 1, result.Nodes[1].Pods)
+
+	result, err = client.TopNodes(context.Background(), TopOptions{SortBy: TopSortMemory})
+	require.NoError(t, err)
+	assert.Equal(t, "node-1", result.Nodes[0].Name)
+
+	result, err = client.TopNodes(context.Background(), TopOptions{Node: "node-1"})
+	require.NoError(t, err)
+	require.Len(t, result.Nodes, 1)
+	assert.Equal(t, "node-1", result.Nodes[0].Name)
+}
+
+func TestTopNodesWithoutMetrics(t *testing.T) {
+	client := newTopTestClient(t, false)
+
+	result, err := client.TopNodes(context.Background(), TopOptions{})
+	require.NoError(t, err)
+	assert.False(t, result.MetricsAvailable)
+	assert.NotEmpty(t, result.Message)
+	require.Len(t, result.Nodes, 2)
+	assert.Equal(t, "node-2", result.Nodes[0].Name)
+	assert.Empty(t, result.Nodes[0].CPUUsage)
+	assert.Nil(t, result.Nodes[0].CPUPercent)
+	assert.Equal(t, "500m", result.Nodes[0].CPURequests)
+}
+
+func TestTopOtherMetricsError(t *testing.T) {
+	client := newTopTestClient(t, true)
+	client.dynamicClient.(*dynamicfake.FakeDynamicClient).PrependReactor("list", "*",
+		func(ktesting.Action) (bool, runtime.Object, error) {
+			return true, nil, apierrors.NewForbidden(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, "", nil)
+		})
+
+	_, err := client.TopPods(context.Background(), TopOptions{})
+	assert.ErrorContains(t, err, "failed to list pod metrics")
+}
+
+func withoutTopResources(row PodUsage) PodUsage {
+	row.usage, row.requests, row.limits = podResources{}, podResources{}, podResources{}
+	return row
+}
+
+func withoutNodeResources(row NodeUsage) NodeUsage {
+	row.usage, row.requests = podResources{}, podResources{}
+	return row
+}
+
+func TestPodRequestsAndLimitsSidecars(t *testing.T) {
+	sidecar := topTestContainer("proxy", "100m", "64Mi", "200m", "128Mi")
+	sidecar.RestartPolicy = ptr.To(corev1.ContainerRestartPolicyAlways)
+	pod := &corev1.Pod{Spec: corev1.PodSpec{
+		InitContainers: []corev1.Container{topTestContainer("migrate", "2", "1Gi", "", ""), sidecar},
+		Containers:     []corev1.Container{topTestContainer("app", "250m", "256Mi", "500m", "512Mi")},
+	}}
+
+	requests, limits := podRequestsAndLimits(pod)
+	assert.Equal(t, int64(350), requests.cpu)
+	assert.Equal(t, int64(320*1024*1024), requests.memory)
+	assert.True(t, limits.cpuBounded)
+	assert.Equal(t, int64(700), limits.cpu)
+	assert.Equal(t, int64(640*1024*1024), limits.memory)
+}
@@ -0,0 +1,587 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// TraceKindService traces a Service.
+	TraceKindService = "Service"
+	// TraceKindIngress traces the Services of the backends of an Ingress.
+	TraceKindIngress = "Ingress"
+	// TraceKindHTTPRoute traces the Services of the backends of a Gateway API
+	// HTTPRoute.
+	TraceKindHTTPRoute = "HTTPRoute"
+
+	// MaxTracePods is the largest number of pods listed for each Service of
+	// a TraceResult.
+	MaxTracePods = 50
+)
+
+const (
+	// IssueError is an issue that breaks the traffic to some or all pods.
+	IssueError = "error"
+	// IssueWarning is an issue that may break the traffic.
+	IssueWarning = "warning"
+	// IssueInfo is a remark on an unusual but valid configuration.
+	IssueInfo = "info"
+)
+
+const (
+	// IssueServiceNotFound is a backend referencing a missing Service.
+	IssueServiceNotFound = "ServiceNotFound"
+	// IssueServicePortNotFound is a backend referencing a port the Service
+	// does not have.
+	IssueServicePortNotFound = "ServicePortNotFound"
+	// IssueUnsupportedBackend is a backend that is not a Service.
+	IssueUnsupportedBackend = "UnsupportedBackend"
+	// IssueRouteNotAccepted is an HTTPRoute a Gateway did not accept, or whose
+	// references it could not resolve.
+	IssueRouteNotAccepted = "RouteNotAccepted"
+	// IssueNoSelector is a Service without selector, whose endpoints are
+	// managed by hand.
+	IssueNoSelector = "NoSelector"
+	// IssueExternalName is an ExternalName Service, which has no endpoints.
+	IssueExternalName = "ExternalName"
+	// IssueSelectorMatchesNoPods is a Service whose selector matches no pods.
+	IssueSelectorMatchesNoPods = "SelectorMatchesNoPods"
+	// IssueTargetPortNotExposed is a target port not declared by the
+	// containers of some pods.
+	IssueTargetPortNotExposed = "TargetPortNotExposed"
+	// IssuePodsNotReady is a Service some of whose pods are not ready.
+	IssuePodsNotReady = "PodsNotReady"
+	// IssueNoReadyEndpoints is a Service without ready endpoints, to which
+	// connections fail.
+	IssueNoReadyEndpoints = "NoReadyEndpoints"
+)
+
+// endpointReady is the state of a ready endpoint of an EndpointSlice.
+const endpointReady = "ready"
+
+// httpRoutesGVR is the resource of the Gateway API HTTPRoutes.
+var httpRoutesGVR = schema.GroupVersionResource{Group: "gateway.networking.k8s.io", Version: "v1", Resource: "httproutes"}
+
+// TraceIssue is a misconfiguration found by TraceService.
+type TraceIssue struct {
+	Severity string `json:"severity"`
+	Type     string `json:"type"`
+	Message  string `json:"message"`
+}
+
+// TraceResult is the result of TraceService.
+type TraceResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Backends are the backends of an Ingress or HTTPRoute.
+	Backends []TraceBackend `json:"backends,omitempty"`
+	// Services are the traced Services.
+	Services []ServiceTrace `json:"services"`
+	// Issues are those of the Ingress or HTTPRoute and of all the Services.
+	Issues []TraceIssue `json:"issues"`
+}
+
+// TraceBackend is a backend of an Ingress or HTTPRoute.
+type TraceBackend struct {
+	// Host and Path are those the backend serves, if any.
+	Host      string `json:"host,omitempty"`
+	Path      string `json:"path,omitempty"`
+	Service   string `json:"service"`
+	Namespace string `json:"namespace"`
+	Port      string `json:"port,omitempty"`
+}
+
+// ServiceTrace is how a Service reaches its pods.
+type ServiceTrace struct {
+	Namespace string            `json:"namespace"`
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	ClusterIP string            `json:"clusterIP,omitempty"`
+	Selector  map[string]string `json:"selector,omitempty"`
+	Ports     []ServicePortInfo `json:"ports"`
+	// ReadyEndpoints and Endpoints count the addresses of the EndpointSlices
+	// of the Service.
+	ReadyEndpoints int `json:"readyEndpoints"`
+	Endpoints      int `json:"endpoints"`
+	// Pods are the pods matching the selector, the first MaxTracePods of
+	// TotalPods.
+	Pods      []TracedPod `json:"pods"`
+	TotalPods int         `json:"totalPods"`
+}
+
+// ServicePortInfo is a port of a Service, with the container ports its
+// target port resolves to on the pods.
+type ServicePortInfo struct {
+	Name       string `json:"name,omitempty"`
+	Port       int32  `json:"port"`
+	Protocol   string `json:"protocol"`
+	TargetPort string `json:"targetPort"`
+	// ContainerPorts are the distinct ports the target port resolves to.
+	ContainerPorts []int32 `json:"containerPorts,omitempty"`
+}
+
+// TracedPod is a pod matching the selector of a Service.
+type TracedPod struct {
+	Name  string `json:"name"`
+	Phase string `json:"phase"`
+	Ready bool   `json:"ready"`
+	Node  string `json:"node,omitempty"`
+	IP    string `json:"ip,omitempty"`
+	// Endpoint is the state of the pod in the EndpointSlices: ready,
+	// serving, terminating, notReady, or empty when it is not listed.
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// TraceService resolves how traffic to a Service, or to the backends of an
+// Ingress or HTTPRoute, reaches pods: the selector of each Service, its
+// EndpointSlices and the pods they list, with their readiness and the
+// container ports the target ports resolve to, including named ports. Common
+// misconfigurations are reported as issues.
+func (c *Client) TraceService(ctx context.Context, kind, namespace, name string) (*TraceResult, error) {
+	if namespace == "" || name == "" {
+		return nil, fmt.Errorf("namespace and name cannot be empty")
+	}
+	c.mu.RLock()
+	clientset := c.clientset
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	if clientset == nil {
+		return nil, fmt.Errorf("clientset is not initialized")
+	}
+
+	t := &serviceTracer{
+		clientset: clientset,
+		result: &TraceResult{
+			Kind:      kind,
+			Namespace: namespace,
+			Name:      name,
+			Services:  []ServiceTrace{},
+			Issues:    []TraceIssue{},
+		},
+		traced: map[string]*corev1.Service{},
+	}
+
+	var err error
+	switch kind {
+	case TraceKindService:
+		var svc *corev1.Service
+		if svc, err = clientset.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{}); err != nil {
+			return nil, fmt.Errorf("failed to get service: %w", err)
+		}
+		err = t.traceService(ctx, svc)
+	case TraceKindIngress:
+		err = t.traceIngress(ctx, namespace, name)
+	case TraceKindHTTPRoute:
+		if dynamicClient == nil {
+			return nil, fmt.Errorf("dynamic client is not initialized")
+		}
+		err = t.traceHTTPRoute(ctx, dynamicClient, namespace, name)
+	default:
+		return nil, fmt.Errorf("unsupported kind %q: must be %s, %s or %s",
+			kind, TraceKindService, TraceKindIngress, TraceKindHTTPRoute)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t.result, nil
+}
+
+// serviceTracer builds a TraceResult.
+type serviceTracer struct {
+	clientset kubernetes.Interface
+	result    *TraceResult
+	// traced holds the Services already traced, or nil for those not found,
+	// by namespace/name.
+	traced map[string]*corev1.Service
+}
+
+// issue records an issue of the result.
+func (t *serviceTracer) issue(severity, issueType, format string, args ...interface{}) {
+	t.result.Issues = append(t.result.Issues, TraceIssue{
+		Severity: severity,
+		Type:     issueType,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// traceIngress traces the Services of the backends of an Ingress.
+func (t *serviceTracer) traceIngress(ctx context.Context, namespace, name string) error {
+	ingress, err := t.clientset.NetworkingV1().Ingresses(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get ingress: %w", err)
+	}
+
+	type ingressBackend struct {
+		host, path string
+		backend    networkingv1.IngressBackend
+	}
+	var backends []ingressBackend
+	if ingress.Spec.DefaultBackend != nil {
+		backends = append(backends, ingressBackend{backend: *ingress.Spec.DefaultBackend})
+	}
+	for _, rule := range ingress.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			backends = append(backends, ingressBackend{host: rule.Host, path: path.Path, backend: path.Backend})
+		}
+	}
+
+	for _, b := range backends {
+		if b.backend.Service == nil {
+			t.issue(IssueInfo, IssueUnsupportedBackend,
+				"backend of host %q and path %q is not a Service and is not traced", b.host, b.path)
+			continue
+		}
+		port := intstr.FromInt32(b.backend.Service.Port.Number)
+		if b.backend.Service.Port.Name != "" {
+			port = intstr.FromString(b.backend.Service.Port.Name)
+		}
+		if err := t.traceBackend(ctx, TraceBackend{
+			Host:      b.host,
+			Path:      b.path,
+			Service:   b.backend.Service.Name,
+			Namespace: namespace,
+			Port:      port.String(),
+		}, port); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceHTTPRoute traces the Services of the backends of an HTTPRoute, and
+// reports the conditions of the Gateways that did not accept it.
+func (t *serviceTracer) traceHTTPRoute(ctx context.Context, dynamicClient dynamic.Interface, namespace, name string) error {
+	route, err := dynamicClient.Resource(httpRoutesGVR).Namespace(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get HTTPRoute: %w", err)
+	}
+
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	host := strings.Join(hostnames, ",")
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		rule, _ := rule.(map[string]interface{})
+		path := httpRoutePath(rule)
+		backendRefs, _, _ := unstructured.NestedSlice(rule, "backendRefs")
+		for _, ref := range backendRefs {
+			ref, _ := ref.(map[string]interface{})
+			if err := t.traceBackendRef(ctx, namespace, host, path, ref); err != nil {
+				return err
+			}
+		}
+	}
+
+	parents, _, _ := unstructured.NestedSlice(route.Object, "status", "parents")
+	for _, parent := range parents {
+		parent, _ := parent.(map[string]interface{})
+		gateway, _, _ := unstructured.NestedString(parent, "parentRef", "name")
+		conditions, _, _ := unstructured.NestedSlice(parent, "conditions")
+		for _, condition := range conditions {
+			condition, _ := condition.(map[string]interface{})
+			conditionType, _ := condition["type"].(string)
+			status, _ := condition["status"].(string)
+			if (conditionType == "Accepted" || conditionType == "ResolvedRefs") && status == "False" {
+				t.issue(IssueError, IssueRouteNotAccepted, "gateway %s reports %s=False: %v: %v",
+					gateway, conditionType, condition["reason"], condition["message"])
+			}
+		}
+	}
+	return nil
+}
+
+// traceBackendRef traces a backendRef of an HTTPRoute rule.
+func (t *serviceTracer) traceBackendRef(ctx context.Context, namespace, host, path string, ref map[string]interface{}) error {
+	group, _ := ref["group"].(string)
+	kind, _ := ref["kind"].(string)
+	serviceName, _ := ref["name"].(string)
+	if (group != "" && group != "core") || (kind != "" && kind != TraceKindService) {
+		t.issue(IssueInfo, IssueUnsupportedBackend, "backend %s %s is not a Service and is not traced", kind, serviceName)
+		return nil
+	}
+	if refNamespace, _ := ref["namespace"].(string); refNamespace != "" {
+		namespace = refNamespace
+	}
+	port, _, _ := unstructured.NestedInt64(ref, "port")
+	backend := TraceBackend{Host: host, Path: path, Service: serviceName, Namespace: namespace}
+	if port != 0 {
+		backend.Port = fmt.Sprint(port)
+	}
+	return t.traceBackend(ctx, backend, intstr.FromInt32(int32(port))) //nolint:gosec // G115 -- ports fit in int32
+}
+
+// httpRoutePath returns the path of the first match of an HTTPRoute rule.
+func httpRoutePath(rule map[string]interface{}) string {
+	matches, _, _ := unstructured.NestedSlice(rule, "matches")
+	if len(matches) == 0 {
+		return ""
+	}
+	match, _ := matches[0].(map[string]interface{})
+	path, _, _ := unstructured.NestedString(match, "path", "value")
+	return path
+}
+
+// traceBackend records backend, checks that its Service has port, unless it
+// is zero, and traces the Service.
+func (t *serviceTracer) traceBackend(ctx context.Context, backend TraceBackend, port intstr.IntOrString) error {
+	t.result.Backends = append(t.result.Backends, backend)
+
+	key := backend.Namespace + "/" + backend.Service
+	svc, seen := t.traced[key]
+	if !seen {
+		var err error
+		svc, err = t.clientset.CoreV1().Services(backend.Namespace).Get(ctx, backend.Service, metav1.GetOptions{})
+		switch {
+		case apierrors.IsNotFound(err):
+			svc = nil
+			t.issue(IssueError, IssueServiceNotFound, "service %s referenced by the backend does not exist", key)
+		case err != nil:
+			return fmt.Errorf("failed to get service %s: %w", key, err)
+		default:
+			if err := t.traceService(ctx, svc); err != nil {
+				return err
+			}
+		}
+		t.traced[key] = svc
+	}
+
+	if svc == nil || (port.Type == intstr.Int && port.IntVal == 0) {
+		return nil
+	}
+	for _, p := range svc.Spec.Ports {
+		if (port.Type == intstr.Int && p.Port == port.IntVal) || (port.Type == intstr.String && p.Name == port.StrVal) {
+			return nil
+		}
+	}
+	t.issue(IssueError, IssueServicePortNotFound, "service %s has no port %s referenced by the backend", key, port.String())
+	return nil
+}
+
+// traceService traces svc and adds it to the result.
+func (t *serviceTracer) traceService(ctx context.Context, svc *corev1.Service) error {
+	trace := ServiceTrace{
+		Namespace: svc.Namespace,
+		Name:      svc.Name,
+		Type:      string(svc.Spec.Type),
+		ClusterIP: svc.Spec.ClusterIP,
+		Selector:  svc.Spec.Selector,
+		Ports:     []ServicePortInfo{},
+		Pods:      []TracedPod{},
+	}
+	if trace.Type == "" {
+		trace.Type = string(corev1.ServiceTypeClusterIP)
+	}
+	for _, p := range svc.Spec.Ports {
+		targetPort := p.TargetPort
+		if targetPort.Type == intstr.Int && targetPort.IntVal == 0 {
+			targetPort = intstr.FromInt32(p.Port)
+		}
+		trace.Ports = append(trace.Ports, ServicePortInfo{
+			Name:       p.Name,
+			Port:       p.Port,
+			Protocol:   string(p.Protocol),
+			TargetPort: targetPort.String(),
+		})
+	}
+	key := svc.Namespace + "/" + svc.Name
+
+	if svc.Spec.Type == corev1.ServiceTypeExternalName {
+		t.issue(IssueInfo, IssueExternalName,
+			"service %s is an alias of %s and has no endpoints", key, svc.Spec.ExternalName)
+		t.result.Services = append(t.result.Services, trace)
+		return nil
+	}
+
+	endpointStates, err := t.traceEndpoints(ctx, svc, &trace)
+	if err != nil {
+		return err
+	}
+
+	if len(svc.Spec.Selector) == 0 {
+		t.issue(IssueInfo, IssueNoSelector,
+			"service %s has no selector: its endpoints are managed outside of Kubernetes' control", key)
+	} else if err := t.tracePods(ctx, svc, &trace, endpointStates); err != nil {
+		return err
+	}
+
+	if trace.ReadyEndpoints == 0 && (len(svc.Spec.Selector) == 0 || trace.TotalPods > 0) {
+		t.issue(IssueError, IssueNoReadyEndpoints,
+			"service %s has no ready endpoints: connections to it are refused or time out", key)
+	}
+	t.result.Services = append(t.result.Services, trace)
+	return nil
+}
+
+// traceEndpoints counts the endpoints of the EndpointSlices of svc in trace,
+// and returns the state of the endpoint of each pod, by name.
+func (t *serviceTracer) traceEndpoints(ctx context.Context, svc *corev1.Service, trace *ServiceTrace) (map[string]string, error) {
+	endpointSlices, err := t.clientset.DiscoveryV1().EndpointSlices(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: discoveryv1.LabelServiceName + "=" + svc.Name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list endpoint slices of service %s/%s: %w", svc.Namespace, svc.Name, err)
+	}
+	states := map[string]string{}
+	for _, endpointSlice := range endpointSlices.Items {
+		for _, endpoint := range endpointSlice.Endpoints {
+			state := endpointState(endpoint.Conditions)
+			trace.Endpoints += len(endpoint.Addresses)
+			if state == endpointReady {
+				trace.ReadyEndpoints += len(endpoint.Addresses)
+			}
+			if endpoint.TargetRef != nil && endpoint.TargetRef.Kind == kindPod {
+				states[endpoint.TargetRef.Name] = state
+			}
+		}
+	}
+	return states, nil
+}
+
+// tracePods adds the pods matching the selector of svc to trace, resolving
+// its target ports on them.
+func (t *serviceTracer) tracePods(
+	ctx context.Context,
+	svc *corev1.Service,
+	trace *ServiceTrace,
+	endpointStates map[string]string,
+) error {
+	key := svc.Namespace + "/" + svc.Name
+	pods, err := t.clientset.CoreV1().Pods(svc.Namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pods of service %s: %w", key, err)
+	}
+	sort.Slice(pods.Items, func(i, j int) bool { return pods.Items[i].Name < pods.Items[j].Name })
+
+	trace.TotalPods = len(pods.Items)
+	if trace.TotalPods == 0 {
+		t.issue(IssueError, IssueSelectorMatchesNoPods, "selector %s of service %s matches no pods",
+			labels.SelectorFromSet(svc.Spec.Selector), key)
+		return nil
+	}
+
+	var notReady []string
+	unresolved := make([][]string, len(svc.Spec.Ports))
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		ready := isPodReady(pod)
+		if !ready {
+			notReady = append(notReady, pod.Name)
+		}
+		if len(trace.Pods) < MaxTracePods {
+			trace.Pods = append(trace.Pods, TracedPod{
+				Name:     pod.Name,
+				Phase:    string(pod.Status.Phase),
+				Ready:    ready,
+				Node:     pod.Spec.NodeName,
+				IP:       pod.Status.PodIP,
+				Endpoint: endpointStates[pod.Name],
+			})
+		}
+		for j := range trace.Ports {
+			port, ok := resolveTargetPort(pod, svc.Spec.Ports[j].Protocol, trace.Ports[j].TargetPort)
+			if !ok {
+				unresolved[j] = append(unresolved[j], pod.Name)
+			} else if !slices.Contains(trace.Ports[j].ContainerPorts, port) {
+				trace.Ports[j].ContainerPorts = append(trace.Ports[j].ContainerPorts, port)
+			}
+		}
+	}
+
+	for j, pods := range unresolved {
+		if len(pods) == 0 {
+			continue
+		}
+		port := trace.Ports[j]
+		if intstr.Parse(port.TargetPort).Type == intstr.String {
+			// Pods without a named port are left out of the endpoints
+			t.issue(IssueError, IssueTargetPortNotExposed,
+				"named targetPort %q of port %d of service %s is not declared by the containers of %s, "+
+					"which do not receive its traffic", port.TargetPort, port.Port, key, podList(pods))
+		} else {
+			t.issue(IssueWarning, IssueTargetPortNotExposed,
+				"targetPort %s of port %d of service %s is not declared by the containers of %s: "+
+					"check that they listen on it", port.TargetPort, port.Port, key, podList(pods))
+		}
+	}
+	if len(notReady) > 0 {
+		t.issue(IssueWarning, IssuePodsNotReady, "%d of %d pods of service %s are not ready: %s",
+			len(notReady), len(pods.Items), key, podList(notReady))
+	}
+	return nil
+}
+
+// resolveTargetPort returns the container port of pod targetPort resolves
+// to, and whether a container declares it.
+func resolveTargetPort(pod *corev1.Pod, protocol corev1.Protocol, targetPort string) (int32, bool) {
+	if protocol == "" {
+		protocol = corev1.ProtocolTCP
+	}
+	for _, container := range pod.Spec.Containers {
+		for _, port := range container.Ports {
+			portProtocol := port.Protocol
+			if portProtocol == "" {
+				portProtocol = corev1.ProtocolTCP
+			}
+			if portProtocol != protocol {
+				continue
+			}
+			if port.Name == targetPort || fmt.Sprint(port.ContainerPort) == targetPort {
+				return port.ContainerPort, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// endpointState returns the state of an endpoint of an EndpointSlice.
+func endpointState(conditions discoveryv1.EndpointConditions) string {
+	switch {
+	case conditions.Ready == nil || *conditions.Ready:
+		return endpointReady
+	case conditions.Terminating != nil && *conditions.Terminating:
+		return "terminating"
+	case conditions.Serving != nil && *conditions.Serving:
+		return "serving"
+	default:
+		return "notReady"
+	}
+}
+
+// isPodReady returns whether the Ready condition of pod is true.
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// podList formats the names of pods for an issue, listing the first few.
+func podList(pods []string) string {
+	const shown = 5
+	if len(pods) <= shown {
+		return strings.Join(pods, ", ")
+	}
+	return fmt.Sprintf("%s and %d more", strings.Join(pods[:shown], ", "), len(pods)-shown)
+}
@@ -0,0 +1,289 @@
+package k8s
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+)
+
+func tracePod(name string, labels map[string]string, ready bool, ports ...corev1.ContainerPort) *corev1.Pod {
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "shop", Labels: labels},
+		Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{
+			{Name: "app", Ports: ports},
+		}},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			PodIP:      "10.0.0." + name[len(name)-1:],
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+func traceEndpointSlice(service string, endpoints map[string]bool) *discoveryv1.EndpointSlice {
+	slice := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      service + "-abc",
+			Namespace: "shop",
+			Labels:    map[string]string{discoveryv1.LabelServiceName: service},
+		},
+	}
+	for pod, ready := range endpoints {
+		slice.Endpoints = append(slice.Endpoints, discoveryv1.Endpoint{
+			Addresses:  []string{"10.0.0." + pod[len(pod)-1:]},
+			Conditions: discoveryv1.EndpointConditions{Ready: ptr.To(ready)},
+			TargetRef:  &corev1.ObjectReference{Kind: "Pod", Name: pod},
+		})
+	}
+	return slice
+}
+
+func newTraceTestClient(t *testing.T, objects ...runtime.Object) *Client {
+	t.Helper()
+	web := map[string]string{"app": "web"}
+	httpPort := corev1.ContainerPort{Name: "http", ContainerPort: 8080}
+	objects = append(objects,
+		// web has a ready pod and a pod that is not ready
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{
+				ClusterIP: "10.96.0.10",
+				Selector:  web,
+				Ports: []corev1.ServicePort{
+					{Name: "http", Port: 80, Protocol: corev1.ProtocolTCP, TargetPort: intstr.FromString("http")},
+					{Name: "metrics", Port: 9090, Protocol: corev1.ProtocolTCP},
+				},
+			},
+		},
+		tracePod("web-1", web, true, httpPort),
+		tracePod("web-2", web, false, httpPort),
+		traceEndpointSlice("web", map[string]bool{"web-1": true, "web-2": false}),
+		// api selects no pods
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "api", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "api"},
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromInt32(8080)}},
+			},
+		},
+		// admin targets a named port its pod does not declare
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "admin", Namespace: "shop"},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": "admin"},
+				Ports:    []corev1.ServicePort{{Port: 80, TargetPort: intstr.FromString("admin")}},
+			},
+		},
+		tracePod("admin-1", map[string]string{"app": "admin"}, true, httpPort),
+		&corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: "external", Namespace: "shop"},
+			Spec:       corev1.ServiceSpec{Type: corev1.ServiceTypeExternalName, ExternalName: "db.example.com"},
+		},
+	)
+
+	route := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "gateway.networking.k8s.io/v1",
+		"kind":       "HTTPRoute",
+		"metadata":   map[string]interface{}{"name": "shop", "namespace": "shop"},
+		"spec": map[string]interface{}{
+			"hostnames": []interface{}{"shop.example.com"},
+			"rules": []interface{}{map[string]interface{}{
+				"matches": []interface{}{map[string]interface{}{
+					"path": map[string]interface{}{"type": "PathPrefix", "value": "/api"},
+				}},
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "web", "port": int64(80)},
+					map[string]interface{}{"name": "missing", "port": int64(80)},
+					map[string]interface{}{"group": "example.com", "kind": "Bucket", "name": "assets"},
+				},
+			}},
+		},
+		"status": map[string]interface{}{"parents": []interface{}{map[string]interface{}{
+			"parentRef": map[string]interface{}{"name": "public"},
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Accepted", "status": "True"},
+				map[string]interface{}{
+					"type": "ResolvedRefs", "status": "False", "reason": "BackendNotFound",
+					"message": "service missing not found",
+				},
+			},
+		}}},
+	}}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{httpRoutesGVR: "HTTPRouteList"})
+	_, err := dynamicClient.Resource(httpRoutesGVR).Namespace("shop").
+		Create(context.Background(), route, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	client := &Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(objects...))
+	client.SetDynamicClient(dynamicClient)
+	return client
+}
+
+func issueTypes(issues []TraceIssue) []string {
+	var types []string
+	for _, issue := range issues {
+		types = append(types, issue.Severity+":"+issue.Type)
+	}
+	return types
+}
+
+func TestTraceService(t *testing.T) {
+	client := newTraceTestClient(t)
+
+	result, err := client.TraceService(context.Background(), TraceKindService, "shop", "web")
+	require.NoError(t, err)
+	assert.Empty(t, result.Backends)
+	require.Len(t, result.Services, 1)
+
+	trace := result.Services[0]
+	assert.Equal(t, "ClusterIP", trace.Type)
+	assert.Equal(t, 2, trace.Endpoints)
+	assert.Equal(t, 1, trace.ReadyEndpoints)
+	assert.Equal(t, 2, trace.TotalPods)
+	assert.Equal(t, []ServicePortInfo{
+		{Name: "http", Port: 80, Protocol: "TCP", TargetPort: "http", ContainerPorts: []int32{8080}},
+		{Name: "metrics", Port: 9090, Protocol: "TCP", TargetPort: "9090"},
+	}, trace.Ports)
+	assert.Equal(t, []TracedPod{
+		{Name: "web-1", Phase: "Running", Ready: true, Node: "node-1", IP: "10.0.0.1", Endpoint: "ready"},
+		{Name: "web-2", Phase: "Running", Node: "node-1", IP: "10.0.0.2", Endpoint: "notReady"},
+	}, trace.Pods)
+
+	assert.Equal(t, []string{"warning:TargetPortNotExposed", "warning:PodsNotReady"}, issueTypes(result.Issues))
+	assert.Contains(t, result.Issues[0].Message, "targetPort 9090 of port 9090")
+	assert.Contains(t, result.Issues[1].Message, "1 of 2 pods")
+}
+
+func TestTraceServiceMisconfigured(t *testing.T) {
+	client := newTraceTestClient(t)
+
+	result, err := client.TraceService(context.Background(), TraceKindService, "shop", "api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"error:SelectorMatchesNoPods"}, issueTypes(result.Issues))
+	assert.Contains(t, result.Issues[0].Message, "app=api")
+
+	result, err = client.TraceService(context.Background(), TraceKindService, "shop", "admin")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"error:TargetPortNotExposed", "error:NoReadyEndpoints"}, issueTypes(result.Issues))
+	assert.Contains(t, result.Issues[0].Message, `named targetPort "admin"`)
+	assert.Empty(t, result.Services[0].Ports[0].ContainerPorts)
+
+	result, err = client.TraceService(context.Background(), TraceKindService, "shop", "external")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info:ExternalName"}, issueTypes(result.Issues))
+
+	_, err = client.TraceService(context.Background(), TraceKindService, "shop", "missing")
+	assert.ErrorContains(t, err, "failed to get service")
+	_, err = client.TraceService(context.Background(), "Gateway", "shop", "web")
+	assert.ErrorContains(t, err, "unsupported kind")
+}
+
+func TestTraceServiceWithoutSelector(t *testing.T) {
+	client := newTraceTestClient(t, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy", Namespace: "shop"},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 5432}}},
+	})
+
+	result, err := client.TraceService(context.Background(), TraceKindService, "shop", "legacy")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info:NoSelector", "error:NoReadyEndpoints"}, issueTypes(result.Issues))
+}
+
+func TestTraceServiceManyPods(t *testing.T) {
+	var objects []runtime.Object
+	for i := range MaxTracePods + 2 {
+		pod := tracePod(fmt.Sprintf("batch-%03d", i), map[string]string{"app": "batch"}, false)
+		objects = append(objects, pod)
+	}
+	objects = append(objects, &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "batch", Namespace: "shop"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "batch"},
+			Ports:    []corev1.ServicePort{{Port: 80}},
+		},
+	})
+	client := newTraceTestClient(t, objects...)
+
+	result, err := client.TraceService(context.Background(), TraceKindService, "shop", "batch")
+	require.NoError(t, err)
+	assert.Equal(t, MaxTracePods+2, result.Services[0].TotalPods)
+	assert.Len(t, result.Services[0].Pods, MaxTracePods)
+	assert.Contains(t, result.Issues[1].Message, "and 47 more")
+}
+
+func TestTraceIngress(t *testing.T) {
+	pathType := networkingv1.PathTypePrefix
+	client := newTraceTestClient(t, &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "shop", Namespace: "shop"},
+		Spec: networkingv1.IngressSpec{
+			DefaultBackend: &networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+				Name: "web", Port: networkingv1.ServiceBackendPort{Name: "http"},
+			}},
+			Rules: []networkingv1.IngressRule{{
+				Host: "shop.example.com",
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{
+						{Path: "/", PathType: &pathType, Backend: networkingv1.IngressBackend{
+							Service: &networkingv1.IngressServiceBackend{
+								Name: "web", Port: networkingv1.ServiceBackendPort{Number: 8080},
+							},
+						}},
+						{Path: "/static", PathType: &pathType, Backend: networkingv1.IngressBackend{
+							Resource: &corev1.TypedLocalObjectReference{Kind: "Bucket", Name: "assets"},
+						}},
+					},
+				}},
+			}},
+		},
+	})
+
+	result, err := client.TraceService(context.Background(), TraceKindIngress, "shop", "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []TraceBackend{
+		{Service: "web", Namespace: "shop", Port: "http"},
+		{Host: "shop.example.com", Path: "/", Service: "web", Namespace: "shop", Port: "8080"},
+	}, result.Backends)
+	// The Service is traced once
+	require.Len(t, result.Services, 1)
+	assert.Equal(t, []string{
+		"warning:TargetPortNotExposed", "warning:PodsNotReady",
+		"error:ServicePortNotFound", "info:UnsupportedBackend",
+	}, issueTypes(result.Issues))
+}
+
+func TestTraceHTTPRoute(t *testing.T) {
+	client := newTraceTestClient(t)
+
+	result, err := client.TraceService(context.Background(), TraceKindHTTPRoute, "shop", "shop")
+	require.NoError(t, err)
+	assert.Equal(t, []TraceBackend{
+		{Host: "shop.example.com", Path: "/api", Service: "web", Namespace: "shop", Port: "80"},
+		{Host: "shop.example.com", Path: "/api", Service: "missing", Namespace: "shop", Port: "80"},
+	}, result.Backends)
+	require.Len(t, result.Services, 1)
+	assert.Equal(t, []string{
+		"warning:TargetPortNotExposed", "warning:PodsNotReady", "error:ServiceNotFound",
+		"info:UnsupportedBackend", "error:RouteNotAccepted",
+	}, issueTypes(result.Issues))
+	assert.Contains(t, result.Issues[4].Message, "gateway public reports ResolvedRefs=False: BackendNotFound")
+}
@@ -0,0 +1,321 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// MaxSchemaViolations is the largest number of violations ValidateManifest
+// reports.
+const MaxSchemaViolations = 50
+
+const (
+	// ViolationUnknownField is a field the schema does not declare.
+	ViolationUnknownField = "UnknownField"
+	// ViolationTypeMismatch is a value of a type the schema does not allow.
+	ViolationTypeMismatch = "TypeMismatch"
+	// ViolationRequiredField is a required field that is missing.
+	ViolationRequiredField = "RequiredField"
+	// ViolationInvalidValue is a value the schema or the request does not
+	// allow, such as an apiVersion that does not match the resource.
+	ViolationInvalidValue = "InvalidValue"
+)
+
+// SchemaViolation is a problem found in a manifest by ValidateManifest.
+type SchemaViolation struct {
+	// Path is the JSON path of the field, e.g. spec.template.spec.containers[0].image.
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// ValidationError is returned by ValidateManifest when a manifest does not
+// match the schema of its kind.
+type ValidationError struct {
+	Kind       string
+	Violations []SchemaViolation
+	// Truncated is set when violations beyond MaxSchemaViolations were
+	// dropped.
+	Truncated bool
+}
+
+// Error implements error.
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, violation := range e.Violations {
+		messages = append(messages, violation.Path+": "+violation.Message)
+	}
+	return fmt.Sprintf("invalid %s manifest: %s", e.Kind, strings.Join(messages, "; "))
+}
+
+// ValidateManifest checks obj against gvr and the OpenAPI v3 schema of its
+// kind before it is applied: its apiVersion and kind must be those of the
+// resource, and its fields must be declared by the schema, hold values of the
+// declared types, and include the required ones. Problems are returned as a
+// *ValidationError.
+//
+// Only the apiVersion is checked when the cluster does not publish the
+// resource or its schema, leaving the rest to the API server.
+func (c *Client) ValidateManifest(_ context.Context, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+	c.mu.RLock()
+	discoveryClient := c.discoveryClient
+	c.mu.RUnlock()
+
+	v := &manifestValidator{}
+	gv := gvr.GroupVersion()
+	switch apiVersion := obj.GetAPIVersion(); apiVersion {
+	case "":
+		v.add("apiVersion", ViolationRequiredField, "apiVersion is required")
+	case gv.String():
+	default:
+		v.add("apiVersion", ViolationInvalidValue,
+			fmt.Sprintf("apiVersion %q does not match the resource version %q", apiVersion, gv.String()))
+	}
+	if obj.GetKind() == "" {
+		v.add("kind", ViolationRequiredField, "kind is required")
+	}
+
+	kind := obj.GetKind()
+	if discoveryClient != nil {
+		if resources, err := discoveryClient.ServerResourcesForGroupVersion(gv.String()); err == nil {
+			for _, resource := range resources.APIResources {
+				if resource.Name != gvr.Resource {
+					continue
+				}
+				if kind != "" && kind != resource.Kind {
+					v.add("kind", ViolationInvalidValue,
+						fmt.Sprintf("kind %q does not match the kind %q of resource %q", kind, resource.Kind, gvr.Resource))
+				}
+				kind = resource.Kind
+
+				// Only validate the fields once the object is known to be of
+				// the kind, as they would otherwise all be wrong.
+				if len(v.violations) == 0 {
+					if document, err := c.openAPIDocument(gv); err == nil {
+						v.document = document
+						if kindSchema := document.kindSchema(gv.WithKind(kind)); kindSchema != nil {
+							v.validate("", obj.Object, kindSchema)
+						}
+					}
+				}
+				break
+			}
+		}
+	}
+
+	if len(v.violations) == 0 {
+		return nil
+	}
+	if kind == "" {
+		kind = gvr.Resource
+	}
+	return &ValidationError{Kind: kind, Violations: v.violations, Truncated: v.truncated}
+}
+
+// manifestValidator collects the violations of a manifest against the schemas
+// of an OpenAPI v3 document.
+type manifestValidator struct {
+	document   *openAPIDocument
+	violations []SchemaViolation
+	truncated  bool
+}
+
+// add records a violation at path.
+func (v *manifestValidator) add(path, violationType, message string) {
+	if len(v.violations) >= MaxSchemaViolations {
+		v.truncated = true
+		return
+	}
+	v.violations = append(v.violations, SchemaViolation{Path: path, Type: violationType, Message: message})
+}
+
+// validate checks value, at path, against s.
+func (v *manifestValidator) validate(path string, value interface{}, s openAPISchema) {
+	if value == nil {
+		// Null is the same as unset.
+		return
+	}
+	s = v.document.resolve(s)
+	if s["x-kubernetes-int-or-string"] == true || s["format"] == "int-or-string" {
+		if !matchesType(value, "integer") && !matchesType(value, "string") {
+			v.add(path, ViolationTypeMismatch, fmt.Sprintf("expected integer or string, got %s", jsonTypeName(value)))
+		}
+		return
+	}
+
+	types := schemaTypes(s)
+	if len(types) > 0 && !slices.ContainsFunc(types, func(typ string) bool { return matchesType(value, typ) }) {
+		v.add(path, ViolationTypeMismatch,
+			fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(value)))
+		return
+	}
+	if enum, ok := s["enum"].([]interface{}); ok && len(enum) > 0 && !slices.Contains(enum, value) {
+		v.add(path, ViolationInvalidValue, fmt.Sprintf("unsupported value %v, expected one of %v", value, enum))
+	}
+
+	switch value := value.(type) {
+	case []interface{}:
+		items, _ := s["items"].(map[string]interface{})
+		if items == nil {
+			return
+		}
+		for i, item := range value {
+			v.validate(fmt.Sprintf("%s[%d]", path, i), item, items)
+		}
+	case map[string]interface{}:
+		v.validateObject(path, value, s)
+	}
+}
+
+// validateObject checks the fields of object, at path, against s.
+func (v *manifestValidator) validateObject(path string, object map[string]interface{}, s openAPISchema) {
+	properties, _ := s["properties"].(map[string]interface{})
+	additional := s["additionalProperties"]
+	preserveUnknown := s["x-kubernetes-preserve-unknown-fields"] == true
+
+	for _, field := range sortedKeys(object) {
+		fieldPath := joinFieldPath(path, field)
+		if property, ok := properties[field].(map[string]interface{}); ok {
+			v.validate(fieldPath, object[field], property)
+			continue
+		}
+		switch additional := additional.(type) {
+		case map[string]interface{}:
+			v.validate(fieldPath, object[field], additional)
+			continue
+		case bool:
+			if additional {
+				continue
+			}
+		}
+		// Objects declaring no fields are free-form, e.g. RawExtension.
+		if len(properties) > 0 && !preserveUnknown {
+			v.add(fieldPath, ViolationUnknownField, fmt.Sprintf("unknown field %q", field))
+		}
+	}
+
+	for _, field := range schemaStrings(s["required"]) {
+		if _, ok := object[field]; ok {
+			continue
+		}
+		// Fields with a default are set by the API server when missing. Built-in
+		// kinds declare the zero value as the default of fields that are not
+		// pointers, which still fails validation.
+		if property, ok := properties[field].(map[string]interface{}); ok && !isZeroValue(property["default"]) {
+			continue
+		}
+		v.add(joinFieldPath(path, field), ViolationRequiredField, fmt.Sprintf("missing required field %q", field))
+	}
+}
+
+// schemaTypes returns the types s allows, from its type or its oneOf or anyOf
+// alternatives, or nil if it does not restrict the type.
+func schemaTypes(s openAPISchema) []string {
+	if typ, _ := s["type"].(string); typ != "" {
+		return []string{typ}
+	}
+	var types []string
+	for _, key := range []string{"oneOf", "anyOf"} {
+		alternatives, _ := s[key].([]interface{})
+		for _, alternative := range alternatives {
+			alternative, _ := alternative.(map[string]interface{})
+			typ, _ := alternative["type"].(string)
+			if typ == "" {
+				// An alternative of any type
+				return nil
+			}
+			types = append(types, typ)
+		}
+	}
+	return types
+}
+
+// matchesType returns whether value is of the OpenAPI type typ.
+func matchesType(value interface{}, typ string) bool {
+	switch typ {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "integer":
+		switch value := value.(type) {
+		case int, int32, int64:
+			return true
+		case float64:
+			return value == math.Trunc(value)
+		case json.Number:
+			_, err := value.Int64()
+			return err == nil
+		}
+		return false
+	case "number":
+		switch value.(type) {
+		case int, int32, int64, float32, float64, json.Number:
+			return true
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// isZeroValue returns whether value is unset or the zero value of its type.
+func isZeroValue(value interface{}) bool {
+	switch value := value.(type) {
+	case nil:
+		return true
+	case string:
+		return value == ""
+	case bool:
+		return !value
+	case float64:
+		return value == 0
+	case map[string]interface{}:
+		return len(value) == 0
+	case []interface{}:
+		return len(value) == 0
+	default:
+		return false
+	}
+}
+
+// jsonTypeName returns the JSON type of value, for messages.
+func jsonTypeName(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int32, int64, float32, float64, json.Number:
+		return "number"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+// joinFieldPath returns the path of field within the object at path.
+func joinFieldPath(path, field string) string {
+	if path == "" {
+		return field
+	}
+	return path + "." + field
+}
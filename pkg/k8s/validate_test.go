@@ -0,0 +1,204 @@
+package k8s
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newTestDeploymentManifest() *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "labels": map[string]interface{}{"app": "web"}},
+		"spec": map[string]interface{}{
+			// Numbers decoded from JSON are float64
+			"replicas": float64(2),
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+			"strategy": map[string]interface{}{
+				"type":          "RollingUpdate",
+				"rollingUpdate": map[string]interface{}{"maxSurge": "25%", "maxUnavailable": float64(0)},
+			},
+			"template": map[string]interface{}{
+				"metadata": map[string]interface{}{"labels": map[string]interface{}{"app": "web"}},
+				"spec": map[string]interface{}{
+					"containers": []interface{}{map[string]interface{}{
+						"name":      "web",
+						"image":     "nginx:1.27",
+						"ports":     []interface{}{map[string]interface{}{"containerPort": float64(80)}},
+						"resources": map[string]interface{}{"limits": map[string]interface{}{"cpu": float64(1), "memory": "128Mi"}},
+					}},
+				},
+			},
+		},
+	}}
+}
+
+func TestValidateManifestValid(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	err := client.ValidateManifest(context.Background(), deploymentsGVR, newTestDeploymentManifest())
+	assert.NoError(t, err)
+
+	// Fields of custom resources, including int-or-string and maps
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w"},
+		"spec": map[string]interface{}{
+			"size":   "large",
+			"port":   "http",
+			"labels": map[string]interface{}{"a": "b"},
+			"parts":  []interface{}{map[string]interface{}{"name": "bolt"}},
+		},
+	}}
+	err = client.ValidateManifest(context.Background(),
+		schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}, widget)
+	assert.NoError(t, err)
+}
+
+func TestValidateManifestViolations(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	manifest := newTestDeploymentManifest()
+	require.NoError(t, unstructured.SetNestedField(manifest.Object, "two", "spec", "replicas"))
+	require.NoError(t, unstructured.SetNestedField(manifest.Object, true, "spec", "replica"))
+	unstructured.RemoveNestedField(manifest.Object, "spec", "selector")
+	containers, _, _ := unstructured.NestedSlice(manifest.Object, "spec", "template", "spec", "containers")
+	container := containers[0].(map[string]interface{})
+	delete(container, "name")
+	container["ports"] = []interface{}{map[string]interface{}{"containerPort": 80.5}}
+	container["imagePullPolicy"] = []interface{}{"Always"}
+	require.NoError(t, unstructured.SetNestedSlice(manifest.Object, containers, "spec", "template", "spec", "containers"))
+
+	err := client.ValidateManifest(context.Background(), deploymentsGVR, manifest)
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid), "%v", err)
+	assert.Equal(t, "Deployment", invalid.Kind)
+	assert.False(t, invalid.Truncated)
+	assert.ElementsMatch(t, []SchemaViolation{
+		{Path: "spec.replica", Type: ViolationUnknownField, Message: `unknown field "replica"`},
+		{Path: "spec.replicas", Type: ViolationTypeMismatch, Message: "expected integer, got string"},
+		{Path: "spec.selector", Type: ViolationRequiredField, Message: `missing required field "selector"`},
+		{
+			Path: "spec.template.spec.containers[0].imagePullPolicy", Type: ViolationTypeMismatch,
+			Message: "expected string, got array",
+		},
+		{
+			Path: "spec.template.spec.containers[0].ports[0].containerPort", Type: ViolationTypeMismatch,
+			Message: "expected integer, got number",
+		},
+		{
+			Path: "spec.template.spec.containers[0].name", Type: ViolationRequiredField,
+			Message: `missing required field "name"`,
+		},
+	}, invalid.Violations)
+	assert.Contains(t, err.Error(), "invalid Deployment manifest: ")
+}
+
+func TestValidateManifestEnum(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	widget := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "example.com/v1",
+		"kind":       "Widget",
+		"metadata":   map[string]interface{}{"name": "w"},
+		"spec":       map[string]interface{}{"size": "huge", "port": true},
+	}}
+	err := client.ValidateManifest(context.Background(),
+		schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}, widget)
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid), "%v", err)
+	assert.Equal(t, []SchemaViolation{
+		{Path: "spec.port", Type: ViolationTypeMismatch, Message: "expected integer or string, got boolean"},
+		{Path: "spec.size", Type: ViolationInvalidValue, Message: "unsupported value huge, expected one of [small large]"},
+	}, invalid.Violations)
+}
+
+func TestValidateManifestResourceMismatch(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	testCases := []struct {
+		name       string
+		apiVersion string
+		kind       string
+		violation  SchemaViolation
+	}{
+		{
+			name:       "Wrong version",
+			apiVersion: "apps/v1beta1",
+			kind:       "Deployment",
+			violation: SchemaViolation{Path: "apiVersion", Type: ViolationInvalidValue,
+				Message: `apiVersion "apps/v1beta1" does not match the resource version "apps/v1"`},
+		},
+		{
+			name:       "Wrong kind",
+			apiVersion: "apps/v1",
+			kind:       "StatefulSet",
+			violation: SchemaViolation{Path: "kind", Type: ViolationInvalidValue,
+				Message: `kind "StatefulSet" does not match the kind "Deployment" of resource "deployments"`},
+		},
+		{
+			name:      "Missing apiVersion",
+			kind:      "Deployment",
+			violation: SchemaViolation{Path: "apiVersion", Type: ViolationRequiredField, Message: "apiVersion is required"},
+		},
+		{
+			name:       "Missing kind",
+			apiVersion: "apps/v1",
+			violation:  SchemaViolation{Path: "kind", Type: ViolationRequiredField, Message: "kind is required"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifest := newTestDeploymentManifest()
+			manifest.SetAPIVersion(tc.apiVersion)
+			manifest.SetKind(tc.kind)
+
+			err := client.ValidateManifest(context.Background(), deploymentsGVR, manifest)
+			var invalid *ValidationError
+			require.True(t, errors.As(err, &invalid), "%v", err)
+			// The fields are not validated against the schema of another kind
+			assert.Equal(t, []SchemaViolation{tc.violation}, invalid.Violations)
+		})
+	}
+}
+
+func TestValidateManifestWithoutSchema(t *testing.T) {
+	// Without discovery only the apiVersion is checked
+	client := &Client{}
+	manifest := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"spec":       map[string]interface{}{"unknown": true},
+	}}
+	assert.NoError(t, client.ValidateManifest(context.Background(), deploymentsGVR, manifest))
+
+	// Unknown resources are left to the API server
+	client, _ = newExplainTestClient()
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "gadgets"}
+	manifest.SetKind("Gadget")
+	assert.NoError(t, client.ValidateManifest(context.Background(), gvr, manifest))
+}
+
+func TestValidateManifestTruncated(t *testing.T) {
+	client, _ := newExplainTestClient()
+
+	manifest := newTestDeploymentManifest()
+	for i := range MaxSchemaViolations + 5 {
+		manifest.Object[fmt.Sprintf("field%d", i)] = i
+	}
+
+	err := client.ValidateManifest(context.Background(), deploymentsGVR, manifest)
+	var invalid *ValidationError
+	require.True(t, errors.As(err, &invalid), "%v", err)
+	assert.Len(t, invalid.Violations, MaxSchemaViolations)
+	assert.True(t, invalid.Truncated)
+}
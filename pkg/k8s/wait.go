@@ -0,0 +1,444 @@
+package k8s
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+	watchtools "k8s.io/client-go/tools/watch"
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// Kinds of state a WaitFor call can wait for.
+const (
+	// WaitForCondition waits for a status condition to have a given status.
+	WaitForCondition = "condition"
+	// WaitForJSONPath waits for a JSONPath expression to match a value.
+	WaitForJSONPath = "jsonpath"
+	// WaitForDelete waits for the objects to be deleted.
+	WaitForDelete = "delete"
+	// WaitForRollout waits for the rollout of a Deployment, StatefulSet or
+	// DaemonSet to complete.
+	WaitForRollout = "rollout"
+)
+
+// DefaultWaitTimeout is how long WaitFor waits when no timeout is given.
+const DefaultWaitTimeout = 1 * time.Minute
+
+// WaitOptions configures a WaitFor call.
+type WaitOptions struct {
+	// For is what to wait for: WaitForCondition, WaitForJSONPath,
+	// WaitForDelete or WaitForRollout.
+	For string
+	// Condition is the type of the status condition to wait for, matched
+	// case-insensitively.
+	Condition string
+	// ConditionStatus is the status the condition must have. Defaults to
+	// "True".
+	ConditionStatus string
+	// JSONPath is the expression to evaluate, with or without braces
+	// (e.g. "{.status.phase}" or ".status.phase").
+	JSONPath string
+	// Value is the value the JSONPath expression must match. When empty,
+	// the expression only has to match a non-empty value.
+	Value string
+	// LabelSelector selects the objects to wait for when no name is given.
+	// All selected objects must reach the state.
+	LabelSelector string
+	// Timeout bounds the wait. Defaults to DefaultWaitTimeout.
+	Timeout time.Duration
+	// OnProgress, if set, is called with a short message whenever the
+	// observed state changes.
+	OnProgress func(message string)
+}
+
+// WaitResult is the result of a WaitFor call.
+type WaitResult struct {
+	Group         string             `json:"group"`
+	Version       string             `json:"version"`
+	Resource      string             `json:"resource"`
+	Namespace     string             `json:"namespace,omitempty"`
+	Name          string             `json:"name,omitempty"`
+	LabelSelector string             `json:"labelSelector,omitempty"`
+	For           string             `json:"for"`
+	Met           bool               `json:"met"`
+	Message       string             `json:"message"`
+	Objects       []WaitObjectStatus `json:"objects,omitempty"`
+	DurationMs    int64              `json:"durationMs"`
+}
+
+// WaitObjectStatus is the last observed state of one of the objects a
+// WaitFor call waited for.
+type WaitObjectStatus struct {
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name"`
+	Met       bool   `json:"met"`
+	Message   string `json:"message,omitempty"`
+}
+
+// objectWaiter evaluates whether a single object has reached the awaited
+// state.
+type objectWaiter func(obj *unstructured.Unstructured) (bool, string, error)
+
+// WaitFor watches a named object, or the objects matching opts.LabelSelector,
+// until they reach the state described by opts. It uses an informer, so
+// expired watches and dropped connections are recovered by re-listing. An
+// empty namespace addresses cluster-scoped resources.
+//
+// A timeout is not an error: the result reports Met=false with the last
+// observed state. Errors are returned when the wait cannot start, when the
+// caller's context is cancelled, and when the state can no longer be reached
+// (e.g. a Deployment exceeded its progress deadline).
+func (c *Client) WaitFor(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	namespace, name string,
+	opts WaitOptions,
+) (*WaitResult, error) {
+	if (name == "") == (opts.LabelSelector == "") {
+		return nil, fmt.Errorf("exactly one of name and label selector must be set")
+	}
+	waiter, err := newObjectWaiter(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWaitTimeout
+	}
+
+	c.mu.RLock()
+	dynamicClient := c.dynamicClient
+	c.mu.RUnlock()
+	client := resourceInterface(dynamicClient, gvr, namespace)
+
+	fieldSelector := ""
+	if name != "" {
+		fieldSelector = fields.OneTermEqualSelector("metadata.name", name).String()
+	}
+	listWatch := &cache.ListWatch{
+		ListWithContextFunc: func(ctx context.Context, options metav1.ListOptions) (runtime.Object, error) {
+			options.FieldSelector = fieldSelector
+			options.LabelSelector = opts.LabelSelector
+			return client.List(ctx, options)
+		},
+		WatchFuncWithContext: func(ctx context.Context, options metav1.ListOptions) (watch.Interface, error) {
+			options.FieldSelector = fieldSelector
+			options.LabelSelector = opts.LabelSelector
+			return client.Watch(ctx, options)
+		},
+	}
+	// Let the reflector know whether the client supports streaming lists,
+	// as the informers built by client-go do.
+	lw := cache.ToListWatcherWithWatchListSemantics(listWatch, dynamicClient)
+
+	result := &WaitResult{
+		Group:         gvr.Group,
+		Version:       gvr.Version,
+		Resource:      gvr.Resource,
+		Namespace:     namespace,
+		Name:          name,
+		LabelSelector: opts.LabelSelector,
+		For:           opts.For,
+	}
+
+	// objects holds the last observed state of every watched object, keyed
+	// by namespace/name.
+	objects := map[string]*unstructured.Unstructured{}
+	lastMessage := ""
+	evaluate := func() (bool, error) {
+		met, err := evaluateWait(result, objects, name, opts.For, waiter)
+		if err != nil {
+			return false, err
+		}
+		if opts.OnProgress != nil && result.Message != lastMessage {
+			opts.OnProgress(result.Message)
+		}
+		lastMessage = result.Message
+		return met, nil
+	}
+
+	precondition := func(store cache.Store) (bool, error) {
+		for _, item := range store.List() {
+			if obj, ok := item.(*unstructured.Unstructured); ok {
+				objects[waitObjectKey(obj)] = obj
+			}
+		}
+		return evaluate()
+	}
+	condition := func(event watch.Event) (bool, error) {
+		if !applyWaitEvent(objects, event) {
+			return false, nil
+		}
+		return evaluate()
+	}
+
+	start := time.Now()
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	_, err = watchtools.UntilWithSync(waitCtx, lw, &unstructured.Unstructured{}, precondition, condition)
+	result.DurationMs = time.Since(start).Milliseconds()
+	switch {
+	case err == nil:
+		result.Met = true
+	case ctx.Err() != nil:
+		return nil, ctx.Err()
+	case waitCtx.Err() != nil || wait.Interrupted(err):
+		result.Message = fmt.Sprintf("timed out after %s: %s", timeout, result.Message)
+	default:
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// applyWaitEvent records the object of a watch event in objects, keyed by
+// namespace/name, and returns whether the event changed them.
+func applyWaitEvent(objects map[string]*unstructured.Unstructured, event watch.Event) bool {
+	obj, ok := event.Object.(*unstructured.Unstructured)
+	if !ok {
+		return false
+	}
+	switch event.Type {
+	case watch.Added, watch.Modified:
+		objects[waitObjectKey(obj)] = obj
+	case watch.Deleted:
+		delete(objects, waitObjectKey(obj))
+	default:
+		return false
+	}
+	return true
+}
+
+// newObjectWaiter returns the evaluator for the state described by opts.
+func newObjectWaiter(opts WaitOptions) (objectWaiter, error) {
+	switch opts.For {
+	case WaitForCondition:
+		if opts.Condition == "" {
+			return nil, fmt.Errorf("condition is required when waiting for a condition")
+		}
+		status := opts.ConditionStatus
+		if status == "" {
+			status = string(metav1.ConditionTrue)
+		}
+		return func(obj *unstructured.Unstructured) (bool, string, error) {
+			return conditionMet(obj, opts.Condition, status)
+		}, nil
+	case WaitForJSONPath:
+		if opts.JSONPath == "" {
+			return nil, fmt.Errorf("jsonpath is required when waiting for a JSONPath expression")
+		}
+		expression := opts.JSONPath
+		if !strings.HasPrefix(expression, "{") {
+			expression = "{" + expression + "}"
+		}
+		parser := jsonpath.New("wait").AllowMissingKeys(true)
+		if err := parser.Parse(expression); err != nil {
+			return nil, fmt.Errorf("invalid jsonpath %q: %w", opts.JSONPath, err)
+		}
+		return func(obj *unstructured.Unstructured) (bool, string, error) {
+			return jsonPathMet(parser, obj, opts.Value)
+		}, nil
+	case WaitForDelete:
+		// Deletion is decided by the absence of objects, not by their state.
+		return func(*unstructured.Unstructured) (bool, string, error) {
+			return false, "not deleted yet", nil
+		}, nil
+	case WaitForRollout:
+		return rolloutMet, nil
+	default:
+		return nil, fmt.Errorf("invalid wait type %q, must be one of %s, %s, %s or %s",
+			opts.For, WaitForCondition, WaitForJSONPath, WaitForDelete, WaitForRollout)
+	}
+}
+
+// evaluateWait updates result from the observed objects and reports whether
+// the wait is over.
+func evaluateWait(
+	result *WaitResult,
+	objects map[string]*unstructured.Unstructured,
+	name, waitFor string,
+	waiter objectWaiter,
+) (bool, error) {
+	keys := make([]string, 0, len(objects))
+	for key, obj := range objects {
+		// Field selectors are not honoured by every client; filter again.
+		if name != "" && obj.GetName() != name {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result.Objects = make([]WaitObjectStatus, 0, len(keys))
+	metCount := 0
+	var pending *WaitObjectStatus
+	for _, key := range keys {
+		obj := objects[key]
+		met, message, err := waiter(obj)
+		if err != nil {
+			return false, fmt.Errorf("%s/%s: %w", result.Resource, obj.GetName(), err)
+		}
+		status := WaitObjectStatus{
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+			Met:       met,
+			Message:   message,
+		}
+		result.Objects = append(result.Objects, status)
+		if met {
+			metCount++
+		} else if pending == nil {
+			pending = &result.Objects[len(result.Objects)-1]
+		}
+	}
+
+	if waitFor == WaitForDelete {
+		if len(keys) == 0 {
+			result.Message = "deleted"
+			return true, nil
+		}
+		result.Message = fmt.Sprintf("%d object(s) not deleted yet", len(keys))
+		return false, nil
+	}
+
+	switch {
+	case len(keys) == 0 && name != "":
+		result.Message = fmt.Sprintf("%s/%s not found", result.Resource, name)
+		return false, nil
+	case len(keys) == 0:
+		result.Message = "no objects match the label selector"
+		return false, nil
+	case pending != nil:
+		result.Message = fmt.Sprintf("%d/%d met; %s/%s: %s", metCount, len(keys), result.Resource, pending.Name, pending.Message)
+		return false, nil
+	default:
+		result.Message = fmt.Sprintf("%d/%d met", metCount, len(keys))
+		return true, nil
+	}
+}
+
+// conditionMet reports whether obj has a status condition of the given type
+// (matched case-insensitively) with the given status. Conditions recording
+// an observedGeneration older than the object's generation are stale and do
+// not count.
+func conditionMet(obj *unstructured.Unstructured, conditionType, status string) (bool, string, error) {
+	conditions, _, err := unstructured.NestedSlice(obj.Object, fieldStatus, "conditions")
+	if err != nil {
+		return false, "", fmt.Errorf("failed to read status conditions: %w", err)
+	}
+	for _, item := range conditions {
+		condition, ok := item.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if t, _, _ := unstructured.NestedString(condition, "type"); !strings.EqualFold(t, conditionType) {
+			continue
+		}
+		actual, _, _ := unstructured.NestedString(condition, "status")
+		if generation, found, _ := unstructured.NestedInt64(condition, "observedGeneration"); found &&
+			generation < obj.GetGeneration() {
+			return false, fmt.Sprintf("condition %s is %s for an older generation", conditionType, actual), nil
+		}
+		message := fmt.Sprintf("condition %s is %s", conditionType, actual)
+		if reason, _, _ := unstructured.NestedString(condition, "reason"); reason != "" {
+			message += " (" + reason + ")"
+		}
+		return strings.EqualFold(actual, status), message, nil
+	}
+	return false, fmt.Sprintf("condition %s not found", conditionType), nil
+}
+
+// jsonPathMet reports whether the JSONPath expression parsed by parser
+// matches value on obj, or matches anything non-empty when value is empty.
+func jsonPathMet(parser *jsonpath.JSONPath, obj *unstructured.Unstructured, value string) (bool, string, error) {
+	results, err := parser.FindResults(obj.Object)
+	if err != nil {
+		return false, "", fmt.Errorf("failed to evaluate jsonpath: %w", err)
+	}
+
+	var values []string
+	for _, result := range results {
+		for _, v := range result {
+			formatted, err := formatJSONPathValue(v)
+			if err != nil {
+				return false, "", err
+			}
+			values = append(values, formatted)
+		}
+	}
+
+	switch {
+	case len(values) == 0:
+		return false, "jsonpath matched nothing", nil
+	case len(values) > 1:
+		return false, "", fmt.Errorf("jsonpath matched %d values, expected a single value", len(values))
+	case value == "":
+		return values[0] != "", fmt.Sprintf("jsonpath is %q", values[0]), nil
+	default:
+		return values[0] == value, fmt.Sprintf("jsonpath is %q", values[0]), nil
+	}
+}
+
+// formatJSONPathValue formats a JSONPath result for comparison: strings as
+// they are, anything else as JSON.
+func formatJSONPathValue(v reflect.Value) (string, error) {
+	if !v.IsValid() {
+		return "", nil
+	}
+	if v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return "", nil
+		}
+		v = v.Elem()
+	}
+	if v.Kind() == reflect.String {
+		return v.String(), nil
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", fmt.Errorf("failed to format jsonpath value: %w", err)
+	}
+	return string(data), nil
+}
+
+// rolloutMet reports whether the rollout of a Deployment, StatefulSet or
+// DaemonSet is complete, using WorkloadRolloutStatus.
+func rolloutMet(obj *unstructured.Unstructured) (bool, string, error) {
+	var typed interface{}
+	switch obj.GroupVersionKind().GroupKind() {
+	case appsv1.SchemeGroupVersion.WithKind("Deployment").GroupKind():
+		typed = &appsv1.Deployment{}
+	case appsv1.SchemeGroupVersion.WithKind("StatefulSet").GroupKind():
+		typed = &appsv1.StatefulSet{}
+	case appsv1.SchemeGroupVersion.WithKind("DaemonSet").GroupKind():
+		typed = &appsv1.DaemonSet{}
+	default:
+		return false, "", fmt.Errorf("rollout status is not supported for %s", obj.GetKind())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, typed); err != nil {
+		return false, "", fmt.Errorf("failed to convert %s: %w", obj.GetKind(), err)
+	}
+	done, message, err := WorkloadRolloutStatus(typed)
+	return done, strings.TrimSpace(message), err
+}
+
+// waitObjectKey returns the namespace/name key of obj.
+func waitObjectKey(obj *unstructured.Unstructured) string {
+	return obj.GetNamespace() + "/" + obj.GetName()
+}
@@ -0,0 +1,226 @@
+package k8s
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+var waitPodsGVR = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+
+func waitTestPod(name, phase, ready string) *unstructured.Unstructured {
+	return &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      name,
+			"namespace": "default",
+			"labels":    map[string]interface{}{"app": "web"},
+		},
+		"status": map[string]interface{}{
+			"phase": phase,
+			"conditions": []interface{}{
+				map[string]interface{}{"type": "Ready", "status": ready, "reason": "ContainersNotReady"},
+			},
+		},
+	}}
+}
+
+func newWaitTestClient(objects ...runtime.Object) (*Client, *dynamicfake.FakeDynamicClient) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{
+			waitPodsGVR: "PodList",
+			{Group: "apps", Version: "v1", Resource: "deployments"}: "DeploymentList",
+		}, objects...)
+	client := &Client{}
+	client.SetDynamicClient(fakeDynamic)
+	return client, fakeDynamic
+}
+
+func TestWaitForConditionAlreadyMet(t *testing.T) {
+	client, _ := newWaitTestClient(waitTestPod("web-0", "Running", "True"))
+
+	var progress []string
+	result, err := client.WaitFor(context.Background(), waitPodsGVR, "default", "web-0", WaitOptions{
+		For:        WaitForCondition,
+		Condition:  "ready",
+		Timeout:    5 * time.Second,
+		OnProgress: func(message string) { progress = append(progress, message) },
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Met)
+	assert.Equal(t, "1/1 met", result.Message)
+	require.Len(t, result.Objects, 1)
+	assert.Equal(t, "condition ready is True (ContainersNotReady)", result.Objects[0].Message)
+	assert.NotEmpty(t, progress)
+}
+
+func TestWaitForJSONPathAfterUpdate(t *testing.T) {
+	client, fakeDynamic := newWaitTestClient(waitTestPod("web-0", "Pending", "False"))
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		_, _ = fakeDynamic.Resource(waitPodsGVR).Namespace("default").Update(context.Background(),
+			waitTestPod("web-0", "Running", "False"), metav1.UpdateOptions{})
+	}()
+
+	result, err := client.WaitFor(context.Background(), waitPodsGVR, "default", "web-0", WaitOptions{
+		For:      WaitForJSONPath,
+		JSONPath: ".status.phase",
+		Value:    "Running",
+		Timeout:  5 * time.Second,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Met)
+	assert.Equal(t, `jsonpath is "Running"`, result.Objects[0].Message)
+}
+
+func TestWaitForDelete(t *testing.T) {
+	client, fakeDynamic := newWaitTestClient(
+		waitTestPod("web-0", "Running", "True"),
+		waitTestPod("web-1", "Running", "True"),
+	)
+
+	go func() {
+		time.Sleep(200 * time.Millisecond)
+		pods := fakeDynamic.Resource(waitPodsGVR).Namespace("default")
+		_ = pods.Delete(context.Background(), "web-0", metav1.DeleteOptions{})
+		_ = pods.Delete(context.Background(), "web-1", metav1.DeleteOptions{})
+	}()
+
+	result, err := client.WaitFor(context.Background(), waitPodsGVR, "default", "", WaitOptions{
+		For:           WaitForDelete,
+		LabelSelector: "app=web",
+		Timeout:       5 * time.Second,
+	})
+	require.NoError(t, err)
+	assert.True(t, result.Met)
+	assert.Equal(t, "deleted", result.Message)
+	assert.Empty(t, result.Objects)
+}
+
+func TestWaitForTimeout(t *testing.T) {
+	client, _ := newWaitTestClient(
+		waitTestPod("web-0", "Running", "True"),
+		waitTestPod("web-1", "Running", "False"),
+	)
+
+	result, err := client.WaitFor(context.Background(), waitPodsGVR, "default", "", WaitOptions{
+		For:           WaitForCondition,
+		Condition:     "Ready",
+		LabelSelector: "app=web",
+		Timeout:       300 * time.Millisecond,
+	})
+	require.NoError(t, err)
+	assert.False(t, result.Met)
+	assert.Contains(t, result.Message, "timed out")
+	assert.Contains(t, result.Message, "1/2 met; pods/web-1: condition Ready is False (ContainersNotReady)")
+	require.Len(t, result.Objects, 2)
+	assert.True(t, result.Objects[0].Met)
+	assert.False(t, result.Objects[1].Met)
+}
+
+func TestWaitForRollout(t *testing.T) {
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "default", "generation": int64(2)},
+		"spec":       map[string]interface{}{"replicas": int64(2)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(2),
+			"replicas":           int64(2),
+			"updatedReplicas":    int64(2),
+			"availableReplicas":  int64(2),
+		},
+	}}
+	client, _ := newWaitTestClient(deployment)
+
+	result, err := client.WaitFor(context.Background(),
+		schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}, "default", "web",
+		WaitOptions{For: WaitForRollout, Timeout: 5 * time.Second})
+	require.NoError(t, err)
+	assert.True(t, result.Met)
+	assert.Contains(t, result.Objects[0].Message, "successfully rolled out")
+}
+
+func TestWaitForRolloutUnsupportedKind(t *testing.T) {
+	client, _ := newWaitTestClient(waitTestPod("web-0", "Running", "True"))
+
+	_, err := client.WaitFor(context.Background(), waitPodsGVR, "default", "web-0", WaitOptions{
+		For:     WaitForRollout,
+		Timeout: 5 * time.Second,
+	})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not supported for Pod")
+}
+
+func TestWaitForCancelled(t *testing.T) {
+	client, _ := newWaitTestClient(waitTestPod("web-0", "Pending", "False"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(200*time.Millisecond, cancel)
+
+	_, err := client.WaitFor(ctx, waitPodsGVR, "default", "web-0", WaitOptions{
+		For:       WaitForCondition,
+		Condition: "Ready",
+		Timeout:   5 * time.Second,
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWaitForInvalidOptions(t *testing.T) {
+	client, _ := newWaitTestClient()
+
+	testCases := []struct {
+		name     string
+		podName  string
+		opts     WaitOptions
+		errorMsg string
+	}{
+		{name: "No name or selector", opts: WaitOptions{For: WaitForDelete}, errorMsg: "exactly one of"},
+		{
+			name:     "Name and selector",
+			podName:  "web-0",
+			opts:     WaitOptions{For: WaitForDelete, LabelSelector: "app=web"},
+			errorMsg: "exactly one of",
+		},
+		{name: "Invalid type", podName: "web-0", opts: WaitOptions{For: "ready"}, errorMsg: "invalid wait type"},
+		{name: "Missing condition", podName: "web-0", opts: WaitOptions{For: WaitForCondition}, errorMsg: "condition is required"},
+		{name: "Missing jsonpath", podName: "web-0", opts: WaitOptions{For: WaitForJSONPath}, errorMsg: "jsonpath is required"},
+		{
+			name:     "Invalid jsonpath",
+			podName:  "web-0",
+			opts:     WaitOptions{For: WaitForJSONPath, JSONPath: "{.status[}"},
+			errorMsg: "invalid jsonpath",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := client.WaitFor(context.Background(), waitPodsGVR, "default", tc.podName, tc.opts)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tc.errorMsg)
+		})
+	}
+}
+
+func TestConditionMetStaleGeneration(t *testing.T) {
+	obj := waitTestPod("web-0", "Running", "True")
+	obj.SetGeneration(3)
+	conditions, _, _ := unstructured.NestedSlice(obj.Object, "status", "conditions")
+	conditions[0].(map[string]interface{})["observedGeneration"] = int64(2)
+	require.NoError(t, unstructured.SetNestedSlice(obj.Object, conditions, "status", "conditions"))
+
+	met, message, err := conditionMet(obj, "Ready", "True")
+	require.NoError(t, err)
+	assert.False(t, met)
+	assert.Contains(t, message, "older generation")
+}
@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleCanI handles the can_i tool
+func (m *Implementation) HandleCanI(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	check := k8s.AccessCheck{
+		Verb:           mcp.ParseString(request, "verb", ""),
+		Group:          mcp.ParseString(request, "group", ""),
+		Resource:       mcp.ParseString(request, "resource", ""),
+		Subresource:    mcp.ParseString(request, "subresource", ""),
+		Name:           mcp.ParseString(request, "name", ""),
+		Namespace:      mcp.ParseString(request, "namespace", ""),
+		NonResourceURL: mcp.ParseString(request, "non_resource_url", ""),
+	}
+	// Accept resources given as resource/subresource, e.g. pods/log
+	if resource, subresource, ok := strings.Cut(check.Resource, "/"); ok && check.Subresource == "" {
+		check.Resource, check.Subresource = resource, subresource
+	}
+
+	// Validate parameters
+	if check.Verb == "" {
+		return mcp.NewToolResultError("verb is required"), nil
+	}
+	if check.Resource == "" && check.NonResourceURL == "" {
+		return mcp.NewToolResultError("resource or non_resource_url is required"), nil
+	}
+	if check.Resource != "" && check.NonResourceURL != "" {
+		return mcp.NewToolResultError("resource and non_resource_url are mutually exclusive"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.CanI(ctx, check)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to check access", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// HandleListPermissions handles the list_permissions tool
+func (m *Implementation) HandleListPermissions(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "default")
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.ListPermissions(ctx, namespace)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to list permissions", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// HandleWhoAmI handles the whoami tool
+func (m *Implementation) HandleWhoAmI(ctx context.Context, _ mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.WhoAmI(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get user", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewCanITool creates a new can_i tool
+func NewCanITool() mcp.Tool {
+	return mcp.NewTool(types.CanIToolName,
+		mcp.WithDescription("Check whether the current user may perform an action, like `kubectl auth can-i`, "+
+			"before attempting it. The check is made as the impersonated user when impersonation is enabled"),
+		mcp.WithString("verb",
+			mcp.Description("Verb of the action (e.g., get, list, watch, create, update, patch, delete)"),
+			mcp.Required()),
+		mcp.WithString("resource",
+			mcp.Description("Resource of the action (e.g., pods, deployments, pods/log)")),
+		mcp.WithString("group",
+			mcp.Description("API group of the resource (e.g., apps; default: the core group)")),
+		mcp.WithString("subresource",
+			mcp.Description("Subresource of the action (e.g., log, exec, scale)")),
+		mcp.WithString("name",
+			mcp.Description("Name of the resource (default: any)")),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the resource (default: all namespaces, or cluster-scoped)")),
+		mcp.WithString("non_resource_url",
+			mcp.Description("Non-resource URL of the action instead of a resource (e.g., /healthz)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Check access",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewListPermissionsTool creates a new list_permissions tool
+func NewListPermissionsTool() mcp.Tool {
+	return mcp.NewTool(types.ListPermissionsToolName,
+		mcp.WithDescription("List what the current user may do in a namespace, like `kubectl auth can-i --list`, "+
+			"including rules granted cluster-wide. The rules are those of the impersonated user when "+
+			"impersonation is enabled"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to list the permissions in (default: default)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "List permissions",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewWhoAmITool creates a new whoami tool
+func NewWhoAmITool() mcp.Tool {
+	return mcp.NewTool(types.WhoAmIToolName,
+		mcp.WithDescription("Get the user and groups the API server authenticates requests as, like "+
+			"`kubectl auth whoami`, and the user impersonated, if any"),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Get the current user",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
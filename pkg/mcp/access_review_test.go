@@ -0,0 +1,117 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func newAccessReviewTestImplementation() *Implementation {
+	clientset := kubefake.NewSimpleClientset()
+	clientset.PrependReactor("create", "selfsubjectaccessreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview).DeepCopy()
+		attributes := review.Spec.ResourceAttributes
+		review.Status.Allowed = attributes != nil && attributes.Resource == "pods" && attributes.Subresource == "log"
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectrulesreviews", func(action ktesting.Action) (bool, runtime.Object, error) {
+		review := action.(ktesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectRulesReview).DeepCopy()
+		review.Status.ResourceRules = []authorizationv1.ResourceRule{
+			{Verbs: []string{"get"}, APIGroups: []string{""}, Resources: []string{"pods"}},
+		}
+		return true, review, nil
+	})
+	clientset.PrependReactor("create", "selfsubjectreviews", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, &authenticationv1.SelfSubjectReview{Status: authenticationv1.SelfSubjectReviewStatus{
+			UserInfo: authenticationv1.UserInfo{Username: "alice", Groups: []string{"developers"}},
+		}}, nil
+	})
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	return NewImplementation(client)
+}
+
+func TestHandleCanI(t *testing.T) {
+	impl := newAccessReviewTestImplementation()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.CanIToolName
+	request.Params.Arguments = map[string]interface{}{"verb": "get", "resource": "pods/log", "namespace": "shop"}
+
+	result, err := impl.HandleCanI(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var check k8s.AccessCheckResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &check))
+	assert.True(t, check.Allowed)
+	assert.Equal(t, "pods", check.Resource)
+	assert.Equal(t, "log", check.Subresource)
+
+	// Invalid parameters
+	for _, arguments := range []map[string]interface{}{
+		{"resource": "pods"},
+		{"verb": "get"},
+		{"verb": "get", "resource": "pods", "non_resource_url": "/healthz"},
+	} {
+		request.Params.Arguments = arguments
+		result, err = impl.HandleCanI(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", arguments)
+	}
+}
+
+func TestHandleListPermissions(t *testing.T) {
+	impl := newAccessReviewTestImplementation()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ListPermissionsToolName
+	request.Params.Arguments = map[string]interface{}{}
+
+	result, err := impl.HandleListPermissions(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var permissions k8s.PermissionsResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &permissions))
+	assert.Equal(t, "default", permissions.Namespace)
+	assert.Len(t, permissions.Rules, 1)
+}
+
+func TestHandleWhoAmI(t *testing.T) {
+	impl := newAccessReviewTestImplementation()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.WhoAmIToolName
+
+	result, err := impl.HandleWhoAmI(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.JSONEq(t, `{"username":"alice","groups":["developers"]}`, textContent.Text)
+}
+
+func TestNewAccessReviewTools(t *testing.T) {
+	canI := NewCanITool()
+	assert.Equal(t, types.CanIToolName, canI.Name)
+	assert.Equal(t, []string{"verb"}, canI.InputSchema.Required)
+
+	assert.Equal(t, types.ListPermissionsToolName, NewListPermissionsTool().Name)
+	assert.Equal(t, types.WhoAmIToolName, NewWhoAmITool().Name)
+	assert.True(t, *NewWhoAmITool().Annotations.ReadOnlyHint)
+}
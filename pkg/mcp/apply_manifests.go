@@ -0,0 +1,85 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// applyManifestsTimeout is the timeout of the apply_manifests and
+// kustomize_build tools, which apply up to k8s.MaxManifestObjects objects one
+// after the other.
+const applyManifestsTimeout = 2 * time.Minute
+
+// HandleApplyManifests handles the apply_manifests tool
+func (m *Implementation) HandleApplyManifests(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	manifests := mcp.ParseString(request, "manifests", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+
+	// Validate parameters
+	if manifests == "" {
+		return mcp.NewToolResultError("manifests is required"), nil
+	}
+
+	// Decode every document before applying anything
+	objects, err := k8s.DecodeManifests(manifests)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to decode manifests", err), nil
+	}
+	if len(objects) == 0 {
+		return mcp.NewToolResultError("manifests contain no objects"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop applying as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	notifier := newProgressNotifier(ctx, request, types.ApplyManifestsToolName)
+	result, err := client.ApplyManifests(ctx, objects, k8s.ApplyManifestsOptions{
+		Namespace:  namespace,
+		OnProgress: notifier.Notify,
+	})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to apply manifests", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewApplyManifestsTool creates a new apply_manifests tool
+func NewApplyManifestsTool() mcp.Tool {
+	return mcp.NewTool(types.ApplyManifestsToolName,
+		mcp.WithDescription("Apply (create or update) the objects of YAML or JSON manifests, which may hold "+
+			"multiple documents separated by --- or a v1/List. The resource of each object is resolved from its "+
+			"apiVersion and kind. Namespaces and CustomResourceDefinitions are applied first. Returns the result "+
+			"of each object; a failing object does not stop the others from being applied"),
+		mcp.WithString("manifests",
+			mcp.Description("YAML or JSON manifests"),
+			mcp.Required()),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of namespaced objects that do not set one (default: default)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:          "Apply Kubernetes manifests",
+			ReadOnlyHint:   BoolPtr(false),
+			IdempotentHint: BoolPtr(true),
+		}),
+	)
+}
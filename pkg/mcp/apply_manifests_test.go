@@ -0,0 +1,119 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleApplyManifests(t *testing.T) {
+	fakeDynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	fakeDiscoveryClient := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "namespaces", Kind: "Namespace"},
+				{Name: "services", Kind: "Service", Namespaced: true},
+			},
+		},
+	}
+	mockClient := &k8s.Client{}
+	mockClient.SetDynamicClient(fakeDynamicClient)
+	mockClient.SetDiscoveryClient(fakeDiscoveryClient)
+	impl := NewImplementation(mockClient)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ApplyManifestsToolName
+	request.Params.Arguments = map[string]interface{}{
+		"namespace": "shop",
+		"manifests": `apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: shop
+---
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: gadget
+`,
+	}
+
+	result, err := impl.HandleApplyManifests(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var applyResult k8s.ApplyManifestsResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &applyResult))
+	assert.Equal(t, 2, applyResult.Applied)
+	assert.Equal(t, 1, applyResult.Failed)
+	require.Len(t, applyResult.Results, 3)
+	assert.Equal(t, "Namespace", applyResult.Results[0].Kind)
+	assert.Equal(t, "Service", applyResult.Results[1].Kind)
+	assert.Equal(t, "shop", applyResult.Results[1].Namespace)
+	assert.Equal(t, k8s.ManifestStatusFailed, applyResult.Results[2].Status)
+
+	servicesGVR := schema.GroupVersionResource{Version: "v1", Resource: "services"}
+	_, err = fakeDynamicClient.Resource(servicesGVR).Namespace("shop").Get(context.Background(), "web", metav1.GetOptions{})
+	require.NoError(t, err)
+}
+
+func TestHandleApplyManifestsInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	testCases := []struct {
+		name      string
+		manifests string
+		errorMsg  string
+	}{
+		{name: "Missing manifests", errorMsg: "manifests is required"},
+		{name: "No objects", manifests: "---\n---\n", errorMsg: "no objects"},
+		{name: "Invalid document", manifests: "kind: [\n", errorMsg: "Failed to decode manifests"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.ApplyManifestsToolName
+			request.Params.Arguments = map[string]interface{}{"manifests": tc.manifests}
+
+			result, err := impl.HandleApplyManifests(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestApplyManifestsToolRegistration(t *testing.T) {
+	for _, readWrite := range []bool{false, true} {
+		srv := CreateServer(&k8s.Client{}, &Config{ReadWrite: readWrite})
+		assert.Equal(t, readWrite, srv.MCPServer().GetTool(types.ApplyManifestsToolName) != nil)
+		srv.Stop()
+	}
+}
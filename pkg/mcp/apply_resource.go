@@ -4,15 +4,36 @@ package mcp
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/StacklokLabs/mkp/pkg/k8s"
 	"github.com/StacklokLabs/mkp/pkg/types"
 )
 
+// applyConflict is the error returned by the apply_resource tool when the
+// resource was modified since the resourceVersion of the manifest. It carries
+// the current resource so that the changes can be merged and applied again.
+type applyConflict struct {
+	Error                  string                     `json:"error"`
+	Message                string                     `json:"message"`
+	CurrentResourceVersion string                     `json:"currentResourceVersion,omitempty"`
+	Current                *unstructured.Unstructured `json:"current,omitempty"`
+}
+
+// applyInvalid is the error returned by the apply_resource tool when the
+// manifest does not match the resource or the schema of its kind.
+type applyInvalid struct {
+	Error      string                `json:"error"`
+	Message    string                `json:"message"`
+	Violations []k8s.SchemaViolation `json:"violations"`
+	Truncated  bool                  `json:"truncated,omitempty"`
+}
+
 // HandleApplyResource handles the apply_resource tool
 func (m *Implementation) HandleApplyResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
 	// Parse parameters
@@ -22,6 +43,8 @@ func (m *Implementation) HandleApplyResource(ctx context.Context, request mcp.Ca
 	resource := mcp.ParseString(request, "resource", "")
 	namespace := mcp.ParseString(request, "namespace", "")
 	manifestMap := mcp.ParseStringMap(request, "manifest", nil)
+	requireResourceVersion := request.GetBool("require_resource_version", false)
+	validate := request.GetBool("validate", true)
 
 	// Validate parameters
 	if resourceType == "" {
@@ -57,17 +80,47 @@ func (m *Implementation) HandleApplyResource(ctx context.Context, request mcp.Ca
 	obj := &unstructured.Unstructured{Object: manifestMap}
 
 	// Apply resource
-	var result *unstructured.Unstructured
-	var err error
 	switch resourceType {
 	case types.ResourceTypeClustered:
-		result, err = client.ApplyClusteredResource(ctx, gvr, obj)
+		namespace = ""
 	case types.ResourceTypeNamespaced:
-		result, err = client.ApplyNamespacedResource(ctx, gvr, namespace, obj)
 	default:
 		return mcp.NewToolResultError(fmt.Sprintf("Invalid resource_type: %s", resourceType)), nil
 	}
 
+	opts := k8s.ApplyOptions{RequireResourceVersion: requireResourceVersion}
+	return applyManifest(ctx, client, gvr, namespace, obj, validate, opts)
+}
+
+// applyManifest validates obj against the resource and the schema of its
+// kind if validate is set, then applies it, reporting invalid manifests and
+// conflicts as structured error results.
+func applyManifest(
+	ctx context.Context,
+	client *k8s.Client,
+	gvr schema.GroupVersionResource,
+	namespace string,
+	obj *unstructured.Unstructured,
+	validate bool,
+	opts k8s.ApplyOptions,
+) (*mcp.CallToolResult, error) {
+	// Validate the manifest before sending it
+	if validate {
+		if err := client.ValidateManifest(ctx, gvr, obj); err != nil {
+			var invalid *k8s.ValidationError
+			if errors.As(err, &invalid) {
+				return newApplyInvalidResult(invalid)
+			}
+			return mcp.NewToolResultErrorFromErr("Failed to validate manifest", err), nil
+		}
+	}
+
+	result, err := client.ApplyResource(ctx, gvr, namespace, obj, opts)
+
+	var conflict *k8s.ConflictError
+	if errors.As(err, &conflict) {
+		return newApplyConflictResult(conflict)
+	}
 	if err != nil {
 		return mcp.NewToolResultErrorFromErr("Failed to apply resource", err), nil
 	}
@@ -80,3 +133,37 @@ func (m *Implementation) HandleApplyResource(ctx context.Context, request mcp.Ca
 
 	return mcp.NewToolResultText(string(resultJSON)), nil
 }
+
+// newApplyConflictResult returns the error result for an apply conflict.
+func newApplyConflictResult(conflict *k8s.ConflictError) (*mcp.CallToolResult, error) {
+	body := applyConflict{
+		Error:   "Conflict",
+		Message: conflict.Error(),
+		Current: conflict.Current,
+	}
+	if conflict.Current != nil {
+		body.CurrentResourceVersion = conflict.Current.GetResourceVersion()
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal conflict", err), nil
+	}
+	return mcp.NewToolResultError(string(bodyJSON)), nil
+}
+
+// newApplyInvalidResult returns the error result for an invalid manifest.
+func newApplyInvalidResult(invalid *k8s.ValidationError) (*mcp.CallToolResult, error) {
+	body := applyInvalid{
+		Error:      "Invalid",
+		Message:    fmt.Sprintf("the %s manifest is invalid", invalid.Kind),
+		Violations: invalid.Violations,
+		Truncated:  invalid.Truncated,
+	}
+
+	bodyJSON, err := json.Marshal(body)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal violations", err), nil
+	}
+	return mcp.NewToolResultError(string(bodyJSON)), nil
+}
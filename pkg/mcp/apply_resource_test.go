@@ -2,13 +2,19 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	discoveryfake "k8s.io/client-go/discovery/fake"
 	"k8s.io/client-go/dynamic/fake"
 	ktesting "k8s.io/client-go/testing"
 
@@ -381,3 +387,153 @@ func TestHandleApplyResourceApplyError(t *testing.T) {
 	assert.True(t, ok, "Content should be TextContent")
 	assert.Contains(t, textContent.Text, "Failed to apply resource", "Error message should contain 'Failed to apply resource'")
 }
+
+func TestHandleApplyResourceConflict(t *testing.T) {
+	live := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name":            "settings",
+				"namespace":       "default",
+				"resourceVersion": "7",
+			},
+			"data": map[string]interface{}{"mode": "live"},
+		},
+	}
+	fakeDynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), live)
+	fakeDynamicClient.PrependReactor("update", "configmaps", func(action ktesting.Action) (bool, runtime.Object, error) {
+		obj := action.(ktesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		if obj.GetResourceVersion() != "7" {
+			return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "configmaps"}, obj.GetName(),
+				fmt.Errorf("the object has been modified"))
+		}
+		return false, nil, nil
+	})
+
+	mockClient := &k8s.Client{}
+	mockClient.SetDynamicClient(fakeDynamicClient)
+	impl := NewImplementation(mockClient)
+
+	newRequest := func(resourceVersion string, requireResourceVersion bool) mcp.CallToolRequest {
+		metadata := map[string]interface{}{"name": "settings", "namespace": "default"}
+		if resourceVersion != "" {
+			metadata["resourceVersion"] = resourceVersion
+		}
+		request := mcp.CallToolRequest{}
+		request.Params.Name = types.ApplyResourceToolName
+		request.Params.Arguments = map[string]interface{}{
+			"resource_type":            types.ResourceTypeNamespaced,
+			"version":                  "v1",
+			"resource":                 "configmaps",
+			"namespace":                "default",
+			"require_resource_version": requireResourceVersion,
+			"manifest": map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   metadata,
+				"data":       map[string]interface{}{"mode": "mine"},
+			},
+		}
+		return request
+	}
+
+	// A stale resourceVersion returns the current resource
+	result, err := impl.HandleApplyResource(context.Background(), newRequest("3", false))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+
+	var conflict applyConflict
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &conflict))
+	assert.Equal(t, "Conflict", conflict.Error)
+	assert.Contains(t, conflict.Message, "has been modified")
+	assert.Equal(t, "7", conflict.CurrentResourceVersion)
+	require.NotNil(t, conflict.Current)
+	mode, _, _ := unstructured.NestedString(conflict.Current.Object, "data", "mode")
+	assert.Equal(t, "live", mode)
+
+	// A missing resourceVersion is refused when required
+	result, err = impl.HandleApplyResource(context.Background(), newRequest("", true))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok = mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "no metadata.resourceVersion")
+
+	// The current resourceVersion is applied
+	result, err = impl.HandleApplyResource(context.Background(), newRequest("7", true))
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+}
+
+func TestHandleApplyResourceInvalidManifest(t *testing.T) {
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "configmaps", Kind: "ConfigMap", Namespaced: true}},
+		},
+	}
+	mockClient := &k8s.Client{}
+	mockClient.SetDynamicClient(fake.NewSimpleDynamicClient(runtime.NewScheme()))
+	mockClient.SetDiscoveryClient(openAPITestDiscovery{FakeDiscovery: fakeDiscovery})
+	impl := NewImplementation(mockClient)
+
+	newRequest := func(manifest map[string]interface{}, validate bool) mcp.CallToolRequest {
+		request := mcp.CallToolRequest{}
+		request.Params.Name = types.ApplyResourceToolName
+		request.Params.Arguments = map[string]interface{}{
+			"resource_type": types.ResourceTypeNamespaced,
+			"version":       "v1",
+			"resource":      "configmaps",
+			"namespace":     "default",
+			"manifest":      manifest,
+			"validate":      validate,
+		}
+		return request
+	}
+	manifest := map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "default"},
+		"data":       map[string]interface{}{"replicas": float64(3)},
+		"spec":       map[string]interface{}{},
+	}
+
+	result, err := impl.HandleApplyResource(context.Background(), newRequest(manifest, true))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+
+	var invalid applyInvalid
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &invalid))
+	assert.Equal(t, "Invalid", invalid.Error)
+	assert.Equal(t, "the ConfigMap manifest is invalid", invalid.Message)
+	assert.Equal(t, []k8s.SchemaViolation{
+		{Path: "data.replicas", Type: k8s.ViolationTypeMismatch, Message: "expected string, got number"},
+		{Path: "spec", Type: k8s.ViolationUnknownField, Message: `unknown field "spec"`},
+	}, invalid.Violations)
+
+	// The apiVersion must match the version of the resource
+	manifest = map[string]interface{}{
+		"apiVersion": "v2",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "settings", "namespace": "default"},
+	}
+	result, err = impl.HandleApplyResource(context.Background(), newRequest(manifest, true))
+	require.NoError(t, err)
+	require.True(t, result.IsError)
+	textContent, ok = mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, `apiVersion \"v2\" does not match the resource version \"v1\"`)
+
+	// Validation can be skipped
+	manifest["apiVersion"] = "v1"
+	manifest["spec"] = map[string]interface{}{}
+	result, err = impl.HandleApplyResource(context.Background(), newRequest(manifest, false))
+	require.NoError(t, err)
+	assert.False(t, result.IsError, "%v", result.Content)
+}
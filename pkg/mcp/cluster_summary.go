@@ -0,0 +1,51 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleClusterSummary handles the cluster_summary tool
+func (m *Implementation) HandleClusterSummary(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	summary, err := client.ClusterSummary(ctx, namespace)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to summarize cluster", err), nil
+	}
+
+	// Convert to JSON
+	summaryJSON, err := json.Marshal(summary)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal summary", err), nil
+	}
+
+	return mcp.NewToolResultText(string(summaryJSON)), nil
+}
+
+// NewClusterSummaryTool creates a new cluster_summary tool
+func NewClusterSummaryTool() mcp.Tool {
+	return mcp.NewTool(types.ClusterSummaryToolName,
+		mcp.WithDescription("Get a concise snapshot of the health of the cluster: the server version, node "+
+			"readiness and pressure, pods by phase and those restarting the most, workloads with unavailable "+
+			"replicas, pending PVCs, recent Warning events, and unavailable APIServices and admission webhooks. "+
+			"Each list is bounded, and sections that cannot be read are reported in errors"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to summarize the pods, workloads, PVCs and events of (default: all namespaces)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Summarize cluster health",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,63 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleClusterSummary(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Conditions: []corev1.NodeCondition{
+				{Type: corev1.NodeReady, Status: corev1.ConditionTrue},
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "job", Namespace: "batch"},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ClusterSummaryToolName
+	request.Params.Arguments = map[string]interface{}{"namespace": "shop"}
+
+	result, err := impl.HandleClusterSummary(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var summary k8s.ClusterSummary
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &summary))
+	assert.Equal(t, "shop", summary.Namespace)
+	assert.Equal(t, 1, summary.Nodes.Ready)
+	assert.Equal(t, map[string]int{"Running": 1}, summary.Pods.ByPhase)
+	// Without a dynamic client the APIServices cannot be listed
+	assert.Len(t, summary.Errors, 1)
+}
+
+func TestNewClusterSummaryTool(t *testing.T) {
+	tool := NewClusterSummaryTool()
+	assert.Equal(t, types.ClusterSummaryToolName, tool.Name)
+	assert.Empty(t, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,266 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// maxInlineTextFileBytes is the largest text file returned inline as text
+// content by copy_from_pod. Larger text files are returned as resources, like
+// binary files, so that clients can decide whether to load them into context.
+const maxInlineTextFileBytes = 64 << 10 // 64 KiB
+
+// textSniffBytes is how much of a file is inspected to decide whether it is
+// text or binary.
+const textSniffBytes = 8 << 10 // 8 KiB
+
+// copiedFileSummary describes one file returned by copy_from_pod.
+type copiedFileSummary struct {
+	Path     string `json:"path"`
+	Size     int    `json:"size"`
+	Mode     string `json:"mode"`
+	Encoding string `json:"encoding"`
+	URI      string `json:"uri,omitempty"`
+}
+
+// HandleCopyFromPod handles the copy_from_pod tool
+func (m *Implementation) HandleCopyFromPod(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	container := mcp.ParseString(request, "container", "")
+	srcPath := mcp.ParseString(request, "path", "")
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if srcPath == "" {
+		return mcp.NewToolResultError("path is required"), nil
+	}
+	if _, err := k8s.SanitizePodPath(srcPath); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid path: %v", err)), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	files, err := client.CopyFromPod(ctx, namespace, name, container, srcPath)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to copy from pod", err), nil
+	}
+
+	return copiedFilesResult(namespace, name, files), nil
+}
+
+// copiedFilesResult builds the copy_from_pod result: a JSON summary of the
+// files followed by one content item per file. Small text files are returned
+// inline as text; binary and large text files as base64 embedded resources.
+func copiedFilesResult(namespace, name string, files []k8s.PodFile) *mcp.CallToolResult {
+	summaries := make([]copiedFileSummary, 0, len(files))
+	contents := make([]mcp.Content, 0, len(files)+1)
+
+	for _, file := range files {
+		summary := copiedFileSummary{
+			Path: file.Path,
+			Size: len(file.Data),
+			Mode: fmt.Sprintf("%04o", file.Mode),
+		}
+
+		if isTextContent(file.Data) && len(file.Data) <= maxInlineTextFileBytes {
+			summary.Encoding = "text"
+			contents = append(contents, mcp.NewTextContent(string(file.Data)))
+		} else {
+			summary.Encoding = "base64"
+			summary.URI = podFileURI(namespace, name, file.Path)
+			contents = append(contents, mcp.NewEmbeddedResource(mcp.BlobResourceContents{
+				URI:      summary.URI,
+				MIMEType: http.DetectContentType(file.Data),
+				Blob:     base64.StdEncoding.EncodeToString(file.Data),
+			}))
+		}
+		summaries = append(summaries, summary)
+	}
+
+	summaryJSON, err := json.Marshal(map[string]interface{}{
+		"namespace": namespace,
+		"pod":       name,
+		"files":     summaries,
+	})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content: append([]mcp.Content{mcp.NewTextContent(string(summaryJSON))}, contents...),
+	}
+}
+
+// isTextContent reports whether data looks like text: valid UTF-8 without NUL
+// bytes in its first textSniffBytes bytes.
+func isTextContent(data []byte) bool {
+	sniff := data
+	if len(sniff) > textSniffBytes {
+		sniff = sniff[:textSniffBytes]
+		// Don't reject a file because the cut split a multi-byte rune.
+		for i := 0; i < utf8.UTFMax-1 && !utf8.Valid(sniff); i++ {
+			sniff = sniff[:len(sniff)-1]
+		}
+	}
+	return utf8.Valid(sniff) && !bytes.ContainsRune(sniff, 0)
+}
+
+// podFileURI returns the URI identifying a file inside a pod.
+func podFileURI(namespace, name, filePath string) string {
+	return fmt.Sprintf("k8s://namespaced/%s//v1/pods/%s/files%s", namespace, name, filePath)
+}
+
+// HandleCopyToPod handles the copy_to_pod tool
+func (m *Implementation) HandleCopyToPod(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	container := mcp.ParseString(request, "container", "")
+	destPath := mcp.ParseString(request, "path", "")
+	modeString := mcp.ParseString(request, "mode", "0644")
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if destPath == "" {
+		return mcp.NewToolResultError("path is required"), nil
+	}
+	if _, err := k8s.SanitizePodPath(destPath); err != nil {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid path: %v", err)), nil
+	}
+	mode, err := strconv.ParseInt(modeString, 8, 64)
+	if err != nil || mode <= 0 || mode > 0o7777 {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid mode: %q (expected octal, e.g. 0644)", modeString)), nil
+	}
+	data, err := parseCopyToPodContent(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	if err := client.CopyToPod(ctx, namespace, name, container, destPath, data, mode); err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to copy to pod", err), nil
+	}
+
+	return mcp.NewToolResultText(
+		fmt.Sprintf("Successfully copied %d bytes to %s in pod %s/%s", len(data), destPath, namespace, name)), nil
+}
+
+// parseCopyToPodContent returns the file content of a copy_to_pod request,
+// given either as text (content) or base64 (content_base64).
+func parseCopyToPodContent(request mcp.CallToolRequest) ([]byte, error) {
+	content, hasContent := request.GetArguments()["content"]
+	contentBase64, hasContentBase64 := request.GetArguments()["content_base64"]
+	if hasContent == hasContentBase64 {
+		return nil, fmt.Errorf("exactly one of content or content_base64 is required")
+	}
+
+	var data []byte
+	if hasContent {
+		text, ok := content.(string)
+		if !ok {
+			return nil, fmt.Errorf("content must be a string")
+		}
+		data = []byte(text)
+	} else {
+		encoded, ok := contentBase64.(string)
+		if !ok {
+			return nil, fmt.Errorf("content_base64 must be a string")
+		}
+		var err error
+		data, err = base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("invalid content_base64: %w", err)
+		}
+	}
+
+	if int64(len(data)) > k8s.MaxCopyToPodBytes {
+		return nil, fmt.Errorf("content exceeds maximum size of %d bytes", k8s.MaxCopyToPodBytes)
+	}
+	return data, nil
+}
+
+// NewCopyFromPodTool creates a new copy_from_pod tool
+func NewCopyFromPodTool() mcp.Tool {
+	return mcp.NewTool(types.CopyFromPodToolName,
+		mcp.WithDescription("Copy a file or directory out of a pod container (requires tar in the container). "+
+			"Small text files are returned inline; binary and large files as base64 resources"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod"),
+			mcp.Required()),
+		mcp.WithString("container",
+			mcp.Description("Container to copy from (defaults to the first container)")),
+		mcp.WithString("path",
+			mcp.Description(fmt.Sprintf("Absolute path of the file or directory to copy (maximum %d bytes in total)",
+				k8s.MaxCopyFromPodBytes)),
+			mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Copy files out of a pod",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewCopyToPodTool creates a new copy_to_pod tool
+func NewCopyToPodTool() mcp.Tool {
+	return mcp.NewTool(types.CopyToPodToolName,
+		mcp.WithDescription("Write a file into a pod container (requires tar in the container). "+
+			"The parent directory must exist"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod"),
+			mcp.Required()),
+		mcp.WithString("container",
+			mcp.Description("Container to copy to (defaults to the first container)")),
+		mcp.WithString("path",
+			mcp.Description("Absolute destination path of the file"),
+			mcp.Required()),
+		mcp.WithString("content",
+			mcp.Description(fmt.Sprintf("Text content of the file (maximum %d bytes)", k8s.MaxCopyToPodBytes))),
+		mcp.WithString("content_base64",
+			mcp.Description("Base64-encoded content of the file, for binary files (alternative to content)")),
+		mcp.WithString("mode",
+			mcp.Description("Octal file mode (default: 0644)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:          "Copy a file into a pod",
+			ReadOnlyHint:   BoolPtr(false),
+			IdempotentHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,147 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestCopiedFilesResult(t *testing.T) {
+	binary := []byte{0x7f, 'E', 'L', 'F', 0x00, 0x01}
+	result := copiedFilesResult("default", "test-pod", []k8s.PodFile{
+		{Path: "/etc/app.conf", Mode: 0o644, Data: []byte("key=value\n")},
+		{Path: "/bin/app", Mode: 0o755, Data: binary},
+	})
+	require.False(t, result.IsError)
+	require.Len(t, result.Content, 3, "summary plus one item per file")
+
+	summary, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var summaryObj struct {
+		Files []copiedFileSummary `json:"files"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(summary.Text), &summaryObj))
+	require.Len(t, summaryObj.Files, 2)
+	assert.Equal(t, "text", summaryObj.Files[0].Encoding)
+	assert.Equal(t, "0644", summaryObj.Files[0].Mode)
+	assert.Equal(t, "base64", summaryObj.Files[1].Encoding)
+
+	text, ok := mcp.AsTextContent(result.Content[1])
+	require.True(t, ok, "text files are returned inline")
+	assert.Equal(t, "key=value\n", text.Text)
+
+	resource, ok := mcp.AsEmbeddedResource(result.Content[2])
+	require.True(t, ok, "binary files are returned as resources")
+	blob, ok := mcp.AsBlobResourceContents(resource.Resource)
+	require.True(t, ok)
+	assert.Equal(t, base64.StdEncoding.EncodeToString(binary), blob.Blob)
+	assert.Equal(t, summaryObj.Files[1].URI, blob.URI)
+}
+
+func TestIsTextContent(t *testing.T) {
+	assert.True(t, isTextContent([]byte("plain text\n")))
+	assert.True(t, isTextContent([]byte("")))
+	assert.False(t, isTextContent([]byte{'a', 0x00, 'b'}))
+	assert.False(t, isTextContent([]byte{0xff, 0xfe, 0xfd}))
+
+	// A multi-byte rune split at the sniff boundary must not make text binary.
+	split := strings.Repeat("a", textSniffBytes-1) + "é" + "tail"
+	assert.True(t, isTextContent([]byte(split)))
+}
+
+func TestHandleCopyFromPodMissingParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing namespace", arguments: map[string]interface{}{"name": "p", "path": "/x"}, errorMsg: "namespace is required"},
+		{name: "Missing name", arguments: map[string]interface{}{"namespace": "d", "path": "/x"}, errorMsg: "name is required"},
+		{name: "Missing path", arguments: map[string]interface{}{"namespace": "d", "name": "p"}, errorMsg: "path is required"},
+		{name: "Traversal", arguments: map[string]interface{}{"namespace": "d", "name": "p", "path": "/a/../b"}, errorMsg: "invalid path"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.CopyFromPodToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleCopyFromPod(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestHandleCopyToPodInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"namespace": "default", "name": "test-pod", "path": "/tmp/app.conf"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "No content", arguments: base(nil), errorMsg: "exactly one of content or content_base64"},
+		{
+			name:      "Both contents",
+			arguments: base(map[string]interface{}{"content": "a", "content_base64": "YQ=="}),
+			errorMsg:  "exactly one of content or content_base64",
+		},
+		{name: "Bad base64", arguments: base(map[string]interface{}{"content_base64": "!!"}), errorMsg: "invalid content_base64"},
+		{name: "Bad mode", arguments: base(map[string]interface{}{"content": "a", "mode": "rwx"}), errorMsg: "invalid mode"},
+		{
+			name:      "Oversized",
+			arguments: base(map[string]interface{}{"content": strings.Repeat("x", int(k8s.MaxCopyToPodBytes)+1)}),
+			errorMsg:  "content exceeds maximum size",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.CopyToPodToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleCopyToPod(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestNewCopyTools(t *testing.T) {
+	fromTool := NewCopyFromPodTool()
+	assert.Equal(t, types.CopyFromPodToolName, fromTool.Name)
+	assert.Contains(t, fromTool.InputSchema.Required, "path")
+	assert.True(t, *fromTool.Annotations.ReadOnlyHint)
+
+	toTool := NewCopyToPodTool()
+	assert.Equal(t, types.CopyToPodToolName, toTool.Name)
+	assert.Contains(t, toTool.InputSchema.Required, "path")
+	assert.False(t, *toTool.Annotations.ReadOnlyHint)
+}
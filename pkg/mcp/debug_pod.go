@@ -0,0 +1,162 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// debugPodTimeoutGrace is added on top of the startup and exec caps when
+// computing the debug_pod tool timeout, leaving room to create (and clean up)
+// the debug container.
+const debugPodTimeoutGrace = 15 * time.Second
+
+// HandleDebugPod handles the debug_pod tool
+func (m *Implementation) HandleDebugPod(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	opts, err := m.parseDebugOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.DebugPod(ctx, namespace, name, opts)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to debug pod", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// parseDebugOptions parses and validates the debug container parameters of
+// the debug_pod tool. The image defaults to the first entry of the allowlist,
+// unless that entry is a prefix.
+func (m *Implementation) parseDebugOptions(request mcp.CallToolRequest) (k8s.DebugOptions, error) {
+	opts := k8s.DebugOptions{
+		Image:           mcp.ParseString(request, "image", ""),
+		TargetContainer: mcp.ParseString(request, "target_container", ""),
+		Timeout:         time.Duration(request.GetInt("timeout", 0)) * time.Second,
+		StartupTimeout:  time.Duration(request.GetInt("startup_timeout", 0)) * time.Second,
+		CopyPod:         request.GetBool("copy_pod", false),
+		KeepCopy:        request.GetBool("keep_copy", false),
+	}
+	if opts.Image == "" && len(m.debugImages) > 0 && !strings.HasSuffix(m.debugImages[0], "*") {
+		opts.Image = m.debugImages[0]
+	}
+	if opts.Image == "" {
+		return opts, fmt.Errorf("image is required")
+	}
+	if !debugImageAllowed(opts.Image, m.debugImages) {
+		return opts, fmt.Errorf("image %q is not in the debug image allowlist (allowed: %s)",
+			opts.Image, strings.Join(m.debugImages, ", "))
+	}
+	if opts.KeepCopy && !opts.CopyPod {
+		return opts, fmt.Errorf("keep_copy requires copy_pod")
+	}
+
+	// The command is given the same way as for pod exec: a command (string
+	// or array) or a script run through a shell.
+	body := map[string]interface{}{}
+	for _, key := range []string{"command", "script", "shell"} {
+		if value, ok := request.GetArguments()[key]; ok {
+			body[key] = value
+		}
+	}
+	command, err := k8s.ParseExecCommand(body)
+	if err != nil {
+		return opts, err
+	}
+	opts.Command = command
+	return opts, nil
+}
+
+// debugImageAllowed reports whether image matches an entry of the allowlist.
+// Entries ending in "*" match any image with the preceding prefix; other
+// entries must match exactly.
+func debugImageAllowed(image string, allowlist []string) bool {
+	for _, allowed := range allowlist {
+		if prefix, ok := strings.CutSuffix(allowed, "*"); ok {
+			if strings.HasPrefix(image, prefix) {
+				return true
+			}
+			continue
+		}
+		if image == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+// NewDebugPodTool creates a new debug_pod tool. The allowed images are listed
+// in the description of the image parameter.
+func NewDebugPodTool(debugImages []string) mcp.Tool {
+	return mcp.NewTool(types.DebugPodToolName,
+		mcp.WithDescription("Run a command in a debug container attached to a pod, for pods without a shell "+
+			"(e.g. distroless images). By default an ephemeral container is added to the pod, sharing the process "+
+			"namespace of the target container; with copy_pod a copy of the pod with the debug container is "+
+			"created instead. Ephemeral containers cannot be removed and exit on their own after a few minutes"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod"),
+			mcp.Required()),
+		mcp.WithString("image",
+			mcp.Description(fmt.Sprintf("Image of the debug container; must provide sleep (allowed: %s; "+
+				"default: the first allowed image)", strings.Join(debugImages, ", ")))),
+		mcp.WithString("target_container",
+			mcp.Description("Container whose process namespace the debug container joins (ephemeral mode only)")),
+		mcp.WithArray("command",
+			mcp.Description("Command to execute in the debug container"),
+			mcp.WithStringItems()),
+		mcp.WithString("script",
+			mcp.Description("Script to run through shell instead of command")),
+		mcp.WithString("shell",
+			mcp.Description("Shell used to run script (default: /bin/sh)")),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Timeout of the command in seconds (default: 15, maximum: %d)",
+				int(k8s.MaxExecTimeout.Seconds())))),
+		mcp.WithNumber("startup_timeout",
+			mcp.Description(fmt.Sprintf("How long to wait for the debug container to start in seconds "+
+				"(default: 60, maximum: %d)", int(k8s.MaxDebugStartupTimeout.Seconds())))),
+		mcp.WithBoolean("copy_pod",
+			mcp.Description("Debug a copy of the pod instead of adding an ephemeral container, for pods where "+
+				"ephemeral containers are not allowed (default: false)")),
+		mcp.WithBoolean("keep_copy",
+			mcp.Description("Keep the copy of the pod after the command ran (default: false)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Debug a pod",
+			ReadOnlyHint:    BoolPtr(false),
+			DestructiveHint: BoolPtr(false),
+		}),
+	)
+}
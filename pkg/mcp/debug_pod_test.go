@@ -0,0 +1,166 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestDebugImageAllowed(t *testing.T) {
+	allowlist := []string{"busybox:1.37", "ghcr.io/example/debug:*"}
+
+	assert.True(t, debugImageAllowed("busybox:1.37", allowlist))
+	assert.True(t, debugImageAllowed("ghcr.io/example/debug:v2", allowlist))
+	assert.False(t, debugImageAllowed("busybox:latest", allowlist))
+	assert.False(t, debugImageAllowed("ghcr.io/example/other:v1", allowlist))
+	assert.False(t, debugImageAllowed("busybox:1.37", nil))
+}
+
+func TestHandleDebugPodInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	impl.debugImages = []string{"busybox:1.37"}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{
+			name:      "Missing namespace",
+			arguments: map[string]interface{}{"name": "p", "command": []interface{}{"ls"}},
+			errorMsg:  "namespace is required",
+		},
+		{
+			name:      "Missing name",
+			arguments: map[string]interface{}{"namespace": "d", "command": []interface{}{"ls"}},
+			errorMsg:  "name is required",
+		},
+		{
+			name:      "Image not allowed",
+			arguments: map[string]interface{}{"namespace": "d", "name": "p", "image": "alpine", "command": []interface{}{"ls"}},
+			errorMsg:  "not in the debug image allowlist",
+		},
+		{
+			name:      "Missing command",
+			arguments: map[string]interface{}{"namespace": "d", "name": "p"},
+			errorMsg:  "command is required",
+		},
+		{
+			name:      "Keep copy without copy",
+			arguments: map[string]interface{}{"namespace": "d", "name": "p", "keep_copy": true, "command": []interface{}{"ls"}},
+			errorMsg:  "keep_copy requires copy_pod",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.DebugPodToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleDebugPod(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestHandleDebugPod(t *testing.T) {
+	fakeClientset := kubefake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Namespace: "default"},
+		Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+	})
+	// Report every ephemeral container as running.
+	fakeClientset.PrependReactor("get", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		get := action.(k8stesting.GetAction)
+		obj, err := fakeClientset.Tracker().Get(action.GetResource(), get.GetNamespace(), get.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		pod := obj.(*corev1.Pod).DeepCopy()
+		for _, ec := range pod.Spec.EphemeralContainers {
+			pod.Status.EphemeralContainerStatuses = append(pod.Status.EphemeralContainerStatuses, corev1.ContainerStatus{
+				Name:  ec.Name,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			})
+		}
+		return true, pod, nil
+	})
+
+	client := &k8s.Client{}
+	client.SetClientset(fakeClientset)
+	var execCommand []string
+	client.SetExecInPodFunc(func(_ context.Context, namespace, name string, command []string,
+		_ string, _ string, _ time.Duration) (*unstructured.Unstructured, error) {
+		execCommand = command
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"metadata": map[string]interface{}{"name": name, "namespace": namespace},
+			"status":   map[string]interface{}{"stdout": "PID 1", "stderr": "", "error": ""},
+		}}, nil
+	})
+
+	impl := NewImplementation(client)
+	impl.debugImages = []string{"busybox:1.37"}
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.DebugPodToolName
+	request.Params.Arguments = map[string]interface{}{
+		"namespace":        "default",
+		"name":             "test-pod",
+		"target_container": "app",
+		"script":           "ps aux",
+	}
+
+	result, err := impl.HandleDebugPod(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+	assert.Equal(t, []string{"/bin/sh", "-c", "ps aux"}, execCommand)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var resultObj map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &resultObj))
+	image, _, _ := unstructured.NestedString(resultObj, "status", "debug", "image")
+	assert.Equal(t, "busybox:1.37", image, "the first allowed image is used by default")
+	stdout, _, _ := unstructured.NestedString(resultObj, "status", "stdout")
+	assert.Equal(t, "PID 1", stdout)
+}
+
+func TestDebugPodToolRegistration(t *testing.T) {
+	testCases := []struct {
+		name       string
+		config     *Config
+		expectTool bool
+	}{
+		{name: "Read-only", config: &Config{DebugImages: []string{"busybox"}}, expectTool: false},
+		{name: "No allowlist", config: &Config{ReadWrite: true}, expectTool: false},
+		{name: "Read-write with allowlist", config: &Config{ReadWrite: true, DebugImages: []string{"busybox"}}, expectTool: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := CreateServer(&k8s.Client{}, tc.config)
+			defer srv.Stop()
+
+			tool := srv.MCPServer().GetTool(types.DebugPodToolName)
+			assert.Equal(t, tc.expectTool, tool != nil)
+		})
+	}
+}
@@ -3,11 +3,14 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 
 	"github.com/mark3labs/mcp-go/mcp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
+	"github.com/StacklokLabs/mkp/pkg/k8s"
 	"github.com/StacklokLabs/mkp/pkg/types"
 )
 
@@ -20,6 +23,7 @@ func (m *Implementation) HandleDeleteResource(ctx context.Context, request mcp.C
 	resource := mcp.ParseString(request, "resource", "")
 	namespace := mcp.ParseString(request, "namespace", "")
 	name := mcp.ParseString(request, "name", "")
+	labelSelector := mcp.ParseString(request, "label_selector", "")
 
 	// Validate parameters
 	if resourceType == "" {
@@ -31,53 +35,124 @@ func (m *Implementation) HandleDeleteResource(ctx context.Context, request mcp.C
 	if resource == "" {
 		return mcp.NewToolResultError("resource is required"), nil
 	}
-	if name == "" {
-		return mcp.NewToolResultError("name is required"), nil
-	}
 	if resourceType == types.ResourceTypeNamespaced && namespace == "" {
 		return mcp.NewToolResultError("namespace is required for namespaced resources"), nil
 	}
-
-	// Create GVR
-	// Validate resource_type
 	if resourceType != types.ResourceTypeClustered && resourceType != types.ResourceTypeNamespaced {
 		return mcp.NewToolResultError("Invalid resource_type: " + resourceType), nil
 	}
+	opts, err := parseDeleteOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
 
 	gvr := schema.GroupVersionResource{
 		Group:    group,
 		Version:  version,
 		Resource: resource,
 	}
+	if resourceType == types.ResourceTypeClustered {
+		namespace = ""
+	}
 
+	switch {
+	case name != "" && labelSelector != "":
+		return mcp.NewToolResultError("name and label_selector are mutually exclusive"), nil
+	case labelSelector != "":
+		return m.deleteResourcesBySelector(ctx, request, gvr, namespace, labelSelector, opts), nil
+	case name != "":
+		return m.deleteResourceByName(ctx, gvr, resourceType, namespace, name, opts), nil
+	default:
+		return mcp.NewToolResultError("name or label_selector is required"), nil
+	}
+}
+
+// parseDeleteOptions parses the delete options of the delete_resource tool
+func parseDeleteOptions(request mcp.CallToolRequest) (k8s.DeleteOptions, error) {
+	opts := k8s.DeleteOptions{
+		PropagationPolicy: mcp.ParseString(request, "propagation_policy", ""),
+		UID:               mcp.ParseString(request, "uid", ""),
+		ResourceVersion:   mcp.ParseString(request, "resource_version", ""),
+	}
+	if _, ok := request.GetArguments()["grace_period_seconds"]; ok {
+		gracePeriod := int64(request.GetInt("grace_period_seconds", -1))
+		if gracePeriod < 0 {
+			return opts, fmt.Errorf("grace_period_seconds must not be negative")
+		}
+		opts.GracePeriodSeconds = &gracePeriod
+	}
+	return opts, nil
+}
+
+// deleteResourceByName deletes a single resource by name
+func (m *Implementation) deleteResourceByName(
+	ctx context.Context,
+	gvr schema.GroupVersionResource,
+	resourceType, namespace, name string,
+	opts k8s.DeleteOptions,
+) *mcp.CallToolResult {
 	// Get the appropriate client (may be impersonated)
-	client, clientErr := m.clientForContext(ctx)
-	if clientErr != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", clientErr), nil
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err)
 	}
 
 	// Delete resource
-	var err error
-	switch resourceType {
-	case types.ResourceTypeClustered:
-		err = client.DeleteClusteredResource(ctx, gvr, name)
-	case types.ResourceTypeNamespaced:
-		err = client.DeleteNamespacedResource(ctx, gvr, namespace, name)
-	default:
-		return mcp.NewToolResultError(fmt.Sprintf("Invalid resource_type: %s", resourceType)), nil
+	if err := client.DeleteResource(ctx, gvr, namespace, name, opts); err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to delete resource", err)
 	}
 
+	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s resource %s", resourceType, name))
+}
+
+// deleteResourcesBySelector deletes, or previews the deletion of, the
+// resources matching labelSelector, up to the max_items of request
+func (m *Implementation) deleteResourcesBySelector(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	gvr schema.GroupVersionResource,
+	namespace, labelSelector string,
+	opts k8s.DeleteOptions,
+) *mcp.CallToolResult {
+	maxItems := request.GetInt("max_items", 0)
+	preview := request.GetBool("preview", false)
+
+	// Validate parameters
+	if opts.UID != "" || opts.ResourceVersion != "" {
+		return mcp.NewToolResultError("uid and resource_version cannot be used with label_selector")
+	}
+	if maxItems <= 0 {
+		return mcp.NewToolResultError("max_items is required with label_selector")
+	}
+	if maxItems > k8s.MaxDeleteCollectionItems {
+		return mcp.NewToolResultError(fmt.Sprintf("max_items must not exceed %d", k8s.MaxDeleteCollectionItems))
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err)
+	}
+
+	// Delete the matching resources
+	result, err := client.DeleteCollection(ctx, gvr, namespace, labelSelector, maxItems, preview, opts)
 	if err != nil {
-		return mcp.NewToolResultErrorFromErr("Failed to delete resource", err), nil
+		return mcp.NewToolResultErrorFromErr("Failed to delete resources", err)
 	}
 
-	return mcp.NewToolResultText(fmt.Sprintf("Successfully deleted %s resource %s", resourceType, name)), nil
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err)
+	}
+	return mcp.NewToolResultText(string(resultJSON))
 }
 
 // NewDeleteResourceTool creates a new delete_resource tool
 func NewDeleteResourceTool() mcp.Tool {
 	return mcp.NewTool(types.DeleteResourceToolName,
-		mcp.WithDescription("Delete a Kubernetes resource"),
+		mcp.WithDescription("Delete a Kubernetes resource by name, or the resources matching a label selector. "+
+			"Selector deletes are capped by max_items and can be previewed first"),
 		mcp.WithString("resource_type",
 			mcp.Description("Type of resource to delete (clustered or namespaced)"),
 			mcp.Required()),
@@ -92,8 +167,25 @@ func NewDeleteResourceTool() mcp.Tool {
 		mcp.WithString("namespace",
 			mcp.Description("Namespace (required for namespaced resources)")),
 		mcp.WithString("name",
-			mcp.Description("Name of the resource to delete"),
-			mcp.Required()),
+			mcp.Description("Name of the resource to delete (required unless label_selector is set)")),
+		mcp.WithString("label_selector",
+			mcp.Description("Delete all resources matching this label selector instead of a single resource "+
+				"(e.g., app=batch,state=complete)")),
+		mcp.WithNumber("max_items",
+			mcp.Description(fmt.Sprintf("Required with label_selector: nothing is deleted if more resources match "+
+				"(maximum: %d)", k8s.MaxDeleteCollectionItems))),
+		mcp.WithBoolean("preview",
+			mcp.Description("With label_selector, only list the resources that would be deleted (default: false)")),
+		mcp.WithString("propagation_policy",
+			mcp.Description("How dependents are garbage collected (default: the resource's own policy)"),
+			mcp.Enum(string(metav1.DeletePropagationForeground), string(metav1.DeletePropagationBackground),
+				string(metav1.DeletePropagationOrphan))),
+		mcp.WithNumber("grace_period_seconds",
+			mcp.Description("Grace period before the resource is deleted; 0 deletes immediately (default: the resource's own)")),
+		mcp.WithString("uid",
+			mcp.Description("Only delete the resource if it still has this UID, not a recreated one with the same name")),
+		mcp.WithString("resource_version",
+			mcp.Description("Only delete the resource if it was not modified since this resourceVersion")),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:          "Delete a Kubernetes resource",
 			ReadOnlyHint:   BoolPtr(false),
@@ -2,12 +2,17 @@ package mcp
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"testing"
 
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/dynamic/fake"
 	ktesting "k8s.io/client-go/testing"
 
@@ -165,7 +170,7 @@ func TestHandleDeleteResourceMissingParameters(t *testing.T) {
 				"version":       "v1",
 				"resource":      "deployments",
 			},
-			errorMsg: "name is required",
+			errorMsg: "name or label_selector is required",
 		},
 		{
 			name: "Missing namespace for namespaced resource",
@@ -293,3 +298,169 @@ func TestHandleDeleteResourceDeleteError(t *testing.T) {
 	assert.True(t, ok, "Content should be TextContent")
 	assert.Contains(t, textContent.Text, "Failed to delete resource", "Error message should contain 'Failed to delete resource'")
 }
+
+func TestHandleDeleteResourceWithOptions(t *testing.T) {
+	mockClient := &k8s.Client{}
+	fakeDynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	var deleteOptions metav1.DeleteOptions
+	fakeDynamicClient.PrependReactor("delete", "deployments", func(action ktesting.Action) (bool, runtime.Object, error) {
+		deleteOptions = action.(ktesting.DeleteAction).GetDeleteOptions()
+		return true, nil, nil
+	})
+	mockClient.SetDynamicClient(fakeDynamicClient)
+	impl := NewImplementation(mockClient)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.DeleteResourceToolName
+	request.Params.Arguments = map[string]interface{}{
+		"resource_type":        types.ResourceTypeNamespaced,
+		"group":                "apps",
+		"version":              "v1",
+		"resource":             "deployments",
+		"namespace":            "default",
+		"name":                 "web",
+		"propagation_policy":   "Orphan",
+		"grace_period_seconds": float64(10),
+		"uid":                  "1234",
+	}
+
+	result, err := impl.HandleDeleteResource(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	require.NotNil(t, deleteOptions.PropagationPolicy)
+	assert.Equal(t, metav1.DeletePropagationOrphan, *deleteOptions.PropagationPolicy)
+	require.NotNil(t, deleteOptions.GracePeriodSeconds)
+	assert.Equal(t, int64(10), *deleteOptions.GracePeriodSeconds)
+	require.NotNil(t, deleteOptions.Preconditions)
+	assert.Equal(t, "1234", string(*deleteOptions.Preconditions.UID))
+}
+
+func TestHandleDeleteResourceLabelSelector(t *testing.T) {
+	jobsGVR := schema.GroupVersionResource{Group: "batch", Version: "v1", Resource: "jobs"}
+	job := func(name, state string) *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "batch/v1",
+			"kind":       "Job",
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "default",
+				"labels":    map[string]interface{}{"state": state},
+			},
+		}}
+	}
+	fakeDynamicClient := fake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{jobsGVR: "JobList"},
+		job("job-1", "complete"), job("job-2", "complete"), job("job-3", "running"))
+	deleteCollections := 0
+	fakeDynamicClient.PrependReactor("delete-collection", "jobs", func(ktesting.Action) (bool, runtime.Object, error) {
+		deleteCollections++
+		return true, nil, nil
+	})
+	mockClient := &k8s.Client{}
+	mockClient.SetDynamicClient(fakeDynamicClient)
+	impl := NewImplementation(mockClient)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.DeleteResourceToolName
+	arguments := map[string]interface{}{
+		"resource_type":  types.ResourceTypeNamespaced,
+		"group":          "batch",
+		"version":        "v1",
+		"resource":       "jobs",
+		"namespace":      "default",
+		"label_selector": "state=complete",
+		"max_items":      float64(5),
+		"preview":        true,
+	}
+	request.Params.Arguments = arguments
+
+	result, err := impl.HandleDeleteResource(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var deleteResult k8s.DeleteCollectionResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &deleteResult))
+	assert.True(t, deleteResult.Preview)
+	assert.Equal(t, 2, deleteResult.Count)
+	assert.Equal(t, 0, deleteCollections)
+
+	arguments["preview"] = false
+	result, err = impl.HandleDeleteResource(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	assert.Equal(t, 1, deleteCollections)
+
+	// Too many matches
+	arguments["max_items"] = float64(1)
+	result, err = impl.HandleDeleteResource(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+	assert.Equal(t, 1, deleteCollections)
+}
+
+func TestHandleDeleteResourceInvalidOptions(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{
+			"resource_type": types.ResourceTypeNamespaced,
+			"version":       "v1",
+			"resource":      "pods",
+			"namespace":     "default",
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{
+			name:      "Name and label selector",
+			arguments: base(map[string]interface{}{"name": "web", "label_selector": "app=web", "max_items": float64(1)}),
+			errorMsg:  "mutually exclusive",
+		},
+		{
+			name:      "Missing max_items",
+			arguments: base(map[string]interface{}{"label_selector": "app=web"}),
+			errorMsg:  "max_items is required",
+		},
+		{
+			name:      "Too large max_items",
+			arguments: base(map[string]interface{}{"label_selector": "app=web", "max_items": float64(k8s.MaxDeleteCollectionItems + 1)}),
+			errorMsg:  "max_items must not exceed",
+		},
+		{
+			name:      "UID with label selector",
+			arguments: base(map[string]interface{}{"label_selector": "app=web", "max_items": float64(1), "uid": "1234"}),
+			errorMsg:  "cannot be used with label_selector",
+		},
+		{
+			name:      "Negative grace period",
+			arguments: base(map[string]interface{}{"name": "web", "grace_period_seconds": float64(-1)}),
+			errorMsg:  "grace_period_seconds must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.DeleteResourceToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleDeleteResource(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
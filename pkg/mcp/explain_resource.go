@@ -0,0 +1,61 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleExplainResource handles the explain_resource tool
+func (m *Implementation) HandleExplainResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	resource := mcp.ParseString(request, "resource", "")
+	apiVersion := mcp.ParseString(request, "api_version", "")
+
+	// Validate parameters
+	if resource == "" {
+		return mcp.NewToolResultError("resource is required"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.ExplainResource(ctx, resource, apiVersion)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to explain resource", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewExplainResourceTool creates a new explain_resource tool
+func NewExplainResourceTool() mcp.Tool {
+	return mcp.NewTool(types.ExplainResourceToolName,
+		mcp.WithDescription("Explain the schema of a resource kind or of one of its fields, like `kubectl explain`: "+
+			"type, description, required fields and the fields it holds, from the OpenAPI v3 schema the cluster "+
+			"publishes, including that of custom resources"),
+		mcp.WithString("resource",
+			mcp.Description("Resource or kind, by name, singular name, kind or short name, optionally followed by "+
+				"a dotted field path (e.g., deployment, deployment.spec.strategy, pods.spec.containers.resources)"),
+			mcp.Required()),
+		mcp.WithString("api_version",
+			mcp.Description("API version of the resource (e.g., apps/v1), for resources served in several "+
+				"versions (default: the preferred version)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Explain a resource",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,81 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/openapi"
+	"k8s.io/client-go/openapi/openapitest"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// openAPITestDiscovery is a fake discovery client serving the OpenAPI v3
+// documents embedded in client-go.
+type openAPITestDiscovery struct {
+	*discoveryfake.FakeDiscovery
+}
+
+func (openAPITestDiscovery) OpenAPIV3() openapi.Client {
+	return openapitest.NewEmbeddedFileClient()
+}
+
+func TestHandleExplainResource(t *testing.T) {
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{{
+				Name: "deployments", SingularName: "deployment", Kind: "Deployment", Namespaced: true,
+				ShortNames: []string{"deploy"},
+			}},
+		},
+	}
+	client := &k8s.Client{}
+	client.SetDiscoveryClient(openAPITestDiscovery{FakeDiscovery: fakeDiscovery})
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ExplainResourceToolName
+	request.Params.Arguments = map[string]interface{}{"resource": "deploy.spec.strategy", "api_version": "apps/v1"}
+
+	result, err := impl.HandleExplainResource(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var explained k8s.ExplainResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &explained))
+	assert.Equal(t, "Deployment", explained.Kind)
+	assert.Equal(t, "spec.strategy", explained.Path)
+	assert.Equal(t, "DeploymentStrategy", explained.Type)
+	assert.Len(t, explained.Fields, 2)
+
+	// Unknown field
+	request.Params.Arguments = map[string]interface{}{"resource": "deploy.spec.nope"}
+	result, err = impl.HandleExplainResource(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+
+	// Missing resource
+	request.Params.Arguments = map[string]interface{}{}
+	result, err = impl.HandleExplainResource(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestNewExplainResourceTool(t *testing.T) {
+	tool := NewExplainResourceTool()
+	assert.Equal(t, types.ExplainResourceToolName, tool.Name)
+	assert.Contains(t, tool.InputSchema.Required, "resource")
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,120 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// followLogsTimeoutGrace is added on top of k8s.MaxFollowLogsDuration when
+// computing the tool timeout, leaving room to open the stream and build the
+// final summary after the follow window closes.
+const followLogsTimeoutGrace = 15 * time.Second
+
+// HandleFollowLogs handles the follow_logs tool
+func (m *Implementation) HandleFollowLogs(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	container := mcp.ParseString(request, "container", "")
+	durationSeconds := request.GetInt("duration_seconds", 0)
+	tailLines := request.GetInt("tail_lines", 0)
+	sinceSeconds := request.GetInt("since_seconds", 0)
+	limitBytes := request.GetInt("limit_bytes", 0)
+	timestamps := request.GetBool("timestamps", false)
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if durationSeconds < 0 {
+		return mcp.NewToolResultError("duration_seconds must not be negative"), nil
+	}
+
+	// Reuse the pod log parameter names understood by get_resource so that
+	// the same clamping rules apply.
+	parameters := map[string]string{
+		"timestamps": strconv.FormatBool(timestamps),
+	}
+	if container != "" {
+		parameters["container"] = container
+	}
+	if tailLines > 0 {
+		parameters["tailLines"] = strconv.Itoa(tailLines)
+	}
+	if sinceSeconds > 0 {
+		parameters["sinceSeconds"] = strconv.Itoa(sinceSeconds)
+	}
+	if limitBytes > 0 {
+		parameters["limitBytes"] = strconv.Itoa(limitBytes)
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop following as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	notifier := newProgressNotifier(ctx, request, fmt.Sprintf("%s/%s", namespace, name))
+	result, err := client.FollowPodLogs(
+		ctx, namespace, name, parameters,
+		time.Duration(durationSeconds)*time.Second,
+		notifier.Notify,
+	)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to follow pod logs", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewFollowLogsTool creates a new follow_logs tool
+func NewFollowLogsTool() mcp.Tool {
+	return mcp.NewTool(types.FollowLogsToolName,
+		mcp.WithDescription("Stream a pod's logs for a bounded duration. Chunks are sent as progress and "+
+			"logging notifications while streaming; the result contains the collected logs and a summary"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod"),
+			mcp.Required()),
+		mcp.WithString("container",
+			mcp.Description("Container to follow (defaults to the only container in the pod)")),
+		mcp.WithNumber("duration_seconds",
+			mcp.Description(fmt.Sprintf("How long to follow the logs in seconds (default: 30, maximum: %d)",
+				int(k8s.MaxFollowLogsDuration.Seconds())))),
+		mcp.WithNumber("tail_lines",
+			mcp.Description("Number of existing lines to return before following (default: 10)")),
+		mcp.WithNumber("since_seconds",
+			mcp.Description("Start from logs newer than this many seconds instead of tail_lines")),
+		mcp.WithNumber("limit_bytes",
+			mcp.Description("Maximum number of bytes to collect before stopping (default: 32768)")),
+		mcp.WithBoolean("timestamps",
+			mcp.Description("Whether to include timestamps on each line (default: false)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Follow pod logs",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleFollowLogsSuccess(t *testing.T) {
+	mockClient := &k8s.Client{}
+	mockClient.SetClientset(kubefake.NewSimpleClientset())
+	impl := NewImplementation(mockClient)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.FollowLogsToolName
+	request.Params.Arguments = map[string]interface{}{
+		"namespace":        "default",
+		"name":             "test-pod",
+		"duration_seconds": 1,
+	}
+
+	result, err := impl.HandleFollowLogs(context.Background(), request)
+	require.NoError(t, err)
+	require.NotNil(t, result)
+	assert.False(t, result.IsError, "Result should not be an error")
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok, "Content should be TextContent")
+
+	var resultObj map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &resultObj))
+	assert.Equal(t, "fake logs", resultObj["logs"])
+	follow, ok := resultObj["follow"].(map[string]interface{})
+	require.True(t, ok, "Result should contain follow summary")
+	assert.Equal(t, k8s.FollowStopStreamEnded, follow["stopReason"])
+}
+
+func TestHandleFollowLogsMissingParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{
+			name:      "Missing namespace",
+			arguments: map[string]interface{}{"name": "test-pod"},
+			errorMsg:  "namespace is required",
+		},
+		{
+			name:      "Missing name",
+			arguments: map[string]interface{}{"namespace": "default"},
+			errorMsg:  "name is required",
+		},
+		{
+			name:      "Negative duration",
+			arguments: map[string]interface{}{"namespace": "default", "name": "test-pod", "duration_seconds": -1},
+			errorMsg:  "duration_seconds must not be negative",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.FollowLogsToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleFollowLogs(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError, "Result should be an error")
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestWithRequestCancellation(t *testing.T) {
+	reqCtx, cancelReq := context.WithCancel(context.Background())
+	ctx := context.WithValue(context.Background(), requestContextKey{}, reqCtx)
+
+	derived, cancel := withRequestCancellation(ctx)
+	defer cancel()
+	assert.NoError(t, derived.Err())
+
+	// Cancelling the original request must cancel the derived context.
+	cancelReq()
+	<-derived.Done()
+	assert.ErrorIs(t, derived.Err(), context.Canceled)
+}
+
+func TestNewFollowLogsTool(t *testing.T) {
+	tool := NewFollowLogsTool()
+
+	assert.Equal(t, types.FollowLogsToolName, tool.Name)
+	assert.Contains(t, tool.InputSchema.Required, "namespace")
+	assert.Contains(t, tool.InputSchema.Required, "name")
+	_, ok := tool.InputSchema.Properties["duration_seconds"]
+	assert.True(t, ok, "Should have 'duration_seconds' parameter")
+}
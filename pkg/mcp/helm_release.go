@@ -0,0 +1,126 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleListHelmReleases handles the list_helm_releases tool
+func (m *Implementation) HandleListHelmReleases(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	storage := mcp.ParseString(request, "storage", k8s.HelmStorageSecret)
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	releases, err := client.ListHelmReleases(ctx, namespace, storage)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to list Helm releases", err), nil
+	}
+
+	// Convert to JSON
+	releasesJSON, err := json.Marshal(releases)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal releases", err), nil
+	}
+
+	return mcp.NewToolResultText(string(releasesJSON)), nil
+}
+
+// HandleGetHelmRelease handles the get_helm_release tool
+func (m *Implementation) HandleGetHelmRelease(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	revision := request.GetInt("revision", 0)
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if revision < 0 {
+		return mcp.NewToolResultError("revision must not be negative"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	release, err := client.GetHelmRelease(ctx, namespace, name, k8s.HelmReleaseOptions{
+		Storage:         mcp.ParseString(request, "storage", k8s.HelmStorageSecret),
+		Revision:        revision,
+		AllValues:       request.GetBool("all_values", false),
+		IncludeManifest: request.GetBool("include_manifest", false),
+	})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Helm release", err), nil
+	}
+
+	// Convert to JSON
+	releaseJSON, err := json.Marshal(release)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal release", err), nil
+	}
+
+	return mcp.NewToolResultText(string(releaseJSON)), nil
+}
+
+// NewListHelmReleasesTool creates a new list_helm_releases tool
+func NewListHelmReleasesTool() mcp.Tool {
+	return mcp.NewTool(types.ListHelmReleasesToolName,
+		mcp.WithDescription("List Helm releases with their latest revision, status, chart and app version "+
+			"(like `helm list`), read from the release Secrets or ConfigMaps without the helm binary"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the releases (default: all namespaces)")),
+		mcp.WithString("storage",
+			mcp.Description("Storage driver the releases are kept in (default: secret)"),
+			mcp.Enum(k8s.HelmStorageSecret, k8s.HelmStorageConfigMap)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "List Helm releases",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewGetHelmReleaseTool creates a new get_helm_release tool
+func NewGetHelmReleaseTool() mcp.Tool {
+	return mcp.NewTool(types.GetHelmReleaseToolName,
+		mcp.WithDescription("Get a Helm release: chart name and version, status, notes, revision history, "+
+			"values and the resources of its rendered manifest. Sensitive values, such as passwords and tokens, "+
+			"and Secret data are redacted"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the release"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the release"),
+			mcp.Required()),
+		mcp.WithNumber("revision",
+			mcp.Description("Revision of the release (default: the latest)")),
+		mcp.WithBoolean("all_values",
+			mcp.Description("Return the chart default values merged with the supplied values, rather than "+
+				"only the supplied values (default: false)")),
+		mcp.WithBoolean("include_manifest",
+			mcp.Description("Also return the rendered manifest (default: false)")),
+		mcp.WithString("storage",
+			mcp.Description("Storage driver the release is kept in (default: secret)"),
+			mcp.Enum(k8s.HelmStorageSecret, k8s.HelmStorageConfigMap)),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Get a Helm release",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
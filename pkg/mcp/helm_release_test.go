@@ -0,0 +1,135 @@
+package mcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func newHelmReleaseTestImplementation(t *testing.T) *Implementation {
+	release, err := json.Marshal(map[string]interface{}{
+		"name":      "web",
+		"namespace": "shop",
+		"version":   1,
+		"info":      map[string]interface{}{"status": "deployed", "last_deployed": "2026-01-01T10:00:00Z"},
+		"chart": map[string]interface{}{
+			"metadata": map[string]interface{}{"name": "web", "version": "1.2.0", "appVersion": "2.0"},
+		},
+		"config":   map[string]interface{}{"replicas": 3, "adminPassword": "hunter2"},
+		"manifest": "---\napiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n",
+	})
+	require.NoError(t, err)
+
+	clientset := kubefake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.web.v1",
+			Namespace: "shop",
+			Labels:    map[string]string{"owner": "helm", "name": "web", "status": "deployed", "version": "1"},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(release))},
+	})
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	return NewImplementation(client)
+}
+
+func TestHandleListHelmReleases(t *testing.T) {
+	impl := newHelmReleaseTestImplementation(t)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ListHelmReleasesToolName
+	request.Params.Arguments = map[string]interface{}{"namespace": "shop"}
+
+	result, err := impl.HandleListHelmReleases(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var releases []k8s.HelmReleaseSummary
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &releases))
+	require.Len(t, releases, 1)
+	assert.Equal(t, "web", releases[0].Name)
+	assert.Equal(t, "1.2.0", releases[0].ChartVersion)
+	assert.Equal(t, "deployed", releases[0].Status)
+
+	// Invalid storage
+	request.Params.Arguments = map[string]interface{}{"storage": "sql"}
+	result, err = impl.HandleListHelmReleases(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleGetHelmRelease(t *testing.T) {
+	impl := newHelmReleaseTestImplementation(t)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.GetHelmReleaseToolName
+	request.Params.Arguments = map[string]interface{}{"namespace": "shop", "name": "web", "include_manifest": true}
+
+	result, err := impl.HandleGetHelmRelease(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	assert.NotContains(t, textContent.Text, "hunter2")
+
+	var release k8s.HelmRelease
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &release))
+	assert.Equal(t, 1, release.Revision)
+	assert.Equal(t, float64(3), release.Values["replicas"])
+	assert.Equal(t, "[REDACTED]", release.Values["adminPassword"])
+	assert.Equal(t, []k8s.HelmResource{{APIVersion: "apps/v1", Kind: "Deployment", Name: "web"}}, release.Resources)
+	assert.Contains(t, release.Manifest, "kind: Deployment")
+}
+
+func TestHandleGetHelmReleaseInvalidParameters(t *testing.T) {
+	impl := newHelmReleaseTestImplementation(t)
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing namespace", arguments: map[string]interface{}{"name": "web"}, errorMsg: "namespace is required"},
+		{name: "Missing name", arguments: map[string]interface{}{"namespace": "shop"}, errorMsg: "name is required"},
+		{
+			name:      "Negative revision",
+			arguments: map[string]interface{}{"namespace": "shop", "name": "web", "revision": float64(-1)},
+			errorMsg:  "revision must not be negative",
+		},
+		{
+			name:      "Missing release",
+			arguments: map[string]interface{}{"namespace": "shop", "name": "missing"},
+			errorMsg:  "not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.GetHelmReleaseToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleGetHelmRelease(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
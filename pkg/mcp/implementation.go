@@ -3,6 +3,7 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/StacklokLabs/mkp/pkg/identity"
 	"github.com/StacklokLabs/mkp/pkg/k8s"
@@ -12,6 +13,17 @@ import (
 type Implementation struct {
 	k8sClient            *k8s.Client
 	impersonationEnabled bool
+
+	// readWrite enables the mutating actions of tools that also serve
+	// read-only actions (e.g. rollout).
+	readWrite bool
+
+	// debugImages is the allowlist of images the debug_pod tool may use.
+	debugImages []string
+
+	// maxWaitTimeout caps the timeout of the wait_for tool. Zero means
+	// DefaultMaxWaitTimeout.
+	maxWaitTimeout time.Duration
 }
 
 // NewImplementation creates a new MCP implementation
@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// kustomizeBuildResult is the result of the kustomize_build tool.
+type kustomizeBuildResult struct {
+	Objects int `json:"objects"`
+	// Manifests is the rendered YAML, returned unless it is applied.
+	Manifests string                    `json:"manifests,omitempty"`
+	Apply     *k8s.ApplyManifestsResult `json:"apply,omitempty"`
+}
+
+// HandleKustomizeBuild handles the kustomize_build tool
+func (m *Implementation) HandleKustomizeBuild(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	dir := mcp.ParseString(request, "path", ".")
+	apply := request.GetBool("apply", false)
+	dryRun := request.GetBool("dry_run", false)
+
+	// Validate parameters
+	files, err := parseKustomizeFiles(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if (apply || dryRun) && !m.readWrite {
+		return mcp.NewToolResultError("apply requires the server to run with --read-write"), nil
+	}
+	if dryRun && !apply {
+		return mcp.NewToolResultError("dry_run requires apply"), nil
+	}
+
+	manifests, err := k8s.BuildKustomization(files, dir)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to build kustomization", err), nil
+	}
+	objects, err := k8s.DecodeManifests(manifests)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to decode kustomization output", err), nil
+	}
+
+	result := kustomizeBuildResult{Objects: len(objects)}
+	if !apply {
+		result.Manifests = manifests
+	} else if len(objects) > 0 {
+		return m.applyKustomization(ctx, request, objects, dryRun, result)
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// parseKustomizeFiles returns the files parameter of the kustomize_build
+// tool, a map of path to file content.
+func parseKustomizeFiles(request mcp.CallToolRequest) (map[string]string, error) {
+	filesMap := mcp.ParseStringMap(request, "files", nil)
+	if len(filesMap) == 0 {
+		return nil, fmt.Errorf("files is required")
+	}
+	files := make(map[string]string, len(filesMap))
+	for name, content := range filesMap {
+		contentString, ok := content.(string)
+		if !ok {
+			return nil, fmt.Errorf("content of file %q must be a string", name)
+		}
+		files[name] = contentString
+	}
+	return files, nil
+}
+
+// applyKustomization applies the rendered objects of a kustomization like
+// apply_manifests and returns result with the outcome.
+func (m *Implementation) applyKustomization(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	objects []*unstructured.Unstructured,
+	dryRun bool,
+	result kustomizeBuildResult,
+) (*mcp.CallToolResult, error) {
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop applying as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	notifier := newProgressNotifier(ctx, request, types.KustomizeBuildToolName)
+	result.Apply, err = client.ApplyManifests(ctx, objects, k8s.ApplyManifestsOptions{
+		DryRun:     dryRun,
+		OnProgress: notifier.Notify,
+	})
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to apply kustomization", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewKustomizeBuildTool creates a new kustomize_build tool. Applying the
+// result is only offered with readWrite.
+func NewKustomizeBuildTool(readWrite bool) mcp.Tool {
+	description := "Render an inline kustomization, like `kustomize build`. The kustomization.yaml files, " +
+		"resources and patches are passed as a map of path to content; remote bases and URLs are not supported"
+	if readWrite {
+		description += ". With apply, the rendered objects are applied like apply_manifests instead of returned"
+	}
+
+	options := []mcp.ToolOption{
+		mcp.WithDescription(description),
+		mcp.WithObject("files",
+			mcp.Description("Files of the kustomization, as a map of path relative to the root (e.g., "+
+				"base/kustomization.yaml, overlays/prod/kustomization.yaml) to file content"),
+			mcp.Required()),
+		mcp.WithString("path",
+			mcp.Description("Directory of the kustomization to build, relative to the root (default: .)")),
+	}
+	if readWrite {
+		options = append(options,
+			mcp.WithBoolean("apply",
+				mcp.Description("Apply the rendered objects instead of returning them (default: false)")),
+			mcp.WithBoolean("dry_run",
+				mcp.Description("With apply, apply as a server-side dry run without persisting (default: false)")),
+		)
+	}
+	options = append(options, mcp.WithToolAnnotation(mcp.ToolAnnotation{
+		Title:          "Build a kustomization",
+		ReadOnlyHint:   BoolPtr(!readWrite),
+		IdempotentHint: BoolPtr(true),
+	}))
+
+	return mcp.NewTool(types.KustomizeBuildToolName, options...)
+}
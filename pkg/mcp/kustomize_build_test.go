@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+var testKustomizeFiles = map[string]interface{}{
+	"kustomization.yaml": "namespace: shop\nresources:\n- service.yaml\n",
+	"service.yaml":       "apiVersion: v1\nkind: Service\nmetadata:\n  name: web\nspec:\n  ports:\n  - port: 80\n",
+}
+
+func TestHandleKustomizeBuild(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.KustomizeBuildToolName
+	request.Params.Arguments = map[string]interface{}{"files": testKustomizeFiles}
+
+	result, err := impl.HandleKustomizeBuild(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var buildResult kustomizeBuildResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &buildResult))
+	assert.Equal(t, 1, buildResult.Objects)
+	assert.Contains(t, buildResult.Manifests, "namespace: shop")
+	assert.Nil(t, buildResult.Apply)
+}
+
+func TestHandleKustomizeBuildApply(t *testing.T) {
+	fakeDynamicClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+	var dryRun []string
+	fakeDynamicClient.PrependReactor("create", "services", func(action ktesting.Action) (bool, runtime.Object, error) {
+		dryRun = action.(ktesting.CreateActionImpl).CreateOptions.DryRun
+		return true, action.(ktesting.CreateAction).GetObject(), nil
+	})
+	fakeDiscoveryClient := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscoveryClient.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{{Name: "services", Kind: "Service", Namespaced: true}},
+		},
+	}
+	mockClient := &k8s.Client{}
+	mockClient.SetDynamicClient(fakeDynamicClient)
+	mockClient.SetDiscoveryClient(fakeDiscoveryClient)
+	impl := NewImplementation(mockClient)
+	impl.readWrite = true
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.KustomizeBuildToolName
+	request.Params.Arguments = map[string]interface{}{"files": testKustomizeFiles, "apply": true, "dry_run": true}
+
+	result, err := impl.HandleKustomizeBuild(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var buildResult kustomizeBuildResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &buildResult))
+	assert.Empty(t, buildResult.Manifests)
+	require.NotNil(t, buildResult.Apply)
+	assert.True(t, buildResult.Apply.DryRun)
+	assert.Equal(t, 1, buildResult.Apply.Applied)
+	assert.Equal(t, "shop", buildResult.Apply.Results[0].Namespace)
+	assert.Equal(t, []string{metav1.DryRunAll}, dryRun)
+}
+
+func TestHandleKustomizeBuildInvalidParameters(t *testing.T) {
+	testCases := []struct {
+		name      string
+		readWrite bool
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing files", arguments: map[string]interface{}{}, errorMsg: "files is required"},
+		{
+			name:      "Non-string content",
+			arguments: map[string]interface{}{"files": map[string]interface{}{"kustomization.yaml": 1}},
+			errorMsg:  "must be a string",
+		},
+		{
+			name:      "Apply without read-write",
+			arguments: map[string]interface{}{"files": testKustomizeFiles, "apply": true},
+			errorMsg:  "--read-write",
+		},
+		{
+			name:      "Dry run without apply",
+			readWrite: true,
+			arguments: map[string]interface{}{"files": testKustomizeFiles, "dry_run": true},
+			errorMsg:  "dry_run requires apply",
+		},
+		{
+			name: "Remote base",
+			arguments: map[string]interface{}{
+				"files": map[string]interface{}{"kustomization.yaml": "resources:\n- https://example.com/app.yaml\n"},
+			},
+			errorMsg: "remote reference",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			impl := NewImplementation(&k8s.Client{})
+			impl.readWrite = tc.readWrite
+
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.KustomizeBuildToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleKustomizeBuild(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestNewKustomizeBuildTool(t *testing.T) {
+	readOnlyTool := NewKustomizeBuildTool(false)
+	assert.Equal(t, types.KustomizeBuildToolName, readOnlyTool.Name)
+	assert.NotContains(t, readOnlyTool.InputSchema.Properties, "apply")
+	assert.True(t, *readOnlyTool.Annotations.ReadOnlyHint)
+
+	readWriteTool := NewKustomizeBuildTool(true)
+	assert.Contains(t, readWriteTool.InputSchema.Properties, "apply")
+	assert.Contains(t, readWriteTool.InputSchema.Properties, "dry_run")
+	assert.False(t, *readWriteTool.Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,80 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleListAPIResources handles the list_api_resources tool
+func (m *Implementation) HandleListAPIResources(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	filter := k8s.APIResourceFilter{
+		Verb:        mcp.ParseString(request, "verb", ""),
+		Category:    mcp.ParseString(request, "category", ""),
+		Name:        mcp.ParseString(request, "name", ""),
+		AllVersions: request.GetBool("all_versions", false),
+	}
+	if _, ok := request.GetArguments()["group"]; ok {
+		filter.Group = mcp.ParseString(request, "group", "")
+		if filter.Group == "core" {
+			filter.Group = ""
+		}
+		filter.GroupSet = true
+	}
+	if _, ok := request.GetArguments()["namespaced"]; ok {
+		namespaced := request.GetBool("namespaced", false)
+		filter.Namespaced = &namespaced
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.FindAPIResources(ctx, filter)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to list API resources", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewListAPIResourcesTool creates a new list_api_resources tool
+func NewListAPIResourcesTool() mcp.Tool {
+	return mcp.NewTool(types.ListAPIResourcesToolName,
+		mcp.WithDescription("List the resources served by the API server, like `kubectl api-resources`: group, "+
+			"version, kind, scope, short names, verbs, categories and subresources. Use it to find the group, "+
+			"version and resource to pass to the other tools"),
+		mcp.WithString("group",
+			mcp.Description("API group of the resources, core or an empty string for the core group "+
+				"(default: all groups)")),
+		mcp.WithBoolean("namespaced",
+			mcp.Description("Only namespaced (true) or cluster-scoped (false) resources (default: both)")),
+		mcp.WithString("verb",
+			mcp.Description("Verb the resources must support (e.g., list, watch, patch)")),
+		mcp.WithString("category",
+			mcp.Description("Category the resources must belong to (e.g., all)")),
+		mcp.WithString("name",
+			mcp.Description("Case-insensitive substring of the name, singular name, kind or a short name "+
+				"of the resources")),
+		mcp.WithBoolean("all_versions",
+			mcp.Description("Include every version of each group rather than only the preferred one "+
+				"(default: false)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "List API resources",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,83 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleListAPIResources(t *testing.T) {
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "pods", Kind: "Pod", Namespaced: true, ShortNames: []string{"po"}, Verbs: []string{"get", "list"}},
+				{Name: "pods/log", Kind: "Pod", Namespaced: true, Verbs: []string{"get"}},
+				{Name: "nodes", Kind: "Node", Verbs: []string{"get", "list"}},
+			},
+		},
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "deployments", Kind: "Deployment", Namespaced: true, Verbs: []string{"get", "list", "patch"}},
+			},
+		},
+	}
+	client := &k8s.Client{}
+	client.SetDiscoveryClient(fakeDiscovery)
+	impl := NewImplementation(client)
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		expected  []string
+	}{
+		{name: "All", arguments: map[string]interface{}{}, expected: []string{"nodes", "pods", "deployments"}},
+		{name: "Core group", arguments: map[string]interface{}{"group": "core"}, expected: []string{"nodes", "pods"}},
+		{name: "Empty group", arguments: map[string]interface{}{"group": ""}, expected: []string{"nodes", "pods"}},
+		{name: "Namespaced", arguments: map[string]interface{}{"namespaced": true}, expected: []string{"pods", "deployments"}},
+		{name: "Cluster-scoped", arguments: map[string]interface{}{"namespaced": false}, expected: []string{"nodes"}},
+		{name: "Verb", arguments: map[string]interface{}{"verb": "patch"}, expected: []string{"deployments"}},
+		{name: "Name", arguments: map[string]interface{}{"name": "po"}, expected: []string{"pods"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.ListAPIResourcesToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleListAPIResources(context.Background(), request)
+			require.NoError(t, err)
+			require.False(t, result.IsError, "%v", result.Content)
+
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			var resources k8s.APIResourcesResult
+			require.NoError(t, json.Unmarshal([]byte(textContent.Text), &resources))
+			names := []string{}
+			for _, resource := range resources.Resources {
+				names = append(names, resource.Name)
+			}
+			assert.Equal(t, tc.expected, names)
+		})
+	}
+}
+
+func TestNewListAPIResourcesTool(t *testing.T) {
+	tool := NewListAPIResourcesTool()
+	assert.Equal(t, types.ListAPIResourcesToolName, tool.Name)
+	assert.Empty(t, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
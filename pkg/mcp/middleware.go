@@ -11,9 +11,20 @@ import (
 	"github.com/StacklokLabs/mkp/pkg/ratelimit"
 )
 
+// requestContextKey is the context key under which the middleware stores the
+// original (transport) context of a tool call.
+type requestContextKey struct{}
+
 // WithTimeoutContext adds a timeout context to all tool handlers
 // This helps prevent context cancellation errors from the k8s client
 func WithTimeoutContext(timeout time.Duration) server.ServerOption {
+	return WithToolTimeoutContext(timeout, nil)
+}
+
+// WithToolTimeoutContext is like WithTimeoutContext, but lets individual tools
+// run with a different timeout than the default. This is used by long-running
+// tools (e.g. follow_logs) whose duration is bounded by their own caps.
+func WithToolTimeoutContext(defaultTimeout time.Duration, toolTimeouts map[string]time.Duration) server.ServerOption {
 	return server.WithToolHandlerMiddleware(func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return func(ctx context.Context, request mcp.CallToolRequest) (result *mcp.CallToolResult, err error) {
 			// Extract session ID from the original context
@@ -25,6 +36,11 @@ func WithTimeoutContext(timeout time.Duration) server.ServerOption {
 			// Extract identity from the original context (set by HTTP context func)
 			id := identity.FromContext(ctx)
 
+			timeout := defaultTimeout
+			if toolTimeout, ok := toolTimeouts[request.Params.Name]; ok {
+				timeout = toolTimeout
+			}
+
 			// Create a fresh context with a longer timeout (prevents cancellation)
 			timeoutCtx, cancel := context.WithTimeout(context.Background(), timeout)
 			defer cancel()
@@ -39,8 +55,38 @@ func WithTimeoutContext(timeout time.Duration) server.ServerOption {
 				timeoutCtx = identity.WithContext(timeoutCtx, id)
 			}
 
+			// Keep a handle on the original context so that long-running tools
+			// can notify the calling session and honour client cancellation.
+			timeoutCtx = context.WithValue(timeoutCtx, requestContextKey{}, ctx)
+
 			// Call the next handler with the timeout context
 			return next(timeoutCtx, request)
 		}
 	})
 }
+
+// requestContextFromContext returns the original transport context stored by
+// the timeout middleware, or nil if the handler was not called through it.
+func requestContextFromContext(ctx context.Context) context.Context {
+	if reqCtx, ok := ctx.Value(requestContextKey{}).(context.Context); ok {
+		return reqCtx
+	}
+	return nil
+}
+
+// withRequestCancellation returns a context derived from ctx that is also
+// cancelled when the original transport context of the tool call is done,
+// i.e. when the client goes away or cancels the request. Handlers that stream
+// or wait for a long time use this; short handlers keep the detached context.
+func withRequestCancellation(ctx context.Context) (context.Context, context.CancelFunc) {
+	cancelCtx, cancel := context.WithCancel(ctx)
+	reqCtx := requestContextFromContext(ctx)
+	if reqCtx == nil {
+		return cancelCtx, cancel
+	}
+	stop := context.AfterFunc(reqCtx, cancel)
+	return cancelCtx, func() {
+		stop()
+		cancel()
+	}
+}
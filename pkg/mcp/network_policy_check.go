@@ -0,0 +1,112 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleNetworkPolicyCheck handles the network_policy_check tool
+func (m *Implementation) HandleNetworkPolicyCheck(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	query := k8s.NetworkPolicyQuery{
+		Source: k8s.NetworkEndpoint{
+			Namespace: mcp.ParseString(request, "source_namespace", ""),
+			Pod:       mcp.ParseString(request, "source_pod", ""),
+			IP:        mcp.ParseString(request, "source_ip", ""),
+		},
+		Destination: k8s.NetworkEndpoint{
+			Namespace: mcp.ParseString(request, "destination_namespace", ""),
+			Pod:       mcp.ParseString(request, "destination_pod", ""),
+		},
+		Protocol: mcp.ParseString(request, "protocol", ""),
+	}
+	sourceLabels := mcp.ParseString(request, "source_labels", "")
+	port := request.GetInt("port", 0)
+
+	// Validate parameters
+	if query.Source.Namespace == "" {
+		return mcp.NewToolResultError("source_namespace is required"), nil
+	}
+	if query.Destination.Namespace == "" {
+		return mcp.NewToolResultError("destination_namespace is required"), nil
+	}
+	if query.Destination.Pod == "" {
+		return mcp.NewToolResultError("destination_pod is required"), nil
+	}
+	if port <= 0 || port > 65535 {
+		return mcp.NewToolResultError("port must be between 1 and 65535"), nil
+	}
+	query.Port = int32(port)
+	if query.Source.Pod != "" && (sourceLabels != "" || query.Source.IP != "") {
+		return mcp.NewToolResultError("source_pod is mutually exclusive with source_labels and source_ip"), nil
+	}
+	if sourceLabels != "" {
+		set, err := labels.ConvertSelectorToLabelsMap(sourceLabels)
+		if err != nil {
+			return mcp.NewToolResultError(fmt.Sprintf("invalid source_labels: %v", err)), nil
+		}
+		query.Source.Labels = set
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.CheckNetworkPolicies(ctx, query)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to check network policies", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewNetworkPolicyCheckTool creates a new network_policy_check tool
+func NewNetworkPolicyCheckTool() mcp.Tool {
+	return mcp.NewTool(types.NetworkPolicyCheckToolName,
+		mcp.WithDescription("Check whether NetworkPolicies allow traffic from a source pod, or a pod with given "+
+			"labels, to a port of a destination pod, for the egress of the source and the ingress of the "+
+			"destination, and which policies and rules decided it. The policies are evaluated from the listed "+
+			"NetworkPolicy, Pod and Namespace objects: no traffic is sent, and policies specific to a network "+
+			"plugin are not considered"),
+		mcp.WithString("source_namespace",
+			mcp.Description("Namespace of the source"),
+			mcp.Required()),
+		mcp.WithString("source_pod",
+			mcp.Description("Name of the source pod")),
+		mcp.WithString("source_labels",
+			mcp.Description("Labels of the source instead of a pod (e.g., app=web,tier=frontend)")),
+		mcp.WithString("source_ip",
+			mcp.Description("IP address of the source instead of a pod, to evaluate ipBlock peers")),
+		mcp.WithString("destination_namespace",
+			mcp.Description("Namespace of the destination pod"),
+			mcp.Required()),
+		mcp.WithString("destination_pod",
+			mcp.Description("Name of the destination pod"),
+			mcp.Required()),
+		mcp.WithNumber("port",
+			mcp.Description("Port of the destination pod"),
+			mcp.Required()),
+		mcp.WithString("protocol",
+			mcp.Description("Protocol of the traffic (default: TCP)"),
+			mcp.Enum("TCP", "UDP", "SCTP")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Check network policies",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
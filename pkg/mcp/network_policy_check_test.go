@@ -0,0 +1,95 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleNetworkPolicyCheck(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "shop", Labels: map[string]string{"app": "db"}}},
+		&networkingv1.NetworkPolicy{
+			ObjectMeta: metav1.ObjectMeta{Name: "db-ingress", Namespace: "shop"},
+			Spec: networkingv1.NetworkPolicySpec{
+				PodSelector: metav1.LabelSelector{MatchLabels: map[string]string{"app": "db"}},
+				Ingress: []networkingv1.NetworkPolicyIngressRule{{
+					From: []networkingv1.NetworkPolicyPeer{{
+						PodSelector: &metav1.LabelSelector{MatchLabels: map[string]string{"app": "web"}},
+					}},
+				}},
+			},
+		},
+	)
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.NetworkPolicyCheckToolName
+	arguments := map[string]interface{}{
+		"source_namespace":      "shop",
+		"source_labels":         "app=web",
+		"destination_namespace": "shop",
+		"destination_pod":       "db",
+		"port":                  float64(5432),
+	}
+	request.Params.Arguments = arguments
+
+	result, err := impl.HandleNetworkPolicyCheck(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var check k8s.NetworkPolicyResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &check))
+	assert.True(t, check.Allowed)
+	assert.Equal(t, map[string]string{"app": "web"}, check.Source.Labels)
+	require.Len(t, check.Ingress.Policies, 1)
+	assert.Equal(t, "db-ingress", check.Ingress.Policies[0].Name)
+
+	arguments["source_labels"] = "app=cart"
+	result, err = impl.HandleNetworkPolicyCheck(context.Background(), request)
+	require.NoError(t, err)
+	textContent, ok = mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &check))
+	assert.False(t, check.Allowed)
+
+	// Invalid parameters
+	for _, invalid := range []map[string]interface{}{
+		{"destination_namespace": "shop", "destination_pod": "db", "port": float64(80)},
+		{"source_namespace": "shop", "destination_pod": "db", "port": float64(80)},
+		{"source_namespace": "shop", "destination_namespace": "shop", "port": float64(80)},
+		{"source_namespace": "shop", "destination_namespace": "shop", "destination_pod": "db"},
+		{"source_namespace": "shop", "source_pod": "web", "source_labels": "app=web",
+			"destination_namespace": "shop", "destination_pod": "db", "port": float64(80)},
+		{"source_namespace": "shop", "source_labels": "app in (web)",
+			"destination_namespace": "shop", "destination_pod": "db", "port": float64(80)},
+	} {
+		request.Params.Arguments = invalid
+		result, err = impl.HandleNetworkPolicyCheck(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", invalid)
+	}
+}
+
+func TestNewNetworkPolicyCheckTool(t *testing.T) {
+	tool := NewNetworkPolicyCheckTool()
+	assert.Equal(t, types.NetworkPolicyCheckToolName, tool.Name)
+	assert.ElementsMatch(t, []string{"source_namespace", "destination_namespace", "destination_pod", "port"},
+		tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
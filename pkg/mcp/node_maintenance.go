@@ -0,0 +1,180 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// drainTimeoutGrace is added on top of k8s.MaxDrainTimeout when computing the
+// drain_node tool timeout.
+const drainTimeoutGrace = 15 * time.Second
+
+// HandleCordonNode handles the cordon_node tool
+func (m *Implementation) HandleCordonNode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return m.handleCordon(ctx, request, true)
+}
+
+// HandleUncordonNode handles the uncordon_node tool
+func (m *Implementation) HandleUncordonNode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	return m.handleCordon(ctx, request, false)
+}
+
+// handleCordon marks the node named in request as unschedulable or
+// schedulable.
+func (m *Implementation) handleCordon(
+	ctx context.Context,
+	request mcp.CallToolRequest,
+	unschedulable bool,
+) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.CordonNode(ctx, name, unschedulable)
+	if err != nil {
+		action := "cordon"
+		if !unschedulable {
+			action = "uncordon"
+		}
+		return mcp.NewToolResultErrorFromErr(fmt.Sprintf("Failed to %s node", action), err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// HandleDrainNode handles the drain_node tool
+func (m *Implementation) HandleDrainNode(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	name := mcp.ParseString(request, "name", "")
+	timeout := request.GetInt("timeout", 0)
+
+	// Validate parameters
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if timeout < 0 {
+		return mcp.NewToolResultError("timeout must not be negative"), nil
+	}
+
+	opts := k8s.DrainOptions{
+		Timeout:            time.Duration(timeout) * time.Second,
+		Force:              request.GetBool("force", false),
+		DeleteEmptyDirData: request.GetBool("delete_emptydir_data", false),
+		DryRun:             request.GetBool("dry_run", false),
+	}
+	if _, ok := request.GetArguments()["grace_period_seconds"]; ok {
+		gracePeriod := int64(request.GetInt("grace_period_seconds", -1))
+		if gracePeriod < 0 {
+			return mcp.NewToolResultError("grace_period_seconds must not be negative"), nil
+		}
+		opts.GracePeriodSeconds = &gracePeriod
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop draining as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	notifier := newProgressNotifier(ctx, request, "nodes/"+name)
+	opts.OnProgress = notifier.Notify
+
+	result, err := client.DrainNode(ctx, name, opts)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to drain node", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewCordonNodeTool creates a new cordon_node tool
+func NewCordonNodeTool() mcp.Tool {
+	return mcp.NewTool(types.CordonNodeToolName,
+		mcp.WithDescription("Mark a node as unschedulable, so that no new pods are scheduled on it "+
+			"(like `kubectl cordon`)"),
+		mcp.WithString("name",
+			mcp.Description("Name of the node"),
+			mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:          "Cordon a node",
+			ReadOnlyHint:   BoolPtr(false),
+			IdempotentHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewUncordonNodeTool creates a new uncordon_node tool
+func NewUncordonNodeTool() mcp.Tool {
+	return mcp.NewTool(types.UncordonNodeToolName,
+		mcp.WithDescription("Mark a node as schedulable again (like `kubectl uncordon`)"),
+		mcp.WithString("name",
+			mcp.Description("Name of the node"),
+			mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:          "Uncordon a node",
+			ReadOnlyHint:   BoolPtr(false),
+			IdempotentHint: BoolPtr(true),
+		}),
+	)
+}
+
+// NewDrainNodeTool creates a new drain_node tool
+func NewDrainNodeTool() mcp.Tool {
+	return mcp.NewTool(types.DrainNodeToolName,
+		mcp.WithDescription("Cordon a node and evict its pods through the eviction API, respecting "+
+			"PodDisruptionBudgets (like `kubectl drain`). DaemonSet and mirror pods are skipped. Returns the "+
+			"evicted, skipped and failed pods with the reason each pod could not be evicted"),
+		mcp.WithString("name",
+			mcp.Description("Name of the node"),
+			mcp.Required()),
+		mcp.WithNumber("grace_period_seconds",
+			mcp.Description("Termination grace period of evicted pods (default: each pod's own)")),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("How long to drain in seconds (default: 120, maximum: %d)",
+				int(k8s.MaxDrainTimeout.Seconds())))),
+		mcp.WithBoolean("force",
+			mcp.Description("Also evict pods that are not managed by a controller (default: false)")),
+		mcp.WithBoolean("delete_emptydir_data",
+			mcp.Description("Also evict pods using emptyDir volumes, losing their data (default: false)")),
+		mcp.WithBoolean("dry_run",
+			mcp.Description("Only report which pods would be evicted, without cordoning or evicting (default: false)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Drain a node",
+			ReadOnlyHint:    BoolPtr(false),
+			DestructiveHint: BoolPtr(true),
+		}),
+	)
+}
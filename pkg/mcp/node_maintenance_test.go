@@ -0,0 +1,132 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleCordonAndUncordonNode(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}})
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.CordonNodeToolName
+	request.Params.Arguments = map[string]interface{}{"name": "node-1"}
+
+	result, err := impl.HandleCordonNode(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var cordonResult k8s.CordonResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &cordonResult))
+	assert.True(t, cordonResult.Unschedulable)
+	assert.True(t, cordonResult.Changed)
+
+	request.Params.Name = types.UncordonNodeToolName
+	result, err = impl.HandleUncordonNode(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	node, err := clientset.CoreV1().Nodes().Get(context.Background(), "node-1", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.False(t, node.Spec.Unschedulable)
+
+	// Missing node
+	request.Params.Arguments = map[string]interface{}{"name": "missing"}
+	result, err = impl.HandleCordonNode(context.Background(), request)
+	require.NoError(t, err)
+	assert.True(t, result.IsError)
+}
+
+func TestHandleDrainNodeDryRun(t *testing.T) {
+	isController := true
+	clientset := kubefake.NewSimpleClientset(
+		&corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node-1"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "web",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "web", Controller: &isController,
+				}},
+			},
+			Spec: corev1.PodSpec{NodeName: "node-1"},
+		},
+	)
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.DrainNodeToolName
+	request.Params.Arguments = map[string]interface{}{"name": "node-1", "dry_run": true}
+
+	result, err := impl.HandleDrainNode(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var drainResult k8s.DrainResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &drainResult))
+	assert.True(t, drainResult.DryRun)
+	assert.Equal(t, []k8s.DrainPod{{Namespace: "default", Name: "web"}}, drainResult.Evicted)
+}
+
+func TestHandleDrainNodeInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing name", arguments: map[string]interface{}{}, errorMsg: "name is required"},
+		{name: "Negative timeout", arguments: map[string]interface{}{"name": "node-1", "timeout": float64(-1)}, errorMsg: "timeout"},
+		{
+			name:      "Negative grace period",
+			arguments: map[string]interface{}{"name": "node-1", "grace_period_seconds": float64(-5)},
+			errorMsg:  "grace_period_seconds",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.DrainNodeToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleDrainNode(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestNodeMaintenanceToolRegistration(t *testing.T) {
+	for _, readWrite := range []bool{false, true} {
+		srv := CreateServer(&k8s.Client{}, &Config{ReadWrite: readWrite})
+		for _, name := range []string{types.CordonNodeToolName, types.UncordonNodeToolName, types.DrainNodeToolName} {
+			assert.Equal(t, readWrite, srv.MCPServer().GetTool(name) != nil, "%s with read-write=%v", name, readWrite)
+		}
+		srv.Stop()
+	}
+}
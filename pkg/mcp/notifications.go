@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"log"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+)
+
+// progressNotifier sends progress and logging notifications to the session
+// that issued a tool call. It is a no-op when the call did not come through
+// an MCP session (e.g. in unit tests that invoke handlers directly).
+type progressNotifier struct {
+	srv    *server.MCPServer
+	reqCtx context.Context
+	token  mcp.ProgressToken
+	logger string
+	count  float64
+}
+
+// newProgressNotifier creates a notifier for the given tool call. logger is
+// used as the logger name on logging notifications.
+func newProgressNotifier(ctx context.Context, request mcp.CallToolRequest, logger string) *progressNotifier {
+	n := &progressNotifier{logger: logger}
+
+	reqCtx := requestContextFromContext(ctx)
+	if reqCtx == nil {
+		reqCtx = ctx
+	}
+	n.reqCtx = reqCtx
+	n.srv = server.ServerFromContext(reqCtx)
+
+	if request.Params.Meta != nil {
+		n.token = request.Params.Meta.ProgressToken
+	}
+	return n
+}
+
+// Notify sends message to the client. If the caller supplied a progress token,
+// a progress notification is sent; a logging notification is always sent as
+// well, subject to the log level the client has configured.
+func (n *progressNotifier) Notify(message string) {
+	if n.srv == nil {
+		return
+	}
+	n.count++
+
+	if n.token != nil {
+		err := n.srv.SendNotificationToClient(n.reqCtx, string(mcp.MethodNotificationProgress), map[string]any{
+			"progressToken": n.token,
+			"progress":      n.count,
+			"message":       message,
+		})
+		if err != nil {
+			log.Printf("Failed to send progress notification: %v", err)
+		}
+	}
+
+	// Logging is best effort: sessions that do not support logging, or have
+	// not finished initialising, are silently skipped.
+	_ = n.srv.SendLogMessageToClient(n.reqCtx, mcp.NewLoggingMessageNotification(
+		mcp.LoggingLevelInfo, n.logger, message,
+	))
+}
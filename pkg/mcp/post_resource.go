@@ -139,8 +139,10 @@ func NewPostResourceTool() mcp.Tool {
 		mcp.WithString("subresource",
 			mcp.Description("Subresource to post to (e.g., exec)")),
 		mcp.WithObject("body",
-			mcp.Description("Body to post to the resource. For pod exec, include 'command' (string or array), "+
-				"'container' (optional), and 'timeout' (optional, in seconds)"),
+			mcp.Description("Body to post to the resource. For pod exec, include 'command' (string or array) "+
+				"or 'script' (multi-line script run with 'shell' -c, default /bin/sh), 'container' (optional), "+
+				"'stdin' (optional string, max 1 MiB) and 'timeout' (optional, in seconds). "+
+				"The exec result reports the command's exitCode"),
 			mcp.Required()),
 		mcp.WithObject("parameters",
 			mcp.Description("Optional parameters for the request")),
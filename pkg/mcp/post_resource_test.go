@@ -23,7 +23,7 @@ func TestHandlePostResourceExecSuccess(t *testing.T) {
 
 	// Create a mock implementation for ExecInPod
 	mockExecCalled := false
-	mockExecFunc := func(_ context.Context, namespace, name string, command []string, container string, _ time.Duration) (*unstructured.Unstructured, error) {
+	mockExecFunc := func(_ context.Context, namespace, name string, command []string, container string, _ string, _ time.Duration) (*unstructured.Unstructured, error) {
 		// Mark that the function was called
 		mockExecCalled = true
 
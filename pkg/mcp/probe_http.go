@@ -0,0 +1,110 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleProbePodHTTP handles the probe_pod_http tool
+func (m *Implementation) HandleProbePodHTTP(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	kind := strings.ToLower(mcp.ParseString(request, "kind", "pod"))
+	// Accept numeric ports as numbers as well as strings.
+	port := ""
+	if portArg, ok := request.GetArguments()["port"]; ok && portArg != nil {
+		port = fmt.Sprintf("%v", portArg)
+	}
+	path := mcp.ParseString(request, "path", "/")
+	https := request.GetBool("https", false)
+	timeout := request.GetInt("timeout", 0)
+	maxBodyBytes := request.GetInt("max_body_bytes", 0)
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if kind != "pod" && kind != "service" {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid kind %q: must be pod or service", kind)), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	opts := k8s.HTTPProbeOptions{
+		Port:         port,
+		Path:         path,
+		HTTPS:        https,
+		Timeout:      time.Duration(timeout) * time.Second,
+		MaxBodyBytes: int64(maxBodyBytes),
+	}
+
+	var result *k8s.HTTPProbeResult
+	if kind == "service" {
+		result, err = client.ProbeServiceHTTP(ctx, namespace, name, opts)
+	} else {
+		result, err = client.ProbePodHTTP(ctx, namespace, name, opts)
+	}
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to probe HTTP endpoint", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewProbePodHTTPTool creates a new probe_pod_http tool
+func NewProbePodHTTPTool() mcp.Tool {
+	return mcp.NewTool(types.ProbePodHTTPToolName,
+		mcp.WithDescription("Send a single HTTP GET to a pod or service port through a temporary port-forward, "+
+			"e.g. to check a /healthz or /metrics endpoint without exec. Returns the status, headers and a "+
+			"truncated body. Redirects are not followed"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod or service"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod or service"),
+			mcp.Required()),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the target: pod or service (default: pod). For services, a ready backing pod is probed"),
+			mcp.Enum("pod", "service")),
+		mcp.WithString("port",
+			mcp.Description("Port number or name; a container port for pods, a service port for services "+
+				"(optional when the target has a single port)")),
+		mcp.WithString("path",
+			mcp.Description("Request path with optional query string (default: /)")),
+		mcp.WithBoolean("https",
+			mcp.Description("Use HTTPS; the certificate is not verified (default: false)")),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("Request timeout in seconds (default: 5, maximum: %d)",
+				int(k8s.MaxHTTPProbeTimeout.Seconds())))),
+		mcp.WithNumber("max_body_bytes",
+			mcp.Description(fmt.Sprintf("Maximum number of body bytes to return (default: 16384, maximum: %d)",
+				k8s.MaxHTTPProbeBodyBytes))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:         "Probe a pod HTTP endpoint",
+			ReadOnlyHint:  BoolPtr(true),
+			OpenWorldHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleProbePodHTTP(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/healthz", r.URL.Path)
+		_, _ = w.Write([]byte("healthy"))
+	}))
+	defer server.Close()
+	_, portString, err := net.SplitHostPort(server.Listener.Addr().String())
+	require.NoError(t, err)
+	localPort, err := strconv.Atoi(portString)
+	require.NoError(t, err)
+
+	client := &k8s.Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(&corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-0", Namespace: "default"},
+		Status:     corev1.PodStatus{Phase: corev1.PodRunning},
+	}))
+	client.SetPortForwardFunc(func(_ context.Context, _, _ string, port int32) (uint16, func(), error) {
+		assert.Equal(t, int32(8080), port)
+		return uint16(localPort), func() {}, nil
+	})
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ProbePodHTTPToolName
+	request.Params.Arguments = map[string]interface{}{
+		"namespace": "default",
+		"name":      "web-0",
+		"port":      float64(8080),
+		"path":      "/healthz",
+	}
+
+	result, err := impl.HandleProbePodHTTP(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var probeResult k8s.HTTPProbeResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &probeResult))
+	assert.Equal(t, http.StatusOK, probeResult.StatusCode)
+	assert.Equal(t, "healthy", probeResult.Body)
+}
+
+func TestHandleProbePodHTTPInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing namespace", arguments: map[string]interface{}{"name": "p"}, errorMsg: "namespace is required"},
+		{name: "Missing name", arguments: map[string]interface{}{"namespace": "d"}, errorMsg: "name is required"},
+		{
+			name:      "Invalid kind",
+			arguments: map[string]interface{}{"namespace": "d", "name": "p", "kind": "deployment"},
+			errorMsg:  "invalid kind",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.ProbePodHTTPToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleProbePodHTTP(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestProbePodHTTPToolRegistration(t *testing.T) {
+	for _, readWrite := range []bool{false, true} {
+		srv := CreateServer(&k8s.Client{}, &Config{ReadWrite: readWrite})
+		assert.Equal(t, readWrite, srv.MCPServer().GetTool(types.ProbePodHTTPToolName) != nil)
+		srv.Stop()
+	}
+}
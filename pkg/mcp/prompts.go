@@ -0,0 +1,274 @@
+package mcp
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/mark3labs/mcp-go/server"
+	"sigs.k8s.io/yaml"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+)
+
+// builtinPrompts holds the templates of the built-in prompts
+//
+//go:embed prompts/*.yaml
+var builtinPrompts embed.FS
+
+// promptNamePattern matches valid prompt names, e.g. debug-crashloop
+var promptNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]*[a-z0-9])?$`)
+
+// promptTemplate is a prompt whose message is rendered from a text/template.
+// Prompt templates are YAML files with the name, description and arguments of
+// the prompt, and the template. The template is executed with the arguments
+// of the prompt, e.g. {{ .namespace }}, and functions embedding live context
+// from the cluster, e.g. {{ podStatus .namespace .name }}.
+type promptTemplate struct {
+	Name        string               `json:"name"`
+	Description string               `json:"description"`
+	Arguments   []mcp.PromptArgument `json:"arguments,omitempty"`
+	Template    string               `json:"template"`
+
+	template *template.Template
+}
+
+// parsePromptTemplate parses a prompt template from YAML
+func parsePromptTemplate(data []byte) (*promptTemplate, error) {
+	var prompt promptTemplate
+	if err := yaml.UnmarshalStrict(data, &prompt); err != nil {
+		return nil, fmt.Errorf("failed to parse prompt: %w", err)
+	}
+	if !promptNamePattern.MatchString(prompt.Name) {
+		return nil, fmt.Errorf("invalid prompt name %q: must consist of lower case letters, digits and dashes", prompt.Name)
+	}
+	for _, argument := range prompt.Arguments {
+		if argument.Name == "" {
+			return nil, fmt.Errorf("prompt %s has an argument without a name", prompt.Name)
+		}
+	}
+	if strings.TrimSpace(prompt.Template) == "" {
+		return nil, fmt.Errorf("prompt %s has no template", prompt.Name)
+	}
+
+	tmpl, err := template.New(prompt.Name).
+		Funcs(promptFuncs(context.Background(), nil)).
+		Option("missingkey=zero").
+		Parse(prompt.Template)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse template of prompt %s: %w", prompt.Name, err)
+	}
+	prompt.template = tmpl
+	return &prompt, nil
+}
+
+// loadPromptTemplates loads the prompt templates of the YAML files of fsys.
+// Invalid files are logged and skipped.
+func loadPromptTemplates(fsys fs.FS) ([]*promptTemplate, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read prompts: %w", err)
+	}
+
+	var prompts []*promptTemplate
+	for _, entry := range entries {
+		if ext := path.Ext(entry.Name()); entry.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		data, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			log.Printf("Skipping prompt %s: %v", entry.Name(), err)
+			continue
+		}
+		prompt, err := parsePromptTemplate(data)
+		if err != nil {
+			log.Printf("Skipping prompt %s: %v", entry.Name(), err)
+			continue
+		}
+		prompts = append(prompts, prompt)
+	}
+	return prompts, nil
+}
+
+// CheckPromptsDir returns an error if dir, the directory of custom prompt
+// templates, is set but cannot be read.
+func CheckPromptsDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+	if _, err := fs.ReadDir(os.DirFS(dir), "."); err != nil {
+		return fmt.Errorf("failed to read prompts directory %s: %w", dir, err)
+	}
+	return nil
+}
+
+// loadPrompts returns the built-in prompts along with those of dir, if not
+// empty. Prompts of dir replace the built-in prompts of the same name.
+func loadPrompts(dir string) []*promptTemplate {
+	builtin, err := fs.Sub(builtinPrompts, "prompts")
+	if err != nil {
+		log.Printf("Failed to load built-in prompts: %v", err)
+		return nil
+	}
+	prompts, err := loadPromptTemplates(builtin)
+	if err != nil {
+		log.Printf("Failed to load built-in prompts: %v", err)
+	}
+	if dir == "" {
+		return prompts
+	}
+
+	custom, err := loadPromptTemplates(os.DirFS(dir))
+	if err != nil {
+		log.Printf("Failed to load prompts from %s: %v", dir, err)
+		return prompts
+	}
+	for _, prompt := range custom {
+		index := slices.IndexFunc(prompts, func(p *promptTemplate) bool { return p.Name == prompt.Name })
+		if index >= 0 {
+			prompts[index] = prompt
+		} else {
+			prompts = append(prompts, prompt)
+		}
+	}
+	log.Printf("Loaded %d prompts from %s", len(custom), dir)
+	return prompts
+}
+
+// prompt returns the MCP prompt of the template
+func (p *promptTemplate) prompt() mcp.Prompt {
+	return mcp.Prompt{
+		Name:        p.Name,
+		Description: p.Description,
+		Arguments:   p.Arguments,
+	}
+}
+
+// render executes the template with arguments, fetching live context from
+// the cluster with client
+func (p *promptTemplate) render(ctx context.Context, client *k8s.Client, arguments map[string]string) (string, error) {
+	tmpl, err := p.template.Clone()
+	if err != nil {
+		return "", err
+	}
+	if arguments == nil {
+		arguments = map[string]string{}
+	}
+
+	var message strings.Builder
+	if err := tmpl.Funcs(promptFuncs(ctx, client)).Execute(&message, arguments); err != nil {
+		return "", fmt.Errorf("failed to render prompt %s: %w", p.Name, err)
+	}
+	return message.String(), nil
+}
+
+// promptFuncs returns the functions prompt templates can call to embed live
+// context from the cluster. Failures are embedded rather than returned, so
+// that the prompt still guides the model through the tools.
+func promptFuncs(ctx context.Context, client *k8s.Client) template.FuncMap {
+	return template.FuncMap{
+		"podStatus": func(namespace, name string) string {
+			return promptJSON(client.PodStatus(ctx, namespace, name))
+		},
+		"events": func(kind, namespace, name string) string {
+			return promptJSON(client.ObjectEvents(ctx, kind, namespace, name))
+		},
+		"logs": func(namespace, name, container string) string {
+			return promptLogs(client.PodLogTail(ctx, namespace, name, container, false, k8s.DefaultLogTailLines))
+		},
+		"previousLogs": func(namespace, name, container string) string {
+			return promptLogs(client.PodLogTail(ctx, namespace, name, container, true, k8s.DefaultLogTailLines))
+		},
+		"whyPending": func(namespace, name string) string {
+			return promptJSON(client.WhyPending(ctx, namespace, name))
+		},
+		"rolloutStatus": func(kind, namespace, name string) string {
+			kind, err := k8s.NormalizeRolloutKind(kind)
+			if err != nil {
+				return promptUnavailable(err)
+			}
+			return promptJSON(client.RolloutStatus(ctx, kind, namespace, name))
+		},
+		"rolloutHistory": func(kind, namespace, name string) string {
+			kind, err := k8s.NormalizeRolloutKind(kind)
+			if err != nil {
+				return promptUnavailable(err)
+			}
+			return promptJSON(client.RolloutHistory(ctx, kind, namespace, name))
+		},
+		"namespaceSecurity": func(namespace string) string {
+			return promptJSON(client.NamespaceSecurity(ctx, namespace))
+		},
+		"clusterSummary": func(namespace string) string {
+			return promptJSON(client.ClusterSummary(ctx, namespace))
+		},
+	}
+}
+
+// promptJSON formats live context for a prompt as indented JSON
+func promptJSON[T any](value T, err error) string {
+	if err != nil {
+		return promptUnavailable(err)
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return promptUnavailable(err)
+	}
+	return string(data)
+}
+
+// promptLogs formats logs for a prompt
+func promptLogs(logs string, err error) string {
+	switch {
+	case err != nil:
+		return promptUnavailable(err)
+	case strings.TrimSpace(logs) == "":
+		return "(no logs)"
+	default:
+		return strings.TrimRight(logs, "\n")
+	}
+}
+
+// promptUnavailable formats live context that cannot be fetched for a prompt
+func promptUnavailable(err error) string {
+	return "unavailable: " + err.Error()
+}
+
+// promptHandler returns the handler rendering prompt
+func (m *Implementation) promptHandler(prompt *promptTemplate) server.PromptHandlerFunc {
+	return func(ctx context.Context, request mcp.GetPromptRequest) (*mcp.GetPromptResult, error) {
+		// Validate arguments
+		for _, argument := range prompt.Arguments {
+			if argument.Required && request.Params.Arguments[argument.Name] == "" {
+				return nil, fmt.Errorf("argument %s is required", argument.Name)
+			}
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, defaultCtxTimeout)
+		defer cancel()
+
+		// Get the appropriate client (may be impersonated)
+		client, err := m.clientForContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get Kubernetes client: %w", err)
+		}
+
+		message, err := prompt.render(ctx, client, request.Params.Arguments)
+		if err != nil {
+			return nil, err
+		}
+		return mcp.NewGetPromptResult(prompt.Description, []mcp.PromptMessage{
+			mcp.NewPromptMessage(mcp.RoleUser, mcp.NewTextContent(message)),
+		}), nil
+	}
+}
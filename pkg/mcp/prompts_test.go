@@ -0,0 +1,276 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/utils/ptr"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+)
+
+const customPrompt = `
+name: restart-storm
+description: Find the pods restarting the most
+arguments:
+  - name: namespace
+    description: Namespace to look at
+template: |
+  Find the pods restarting the most{{ with .namespace }} in {{ . }}{{ end }}.
+`
+
+func newPromptTestClient(t *testing.T) *k8s.Client {
+	t.Helper()
+	client := &k8s.Client{}
+	client.SetClientset(kubefake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "shop"}},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:         "app",
+					RestartCount: 7,
+					State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{
+						Reason: "CrashLoopBackOff",
+					}},
+				}},
+			},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "web.backoff", Namespace: "shop"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Namespace: "shop", Name: "web"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         "BackOff",
+			Message:        "Back-off restarting failed container app",
+		},
+		&appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop", Generation: 1},
+			Spec:       appsv1.DeploymentSpec{Replicas: ptr.To(int32(1))},
+		},
+	))
+	client.SetPodLogsFunc(func(_ context.Context, _, name string, parameters map[string]string) (*unstructured.Unstructured, error) {
+		if parameters["previous"] != "true" {
+			return nil, fmt.Errorf("only the logs of the previous instance are available")
+		}
+		return &unstructured.Unstructured{Object: map[string]interface{}{"logs": "panic: boom in " + name + "\n"}}, nil
+	})
+	return client
+}
+
+func getPrompt(
+	t *testing.T, impl *Implementation, name string, arguments map[string]string,
+) (*mcp.GetPromptResult, error) {
+	t.Helper()
+	prompts := loadPrompts("")
+	index := -1
+	for i, prompt := range prompts {
+		if prompt.Name == name {
+			index = i
+		}
+	}
+	require.NotEqual(t, -1, index, "prompt %s not found", name)
+
+	request := mcp.GetPromptRequest{}
+	request.Params.Name = name
+	request.Params.Arguments = arguments
+	return impl.promptHandler(prompts[index])(context.Background(), request)
+}
+
+func promptMessage(t *testing.T, result *mcp.GetPromptResult) string {
+	t.Helper()
+	require.Len(t, result.Messages, 1)
+	assert.Equal(t, mcp.RoleUser, result.Messages[0].Role)
+	textContent, ok := mcp.AsTextContent(result.Messages[0].Content)
+	require.True(t, ok)
+	return textContent.Text
+}
+
+func TestBuiltinPrompts(t *testing.T) {
+	builtin, err := fs.Sub(builtinPrompts, "prompts")
+	require.NoError(t, err)
+	prompts, err := loadPromptTemplates(builtin)
+	require.NoError(t, err)
+
+	var names []string
+	for _, prompt := range prompts {
+		names = append(names, prompt.Name)
+		assert.NotEmpty(t, prompt.Description, prompt.Name)
+		require.NotEmpty(t, prompt.Arguments, prompt.Name)
+		assert.Equal(t, "namespace", prompt.Arguments[0].Name, prompt.Name)
+		assert.True(t, prompt.Arguments[0].Required, prompt.Name)
+	}
+	assert.ElementsMatch(t, []string{
+		"audit-namespace-security",
+		"debug-crashloop",
+		"investigate-pending-pod",
+		"review-deployment-rollout",
+	}, names)
+}
+
+func TestParsePromptTemplateInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     string
+		expected string
+	}{
+		{"not YAML", "name: [", "failed to parse prompt"},
+		{"unknown field", "name: x\ntemplate: y\nmessages: []", "failed to parse prompt"},
+		{"invalid name", "name: Debug Pod\ntemplate: y", "invalid prompt name"},
+		{"argument without name", "name: x\narguments:\n  - required: true\ntemplate: y", "argument without a name"},
+		{"no template", "name: x", "has no template"},
+		{"template syntax", "name: x\ntemplate: '{{ .namespace'", "failed to parse template"},
+		{"unknown function", "name: x\ntemplate: '{{ nodes }}'", "failed to parse template"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parsePromptTemplate([]byte(tt.data))
+			assert.ErrorContains(t, err, tt.expected)
+		})
+	}
+}
+
+func TestLoadPromptsFromDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"restart-storm.yaml":  customPrompt,
+		"debug-crashloop.yml": "name: debug-crashloop\ndescription: Custom\ntemplate: Custom crash loop runbook",
+		"invalid.yaml":        "name: Invalid Name\ntemplate: x",
+		"README.md":           "# Prompts",
+		"nested/ignored.yaml": customPrompt,
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0o750))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0o600))
+	}
+
+	prompts := loadPrompts(dir)
+	byName := map[string]*promptTemplate{}
+	for _, prompt := range prompts {
+		byName[prompt.Name] = prompt
+	}
+	assert.Len(t, prompts, 5)
+	require.Contains(t, byName, "restart-storm")
+	require.Contains(t, byName, "debug-crashloop")
+	assert.Equal(t, "Custom", byName["debug-crashloop"].Description)
+
+	message, err := byName["restart-storm"].render(context.Background(), &k8s.Client{}, map[string]string{"namespace": "shop"})
+	require.NoError(t, err)
+	assert.Equal(t, "Find the pods restarting the most in shop.\n", message)
+	message, err = byName["restart-storm"].render(context.Background(), &k8s.Client{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, "Find the pods restarting the most.\n", message)
+
+	// A missing directory only leaves the built-in prompts
+	assert.Len(t, loadPrompts(filepath.Join(dir, "missing")), 4)
+}
+
+func TestCheckPromptsDir(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "restart-storm.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(customPrompt), 0o600))
+
+	assert.NoError(t, CheckPromptsDir(""))
+	assert.NoError(t, CheckPromptsDir(dir))
+	assert.ErrorContains(t, CheckPromptsDir(filepath.Join(dir, "missing")), "failed to read prompts directory")
+	assert.ErrorContains(t, CheckPromptsDir(file), "failed to read prompts directory")
+}
+
+func TestDebugCrashLoopPrompt(t *testing.T) {
+	impl := NewImplementation(newPromptTestClient(t))
+
+	result, err := getPrompt(t, impl, "debug-crashloop", map[string]string{"namespace": "shop", "name": "web"})
+	require.NoError(t, err)
+	message := promptMessage(t, result)
+	assert.Contains(t, message, "The pod shop/web is crash looping")
+	assert.Contains(t, message, `"state": "waiting: CrashLoopBackOff"`)
+	assert.Contains(t, message, `"reason": "BackOff"`)
+	assert.Contains(t, message, "panic: boom in web")
+	assert.Contains(t, message, "`get_resource`")
+
+	result, err = getPrompt(t, impl, "debug-crashloop", map[string]string{"namespace": "shop", "name": "missing"})
+	require.NoError(t, err)
+	assert.Contains(t, promptMessage(t, result), "unavailable: failed to get pod")
+
+	_, err = getPrompt(t, impl, "debug-crashloop", map[string]string{"namespace": "shop"})
+	assert.ErrorContains(t, err, "argument name is required")
+}
+
+func TestBuiltinPromptsRender(t *testing.T) {
+	impl := NewImplementation(newPromptTestClient(t))
+	tests := []struct {
+		name      string
+		arguments map[string]string
+		expected  []string
+	}{
+		{
+			name:      "investigate-pending-pod",
+			arguments: map[string]string{"namespace": "shop", "name": "web"},
+			expected:  []string{"The pod shop/web is Pending", `"totalNodes": 0`},
+		},
+		{
+			name:      "review-deployment-rollout",
+			arguments: map[string]string{"namespace": "shop", "name": "web"},
+			expected:  []string{"Deployment shop/web", `"kind": "deployment"`, `"action": "history"`},
+		},
+		{
+			name:      "audit-namespace-security",
+			arguments: map[string]string{"namespace": "shop"},
+			expected:  []string{"namespace shop", `"defaultDenyIngress": false`, "may run as root"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := getPrompt(t, impl, tt.name, tt.arguments)
+			require.NoError(t, err)
+			message := promptMessage(t, result)
+			for _, expected := range tt.expected {
+				assert.Contains(t, message, expected)
+			}
+			assert.NotContains(t, message, "unavailable")
+		})
+	}
+}
+
+func TestCreateServerPrompts(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "restart-storm.yaml"), []byte(customPrompt), 0o600))
+
+	srv := CreateServer(&k8s.Client{}, &Config{PromptsDir: dir})
+	defer srv.Stop()
+
+	prompts := srv.MCPServer().ListPrompts()
+	assert.Len(t, prompts, 5)
+	require.Contains(t, prompts, "restart-storm")
+	assert.Equal(t, "Find the pods restarting the most", prompts["restart-storm"].Prompt.Description)
+	require.Contains(t, prompts, "debug-crashloop")
+	require.Len(t, prompts["debug-crashloop"].Prompt.Arguments, 3)
+	assert.Equal(t, "container", prompts["debug-crashloop"].Prompt.Arguments[2].Name)
+	assert.False(t, prompts["debug-crashloop"].Prompt.Arguments[2].Required)
+}
+
+func TestPromptFuncsUnavailable(t *testing.T) {
+	prompt, err := parsePromptTemplate([]byte(`
+name: unavailable
+template: '{{ rolloutStatus "CronJob" .namespace .name }} | {{ logs .namespace .name "" }}'
+`))
+	require.NoError(t, err)
+
+	message, err := prompt.render(context.Background(), newPromptTestClient(t), map[string]string{"namespace": "shop", "name": "web"})
+	require.NoError(t, err)
+	assert.Equal(t, `unavailable: unsupported kind "CronJob": must be deployment, statefulset or daemonset | `+
+		"unavailable: only the logs of the previous instance are available", message)
+}
@@ -0,0 +1,102 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleRBACWhoCan handles the rbac_who_can tool
+func (m *Implementation) HandleRBACWhoCan(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	query := k8s.RBACQuery{
+		Verb:      mcp.ParseString(request, "verb", ""),
+		Group:     mcp.ParseString(request, "group", ""),
+		Resource:  mcp.ParseString(request, "resource", ""),
+		Name:      mcp.ParseString(request, "name", ""),
+		Namespace: mcp.ParseString(request, "namespace", ""),
+	}
+	// Accept resources given as resource/subresource, e.g. pods/exec
+	query.Resource, query.Subresource, _ = strings.Cut(query.Resource, "/")
+	subjectValue := mcp.ParseString(request, "subject", "")
+	limit := request.GetInt("limit", k8s.DefaultRBACLimit)
+
+	// Validate parameters
+	if subjectValue != "" && (query.Verb != "" || query.Resource != "") {
+		return mcp.NewToolResultError("subject is mutually exclusive with verb and resource"), nil
+	}
+	if subjectValue == "" && (query.Verb == "" || query.Resource == "") {
+		return mcp.NewToolResultError("verb and resource, or subject, are required"), nil
+	}
+	if limit <= 0 || limit > k8s.MaxRBACLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", k8s.MaxRBACLimit)), nil
+	}
+	var subject k8s.RBACSubject
+	if subjectValue != "" {
+		var err error
+		if subject, err = k8s.ParseRBACSubject(subjectValue); err != nil {
+			return mcp.NewToolResultError(err.Error()), nil
+		}
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	var result interface{}
+	if subjectValue != "" {
+		result, err = client.SubjectPermissions(ctx, subject, query.Namespace, limit)
+	} else {
+		result, err = client.WhoCan(ctx, query, limit)
+	}
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to analyze RBAC", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewRBACWhoCanTool creates a new rbac_who_can tool
+func NewRBACWhoCanTool() mcp.Tool {
+	return mcp.NewTool(types.RBACWhoCanToolName,
+		mcp.WithDescription("Analyze RBAC from the Roles, ClusterRoles and their bindings: with verb and "+
+			"resource, list the users, groups and service accounts that can perform the action and the bindings "+
+			"granting it; with subject, list the rules granted to that subject. Wildcards are honored and "+
+			"aggregated ClusterRoles are resolved. Reading RBAC objects cluster-wide requires permission to list them"),
+		mcp.WithString("verb",
+			mcp.Description("Verb of the action (e.g., get, list, create, delete, escalate)")),
+		mcp.WithString("resource",
+			mcp.Description("Resource of the action (e.g., secrets, deployments, pods/exec)")),
+		mcp.WithString("group",
+			mcp.Description("API group of the resource (e.g., apps; default: the core group)")),
+		mcp.WithString("name",
+			mcp.Description("Name of the resource, to include rules restricted to resource names (default: any)")),
+		mcp.WithString("subject",
+			mcp.Description("Subject to list the permissions of instead, as User/name, Group/name or "+
+				"ServiceAccount/namespace/name")),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace to consider RoleBindings of, besides ClusterRoleBindings "+
+				"(default: all namespaces)")),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of entries to return (default: %d, at most %d)",
+				k8s.DefaultRBACLimit, k8s.MaxRBACLimit))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Analyze RBAC",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,113 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func newRBACWhoCanTestImplementation() *Implementation {
+	clientset := kubefake.NewSimpleClientset(
+		&rbacv1.Role{
+			ObjectMeta: metav1.ObjectMeta{Name: "debugger", Namespace: "shop"},
+			Rules: []rbacv1.PolicyRule{
+				{Verbs: []string{"create"}, APIGroups: []string{""}, Resources: []string{"pods/exec"}},
+			},
+		},
+		&rbacv1.RoleBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "debuggers", Namespace: "shop"},
+			RoleRef:    rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "Role", Name: "debugger"},
+			Subjects:   []rbacv1.Subject{{Kind: rbacv1.UserKind, Name: "alice"}},
+		},
+	)
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	return NewImplementation(client)
+}
+
+func TestHandleRBACWhoCan(t *testing.T) {
+	impl := newRBACWhoCanTestImplementation()
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.RBACWhoCanToolName
+	request.Params.Arguments = map[string]interface{}{"verb": "create", "resource": "pods/exec", "namespace": "shop"}
+
+	result, err := impl.HandleRBACWhoCan(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var whoCan k8s.WhoCanResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &whoCan))
+	assert.Equal(t, "exec", whoCan.Query.Subresource)
+	require.Len(t, whoCan.Grants, 1)
+	assert.Equal(t, "User/alice", whoCan.Grants[0].Subject.String())
+
+	// The other direction
+	request.Params.Arguments = map[string]interface{}{"subject": "User/alice"}
+	result, err = impl.HandleRBACWhoCan(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok = mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var permissions k8s.SubjectPermissionsResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &permissions))
+	require.Len(t, permissions.Permissions, 1)
+	assert.Equal(t, "Role/debugger", permissions.Permissions[0].Role)
+}
+
+func TestHandleRBACWhoCanInvalid(t *testing.T) {
+	impl := newRBACWhoCanTestImplementation()
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Nothing", arguments: map[string]interface{}{}, errorMsg: "are required"},
+		{name: "Missing resource", arguments: map[string]interface{}{"verb": "get"}, errorMsg: "are required"},
+		{
+			name:      "Both directions",
+			arguments: map[string]interface{}{"verb": "get", "resource": "pods", "subject": "User/alice"},
+			errorMsg:  "mutually exclusive",
+		},
+		{name: "Invalid subject", arguments: map[string]interface{}{"subject": "alice"}, errorMsg: "invalid subject"},
+		{
+			name:      "Limit",
+			arguments: map[string]interface{}{"verb": "get", "resource": "pods", "limit": float64(1000)},
+			errorMsg:  "limit must be between",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.RBACWhoCanToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleRBACWhoCan(context.Background(), request)
+			require.NoError(t, err)
+			require.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestNewRBACWhoCanTool(t *testing.T) {
+	tool := NewRBACWhoCanTool()
+	assert.Equal(t, types.RBACWhoCanToolName, tool.Name)
+	assert.Empty(t, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,171 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// rolloutReadOnlyActions are the rollout actions available in read-only mode.
+var rolloutReadOnlyActions = []string{types.RolloutActionStatus, types.RolloutActionHistory}
+
+// rolloutWriteActions are the rollout actions that modify the workload.
+var rolloutWriteActions = []string{
+	types.RolloutActionRestart, types.RolloutActionPause, types.RolloutActionResume, types.RolloutActionUndo,
+}
+
+// rolloutActionFunc performs a rollout action on a workload.
+type rolloutActionFunc func(
+	ctx context.Context,
+	client *k8s.Client,
+	kind, namespace, name string,
+	toRevision int64,
+) (*k8s.RolloutResult, error)
+
+// rolloutActions maps each rollout action to its handler.
+var rolloutActions = map[string]rolloutActionFunc{
+	types.RolloutActionStatus:  rolloutStatus,
+	types.RolloutActionHistory: rolloutHistory,
+	types.RolloutActionRestart: rolloutRestart,
+	types.RolloutActionPause:   rolloutPause,
+	types.RolloutActionResume:  rolloutResume,
+	types.RolloutActionUndo:    rolloutUndo,
+}
+
+// HandleRollout handles the rollout tool
+func (m *Implementation) HandleRollout(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	action := mcp.ParseString(request, "action", "")
+	kindParam := mcp.ParseString(request, "kind", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+	toRevision := request.GetInt("to_revision", 0)
+
+	// Validate parameters
+	if action == "" {
+		return mcp.NewToolResultError("action is required"), nil
+	}
+	if kindParam == "" {
+		return mcp.NewToolResultError("kind is required"), nil
+	}
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	kind, err := k8s.NormalizeRolloutKind(kindParam)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if toRevision < 0 {
+		return mcp.NewToolResultError("to_revision must not be negative"), nil
+	}
+	run, ok := rolloutActions[action]
+	if !ok {
+		return mcp.NewToolResultError(fmt.Sprintf("invalid action %q", action)), nil
+	}
+	if slices.Contains(rolloutWriteActions, action) && !m.readWrite {
+		return mcp.NewToolResultError(fmt.Sprintf("action %q requires the server to run with --read-write", action)), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := run(ctx, client, kind, namespace, name, int64(toRevision))
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr(fmt.Sprintf("Failed to %s rollout", action), err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// rolloutStatus handles the status action of the rollout tool.
+func rolloutStatus(ctx context.Context, client *k8s.Client, kind, namespace, name string, _ int64) (*k8s.RolloutResult, error) {
+	return client.RolloutStatus(ctx, kind, namespace, name)
+}
+
+// rolloutHistory handles the history action of the rollout tool.
+func rolloutHistory(ctx context.Context, client *k8s.Client, kind, namespace, name string, _ int64) (*k8s.RolloutResult, error) {
+	return client.RolloutHistory(ctx, kind, namespace, name)
+}
+
+// rolloutRestart handles the restart action of the rollout tool.
+func rolloutRestart(ctx context.Context, client *k8s.Client, kind, namespace, name string, _ int64) (*k8s.RolloutResult, error) {
+	return client.RolloutRestart(ctx, kind, namespace, name)
+}
+
+// rolloutPause handles the pause action of the rollout tool.
+func rolloutPause(ctx context.Context, client *k8s.Client, kind, namespace, name string, _ int64) (*k8s.RolloutResult, error) {
+	return client.RolloutPause(ctx, kind, namespace, name, true)
+}
+
+// rolloutResume handles the resume action of the rollout tool.
+func rolloutResume(ctx context.Context, client *k8s.Client, kind, namespace, name string, _ int64) (*k8s.RolloutResult, error) {
+	return client.RolloutPause(ctx, kind, namespace, name, false)
+}
+
+// rolloutUndo handles the undo action of the rollout tool, rolling back to
+// toRevision, or the previous revision when it is zero.
+func rolloutUndo(
+	ctx context.Context,
+	client *k8s.Client,
+	kind, namespace, name string,
+	toRevision int64,
+) (*k8s.RolloutResult, error) {
+	return client.RolloutUndo(ctx, kind, namespace, name, toRevision)
+}
+
+// NewRolloutTool creates a new rollout tool. In read-only mode only the
+// status and history actions are offered.
+func NewRolloutTool(readWrite bool) mcp.Tool {
+	actions := rolloutReadOnlyActions
+	description := "Inspect the rollout of a Deployment, StatefulSet or DaemonSet: status (progress, like " +
+		"`kubectl rollout status`) or history (revisions with change-cause and images)"
+	if readWrite {
+		actions = append(append([]string{}, rolloutReadOnlyActions...), rolloutWriteActions...)
+		description = "Manage the rollout of a Deployment, StatefulSet or DaemonSet: status (progress, like " +
+			"`kubectl rollout status`), history (revisions with change-cause and images), restart (restart all " +
+			"pods), pause/resume (deployments only) or undo (roll back to to_revision, default: the previous one)"
+	}
+
+	return mcp.NewTool(types.RolloutToolName,
+		mcp.WithDescription(description),
+		mcp.WithString("action",
+			mcp.Description("Rollout action to perform"),
+			mcp.Enum(actions...),
+			mcp.Required()),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the workload"),
+			mcp.Enum(k8s.RolloutKindDeployment, k8s.RolloutKindStatefulSet, k8s.RolloutKindDaemonSet),
+			mcp.Required()),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the workload"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the workload"),
+			mcp.Required()),
+		mcp.WithNumber("to_revision",
+			mcp.Description("Revision to roll back to with undo (default: the previous revision)")),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Manage workload rollouts",
+			ReadOnlyHint:    BoolPtr(!readWrite),
+			DestructiveHint: BoolPtr(readWrite),
+		}),
+	)
+}
@@ -0,0 +1,109 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleRolloutStatus(t *testing.T) {
+	replicas := int32(2)
+	clientset := kubefake.NewSimpleClientset(&appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "default", Generation: 1},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 1,
+			Replicas:           2,
+			UpdatedReplicas:    2,
+			AvailableReplicas:  2,
+		},
+	})
+
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.RolloutToolName
+	request.Params.Arguments = map[string]interface{}{
+		"action":    "status",
+		"kind":      "Deployment",
+		"namespace": "default",
+		"name":      "web",
+	}
+
+	result, err := impl.HandleRollout(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var rolloutResult k8s.RolloutResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &rolloutResult))
+	assert.Equal(t, k8s.RolloutKindDeployment, rolloutResult.Kind)
+	require.NotNil(t, rolloutResult.Done)
+	assert.True(t, *rolloutResult.Done)
+}
+
+func TestHandleRolloutInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"action": "status", "kind": "deployment", "namespace": "default", "name": "web"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing action", arguments: map[string]interface{}{"kind": "deployment"}, errorMsg: "action is required"},
+		{name: "Missing kind", arguments: map[string]interface{}{"action": "status"}, errorMsg: "kind is required"},
+		{name: "Missing name", arguments: base(map[string]interface{}{"name": ""}), errorMsg: "name is required"},
+		{name: "Invalid kind", arguments: base(map[string]interface{}{"kind": "cronjob"}), errorMsg: "cronjob"},
+		{name: "Invalid action", arguments: base(map[string]interface{}{"action": "promote"}), errorMsg: "invalid action"},
+		{name: "Negative revision", arguments: base(map[string]interface{}{"to_revision": float64(-1)}), errorMsg: "negative"},
+		{name: "Restart in read-only mode", arguments: base(map[string]interface{}{"action": "restart"}), errorMsg: "--read-write"},
+		{name: "Undo in read-only mode", arguments: base(map[string]interface{}{"action": "undo"}), errorMsg: "--read-write"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.RolloutToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleRollout(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestNewRolloutTool(t *testing.T) {
+	readOnly := NewRolloutTool(false)
+	assert.Equal(t, types.RolloutToolName, readOnly.Name)
+	assert.True(t, *readOnly.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, []string{"status", "history"}, readOnly.InputSchema.Properties["action"].(map[string]interface{})["enum"])
+
+	readWrite := NewRolloutTool(true)
+	assert.False(t, *readWrite.Annotations.ReadOnlyHint)
+	assert.ElementsMatch(t, []string{"status", "history", "restart", "pause", "resume", "undo"},
+		readWrite.InputSchema.Properties["action"].(map[string]interface{})["enum"])
+}
@@ -0,0 +1,150 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// scaleTimeoutGrace is added on top of k8s.MaxScaleWaitTimeout when
+// computing the scale_resource tool timeout.
+const scaleTimeoutGrace = 15 * time.Second
+
+// HandleScaleResource handles the scale_resource tool
+func (m *Implementation) HandleScaleResource(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	resourceType := mcp.ParseString(request, "resource_type", types.ResourceTypeNamespaced)
+	group := mcp.ParseString(request, "group", "")
+	version := mcp.ParseString(request, "version", "")
+	resource := mcp.ParseString(request, "resource", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if resourceType != types.ResourceTypeClustered && resourceType != types.ResourceTypeNamespaced {
+		return mcp.NewToolResultError("Invalid resource_type: " + resourceType), nil
+	}
+	if version == "" {
+		return mcp.NewToolResultError("version is required"), nil
+	}
+	if resource == "" {
+		return mcp.NewToolResultError("resource is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	if resourceType == types.ResourceTypeNamespaced && namespace == "" {
+		return mcp.NewToolResultError("namespace is required for namespaced resources"), nil
+	}
+	if resourceType == types.ResourceTypeClustered {
+		namespace = ""
+	}
+
+	opts, err := parseScaleOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    group,
+		Version:  version,
+		Resource: resource,
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop waiting as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	notifier := newProgressNotifier(ctx, request, fmt.Sprintf("%s/%s", resource, name))
+	opts.OnProgress = notifier.Notify
+
+	result, err := client.ScaleResource(ctx, gvr, namespace, name, opts)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to scale resource", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// parseScaleOptions parses the replicas, delta, wait and timeout parameters of
+// the scale_resource tool
+func parseScaleOptions(request mcp.CallToolRequest) (k8s.ScaleOptions, error) {
+	opts := k8s.ScaleOptions{
+		Wait:    request.GetBool("wait", false),
+		Timeout: time.Duration(request.GetInt("timeout", 0)) * time.Second,
+	}
+	_, hasReplicas := request.GetArguments()["replicas"]
+	_, hasDelta := request.GetArguments()["delta"]
+	if hasReplicas && hasDelta {
+		return opts, fmt.Errorf("replicas and delta are mutually exclusive")
+	}
+	if hasReplicas {
+		replicas := int64(request.GetInt("replicas", -1))
+		if replicas < 0 {
+			return opts, fmt.Errorf("replicas must be a non-negative number")
+		}
+		opts.Replicas = &replicas
+	}
+	if hasDelta {
+		opts.Delta = int64(request.GetInt("delta", 0))
+	}
+	return opts, nil
+}
+
+// NewScaleResourceTool creates a new scale_resource tool
+func NewScaleResourceTool() mcp.Tool {
+	return mcp.NewTool(types.ScaleResourceToolName,
+		mcp.WithDescription("Read or change the number of replicas of a scalable resource through its scale "+
+			"subresource (Deployments, StatefulSets, ReplicaSets and custom resources exposing scale). Without "+
+			"replicas or delta, the current scale is returned"),
+		mcp.WithString("resource_type",
+			mcp.Description("Type of resource (clustered or namespaced, default: namespaced)")),
+		mcp.WithString("group",
+			mcp.Description("API group (e.g., apps)")),
+		mcp.WithString("version",
+			mcp.Description("API version (e.g., v1)"),
+			mcp.Required()),
+		mcp.WithString("resource",
+			mcp.Description("Resource name (e.g., deployments, statefulsets)"),
+			mcp.Required()),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace (required for namespaced resources)")),
+		mcp.WithString("name",
+			mcp.Description("Name of the resource to scale"),
+			mcp.Required()),
+		mcp.WithNumber("replicas",
+			mcp.Description("Desired number of replicas")),
+		mcp.WithNumber("delta",
+			mcp.Description("Number of replicas to add (or remove, if negative); alternative to replicas")),
+		mcp.WithBoolean("wait",
+			mcp.Description("Wait until the desired number of replicas is ready (default: false)")),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("How long to wait in seconds (default: 60, maximum: %d)",
+				int(k8s.MaxScaleWaitTimeout.Seconds())))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:           "Scale a resource",
+			ReadOnlyHint:    BoolPtr(false),
+			DestructiveHint: BoolPtr(true),
+			IdempotentHint:  BoolPtr(false),
+		}),
+	)
+}
@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	discoveryfake "k8s.io/client-go/discovery/fake"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleScaleResource(t *testing.T) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "StatefulSet",
+			"metadata":   map[string]interface{}{"name": "db", "namespace": "default"},
+		},
+	})
+	var updatedReplicas int64
+	fakeDynamic.PrependReactor("get", "statefulsets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		if action.GetSubresource() != "scale" {
+			return false, nil, nil
+		}
+		return true, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "autoscaling/v1",
+			"kind":       "Scale",
+			"metadata":   map[string]interface{}{"name": "db", "namespace": "default"},
+			"spec":       map[string]interface{}{"replicas": int64(3)},
+		}}, nil
+	})
+	fakeDynamic.PrependReactor("update", "statefulsets", func(action ktesting.Action) (bool, runtime.Object, error) {
+		scale := action.(ktesting.UpdateAction).GetObject().(*unstructured.Unstructured)
+		updatedReplicas, _, _ = unstructured.NestedInt64(scale.Object, "spec", "replicas")
+		return true, scale, nil
+	})
+
+	fakeDiscovery := &discoveryfake.FakeDiscovery{Fake: &ktesting.Fake{}}
+	fakeDiscovery.Resources = []*metav1.APIResourceList{{
+		GroupVersion: "apps/v1",
+		APIResources: []metav1.APIResource{
+			{Name: "statefulsets", Kind: "StatefulSet", Namespaced: true},
+			{Name: "statefulsets/scale", Kind: "Scale", Namespaced: true},
+		},
+	}}
+
+	client := &k8s.Client{}
+	client.SetDynamicClient(fakeDynamic)
+	client.SetDiscoveryClient(fakeDiscovery)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.ScaleResourceToolName
+	request.Params.Arguments = map[string]interface{}{
+		"group":     "apps",
+		"version":   "v1",
+		"resource":  "statefulsets",
+		"namespace": "default",
+		"name":      "db",
+		"delta":     float64(-1),
+	}
+
+	result, err := impl.HandleScaleResource(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	assert.Equal(t, int64(2), updatedReplicas)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var scaleResult k8s.ScaleResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &scaleResult))
+	assert.Equal(t, int64(3), scaleResult.PreviousReplicas)
+	assert.Equal(t, int64(2), scaleResult.Replicas)
+}
+
+func TestHandleScaleResourceInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"version": "v1", "resource": "deployments", "namespace": "default", "name": "web"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing version", arguments: map[string]interface{}{"resource": "deployments", "name": "web"}, errorMsg: "version is required"},
+		{name: "Missing name", arguments: map[string]interface{}{"version": "v1", "resource": "deployments"}, errorMsg: "name is required"},
+		{
+			name:      "Missing namespace",
+			arguments: map[string]interface{}{"version": "v1", "resource": "deployments", "name": "web"},
+			errorMsg:  "namespace is required",
+		},
+		{
+			name:      "Replicas and delta",
+			arguments: base(map[string]interface{}{"replicas": float64(1), "delta": float64(1)}),
+			errorMsg:  "mutually exclusive",
+		},
+		{name: "Negative replicas", arguments: base(map[string]interface{}{"replicas": float64(-1)}), errorMsg: "non-negative"},
+		{name: "Invalid resource type", arguments: base(map[string]interface{}{"resource_type": "global"}), errorMsg: "Invalid resource_type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.ScaleResourceToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleScaleResource(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
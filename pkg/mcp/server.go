@@ -12,11 +12,15 @@ import (
 	"github.com/StacklokLabs/mkp/pkg/identity"
 	"github.com/StacklokLabs/mkp/pkg/k8s"
 	"github.com/StacklokLabs/mkp/pkg/ratelimit"
+	"github.com/StacklokLabs/mkp/pkg/types"
 )
 
 // defaultCtxTimeout is the default timeout for tool calls
 const defaultCtxTimeout = 30 * time.Second
 
+// DefaultMaxWaitTimeout is the default cap on the timeout of the wait_for tool
+const DefaultMaxWaitTimeout = 10 * time.Minute
+
 // Config holds configuration options for the MCP server
 type Config struct {
 	// ServeResources determines whether to serve cluster resources
@@ -60,6 +64,21 @@ type Config struct {
 	// Only used when ImpersonationJWKSURL is set. Per OIDC Core Section
 	// 3.1.3.7, relying parties must validate the audience.
 	ImpersonationJWTAudience string
+
+	// DebugImages is the allowlist of images that the debug_pod tool may run.
+	// Entries ending in "*" match any image with that prefix. The debug_pod
+	// tool is only served in read-write mode and when the allowlist is not empty.
+	DebugImages []string
+
+	// MaxWaitTimeout caps the timeout a wait_for call may request, and sets the
+	// timeout of the wait_for tool call itself. Defaults to DefaultMaxWaitTimeout
+	// if zero.
+	MaxWaitTimeout time.Duration
+
+	// PromptsDir is an optional directory of YAML prompt templates served along
+	// with the built-in prompts. Templates replace the built-in prompts of the
+	// same name.
+	PromptsDir string
 }
 
 // DefaultConfig returns a Config with default values
@@ -68,6 +87,7 @@ func DefaultConfig() *Config {
 		ServeResources:     true,  // Default to serving resources for backward compatibility
 		ReadWrite:          false, // Default to read-only mode
 		EnableRateLimiting: true,  // Default to enabling rate limiting
+		MaxWaitTimeout:     DefaultMaxWaitTimeout,
 	}
 }
 
@@ -98,12 +118,30 @@ func CreateServer(k8sClient *k8s.Client, config *Config) *Server {
 	} else {
 		impl = NewImplementation(k8sClient)
 	}
+	impl.readWrite = config.ReadWrite
+	impl.debugImages = config.DebugImages
+	maxWaitTimeout := config.MaxWaitTimeout
+	if maxWaitTimeout <= 0 {
+		maxWaitTimeout = DefaultMaxWaitTimeout
+	}
+	impl.maxWaitTimeout = maxWaitTimeout
 
 	options := []server.ServerOption{
 		server.WithResourceCapabilities(true, true),
 		server.WithToolCapabilities(true),
-		// Add timeout middleware to prevent context cancellation errors
-		WithTimeoutContext(defaultCtxTimeout),
+		server.WithPromptCapabilities(false),
+		server.WithLogging(),
+		// Add timeout middleware to prevent context cancellation errors.
+		// Long-running tools get a timeout matching their own caps.
+		WithToolTimeoutContext(defaultCtxTimeout, map[string]time.Duration{
+			types.FollowLogsToolName:     k8s.MaxFollowLogsDuration + followLogsTimeoutGrace,
+			types.DebugPodToolName:       k8s.MaxDebugStartupTimeout + k8s.MaxExecTimeout + debugPodTimeoutGrace,
+			types.ScaleResourceToolName:  k8s.MaxScaleWaitTimeout + scaleTimeoutGrace,
+			types.WaitForToolName:        maxWaitTimeout + waitForTimeoutGrace,
+			types.DrainNodeToolName:      k8s.MaxDrainTimeout + drainTimeoutGrace,
+			types.ApplyManifestsToolName: applyManifestsTimeout,
+			types.KustomizeBuildToolName: applyManifestsTimeout,
+		}),
 		server.WithRecovery(),
 	}
 
@@ -129,11 +167,40 @@ func CreateServer(k8sClient *k8s.Client, config *Config) *Server {
 	// Add tools
 	mcpServer.AddTool(NewListResourcesTool(), impl.HandleListResources)
 	mcpServer.AddTool(NewGetResourceTool(), impl.HandleGetResource)
+	mcpServer.AddTool(NewFollowLogsTool(), impl.HandleFollowLogs)
+	mcpServer.AddTool(NewCopyFromPodTool(), impl.HandleCopyFromPod)
+	mcpServer.AddTool(NewRolloutTool(config.ReadWrite), impl.HandleRollout)
+	mcpServer.AddTool(NewWaitForTool(maxWaitTimeout), impl.HandleWaitFor)
+	mcpServer.AddTool(NewKustomizeBuildTool(config.ReadWrite), impl.HandleKustomizeBuild)
+	mcpServer.AddTool(NewListHelmReleasesTool(), impl.HandleListHelmReleases)
+	mcpServer.AddTool(NewGetHelmReleaseTool(), impl.HandleGetHelmRelease)
+	mcpServer.AddTool(NewExplainResourceTool(), impl.HandleExplainResource)
+	mcpServer.AddTool(NewListAPIResourcesTool(), impl.HandleListAPIResources)
+	mcpServer.AddTool(NewCanITool(), impl.HandleCanI)
+	mcpServer.AddTool(NewListPermissionsTool(), impl.HandleListPermissions)
+	mcpServer.AddTool(NewWhoAmITool(), impl.HandleWhoAmI)
+	mcpServer.AddTool(NewRBACWhoCanTool(), impl.HandleRBACWhoCan)
+	mcpServer.AddTool(NewClusterSummaryTool(), impl.HandleClusterSummary)
+	mcpServer.AddTool(NewTopTool(), impl.HandleTop)
+	mcpServer.AddTool(NewTraceServiceTool(), impl.HandleTraceService)
+	mcpServer.AddTool(NewNetworkPolicyCheckTool(), impl.HandleNetworkPolicyCheck)
+	mcpServer.AddTool(NewWhyPendingTool(), impl.HandleWhyPending)
 
 	if config.ReadWrite {
 		mcpServer.AddTool(NewApplyResourceTool(), impl.HandleApplyResource)
+		mcpServer.AddTool(NewApplyManifestsTool(), impl.HandleApplyManifests)
 		mcpServer.AddTool(NewDeleteResourceTool(), impl.HandleDeleteResource)
 		mcpServer.AddTool(NewPostResourceTool(), impl.HandlePostResource)
+		mcpServer.AddTool(NewCopyToPodTool(), impl.HandleCopyToPod)
+		mcpServer.AddTool(NewProbePodHTTPTool(), impl.HandleProbePodHTTP)
+		mcpServer.AddTool(NewScaleResourceTool(), impl.HandleScaleResource)
+		mcpServer.AddTool(NewCordonNodeTool(), impl.HandleCordonNode)
+		mcpServer.AddTool(NewUncordonNodeTool(), impl.HandleUncordonNode)
+		mcpServer.AddTool(NewDrainNodeTool(), impl.HandleDrainNode)
+
+		if len(config.DebugImages) > 0 {
+			mcpServer.AddTool(NewDebugPodTool(config.DebugImages), impl.HandleDebugPod)
+		}
 	}
 
 	// Add resource templates
@@ -146,6 +213,11 @@ func CreateServer(k8sClient *k8s.Client, config *Config) *Server {
 		impl.HandleNamespacedResource,
 	)
 
+	// Add prompts
+	for _, prompt := range loadPrompts(config.PromptsDir) {
+		mcpServer.AddPrompt(prompt.prompt(), impl.promptHandler(prompt))
+	}
+
 	// Add resources if enabled
 	if config.ServeResources {
 		go func() {
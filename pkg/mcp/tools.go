@@ -53,7 +53,11 @@ func NewListResourcesTool() mcp.Tool {
 // NewApplyResourceTool creates a new apply_resource tool
 func NewApplyResourceTool() mcp.Tool {
 	return mcp.NewTool(types.ApplyResourceToolName,
-		mcp.WithDescription("Apply (create or update) a Kubernetes resource"),
+		mcp.WithDescription("Apply (create or update) a Kubernetes resource. If the manifest sets "+
+			"metadata.resourceVersion, the update is rejected when the resource was modified since, and the "+
+			"current resource is returned so the changes can be merged and applied again. The manifest is first "+
+			"validated against the resource and the cluster's schema of its kind, and unknown fields, type "+
+			"mismatches and missing required fields are reported with their paths"),
 		mcp.WithString("resource_type",
 			mcp.Description("Type of resource to apply (clustered or namespaced)"),
 			mcp.Required()),
@@ -70,6 +74,12 @@ func NewApplyResourceTool() mcp.Tool {
 		mcp.WithObject("manifest",
 			mcp.Description("Resource manifest"),
 			mcp.Required()),
+		mcp.WithBoolean("require_resource_version",
+			mcp.Description("Refuse to update an existing resource unless the manifest sets "+
+				"metadata.resourceVersion (default: false)")),
+		mcp.WithBoolean("validate",
+			mcp.Description("Validate the manifest against the schema of its kind before applying it "+
+				"(default: true)")),
 		mcp.WithToolAnnotation(mcp.ToolAnnotation{
 			Title:          "Apply (create or update) a Kubernetes resource",
 			ReadOnlyHint:   BoolPtr(false),
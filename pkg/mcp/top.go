@@ -0,0 +1,99 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+const (
+	// topKindPods shows the usage of pods
+	topKindPods = "pods"
+	// topKindNodes shows the usage of nodes
+	topKindNodes = "nodes"
+)
+
+// HandleTop handles the top tool
+func (m *Implementation) HandleTop(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	kind := mcp.ParseString(request, "kind", topKindPods)
+	opts := k8s.TopOptions{
+		Namespace:     mcp.ParseString(request, "namespace", ""),
+		LabelSelector: mcp.ParseString(request, "label_selector", ""),
+		Node:          mcp.ParseString(request, "node", ""),
+		SortBy:        mcp.ParseString(request, "sort_by", k8s.TopSortCPU),
+		Limit:         request.GetInt("limit", k8s.DefaultTopLimit),
+	}
+
+	// Validate parameters
+	if kind != topKindPods && kind != topKindNodes {
+		return mcp.NewToolResultError(fmt.Sprintf("kind must be %s or %s", topKindPods, topKindNodes)), nil
+	}
+	if kind == topKindNodes && opts.Namespace != "" {
+		return mcp.NewToolResultError("namespace cannot be used with nodes"), nil
+	}
+	if opts.SortBy != k8s.TopSortCPU && opts.SortBy != k8s.TopSortMemory {
+		return mcp.NewToolResultError(fmt.Sprintf("sort_by must be %s or %s", k8s.TopSortCPU, k8s.TopSortMemory)), nil
+	}
+	if opts.Limit <= 0 || opts.Limit > k8s.MaxTopLimit {
+		return mcp.NewToolResultError(fmt.Sprintf("limit must be between 1 and %d", k8s.MaxTopLimit)), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	var result interface{}
+	if kind == topKindNodes {
+		result, err = client.TopNodes(ctx, opts)
+	} else {
+		result, err = client.TopPods(ctx, opts)
+	}
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get resource usage", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewTopTool creates a new top tool
+func NewTopTool() mcp.Tool {
+	return mcp.NewTool(types.TopToolName,
+		mcp.WithDescription("Show the CPU and memory usage of pods or nodes from the metrics.k8s.io API, like "+
+			"`kubectl top`, sorted by decreasing usage. Pod usage is compared to the requests and limits of the "+
+			"pod, and node usage to the allocatable resources and the requests of the pods on the node. When "+
+			"metrics-server is not installed, requests, limits and allocatable resources are returned alone"),
+		mcp.WithString("kind",
+			mcp.Description("Kind of objects to show the usage of (default: pods)"),
+			mcp.Enum(topKindPods, topKindNodes)),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pods (default: all namespaces)")),
+		mcp.WithString("label_selector",
+			mcp.Description("Label selector of the pods or nodes (e.g., app=web)")),
+		mcp.WithString("node",
+			mcp.Description("Node to show the pods of, or the only node to show")),
+		mcp.WithString("sort_by",
+			mcp.Description("Resource to sort by (default: cpu)"),
+			mcp.Enum(k8s.TopSortCPU, k8s.TopSortMemory)),
+		mcp.WithNumber("limit",
+			mcp.Description(fmt.Sprintf("Maximum number of rows to return (default: %d, at most %d)",
+				k8s.DefaultTopLimit, k8s.MaxTopLimit))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Show resource usage",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
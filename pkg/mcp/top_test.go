@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	ktesting "k8s.io/client-go/testing"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleTop(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-1"},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("2"),
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec: corev1.PodSpec{NodeName: "node-1", Containers: []corev1.Container{{
+				Name: "web",
+				Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("500m"),
+				}},
+			}}},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning},
+		},
+	)
+	metricsGVRs := map[schema.GroupVersionResource]string{
+		{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "pods"}:  "PodMetricsList",
+		{Group: "metrics.k8s.io", Version: "v1beta1", Resource: "nodes"}: "NodeMetricsList",
+	}
+	dynamicClient := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(), metricsGVRs)
+	// Without metrics-server
+	dynamicClient.PrependReactor("list", "*", func(ktesting.Action) (bool, runtime.Object, error) {
+		return true, nil, apierrors.NewServiceUnavailable("the server is currently unable to handle the request")
+	})
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	client.SetDynamicClient(dynamicClient)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.TopToolName
+	request.Params.Arguments = map[string]interface{}{"namespace": "shop"}
+
+	result, err := impl.HandleTop(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var pods k8s.TopPodsResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &pods))
+	assert.False(t, pods.MetricsAvailable)
+	assert.Contains(t, pods.Message, "metrics-server")
+	require.Len(t, pods.Pods, 1)
+	assert.Equal(t, "500m", pods.Pods[0].CPURequest)
+
+	request.Params.Arguments = map[string]interface{}{"kind": "nodes", "sort_by": "memory"}
+	result, err = impl.HandleTop(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok = mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var nodes k8s.TopNodesResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &nodes))
+	require.Len(t, nodes.Nodes, 1)
+	assert.Equal(t, "500m", nodes.Nodes[0].CPURequests)
+	assert.Equal(t, 1, nodes.Nodes[0].Pods)
+
+	// Invalid parameters
+	for _, arguments := range []map[string]interface{}{
+		{"kind": "services"},
+		{"kind": "nodes", "namespace": "shop"},
+		{"sort_by": "disk"},
+		{"limit": float64(k8s.MaxTopLimit + 1)},
+		{"limit": float64(0)},
+	} {
+		request.Params.Arguments = arguments
+		result, err = impl.HandleTop(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", arguments)
+	}
+}
+
+func TestNewTopTool(t *testing.T) {
+	tool := NewTopTool()
+	assert.Equal(t, types.TopToolName, tool.Name)
+	assert.Empty(t, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
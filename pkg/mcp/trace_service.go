@@ -0,0 +1,77 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleTraceService handles the trace_service tool
+func (m *Implementation) HandleTraceService(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	kind := mcp.ParseString(request, "kind", k8s.TraceKindService)
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+	// Accept kinds in any case, e.g. ingress or httproute
+	for _, supported := range []string{k8s.TraceKindService, k8s.TraceKindIngress, k8s.TraceKindHTTPRoute} {
+		if strings.EqualFold(kind, supported) {
+			kind = supported
+		}
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.TraceService(ctx, kind, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to trace service", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewTraceServiceTool creates a new trace_service tool
+func NewTraceServiceTool() mcp.Tool {
+	return mcp.NewTool(types.TraceServiceToolName,
+		mcp.WithDescription("Trace how traffic to a Service, or to the backends of an Ingress or Gateway API "+
+			"HTTPRoute, reaches pods: the Service selector, its EndpointSlices and the pods they list with their "+
+			"readiness, and the container ports the target ports resolve to, including named ports. Flags common "+
+			"misconfigurations such as a selector matching no pods, a target port not exposed by the containers, "+
+			"pods not ready or no ready endpoints. Use it to investigate 503 errors and refused connections"),
+		mcp.WithString("kind",
+			mcp.Description("Kind of the object to trace (default: Service)"),
+			mcp.Enum(k8s.TraceKindService, k8s.TraceKindIngress, k8s.TraceKindHTTPRoute)),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the object"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the object"),
+			mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Trace service connectivity",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,64 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleTraceService(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(&corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+		Spec: corev1.ServiceSpec{
+			Selector: map[string]string{"app": "web"},
+			Ports:    []corev1.ServicePort{{Port: 80}},
+		},
+	})
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.TraceServiceToolName
+	request.Params.Arguments = map[string]interface{}{"kind": "service", "namespace": "shop", "name": "web"}
+
+	result, err := impl.HandleTraceService(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var trace k8s.TraceResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &trace))
+	assert.Equal(t, k8s.TraceKindService, trace.Kind)
+	require.Len(t, trace.Issues, 1)
+	assert.Equal(t, k8s.IssueSelectorMatchesNoPods, trace.Issues[0].Type)
+
+	// Invalid parameters
+	for _, arguments := range []map[string]interface{}{
+		{"name": "web"},
+		{"namespace": "shop"},
+		{"kind": "Gateway", "namespace": "shop", "name": "web"},
+	} {
+		request.Params.Arguments = arguments
+		result, err = impl.HandleTraceService(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", arguments)
+	}
+}
+
+func TestNewTraceServiceTool(t *testing.T) {
+	tool := NewTraceServiceTool()
+	assert.Equal(t, types.TraceServiceToolName, tool.Name)
+	assert.ElementsMatch(t, []string{"namespace", "name"}, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
@@ -0,0 +1,172 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// waitForTimeoutGrace is added on top of the maximum wait timeout when
+// computing the wait_for tool timeout.
+const waitForTimeoutGrace = 15 * time.Second
+
+// HandleWaitFor handles the wait_for tool
+func (m *Implementation) HandleWaitFor(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	resourceType := mcp.ParseString(request, "resource_type", types.ResourceTypeNamespaced)
+	group := mcp.ParseString(request, "group", "")
+	version := mcp.ParseString(request, "version", "")
+	resource := mcp.ParseString(request, "resource", "")
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if resourceType != types.ResourceTypeClustered && resourceType != types.ResourceTypeNamespaced {
+		return mcp.NewToolResultError("Invalid resource_type: " + resourceType), nil
+	}
+	if version == "" {
+		return mcp.NewToolResultError("version is required"), nil
+	}
+	if resource == "" {
+		return mcp.NewToolResultError("resource is required"), nil
+	}
+	opts, err := m.parseWaitOptions(request)
+	if err != nil {
+		return mcp.NewToolResultError(err.Error()), nil
+	}
+	if resourceType == types.ResourceTypeNamespaced && namespace == "" {
+		return mcp.NewToolResultError("namespace is required for namespaced resources"), nil
+	}
+	if resourceType == types.ResourceTypeClustered {
+		namespace = ""
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    group,
+		Version:  version,
+		Resource: resource,
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	// Stop waiting as soon as the client cancels the request.
+	ctx, cancel := withRequestCancellation(ctx)
+	defer cancel()
+
+	target := name
+	if target == "" {
+		target = opts.LabelSelector
+	}
+	notifier := newProgressNotifier(ctx, request, fmt.Sprintf("%s/%s", resource, target))
+	opts.OnProgress = notifier.Notify
+
+	result, err := client.WaitFor(ctx, gvr, namespace, name, opts)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to wait", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// parseWaitOptions parses and validates what the wait_for tool waits for,
+// the name or label selector of its target and its timeout, capped at the
+// maximum wait timeout.
+func (m *Implementation) parseWaitOptions(request mcp.CallToolRequest) (k8s.WaitOptions, error) {
+	name := mcp.ParseString(request, "name", "")
+	labelSelector := mcp.ParseString(request, "label_selector", "")
+	timeout := request.GetInt("timeout", 0)
+
+	opts := k8s.WaitOptions{
+		For:             mcp.ParseString(request, "for", ""),
+		Condition:       mcp.ParseString(request, "condition", ""),
+		ConditionStatus: mcp.ParseString(request, "condition_status", ""),
+		JSONPath:        mcp.ParseString(request, "jsonpath", ""),
+		Value:           mcp.ParseString(request, "value", ""),
+		LabelSelector:   labelSelector,
+	}
+	if opts.For == "" {
+		return opts, fmt.Errorf("for is required")
+	}
+	if name == "" && labelSelector == "" {
+		return opts, fmt.Errorf("name or label_selector is required")
+	}
+	if name != "" && labelSelector != "" {
+		return opts, fmt.Errorf("name and label_selector are mutually exclusive")
+	}
+	if timeout < 0 {
+		return opts, fmt.Errorf("timeout must not be negative")
+	}
+
+	maxTimeout := m.maxWaitTimeout
+	if maxTimeout <= 0 {
+		maxTimeout = DefaultMaxWaitTimeout
+	}
+	opts.Timeout = min(time.Duration(timeout)*time.Second, maxTimeout)
+	if opts.Timeout == 0 {
+		opts.Timeout = min(k8s.DefaultWaitTimeout, maxTimeout)
+	}
+	return opts, nil
+}
+
+// NewWaitForTool creates a new wait_for tool. maxTimeout is the largest
+// timeout a call may request.
+func NewWaitForTool(maxTimeout time.Duration) mcp.Tool {
+	return mcp.NewTool(types.WaitForToolName,
+		mcp.WithDescription("Wait until a resource, or all resources matching a label selector, reach a state: "+
+			"a status condition has a given status, a JSONPath expression matches a value, the resource is "+
+			"deleted, or the rollout of a Deployment, StatefulSet or DaemonSet completes. Uses a watch and "+
+			"reports progress while waiting; a timeout returns met=false with the last observed state"),
+		mcp.WithString("resource_type",
+			mcp.Description("Type of resource (clustered or namespaced, default: namespaced)")),
+		mcp.WithString("group",
+			mcp.Description("API group (e.g., apps)")),
+		mcp.WithString("version",
+			mcp.Description("API version (e.g., v1)"),
+			mcp.Required()),
+		mcp.WithString("resource",
+			mcp.Description("Resource name (e.g., pods, deployments)"),
+			mcp.Required()),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace (required for namespaced resources)")),
+		mcp.WithString("name",
+			mcp.Description("Name of the resource to wait for")),
+		mcp.WithString("label_selector",
+			mcp.Description("Label selector of the resources to wait for, alternative to name (e.g., app=nginx)")),
+		mcp.WithString("for",
+			mcp.Description("What to wait for"),
+			mcp.Enum(k8s.WaitForCondition, k8s.WaitForJSONPath, k8s.WaitForDelete, k8s.WaitForRollout),
+			mcp.Required()),
+		mcp.WithString("condition",
+			mcp.Description("Status condition type, required with for=condition (e.g., Ready, Available)")),
+		mcp.WithString("condition_status",
+			mcp.Description("Status the condition must have (default: True)")),
+		mcp.WithString("jsonpath",
+			mcp.Description("JSONPath expression, required with for=jsonpath (e.g., {.status.phase})")),
+		mcp.WithString("value",
+			mcp.Description("Value the JSONPath expression must match (default: any non-empty value)")),
+		mcp.WithNumber("timeout",
+			mcp.Description(fmt.Sprintf("How long to wait in seconds (default: %d, maximum: %d)",
+				int(min(k8s.DefaultWaitTimeout, maxTimeout).Seconds()), int(maxTimeout.Seconds())))),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Wait for a resource state",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,146 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleWaitFor(t *testing.T) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "pods"}: "PodList"},
+		&unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "Pod",
+			"metadata":   map[string]interface{}{"name": "web-0", "namespace": "default"},
+			"status":     map[string]interface{}{"phase": "Running"},
+		}},
+	)
+	client := &k8s.Client{}
+	client.SetDynamicClient(fakeDynamic)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.WaitForToolName
+	request.Params.Arguments = map[string]interface{}{
+		"version":   "v1",
+		"resource":  "pods",
+		"namespace": "default",
+		"name":      "web-0",
+		"for":       "jsonpath",
+		"jsonpath":  "{.status.phase}",
+		"value":     "Running",
+		"timeout":   float64(5),
+	}
+
+	result, err := impl.HandleWaitFor(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var waitResult k8s.WaitResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &waitResult))
+	assert.True(t, waitResult.Met)
+	assert.Equal(t, "jsonpath", waitResult.For)
+	require.Len(t, waitResult.Objects, 1)
+	assert.Equal(t, "web-0", waitResult.Objects[0].Name)
+}
+
+func TestHandleWaitForInvalidParameters(t *testing.T) {
+	impl := NewImplementation(&k8s.Client{})
+	base := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"version": "v1", "resource": "pods", "namespace": "default", "name": "web-0", "for": "delete"}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	testCases := []struct {
+		name      string
+		arguments map[string]interface{}
+		errorMsg  string
+	}{
+		{name: "Missing version", arguments: map[string]interface{}{"resource": "pods"}, errorMsg: "version is required"},
+		{name: "Missing for", arguments: base(map[string]interface{}{"for": ""}), errorMsg: "for is required"},
+		{name: "Missing target", arguments: base(map[string]interface{}{"name": ""}), errorMsg: "name or label_selector is required"},
+		{
+			name:      "Name and selector",
+			arguments: base(map[string]interface{}{"label_selector": "app=web"}),
+			errorMsg:  "mutually exclusive",
+		},
+		{name: "Missing namespace", arguments: base(map[string]interface{}{"namespace": ""}), errorMsg: "namespace is required"},
+		{name: "Negative timeout", arguments: base(map[string]interface{}{"timeout": float64(-1)}), errorMsg: "must not be negative"},
+		{name: "Invalid resource type", arguments: base(map[string]interface{}{"resource_type": "global"}), errorMsg: "Invalid resource_type"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			request := mcp.CallToolRequest{}
+			request.Params.Name = types.WaitForToolName
+			request.Params.Arguments = tc.arguments
+
+			result, err := impl.HandleWaitFor(context.Background(), request)
+			require.NoError(t, err)
+			assert.True(t, result.IsError)
+			textContent, ok := mcp.AsTextContent(result.Content[0])
+			require.True(t, ok)
+			assert.Contains(t, textContent.Text, tc.errorMsg)
+		})
+	}
+}
+
+func TestHandleWaitForTimeoutCapped(t *testing.T) {
+	fakeDynamic := dynamicfake.NewSimpleDynamicClientWithCustomListKinds(runtime.NewScheme(),
+		map[schema.GroupVersionResource]string{{Version: "v1", Resource: "pods"}: "PodList"})
+	client := &k8s.Client{}
+	client.SetDynamicClient(fakeDynamic)
+	impl := NewImplementation(client)
+	impl.maxWaitTimeout = 200 * time.Millisecond
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.WaitForToolName
+	request.Params.Arguments = map[string]interface{}{
+		"version":   "v1",
+		"resource":  "pods",
+		"namespace": "default",
+		"name":      "missing",
+		"for":       "condition",
+		"condition": "Ready",
+		"timeout":   float64(600),
+	}
+
+	start := time.Now()
+	result, err := impl.HandleWaitFor(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var waitResult k8s.WaitResult
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &waitResult))
+	assert.False(t, waitResult.Met)
+	assert.Contains(t, waitResult.Message, "timed out after 200ms")
+	assert.Contains(t, waitResult.Message, "pods/missing not found")
+}
+
+func TestNewWaitForTool(t *testing.T) {
+	tool := NewWaitForTool(2 * time.Minute)
+	assert.Equal(t, types.WaitForToolName, tool.Name)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+	assert.Contains(t, tool.InputSchema.Properties["timeout"].(map[string]interface{})["description"], "maximum: 120")
+}
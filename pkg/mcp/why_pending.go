@@ -0,0 +1,65 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/mark3labs/mcp-go/mcp"
+
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+// HandleWhyPending handles the why_pending tool
+func (m *Implementation) HandleWhyPending(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
+	// Parse parameters
+	namespace := mcp.ParseString(request, "namespace", "")
+	name := mcp.ParseString(request, "name", "")
+
+	// Validate parameters
+	if namespace == "" {
+		return mcp.NewToolResultError("namespace is required"), nil
+	}
+	if name == "" {
+		return mcp.NewToolResultError("name is required"), nil
+	}
+
+	// Get the appropriate client (may be impersonated)
+	client, err := m.clientForContext(ctx)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to get Kubernetes client", err), nil
+	}
+
+	result, err := client.WhyPending(ctx, namespace, name)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to diagnose pod", err), nil
+	}
+
+	// Convert to JSON
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return mcp.NewToolResultErrorFromErr("Failed to marshal result", err), nil
+	}
+
+	return mcp.NewToolResultText(string(resultJSON)), nil
+}
+
+// NewWhyPendingTool creates a new why_pending tool
+func NewWhyPendingTool() mcp.Tool {
+	return mcp.NewTool(types.WhyPendingToolName,
+		mcp.WithDescription("Explain why a Pending pod cannot be scheduled. Returns its FailedScheduling events and "+
+			"a verdict for each node, evaluated independently of the scheduler: the node selector, required node "+
+			"affinity, taints and tolerations, requests versus allocatable resources minus those requested by the "+
+			"pods of the node, required pod affinity and anti-affinity, and the topology of its volumes. Problems "+
+			"affecting all nodes, such as unbound PersistentVolumeClaims and scheduling gates, are reported separately"),
+		mcp.WithString("namespace",
+			mcp.Description("Namespace of the pod"),
+			mcp.Required()),
+		mcp.WithString("name",
+			mcp.Description("Name of the pod"),
+			mcp.Required()),
+		mcp.WithToolAnnotation(mcp.ToolAnnotation{
+			Title:        "Diagnose pending pod",
+			ReadOnlyHint: BoolPtr(true),
+		}),
+	)
+}
@@ -0,0 +1,74 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/mark3labs/mcp-go/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/StacklokLabs/mkp/pkg/k8s"
+	"github.com/StacklokLabs/mkp/pkg/types"
+)
+
+func TestHandleWhyPending(t *testing.T) {
+	clientset := kubefake.NewSimpleClientset(
+		&corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{Name: "node-a"},
+			Spec: corev1.NodeSpec{Taints: []corev1.Taint{{
+				Key: "dedicated", Value: "gpu", Effect: corev1.TaintEffectNoSchedule,
+			}}},
+			Status: corev1.NodeStatus{Allocatable: corev1.ResourceList{corev1.ResourcePods: resource.MustParse("110")}},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: "shop"},
+			Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			Status:     corev1.PodStatus{Phase: corev1.PodPending},
+		},
+	)
+	client := &k8s.Client{}
+	client.SetClientset(clientset)
+	impl := NewImplementation(client)
+
+	request := mcp.CallToolRequest{}
+	request.Params.Name = types.WhyPendingToolName
+	request.Params.Arguments = map[string]interface{}{"namespace": "shop", "name": "web"}
+
+	result, err := impl.HandleWhyPending(context.Background(), request)
+	require.NoError(t, err)
+	require.False(t, result.IsError, "%v", result.Content)
+	textContent, ok := mcp.AsTextContent(result.Content[0])
+	require.True(t, ok)
+	var diagnosis k8s.SchedulingDiagnosis
+	require.NoError(t, json.Unmarshal([]byte(textContent.Text), &diagnosis))
+	assert.Equal(t, 1, diagnosis.TotalNodes)
+	assert.Equal(t, 0, diagnosis.FitNodes)
+	require.Len(t, diagnosis.Nodes, 1)
+	require.Len(t, diagnosis.Nodes[0].Reasons, 1)
+	assert.Equal(t, k8s.SchedulingReasonTaint, diagnosis.Nodes[0].Reasons[0].Type)
+
+	// Invalid parameters
+	for _, arguments := range []map[string]interface{}{
+		{"name": "web"},
+		{"namespace": "shop"},
+		{"namespace": "shop", "name": "missing"},
+	} {
+		request.Params.Arguments = arguments
+		result, err = impl.HandleWhyPending(context.Background(), request)
+		require.NoError(t, err)
+		assert.True(t, result.IsError, "%v", arguments)
+	}
+}
+
+func TestNewWhyPendingTool(t *testing.T) {
+	tool := NewWhyPendingTool()
+	assert.Equal(t, types.WhyPendingToolName, tool.Name)
+	assert.ElementsMatch(t, []string{"namespace", "name"}, tool.InputSchema.Required)
+	assert.True(t, *tool.Annotations.ReadOnlyHint)
+}
@@ -67,7 +67,22 @@ func GetRateLimiterWithConfig(config *Config) *RateLimiter {
 		WithToolLimit(types.ListResourcesToolName, config.ReadLimit),
 		WithToolLimit(types.GetResourceToolName, config.ReadLimit),
 		WithToolLimit(types.ApplyResourceToolName, config.WriteLimit),
+		WithToolLimit(types.ApplyManifestsToolName, config.WriteLimit),
+		WithToolLimit(types.KustomizeBuildToolName, config.WriteLimit),
 		WithToolLimit(types.DeleteResourceToolName, config.WriteLimit),
+		WithToolLimit(types.CopyToPodToolName, config.WriteLimit),
+		WithToolLimit(types.DebugPodToolName, config.WriteLimit),
+		WithToolLimit(types.ProbePodHTTPToolName, config.WriteLimit),
+		WithToolLimit(types.ScaleResourceToolName, config.WriteLimit),
+		// The status and history actions of rollout only read
+		WithToolLimit(types.RolloutToolName, config.ReadLimit),
+		WithActionLimit(types.RolloutToolName, types.RolloutActionRestart, config.WriteLimit),
+		WithActionLimit(types.RolloutToolName, types.RolloutActionPause, config.WriteLimit),
+		WithActionLimit(types.RolloutToolName, types.RolloutActionResume, config.WriteLimit),
+		WithActionLimit(types.RolloutToolName, types.RolloutActionUndo, config.WriteLimit),
+		WithToolLimit(types.CordonNodeToolName, config.WriteLimit),
+		WithToolLimit(types.UncordonNodeToolName, config.WriteLimit),
+		WithToolLimit(types.DrainNodeToolName, config.WriteLimit),
 	}
 
 	return NewRateLimiter(options...)
@@ -19,7 +19,7 @@ var (
 // Using a fixed window rate limiting algorithm
 type RateLimiter struct {
 	mu            sync.RWMutex
-	limits        map[string]int                       // Tool name to requests per minute
+	limits        map[string]int                       // Tool name or tool/action key to requests per minute
 	defaultLimit  int                                  // Default requests per minute
 	requestCounts map[string]map[string]*windowCounter // SessionID:[Tool:Counter] mapping
 	cleanupTicker *time.Ticker
@@ -42,6 +42,20 @@ func WithToolLimit(toolName string, requestsPerMinute int) RateLimiterOption {
 	}
 }
 
+// WithActionLimit sets the rate limit for one action of a tool, as selected by
+// its action argument. Calls with this action are counted separately from the
+// other calls of the tool.
+func WithActionLimit(toolName, action string, requestsPerMinute int) RateLimiterOption {
+	return func(rl *RateLimiter) {
+		rl.limits[actionKey(toolName, action)] = requestsPerMinute
+	}
+}
+
+// actionKey returns the key of the rate limit for an action of a tool
+func actionKey(toolName, action string) string {
+	return toolName + "/" + action
+}
+
 // WithDefaultLimit sets the default rate limit for all tools
 func WithDefaultLimit(requestsPerMinute int) RateLimiterOption {
 	return func(rl *RateLimiter) {
@@ -153,6 +167,24 @@ func (rl *RateLimiter) getCounter(sessionID, tool string) *windowCounter {
 	return rl.requestCounts[sessionID][tool]
 }
 
+// limitKey returns the key a request is counted and limited under: the
+// tool/action key when its action has its own limit, otherwise the tool name
+func (rl *RateLimiter) limitKey(request mcp.CallToolRequest) string {
+	tool := request.Params.Name
+	action := request.GetString("action", "")
+	if action == "" {
+		return tool
+	}
+
+	rl.mu.RLock()
+	defer rl.mu.RUnlock()
+
+	if _, ok := rl.limits[actionKey(tool, action)]; ok {
+		return actionKey(tool, action)
+	}
+	return tool
+}
+
 // getLimit returns the rate limit for the given tool
 func (rl *RateLimiter) getLimit(tool string) int {
 	rl.mu.RLock()
@@ -168,7 +200,7 @@ func (rl *RateLimiter) getLimit(tool string) int {
 func (rl *RateLimiter) Middleware() server.ToolHandlerMiddleware {
 	return func(next server.ToolHandlerFunc) server.ToolHandlerFunc {
 		return func(ctx context.Context, request mcp.CallToolRequest) (*mcp.CallToolResult, error) {
-			tool := request.Params.Name
+			tool := rl.limitKey(request)
 
 			// Get session ID or use tool-based identifier if session not available
 			sessionID := getSessionID(ctx, tool)
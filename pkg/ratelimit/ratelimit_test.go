@@ -9,6 +9,8 @@ import (
 	"github.com/mark3labs/mcp-go/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+
+	"github.com/StacklokLabs/mkp/pkg/types"
 )
 
 func TestRateLimiterCreation(t *testing.T) {
@@ -240,6 +242,49 @@ func TestToolSpecificLimits(t *testing.T) {
 	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Rate limit exceeded")
 }
 
+func TestActionSpecificLimits(t *testing.T) {
+	// Rate limiter with a limit for one action of a tool
+	limiter := NewRateLimiter(
+		WithDefaultLimit(1),
+		WithToolLimit("multi-action-tool", 2),
+		WithActionLimit("multi-action-tool", "write", 1),
+	)
+	middleware := limiter.Middleware()
+
+	mockHandler := new(MockToolHandler)
+	mockHandler.On("Handle", mock.Anything, mock.Anything).Return(
+		mcp.NewToolResultText("success"), nil,
+	)
+	wrappedHandler := middleware(mockHandler.Handle)
+
+	ctx := SetSessionIDToContext(context.Background(), "test-session")
+
+	request := func(action string) mcp.CallToolRequest {
+		return mcp.CallToolRequest{
+			Params: mcp.CallToolParams{
+				Name:      "multi-action-tool",
+				Arguments: map[string]interface{}{"action": action},
+			},
+		}
+	}
+
+	// The limited action has its own counter (limit = 1)
+	result, _ := wrappedHandler(ctx, request("write"))
+	assert.False(t, result.IsError)
+	result, _ = wrappedHandler(ctx, request("write"))
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Rate limit exceeded")
+
+	// Other actions share the tool limit (limit = 2)
+	result, _ = wrappedHandler(ctx, request("read"))
+	assert.False(t, result.IsError)
+	result, _ = wrappedHandler(ctx, request("other"))
+	assert.False(t, result.IsError)
+	result, _ = wrappedHandler(ctx, request("read"))
+	assert.True(t, result.IsError)
+	assert.Contains(t, result.Content[0].(mcp.TextContent).Text, "Rate limit exceeded")
+}
+
 func TestNewDefaultConfig(t *testing.T) {
 	// Test default values
 	config := NewDefaultConfig()
@@ -306,4 +351,32 @@ func TestGetRateLimiterWithConfig(t *testing.T) {
 	limiter = GetRateLimiterWithConfig(config)
 	assert.NotNil(t, limiter)
 	assert.Equal(t, 100, limiter.defaultLimit)
+
+	// Write operations share the write limit
+	for _, tool := range []string{
+		types.ApplyResourceToolName,
+		types.ApplyManifestsToolName,
+		types.KustomizeBuildToolName,
+		types.DeleteResourceToolName,
+		types.CopyToPodToolName,
+		types.DebugPodToolName,
+		types.ProbePodHTTPToolName,
+		types.ScaleResourceToolName,
+		types.CordonNodeToolName,
+		types.UncordonNodeToolName,
+		types.DrainNodeToolName,
+	} {
+		assert.Equal(t, 50, limiter.limits[tool], tool)
+	}
+
+	// Only the write actions of rollout use the write limit
+	assert.Equal(t, 200, limiter.limits[types.RolloutToolName])
+	for _, action := range []string{
+		types.RolloutActionRestart,
+		types.RolloutActionPause,
+		types.RolloutActionResume,
+		types.RolloutActionUndo,
+	} {
+		assert.Equal(t, 50, limiter.limits[actionKey(types.RolloutToolName, action)], action)
+	}
 }
@@ -20,4 +20,92 @@ const (
 
 	// PostResourceToolName is the name of the post_resource tool for tests
 	PostResourceToolName = "post_resource"
+
+	// FollowLogsToolName is the name of the follow_logs tool
+	FollowLogsToolName = "follow_logs"
+
+	// CopyFromPodToolName is the name of the copy_from_pod tool
+	CopyFromPodToolName = "copy_from_pod"
+
+	// CopyToPodToolName is the name of the copy_to_pod tool
+	CopyToPodToolName = "copy_to_pod"
+
+	// DebugPodToolName is the name of the debug_pod tool
+	DebugPodToolName = "debug_pod"
+
+	// ProbePodHTTPToolName is the name of the probe_pod_http tool
+	ProbePodHTTPToolName = "probe_pod_http"
+
+	// ScaleResourceToolName is the name of the scale_resource tool
+	ScaleResourceToolName = "scale_resource"
+
+	// RolloutToolName is the name of the rollout tool
+	RolloutToolName = "rollout"
+
+	// WaitForToolName is the name of the wait_for tool
+	WaitForToolName = "wait_for"
+
+	// CordonNodeToolName is the name of the cordon_node tool
+	CordonNodeToolName = "cordon_node"
+
+	// UncordonNodeToolName is the name of the uncordon_node tool
+	UncordonNodeToolName = "uncordon_node"
+
+	// DrainNodeToolName is the name of the drain_node tool
+	DrainNodeToolName = "drain_node"
+
+	// ApplyManifestsToolName is the name of the apply_manifests tool
+	ApplyManifestsToolName = "apply_manifests"
+
+	// KustomizeBuildToolName is the name of the kustomize_build tool
+	KustomizeBuildToolName = "kustomize_build"
+
+	// ListHelmReleasesToolName is the name of the list_helm_releases tool
+	ListHelmReleasesToolName = "list_helm_releases"
+
+	// GetHelmReleaseToolName is the name of the get_helm_release tool
+	GetHelmReleaseToolName = "get_helm_release"
+
+	// ExplainResourceToolName is the name of the explain_resource tool
+	ExplainResourceToolName = "explain_resource"
+
+	// ListAPIResourcesToolName is the name of the list_api_resources tool
+	ListAPIResourcesToolName = "list_api_resources"
+
+	// CanIToolName is the name of the can_i tool
+	CanIToolName = "can_i"
+
+	// ListPermissionsToolName is the name of the list_permissions tool
+	ListPermissionsToolName = "list_permissions"
+
+	// WhoAmIToolName is the name of the whoami tool
+	WhoAmIToolName = "whoami"
+
+	// RBACWhoCanToolName is the name of the rbac_who_can tool
+	RBACWhoCanToolName = "rbac_who_can"
+
+	// ClusterSummaryToolName is the name of the cluster_summary tool
+	ClusterSummaryToolName = "cluster_summary"
+
+	// TopToolName is the name of the top tool
+	TopToolName = "top"
+
+	// TraceServiceToolName is the name of the trace_service tool
+	TraceServiceToolName = "trace_service"
+
+	// NetworkPolicyCheckToolName is the name of the network_policy_check tool
+	NetworkPolicyCheckToolName = "network_policy_check"
+
+	// WhyPendingToolName is the name of the why_pending tool
+	WhyPendingToolName = "why_pending"
+)
+
+// Actions of the rollout tool
+const (
+	RolloutActionStatus  = "status"
+	RolloutActionHistory = "history"
+	RolloutActionRestart = "restart"
+	RolloutActionPause   = "pause"
+	RolloutActionResume  = "resume"
+	RolloutActionUndo    = "undo"
 )